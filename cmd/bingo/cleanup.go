@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/bingosuite/bingo/internal/hub"
+)
+
+// runCleanup implements `bingo cleanup`: it finds the processes a server
+// launched and then died without killing, from the PID files servers keep
+// (hub.Options.PIDDir), and kills them, or with -resume lets them run on.
+// A dead tracer's processes are already detached by the kernel, but one that
+// was stopped stays stopped until someone signals it. Returns the process
+// exit code: 0 on success, 1 when a process could not be signalled, 2 on
+// usage or I/O errors.
+func runCleanup(args []string) int {
+	defaultDir := hub.DefaultPIDDir()
+	if dir, ok := os.LookupEnv(envName("runtime-dir")); ok {
+		defaultDir = dir
+	}
+	fs := flag.NewFlagSet("cleanup", flag.ContinueOnError)
+	dir := fs.String("runtime-dir", defaultDir, "directory the server records launched processes in")
+	resume := fs.Bool("resume", false, "send SIGCONT to let orphaned processes run on, instead of SIGKILL")
+	dryRun := fs.Bool("n", false, "list the orphaned processes without signalling them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: bingo cleanup [-runtime-dir dir] [-resume] [-n]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || *dir == "" {
+		fs.Usage()
+		return 2
+	}
+
+	orphans, err := hub.PruneTargetFiles(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 2
+	}
+
+	sig, verb := syscall.SIGKILL, "killed"
+	if *resume {
+		sig, verb = syscall.SIGCONT, "resumed"
+	}
+	code := 0
+	for _, f := range orphans {
+		if *dryRun {
+			fmt.Printf("%d %s (session %s)\n", f.PID, f.Program, f.Session)
+			continue
+		}
+		if err := syscall.Kill(f.PID, sig); err != nil && !errors.Is(err, syscall.ESRCH) {
+			fmt.Fprintf(os.Stderr, "error: pid %d: %v\n", f.PID, err)
+			code = 1
+			continue
+		}
+		fmt.Printf("%s %d %s (session %s)\n", verb, f.PID, f.Program, f.Session)
+		if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	return code
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bingosuite/bingo/internal/hub"
+)
+
+func writeRecord(t *testing.T, dir string, r hub.TargetRecord) string {
+	t.Helper()
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, r.Session+".pid.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// pidDir returns an empty PID directory with the 0700 mode cleanup insists
+// on; t.TempDir's honours the umask.
+func pidDir(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "bingo")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// deadPID returns the PID of a process that has already exited.
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("true: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestCleanupKillsOnlyOrphanedTargets(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("no sleep binary")
+	}
+	start := func() *exec.Cmd {
+		cmd := exec.Command(sleep, "60")
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = cmd.Process.Kill(); _ = cmd.Wait() })
+		return cmd
+	}
+	orphan, owned := start(), start()
+	// Stopped, as a target a crashed server left at a breakpoint would be.
+	if err := orphan.Process.Signal(syscall.SIGSTOP); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := pidDir(t)
+	orphanFile := writeRecord(t, dir, hub.TargetRecord{Session: "orphan", PID: orphan.Process.Pid, ServerPID: deadPID(t), Program: sleep})
+	ownedFile := writeRecord(t, dir, hub.TargetRecord{Session: "owned", PID: owned.Process.Pid, ServerPID: os.Getpid(), Program: sleep})
+	staleFile := writeRecord(t, dir, hub.TargetRecord{Session: "stale", PID: deadPID(t), ServerPID: deadPID(t), Program: sleep})
+
+	if code := runCleanup([]string{"-runtime-dir", dir}); code != 0 {
+		t.Fatalf("runCleanup = %d, want 0", code)
+	}
+
+	waited := make(chan error, 1)
+	go func() { waited <- orphan.Wait() }()
+	select {
+	case <-waited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("orphaned target was not killed")
+	}
+	if err := owned.Process.Signal(syscall.Signal(0)); err != nil {
+		t.Errorf("target of a live server was signalled: %v", err)
+	}
+	for path, want := range map[string]bool{orphanFile: false, ownedFile: true, staleFile: false} {
+		if _, err := os.Stat(path); (err == nil) != want {
+			t.Errorf("%s exists = %t, want %t", filepath.Base(path), err == nil, want)
+		}
+	}
+}
+
+func TestCleanupSkipsReusedPID(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("no sleep binary")
+	}
+	if _, err := os.Stat("/proc/self/exe"); err != nil {
+		t.Skip("no /proc to tell processes apart")
+	}
+	cmd := exec.Command(sleep, "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = cmd.Process.Kill(); _ = cmd.Wait() })
+
+	dir := pidDir(t)
+	path := writeRecord(t, dir, hub.TargetRecord{Session: "reused", PID: cmd.Process.Pid, ServerPID: deadPID(t), Program: "/no/such/target"})
+	if code := runCleanup([]string{"-runtime-dir", dir}); code != 0 {
+		t.Fatalf("runCleanup = %d, want 0", code)
+	}
+	if err := cmd.Process.Signal(syscall.Signal(0)); err != nil {
+		t.Errorf("a process running another program was signalled: %v", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("the stale pid file was kept")
+	}
+}
+
+func TestCleanupRefusesAnOpenDirectory(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("no sleep binary")
+	}
+	cmd := exec.Command(sleep, "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = cmd.Process.Kill(); _ = cmd.Wait() })
+
+	dir := pidDir(t)
+	writeRecord(t, dir, hub.TargetRecord{Session: "planted", PID: cmd.Process.Pid, ServerPID: deadPID(t), Program: sleep})
+	// Another user could have created a directory this loose and planted
+	// the record.
+	if err := os.Chmod(dir, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if code := runCleanup([]string{"-runtime-dir", dir}); code != 2 {
+		t.Errorf("runCleanup = %d, want 2", code)
+	}
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Chmod(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(dir, link); err != nil {
+		t.Fatal(err)
+	}
+	if code := runCleanup([]string{"-runtime-dir", link}); code != 2 {
+		t.Errorf("runCleanup through a symlink = %d, want 2", code)
+	}
+	if err := cmd.Process.Signal(syscall.Signal(0)); err != nil {
+		t.Errorf("a process named by an untrusted record was signalled: %v", err)
+	}
+}
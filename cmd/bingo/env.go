@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// envPrefix namespaces the environment variables that mirror flags.
+const envPrefix = "BINGO_"
+
+// envName maps a flag name to its environment variable: -idle-timeout is
+// BINGO_IDLE_TIMEOUT.
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnv sets every flag in fs whose environment variable is present and
+// which the command line left alone, using the flag's own parser so an env
+// value means exactly what the flag would. Call it after fs.Parse: skipping
+// the flags given there, rather than letting them overwrite the env value,
+// is what gives flag > env > default for flags whose Set accumulates, such
+// as -break.
+func applyEnv(fs *flag.FlagSet, lookup func(string) (string, bool)) error {
+	given := setFlags(fs)
+	var firstErr error
+	fs.VisitAll(func(f *flag.Flag) {
+		if firstErr != nil || given[f.Name] {
+			return
+		}
+		name := envName(f.Name)
+		v, ok := lookup(name)
+		if !ok {
+			return
+		}
+		if err := f.Value.Set(v); err != nil {
+			firstErr = fmt.Errorf("%s=%q: %w", name, v, err)
+		}
+	})
+	return firstErr
+}
+
+// setFlags names the flags fs.Parse found on the command line.
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	return given
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+func newTestFlags() (*flag.FlagSet, *string, *int, *bool, *time.Duration) {
+	fs := flag.NewFlagSet("bingo", flag.ContinueOnError)
+	addr := fs.String("addr", ":6060", "")
+	logBuffer := fs.Int("log-buffer", 256, "")
+	keepAlive := fs.Bool("keep-alive", false, "")
+	idle := idleTimeoutFlag(fs)
+	return fs, addr, logBuffer, keepAlive, idle
+}
+
+func lookupFrom(env map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for flagName, want := range map[string]string{
+		"addr":         "BINGO_ADDR",
+		"idle-timeout": "BINGO_IDLE_TIMEOUT",
+		"v":            "BINGO_V",
+	} {
+		if got := envName(flagName); got != want {
+			t.Errorf("envName(%q) = %q, want %q", flagName, got, want)
+		}
+	}
+}
+
+func TestApplyEnvParsesEachFlagType(t *testing.T) {
+	fs, addr, logBuffer, keepAlive, idle := newTestFlags()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	err := applyEnv(fs, lookupFrom(map[string]string{
+		"BINGO_ADDR":         ":7070",
+		"BINGO_LOG_BUFFER":   "64",
+		"BINGO_KEEP_ALIVE":   "true",
+		"BINGO_IDLE_TIMEOUT": "1h30m",
+	}))
+	if err != nil {
+		t.Fatalf("applyEnv: %v", err)
+	}
+	if *addr != ":7070" || *logBuffer != 64 || !*keepAlive || *idle != 90*time.Minute {
+		t.Errorf("got addr=%q log-buffer=%d keep-alive=%v idle-timeout=%s", *addr, *logBuffer, *keepAlive, *idle)
+	}
+}
+
+func TestFlagsOverrideEnv(t *testing.T) {
+	fs, addr, logBuffer, _, _ := newTestFlags()
+	if err := fs.Parse([]string{"-addr", ":8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyEnv(fs, lookupFrom(map[string]string{
+		"BINGO_ADDR":       ":7070",
+		"BINGO_LOG_BUFFER": "64",
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if *addr != ":8080" {
+		t.Errorf("flag should win over env, addr = %q", *addr)
+	}
+	if *logBuffer != 64 {
+		t.Errorf("env should still apply to flags not given, log-buffer = %d", *logBuffer)
+	}
+}
+
+// -break accumulates, so the env list would survive alongside the
+// command line's if the flag were merely applied after it.
+func TestBreakFlagReplacesEnv(t *testing.T) {
+	fs := flag.NewFlagSet("bingo", flag.ContinueOnError)
+	bps := breakpointsFlag(fs)
+	if err := fs.Parse([]string{"-break", "main.go:10"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyEnv(fs, lookupFrom(map[string]string{"BINGO_BREAK": "util.go:5,util.go:6"})); err != nil {
+		t.Fatal(err)
+	}
+	if want := []protocol.Location{{File: "main.go", Line: 10}}; !slices.Equal(*bps, want) {
+		t.Errorf("-break with BINGO_BREAK = %v, want %v", *bps, want)
+	}
+
+	fs = flag.NewFlagSet("bingo", flag.ContinueOnError)
+	bps = breakpointsFlag(fs)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyEnv(fs, lookupFrom(map[string]string{"BINGO_BREAK": "util.go:5,util.go:6"})); err != nil {
+		t.Fatal(err)
+	}
+	if len(*bps) != 2 {
+		t.Errorf("BINGO_BREAK alone = %v, want both locations", *bps)
+	}
+}
+
+func TestBadBreakAddsNothing(t *testing.T) {
+	fs := flag.NewFlagSet("bingo", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	bps := breakpointsFlag(fs)
+	if err := fs.Parse([]string{"-break", "main.go:10,util.go:x"}); err == nil {
+		t.Fatal("a bad -break entry was accepted")
+	}
+	if len(*bps) != 0 {
+		t.Errorf("a rejected -break kept %v", *bps)
+	}
+}
+
+func TestStopAtMainFromEnvLosesToInitialStopFlag(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		mode       string
+		stopAtMain bool
+		given      map[string]bool
+		want       protocol.InitialStop
+	}{
+		{"env shorthand, flag mode", "run", true, map[string]bool{"initial-stop": true}, protocol.InitialStopRun},
+		{"flag shorthand, env mode", "run", true, map[string]bool{"stop-at-main": true}, protocol.InitialStopMain},
+		{"env shorthand alone", "stop", true, nil, protocol.InitialStopMain},
+		{"no shorthand", "run", false, nil, protocol.InitialStopRun},
+	} {
+		if got := initialStopMode(tc.mode, tc.stopAtMain, tc.given); got != tc.want {
+			t.Errorf("%s: initialStopMode = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestUnsetEnvKeepsDefaults(t *testing.T) {
+	fs, addr, logBuffer, _, _ := newTestFlags()
+	if err := applyEnv(fs, lookupFrom(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if *addr != ":6060" || *logBuffer != 256 {
+		t.Errorf("defaults changed: addr=%q log-buffer=%d", *addr, *logBuffer)
+	}
+}
+
+func TestApplyEnvRejectsBadValues(t *testing.T) {
+	for name, value := range map[string]string{
+		"BINGO_LOG_BUFFER":   "lots",
+		"BINGO_KEEP_ALIVE":   "maybe",
+		"BINGO_IDLE_TIMEOUT": "soon",
+	} {
+		fs, _, _, _, _ := newTestFlags()
+		if err := applyEnv(fs, lookupFrom(map[string]string{name: value})); err == nil {
+			t.Errorf("%s=%q: expected an error", name, value)
+		}
+	}
+}
+
+// Durations come in as Go duration strings from both the command line and
+// the environment; a bare number is rejected rather than read as nanoseconds.
+func TestIdleTimeoutDurationForms(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"30m":    30 * time.Minute,
+		"1h30m":  90 * time.Minute,
+		"90s":    90 * time.Second,
+		"1.5h":   90 * time.Minute,
+		"0":      0,
+		"250ms":  250 * time.Millisecond,
+		"2h0m0s": 2 * time.Hour,
+		// Bare integers are nanoseconds, as they were for a plain
+		// time.Duration.
+		"3600000000000": time.Hour,
+		"3600":          3600 * time.Nanosecond,
+	} {
+		fs, _, _, _, idle := newTestFlags()
+		if err := applyEnv(fs, lookupFrom(map[string]string{"BINGO_IDLE_TIMEOUT": in})); err != nil {
+			t.Errorf("env %q: %v", in, err)
+			continue
+		}
+		if *idle != want {
+			t.Errorf("env %q = %s, want %s", in, *idle, want)
+		}
+
+		fs, _, _, _, idle = newTestFlags()
+		if err := fs.Parse([]string{"-idle-timeout", in}); err != nil {
+			t.Errorf("flag %q: %v", in, err)
+			continue
+		}
+		if *idle != want {
+			t.Errorf("flag %q = %s, want %s", in, *idle, want)
+		}
+	}
+
+	for _, in := range []string{"30 minutes", "1.5", "h"} {
+		fs, _, _, _, _ := newTestFlags()
+		if err := applyEnv(fs, lookupFrom(map[string]string{"BINGO_IDLE_TIMEOUT": in})); err == nil {
+			t.Errorf("env %q should be rejected", in)
+		}
+	}
+}
@@ -1,24 +1,72 @@
-// Command bingo starts the bingo debug server.
+// Command bingo starts the bingo debug server, checks a target binary's
+// debuggability without launching it, or cleans up after a crashed server.
 //
-//	bingo [-addr host:port] [-dap-addr host:port] [-v]
+//	bingo [-addr host:port] [-dap-addr host:port] [-goroutine-snapshots [-leak-threshold n] [-leak-window n]] [-log-buffer n] [-initial-stop stop|continue-to-main|run] [-hit-context] [-thread-events] [-pty] [-inherit-stdin] [-verify-breakpoints] [-stopped-threads] [-block-runtime-breakpoints] [-idle-timeout d] [-keep-alive [-disconnect-grace d] [-disconnect-action continue|end]] [-retention d] [-break file:line] [-tls-cert file -tls-key file] [-runtime-dir dir] [-v]
+//	bingo validate [-json] <binary>
+//	bingo cleanup [-runtime-dir dir] [-resume] [-n]
+//
+// Every server flag can also come from a BINGO_<FLAG> environment variable
+// (-idle-timeout is BINGO_IDLE_TIMEOUT); a flag on the command line wins.
 package main
 
 import (
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/internal/hub"
 	"github.com/bingosuite/bingo/internal/server"
+	"github.com/bingosuite/bingo/pkg/protocol"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "cleanup" {
+		os.Exit(runCleanup(os.Args[2:]))
+	}
+
 	addr := flag.String("addr", ":6060", "listen address (host:port)")
 	dapAddr := flag.String("dap-addr", "", "DAP listen address (host:port); empty disables the DAP server")
+	snapshots := flag.Bool("goroutine-snapshots", false, "broadcast a goroutine snapshot on every stop")
+	leakThreshold := flag.Int("leak-threshold", 0, "with -goroutine-snapshots, warn when the goroutine count grows by this much without falling across -leak-window stops; 0 disables")
+	leakWindow := flag.Int("leak-window", 0, "stops -leak-threshold looks back over (0 = 5)")
+	logBuffer := flag.Int("log-buffer", 256, "per-session log entries kept for clients' logs command")
+	initialStop := flag.String("initial-stop", string(protocol.InitialStopEntry), "what launched programs do at their first instruction: stop, continue-to-main, or run (no stop until a breakpoint); a launch can choose its own")
+	stopAtMain := flag.Bool("stop-at-main", false, "shorthand for -initial-stop continue-to-main")
+	hitContext := flag.Bool("hit-context", false, "include the stopping thread's PC/SP/BP in every breakpoint-hit event")
+	threadEvents := flag.Bool("thread-events", false, "stream tracee thread start/exit events while the program runs")
+	usePTY := flag.Bool("pty", false, "launch programs on their own pseudo-terminal; output and input go through clients")
+	inheritStdin := flag.Bool("inherit-stdin", false, "let launched programs read the server's stdin instead of /dev/null")
+	verifyTraps := flag.Bool("verify-breakpoints", false, "read every breakpoint trap back after writing it and fail if it didn't take")
+	stoppedThreads := flag.Bool("stopped-threads", false, "list every stopped thread, and where, in breakpoint-hit events")
+	blockRuntimeBPs := flag.Bool("block-runtime-breakpoints", false, "refuse breakpoints in runtime functions that can deadlock the program, instead of warning")
+	maxSlice := flag.Int("max-slice-elements", 0, "most elements a slice read returns (0 = 256)")
+	idleTimeout := idleTimeoutFlag(flag.CommandLine)
+	keepAlive := flag.Bool("keep-alive", false, "keep sessions running after their last client disconnects")
+	disconnectGrace := flag.Duration("disconnect-grace", 0, "with -keep-alive, how long a session without clients waits for one before -disconnect-action; 0 waits forever")
+	disconnectAction := flag.String("disconnect-action", "continue", "what a session does once -disconnect-grace runs out: continue (resume a stopped program) or end")
+	retention := flag.Duration("retention", 0, "keep ended sessions listed, with their exit code and logs, for this long before removing them; 0 removes them at once")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file; with -tls-key, serves https/wss")
+	tlsKey := flag.String("tls-key", "", "TLS private key file")
+	runtimeDir := flag.String("runtime-dir", hub.DefaultPIDDir(), "directory launched programs' PID files are kept in, for bingo cleanup; empty disables them")
 	verbose := flag.Bool("v", false, "enable verbose (debug) logging")
+	defaultBPs := breakpointsFlag(flag.CommandLine)
 	flag.Parse()
+	given := setFlags(flag.CommandLine)
+	if err := applyEnv(flag.CommandLine, os.LookupEnv); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid environment: %v\n", err)
+		os.Exit(2)
+	}
+	*initialStop = string(initialStopMode(*initialStop, *stopAtMain, given))
 
 	level := slog.LevelInfo
 	if *verbose {
@@ -28,7 +76,57 @@ func main() {
 		Level: level,
 	}))
 
-	srv := server.New(*addr, log)
+	opts := server.Options{
+		Session: hub.Options{
+			GoroutineSnapshots:      *snapshots,
+			LogBufferSize:           *logBuffer,
+			IdleTimeout:             *idleTimeout,
+			KeepAliveWithoutClients: *keepAlive,
+			DisconnectGrace:         *disconnectGrace,
+			DisconnectAction:        hub.DisconnectAction(*disconnectAction),
+			DefaultBreakpoints:      *defaultBPs,
+			LeakThreshold:           *leakThreshold,
+			LeakWindow:              *leakWindow,
+			PIDDir:                  *runtimeDir,
+		},
+		Debugger: debugger.Options{
+			InitialStop:             protocol.InitialStop(*initialStop),
+			HitContext:              *hitContext,
+			MaxSliceElements:        *maxSlice,
+			ThreadEvents:            *threadEvents,
+			PTY:                     *usePTY,
+			VerifyTraps:             *verifyTraps,
+			StoppedThreads:          *stoppedThreads,
+			BlockRuntimeBreakpoints: *blockRuntimeBPs,
+			InheritStdin:            *inheritStdin,
+		},
+		Retention:   *retention,
+		TLSCertFile: *tlsCert,
+		TLSKeyFile:  *tlsKey,
+	}
+	// An empty -addr would make net/http fall back to :80.
+	if *addr == "" {
+		log.Error("invalid configuration", "err", "-addr must not be empty")
+		os.Exit(2)
+	}
+	if err := opts.Validate(); err != nil {
+		log.Error("invalid configuration", "err", err)
+		os.Exit(2)
+	}
+
+	if *runtimeDir != "" {
+		// Only a crashed server leaves PID files behind; pruning the ones
+		// whose process is gone too keeps the directory from filling up.
+		orphans, err := hub.PruneTargetFiles(*runtimeDir)
+		if err != nil {
+			log.Warn("failed to check for orphaned processes", "err", err)
+		}
+		for _, o := range orphans {
+			log.Warn("process left behind by a crashed server; run bingo cleanup", "pid", o.PID, "program", o.Program, "session", o.Session)
+		}
+	}
+
+	srv := server.NewWithOptions(*addr, opts, log)
 
 	if *dapAddr != "" {
 		if err := srv.StartDAP(*dapAddr); err != nil {
@@ -37,17 +135,93 @@ func main() {
 		}
 	}
 
-	sigCh := make(chan os.Signal, 1)
+	sigCh := make(chan os.Signal, 2)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	// Start returns as soon as the listener closes, but the sessions are
+	// still killing their debuggees then; main must not exit before
+	// Shutdown has finished or those processes are left traced/orphaned.
+	stopped := make(chan struct{})
 	go func() {
 		<-sigCh
-		log.Info("received shutdown signal")
+		log.Info("received shutdown signal; stopping sessions (signal again to force exit)")
+		go func() {
+			<-sigCh
+			log.Warn("forced exit")
+			os.Exit(1)
+		}()
 		srv.Shutdown(10 * time.Second)
+		close(stopped)
 	}()
 
 	if err := srv.Start(); err != nil {
 		log.Error("server error", "err", err)
 		os.Exit(1)
 	}
+	<-stopped
+	log.Info("server stopped")
+}
+
+// breakpointsFlag registers -break on fs. Each use adds to the list, so it
+// can be repeated.
+func breakpointsFlag(fs *flag.FlagSet) *[]protocol.Location {
+	var locs []protocol.Location
+	fs.Func("break", "file:line breakpoint set in every session (repeatable, or comma-separated)", func(v string) error {
+		more, err := parseBreakpoints(v)
+		if err != nil {
+			return err
+		}
+		locs = append(locs, more...)
+		return nil
+	})
+	return &locs
+}
+
+// idleTimeoutFlag registers -idle-timeout on fs. Besides Go durations such
+// as 30m or 1h30m it takes a bare integer as nanoseconds, the form configs
+// written against a plain time.Duration used.
+func idleTimeoutFlag(fs *flag.FlagSet) *time.Duration {
+	var d time.Duration
+	fs.Func("idle-timeout", "shut a session down after this long without client activity, e.g. 30m (a bare number is nanoseconds); 0 disables", func(v string) error {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			d = time.Duration(n)
+			return nil
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		d = parsed
+		return nil
+	})
+	return &d
+}
+
+// initialStopMode applies -stop-at-main, the shorthand for continue-to-main,
+// to the -initial-stop value. given is setFlags' record of the command line:
+// the shorthand from the environment must not beat an -initial-stop given
+// there.
+func initialStopMode(mode string, stopAtMain bool, given map[string]bool) protocol.InitialStop {
+	if stopAtMain && (given["stop-at-main"] || !given["initial-stop"]) {
+		return protocol.InitialStopMain
+	}
+	return protocol.InitialStop(mode)
+}
+
+// parseBreakpoints parses a comma-separated list of file:line locations.
+func parseBreakpoints(v string) ([]protocol.Location, error) {
+	var locs []protocol.Location
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		i := strings.LastIndex(item, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("%q: want file:line", item)
+		}
+		line, err := strconv.Atoi(item[i+1:])
+		if err != nil || line <= 0 {
+			return nil, fmt.Errorf("%q: line must be a positive number", item)
+		}
+		locs = append(locs, protocol.Location{File: item[:i], Line: line})
+	}
+	return locs, nil
 }
@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestParseBreakpoints(t *testing.T) {
+	locs, err := parseBreakpoints("main.go:10, pkg/util.go:5")
+	if err != nil {
+		t.Fatalf("parseBreakpoints: %v", err)
+	}
+	if len(locs) != 2 || locs[0].File != "main.go" || locs[0].Line != 10 ||
+		locs[1].File != "pkg/util.go" || locs[1].Line != 5 {
+		t.Errorf("got %+v", locs)
+	}
+	for _, bad := range []string{"main.go", ":10", "main.go:0", "main.go:x", "main.go:10,"} {
+		if _, err := parseBreakpoints(bad); err == nil {
+			t.Errorf("parseBreakpoints(%q): expected an error", bad)
+		}
+	}
+}
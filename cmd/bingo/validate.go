@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bingosuite/bingo/internal/debugger"
+)
+
+// runValidate implements `bingo validate`. Returns the process exit code:
+// 0 when the binary looks debuggable, 1 when problems were found, 2 on usage
+// or I/O errors.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: bingo validate [-json] <binary>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	report, err := debugger.Validate(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 2
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 2
+		}
+	} else {
+		printReport(report)
+	}
+
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+func printReport(r debugger.ValidationReport) {
+	fmt.Printf("%s\n", r.Path)
+	fmt.Printf("  format:       %s (%s)\n", r.Format, r.Arch)
+	fmt.Printf("  PIE:          %t\n", r.PIE)
+	fmt.Printf("  Go version:   %s\n", orNone(r.GoVersion))
+	fmt.Printf("  debug info:   %t\n", r.HasDebugInfo)
+	fmt.Printf("  main.main:    %t\n", r.HasMainMain)
+	fmt.Printf("  source files: %d\n", r.SourceFiles)
+	if r.OK() {
+		fmt.Println("  ok")
+		return
+	}
+	for _, p := range r.Problems {
+		fmt.Printf("  problem: %s\n", p)
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(unknown)"
+	}
+	return s
+}
@@ -0,0 +1,548 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bingosuite/bingo/pkg/client"
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// errQuit is what exec returns for quit; the caller decides how to leave.
+var errQuit = errors.New("quit")
+
+// usageError is a malformed command line. Interactive mode prints it as a
+// hint rather than an error; script mode fails on it like any other error.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// resuming are the commands that set the debuggee running. Script mode waits
+// for the next stop after each one so the following command sees a
+// suspended (or exited) process, as a person at the prompt would.
+var resuming = map[string]bool{
+	"launch": true, "attach": true, "restart": true,
+	"c": true, "continue": true,
+	"n": true, "next": true,
+	"s": true, "step": true,
+	"out": true, "finish": true,
+	"si": true, "stepi": true,
+}
+
+// session is the state every command runs against.
+type session struct {
+	c    client.Client
+	addr string
+	opts client.Options
+	dash *dashboard
+
+	// maxRunTime and initialStop are passed on every launch (-max-run-time,
+	// -initial-stop).
+	maxRunTime  time.Duration
+	initialStop protocol.InitialStop
+}
+
+// exec runs one command line already split into fields. It is shared by the
+// interactive prompt and -command so both accept exactly the same commands.
+//
+//nolint:gocognit,gocyclo // The CLI keeps command routing in one switch while commands are still small.
+func (s *session) exec(args []string) error {
+	c := s.c
+	cmd := args[0]
+	switch cmd {
+
+	case "sessions", "ls":
+		sessions, err := client.ListSessionsWithOptions(s.addr, s.opts)
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			fmt.Println("  (no active sessions)")
+			return nil
+		}
+		for _, info := range sessions {
+			fmt.Printf("  %s  state=%-10s clients=%d  created=%s  idle=%s",
+				info.ID, info.State, info.Clients, info.CreatedAt.Format("15:04:05"),
+				time.Since(info.LastActivity).Round(time.Second))
+			if !info.FinishedAt.IsZero() {
+				fmt.Printf("  finished=%s", info.FinishedAt.Format("15:04:05"))
+				if info.ExitCode != nil {
+					fmt.Printf(" exit=%d", *info.ExitCode)
+				}
+				if loc := info.LastLocation; loc != nil {
+					fmt.Printf(" last=%s:%d", loc.File, loc.Line)
+				}
+			}
+			fmt.Println()
+		}
+
+	case "state":
+		fmt.Printf("  session=%s  state=%s\n", c.SessionID(), c.State())
+		if n := c.MissedEvents(); n > 0 {
+			fmt.Printf("  warning: %d event(s) missed\n", n)
+		}
+
+	case "dash", "d":
+		s.dash.render(os.Stdout, c)
+
+	case "launch":
+		if len(args) < 2 {
+			return usageError("usage: launch <binary> [args...]")
+		}
+		var launchArgs []string
+		if len(args) > 2 {
+			launchArgs = args[2:]
+		}
+		if err := c.LaunchWith(protocol.LaunchPayload{
+			Program: args[1], Args: launchArgs, MaxRunTime: s.maxRunTime,
+			InitialStop: s.initialStop,
+		}); err != nil {
+			return err
+		}
+
+	case "attach":
+		if len(args) < 2 {
+			return usageError("usage: attach <pid> [binary-path]")
+		}
+		pid, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid pid: %s", args[1])
+		}
+		var binPath string
+		if len(args) > 2 {
+			binPath = args[2]
+		}
+		if err := c.Attach(pid, binPath); err != nil {
+			return err
+		}
+
+	case "kill":
+		if err := c.Kill(); err != nil {
+			return err
+		}
+
+	case "restart":
+		p, err := c.Restart(nil, nil)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  restarted %s\n", p.Program)
+		for _, bp := range p.Breakpoints {
+			fmt.Printf("  breakpoint %d reinstalled at %s:%d\n", bp.ID, bp.Location.File, bp.Location.Line)
+		}
+		for _, d := range p.Discarded {
+			fmt.Printf("  breakpoint at %s:%d discarded: %s\n", d.Location.File, d.Location.Line, d.Reason)
+		}
+
+	case "c", "continue":
+		switch {
+		case len(args) == 1 || args[1] == "all":
+			if err := c.Continue(); err != nil {
+				return err
+			}
+		case args[1] == "thread":
+			if err := c.ContinueThread(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("usage: continue [thread|all]")
+		}
+
+	case "n", "next":
+		n, err := stepCount(args)
+		if err != nil {
+			return err
+		}
+		if err := c.StepOverN(n); err != nil {
+			return err
+		}
+
+	case "s", "step":
+		n, err := stepCount(args)
+		if err != nil {
+			return err
+		}
+		if err := c.StepIntoN(n); err != nil {
+			return err
+		}
+
+	case "out", "finish":
+		if err := c.StepOut(); err != nil {
+			return err
+		}
+
+	case "si", "stepi":
+		if err := c.StepInstruction(); err != nil {
+			return err
+		}
+
+	case "p", "pause":
+		if err := c.Pause(); err != nil {
+			return err
+		}
+
+	case "input":
+		if len(args) < 2 {
+			return usageError("usage: input <text>  (sent with a trailing newline)")
+		}
+		if err := c.Input(strings.Join(args[1:], " ") + "\n"); err != nil {
+			return err
+		}
+
+	case "b", "break":
+		if len(args) < 2 {
+			return usageError("usage: break <file>:<line>|<func>[+<n>|:return] [action, ...]")
+		}
+		file, line, ok := parseFileLine(args[1])
+		if !ok {
+			fn, offset, atReturn, isFunc := parseFuncLine(args[1])
+			if !isFunc {
+				return usageError("usage: break <file>:<line>|<func>[+<n>|:return]  (e.g. main.go:42, main.worker+3)")
+			}
+			r, err := c.ResolveFunction(fn, offset, atReturn)
+			if err != nil {
+				return err
+			}
+			file, line = r.Location.File, r.Location.Line
+		}
+		bp, err := c.SetBreakpoint(file, line, parseActions(args[2:])...)
+		if errors.Is(err, client.ErrBreakpointExists) {
+			fmt.Printf("  breakpoint %d already set at %s:%d\n",
+				bp.ID, bp.Location.File, bp.Location.Line)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  breakpoint %d set at %s:%d\n",
+			bp.ID, bp.Location.File, bp.Location.Line)
+		for _, a := range bp.Actions {
+			fmt.Printf("    on hit: %s\n", a)
+		}
+
+	case "clear":
+		if len(args) < 2 {
+			return usageError("usage: clear <breakpoint-id>|all")
+		}
+		if args[1] == "all" {
+			n, err := c.ClearAllBreakpoints()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("  %d breakpoint(s) cleared\n", n)
+			break
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid breakpoint id: %s", args[1])
+		}
+		if err := c.ClearBreakpoint(id); err != nil {
+			return err
+		}
+		fmt.Printf("  breakpoint %d cleared\n", id)
+
+	case "ignore":
+		if len(args) < 3 {
+			return usageError("usage: ignore <breakpoint-id> <count>")
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid breakpoint id: %s", args[1])
+		}
+		count, err := strconv.Atoi(args[2])
+		if err != nil || count < 0 {
+			return fmt.Errorf("invalid ignore count: %s", args[2])
+		}
+		bp, err := c.SetIgnoreCount(id, count)
+		if err != nil {
+			return err
+		}
+		if bp.IgnoreCount == 0 {
+			fmt.Printf("  breakpoint %d will stop on its next hit\n", bp.ID)
+			break
+		}
+		fmt.Printf("  breakpoint %d will skip its next %d hit(s)\n", bp.ID, bp.IgnoreCount)
+
+	case "disable", "enable":
+		toggle := c.DisableAllBreakpoints
+		if args[0] == "enable" {
+			toggle = c.EnableAllBreakpoints
+		}
+		bps, err := toggle()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %d breakpoint(s) %sd\n", len(bps), args[0])
+
+	case "resolve":
+		if len(args) < 2 {
+			return usageError("usage: resolve <file>:<line>|<func>[+<n>|:return]")
+		}
+		if file, line, ok := parseFileLine(args[1]); ok {
+			r, err := c.ResolveLine(file, line)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("  %s:%d -> %#x  (%s:%d in %s)\n",
+				file, line, r.PC, r.Location.File, r.Location.Line, r.Location.Function)
+			break
+		}
+		fn, offset, atReturn, ok := parseFuncLine(args[1])
+		if !ok {
+			return usageError("usage: resolve <file>:<line>|<func>[+<n>|:return]  (e.g. main.go:42, main.worker:return)")
+		}
+		r, err := c.ResolveFunction(fn, offset, atReturn)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s -> %#x  (%s:%d)\n", args[1], r.PC, r.Location.File, r.Location.Line)
+
+	case "file":
+		if len(args) < 2 {
+			return usageError("usage: file <file>")
+		}
+		var (
+			offset int64
+			line   = 1
+		)
+		for {
+			chunk, err := c.GetFile(args[1], offset, 0)
+			if err != nil {
+				return err
+			}
+			for _, text := range strings.SplitAfter(chunk.Data, "\n") {
+				if text == "" {
+					continue
+				}
+				fmt.Printf("  %5d  %s", line, text)
+				if !strings.HasSuffix(text, "\n") {
+					fmt.Println()
+				}
+				line++
+			}
+			if !chunk.More {
+				break
+			}
+			offset += int64(len(chunk.Data))
+		}
+
+	case "addr":
+		if len(args) < 2 {
+			return usageError("usage: addr <pc>  (hex, e.g. 0x4a1f20)")
+		}
+		pc, err := strconv.ParseUint(strings.TrimPrefix(args[1], "0x"), 16, 64)
+		if err != nil {
+			return fmt.Errorf("invalid address: %s", args[1])
+		}
+		loc, err := c.AddrToLine(pc)
+		if err != nil {
+			return err
+		}
+		if loc.File == "" {
+			fmt.Printf("  %#x  %s\n", pc, loc.Function)
+			return nil
+		}
+		fmt.Printf("  %#x  %s at %s:%d\n", pc, loc.Function, loc.File, loc.Line)
+
+	case "str":
+		if len(args) < 2 {
+			return usageError("usage: str <addr>  (hex address of a string header)")
+		}
+		addr, err := strconv.ParseUint(strings.TrimPrefix(args[1], "0x"), 16, 64)
+		if err != nil {
+			return fmt.Errorf("invalid address: %s", args[1])
+		}
+		v, err := c.ReadString(addr)
+		if err != nil {
+			return err
+		}
+		if v.Truncated {
+			fmt.Printf("  %q... (%d of %d bytes)\n", v.Value, len(v.Value), v.Len)
+			return nil
+		}
+		fmt.Printf("  %q\n", v.Value)
+
+	case "slice":
+		if len(args) < 3 {
+			return usageError("usage: slice <addr> <elem-size>  (hex address of a slice header, element size in bytes)")
+		}
+		addr, err := strconv.ParseUint(strings.TrimPrefix(args[1], "0x"), 16, 64)
+		if err != nil {
+			return fmt.Errorf("invalid address: %s", args[1])
+		}
+		size, err := strconv.Atoi(args[2])
+		if err != nil {
+			return fmt.Errorf("invalid element size: %s", args[2])
+		}
+		v, err := c.ReadSlice(addr, size)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  data=%#x len=%d cap=%d\n", v.Data, v.Len, v.Cap)
+		for i, el := range v.Elements {
+			fmt.Printf("    [%d] %s\n", i, el)
+		}
+		if v.Truncated {
+			fmt.Printf("    ... %d more\n", v.Len-uint64(len(v.Elements)))
+		}
+
+	case "locals":
+		frame := 0
+		if len(args) > 1 {
+			frame, _ = strconv.Atoi(args[1])
+		}
+		vars, err := c.Locals(frame)
+		if err != nil {
+			return err
+		}
+		if len(vars) == 0 {
+			fmt.Println("  (no locals)")
+			return nil
+		}
+		for _, v := range vars {
+			fmt.Printf("  %s %s = %s\n", v.Name, v.Type, v.Value)
+		}
+
+	case "args":
+		p, err := c.InspectArgs()
+		if err != nil {
+			return err
+		}
+		if len(p.Args) == 0 {
+			fmt.Printf("  (%s takes no arguments)\n", p.Function)
+			return nil
+		}
+		fmt.Printf("  %s\n", p.Function)
+		for _, v := range p.Args {
+			fmt.Printf("  %s %s = %s\n", v.Name, v.Type, v.Value)
+		}
+
+	case "bt", "backtrace":
+		frames, err := c.StackFrames()
+		if err != nil {
+			return err
+		}
+		for _, f := range frames {
+			fmt.Printf("  #%d  %s at %s:%d\n",
+				f.Index, f.Location.Function, f.Location.File, f.Location.Line)
+		}
+
+	case "goroutines", "grs":
+		grs, err := c.Goroutines()
+		if err != nil {
+			return err
+		}
+		for _, g := range grs {
+			loc := fmt.Sprintf("%s:%d", g.CurrentLoc.File, g.CurrentLoc.Line)
+			if g.WaitReason != "" {
+				fmt.Printf("  G%-4d %-10s %s  (%s)\n", g.ID, g.Status, loc, g.WaitReason)
+			} else {
+				fmt.Printf("  G%-4d %-10s %s\n", g.ID, g.Status, loc)
+			}
+		}
+
+	case "caps", "capabilities":
+		p, err := c.Capabilities()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  state %s, valid now:", p.State)
+		for _, k := range p.Valid {
+			fmt.Printf(" %s", k)
+		}
+		fmt.Println()
+
+	case "ping":
+		rtt, err := c.Ping()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  session answered in %s\n", rtt.Round(time.Microsecond))
+
+	case "status":
+		p, err := c.Status()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  session %s, process %s", p.Session, p.Process)
+		if p.PID != 0 {
+			fmt.Printf(" (pid %d)", p.PID)
+		}
+		if p.LoadBase != 0 {
+			kind := "non-PIE"
+			if p.PIE {
+				kind = "PIE"
+			}
+			fmt.Printf(", %s loaded at 0x%x", kind, p.LoadBase)
+		}
+		if p.Process == protocol.StateSuspended {
+			fmt.Printf(" at pc 0x%x on thread %d", p.PC, p.TID)
+		}
+		if p.Pending {
+			fmt.Print(" (a pause or step is under way)")
+		}
+		fmt.Println()
+		if !p.CreatedAt.IsZero() {
+			fmt.Printf("  up %s\n", time.Since(p.CreatedAt).Round(time.Second))
+		}
+
+	case "logs":
+		limit := 0
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 0 {
+				return usageError("usage: logs [n]  (n: how many recent lines, default all)")
+			}
+			limit = n
+		}
+		entries, err := c.Logs(limit)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			fmt.Printf("  %s %-5s %s", e.Time.Format("15:04:05.000"), e.Level, e.Message)
+			for _, k := range sortedKeys(e.Attrs) {
+				fmt.Printf(" %s=%s", k, e.Attrs[k])
+			}
+			fmt.Println()
+		}
+
+	case "help", "h", "?":
+		printHelp()
+
+	case "quit", "q", "exit":
+		return errQuit
+
+	default:
+		return fmt.Errorf("unknown command: %s (type 'help' for usage)", cmd)
+	}
+	return nil
+}
+
+// stepCount reads the optional count of next/step, defaulting to 1.
+func stepCount(args []string) (int, error) {
+	if len(args) < 2 {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid step count: %s", args[1])
+	}
+	return n, nil
+}
+
+// parseActions turns the words after a breakpoint's location into its action
+// list: "print x, print y, continue" is three actions.
+func parseActions(words []string) []string {
+	var actions []string
+	for _, a := range strings.Split(strings.Join(words, " "), ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
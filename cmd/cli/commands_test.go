@@ -0,0 +1,16 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLogsRejectsBadCount(t *testing.T) {
+	s := &session{c: &fakeClient{}}
+	for _, n := range []string{"x", "-1", "10lines"} {
+		var usage usageError
+		if err := s.exec([]string{"logs", n}); !errors.As(err, &usage) {
+			t.Errorf("logs %s = %v, want a usage error", n, err)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/bingosuite/bingo/pkg/client"
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// sourceContext is how many lines either side of the current line dash shows.
+const sourceContext = 3
+
+// dashboard is the client-side picture of the session that the dash command
+// renders. It is fed from the event stream rather than queried, because the
+// protocol has no "list breakpoints" command and the stop location only
+// arrives with the suspending event.
+type dashboard struct {
+	mu          sync.Mutex
+	location    *protocol.Location
+	breakpoints map[int]protocol.Breakpoint
+}
+
+func newDashboard() *dashboard {
+	return &dashboard{breakpoints: make(map[int]protocol.Breakpoint)}
+}
+
+// observe updates the view from one event. Unrelated kinds are ignored.
+//
+//nolint:gocyclo // One case per event kind that moves the view.
+func (d *dashboard) observe(evt protocol.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch evt.Kind {
+	case protocol.EventSessionState:
+		var p protocol.SessionStatePayload
+		if protocol.DecodeEventPayload(evt, &p) != nil {
+			return
+		}
+		// Only the welcome copy carries the last-known view; plain
+		// transitions leave both fields empty and must not wipe ours.
+		if p.Location != nil {
+			loc := *p.Location
+			d.location = &loc
+		}
+		for _, bp := range p.Breakpoints {
+			d.breakpoints[bp.ID] = bp
+		}
+
+	case protocol.EventBreakpointHit:
+		var p protocol.BreakpointHitPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			d.setLocation(p.Breakpoint.Location)
+		}
+	case protocol.EventStepped:
+		var p protocol.SteppedPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			d.setLocation(p.Location)
+		}
+	case protocol.EventPaused:
+		var p protocol.PausedPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			d.setLocation(p.Location)
+		}
+	case protocol.EventPanic:
+		var p protocol.PanicPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil && len(p.Frames) > 0 {
+			d.setLocation(p.Frames[0].Location)
+		}
+	case protocol.EventContinued, protocol.EventProcessExited:
+		d.location = nil
+
+	case protocol.EventBreakpointSet:
+		var p protocol.BreakpointSetPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			d.breakpoints[p.Breakpoint.ID] = p.Breakpoint
+		}
+	case protocol.EventBreakpointsSet:
+		var p protocol.BreakpointsSetPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			for _, r := range p.Results {
+				if r.Breakpoint != nil {
+					d.breakpoints[r.Breakpoint.ID] = *r.Breakpoint
+				}
+			}
+		}
+	case protocol.EventBreakpointCleared:
+		var p protocol.BreakpointClearedPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			delete(d.breakpoints, p.ID)
+		}
+	case protocol.EventBreakpointsCleared:
+		var p protocol.BreakpointsClearedPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			for _, id := range p.IDs {
+				delete(d.breakpoints, id)
+			}
+		}
+	case protocol.EventBreakpointsToggled:
+		var p protocol.BreakpointsToggledPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			for _, bp := range p.Breakpoints {
+				d.breakpoints[bp.ID] = bp
+			}
+		}
+	case protocol.EventBreakpointLost:
+		var p protocol.BreakpointLostPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			delete(d.breakpoints, p.Breakpoint.ID)
+		}
+	case protocol.EventRestarted:
+		var p protocol.RestartedPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			d.location = nil
+			d.breakpoints = make(map[int]protocol.Breakpoint, len(p.Breakpoints))
+			for _, bp := range p.Breakpoints {
+				d.breakpoints[bp.ID] = bp
+			}
+		}
+	}
+}
+
+func (d *dashboard) setLocation(loc protocol.Location) {
+	d.location = &loc
+}
+
+// render prints the whole dashboard to w. Goroutines are fetched live, and
+// only while suspended: the engine can't enumerate them on a running process.
+func (d *dashboard) render(w io.Writer, c client.Client) {
+	d.mu.Lock()
+	var loc *protocol.Location
+	if d.location != nil {
+		l := *d.location
+		loc = &l
+	}
+	bps := make([]protocol.Breakpoint, 0, len(d.breakpoints))
+	for _, bp := range d.breakpoints {
+		bps = append(bps, bp)
+	}
+	d.mu.Unlock()
+	sort.Slice(bps, func(i, j int) bool { return bps[i].ID < bps[j].ID })
+
+	state := c.State()
+	_, _ = fmt.Fprintf(w, "  session %s  state %s\n", c.SessionID(), state)
+
+	_, _ = fmt.Fprintln(w)
+	if loc == nil {
+		_, _ = fmt.Fprintln(w, "  location: (not stopped)")
+	} else {
+		_, _ = fmt.Fprintf(w, "  location: %s:%d in %s\n", loc.File, loc.Line, loc.Function)
+		printSourceContext(w, loc.File, loc.Line)
+	}
+
+	_, _ = fmt.Fprintln(w)
+	if len(bps) == 0 {
+		_, _ = fmt.Fprintln(w, "  breakpoints: (none)")
+	} else {
+		_, _ = fmt.Fprintln(w, "  breakpoints:")
+		for _, bp := range bps {
+			state := ""
+			if !bp.Enabled {
+				state = "  (disabled)"
+			}
+			_, _ = fmt.Fprintf(w, "    #%-3d %s:%d%s\n", bp.ID, bp.Location.File, bp.Location.Line, state)
+		}
+	}
+
+	_, _ = fmt.Fprintln(w)
+	if state != protocol.StateSuspended {
+		_, _ = fmt.Fprintln(w, "  goroutines: (available while suspended)")
+		return
+	}
+	grs, err := c.Goroutines()
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "  error: %v\n", err)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "  goroutines: %d\n", len(grs))
+	for _, g := range grs {
+		_, _ = fmt.Fprintf(w, "    G%-4d %-10s %s:%d\n", g.ID, g.Status, g.CurrentLoc.File, g.CurrentLoc.Line)
+	}
+}
+
+// printSourceContext shows the lines around line, marking it. The source is
+// read locally, so this only works when the CLI runs where the target was
+// built; otherwise it says so and moves on.
+func printSourceContext(w io.Writer, file string, line int) {
+	f, err := os.Open(file)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "    (source not available locally: %v)\n", err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	first, last := line-sourceContext, line+sourceContext
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan() && n <= last; n++ {
+		if n < first {
+			continue
+		}
+		marker := "  "
+		if n == line {
+			marker = "=>"
+		}
+		_, _ = fmt.Fprintf(w, "    %s %4d  %s\n", marker, n, sc.Text())
+	}
+}
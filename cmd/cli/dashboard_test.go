@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+func bp(id int, file string, line int, enabled bool) protocol.Breakpoint {
+	return protocol.Breakpoint{ID: id, Location: protocol.Location{File: file, Line: line}, Enabled: enabled}
+}
+
+// renderDash renders d for fc and returns the output.
+func renderDash(d *dashboard, fc *fakeClient) string {
+	var out bytes.Buffer
+	d.render(&out, fc)
+	return out.String()
+}
+
+func TestDashboardTracksBreakpoints(t *testing.T) {
+	d := newDashboard()
+	for _, evt := range []protocol.Event{
+		protocol.MustEvent(protocol.EventBreakpointSet, 1, protocol.BreakpointSetPayload{Breakpoint: bp(1, "a.go", 1, true)}),
+		protocol.MustEvent(protocol.EventBreakpointSet, 2, protocol.BreakpointSetPayload{Breakpoint: bp(2, "b.go", 2, true)}),
+		protocol.MustEvent(protocol.EventBreakpointsSet, 3, protocol.BreakpointsSetPayload{Results: []protocol.BreakpointResult{
+			{Breakpoint: &protocol.Breakpoint{ID: 3, Location: protocol.Location{File: "c.go", Line: 3}, Enabled: true}},
+			{Error: "no code at line"},
+		}}),
+		protocol.MustEvent(protocol.EventBreakpointCleared, 4, protocol.BreakpointClearedPayload{ID: 1}),
+		protocol.MustEvent(protocol.EventBreakpointsToggled, 5, protocol.BreakpointsToggledPayload{
+			Breakpoints: []protocol.Breakpoint{bp(2, "b.go", 2, false)},
+		}),
+		protocol.MustEvent(protocol.EventBreakpointSet, 6, protocol.BreakpointSetPayload{Breakpoint: bp(4, "d.go", 4, true)}),
+		protocol.MustEvent(protocol.EventBreakpointLost, 7, protocol.BreakpointLostPayload{Breakpoint: bp(4, "d.go", 4, true)}),
+	} {
+		d.observe(evt)
+	}
+
+	out := renderDash(d, &fakeClient{state: protocol.StateIdle})
+	want := "  breakpoints:\n" +
+		"    #2   b.go:2  (disabled)\n" +
+		"    #3   c.go:3\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("render =\n%s\nwant it to contain\n%s", out, want)
+	}
+	if strings.Contains(out, "#1 ") || strings.Contains(out, "#4 ") {
+		t.Errorf("cleared and lost breakpoints should be gone:\n%s", out)
+	}
+
+	d.observe(protocol.MustEvent(protocol.EventBreakpointsCleared, 8, protocol.BreakpointsClearedPayload{IDs: []int{2, 3}, Count: 2}))
+	if out := renderDash(d, &fakeClient{state: protocol.StateIdle}); !strings.Contains(out, "  breakpoints: (none)\n") {
+		t.Errorf("after clearing all, render =\n%s", out)
+	}
+}
+
+func TestDashboardRestartReplacesBreakpoints(t *testing.T) {
+	d := newDashboard()
+	d.observe(protocol.MustEvent(protocol.EventBreakpointSet, 1, protocol.BreakpointSetPayload{Breakpoint: bp(1, "a.go", 1, true)}))
+	d.observe(protocol.MustEvent(protocol.EventBreakpointHit, 2, protocol.BreakpointHitPayload{Breakpoint: bp(1, "a.go", 1, true)}))
+	d.observe(protocol.MustEvent(protocol.EventRestarted, 3, protocol.RestartedPayload{
+		Program: "app", Breakpoints: []protocol.Breakpoint{bp(5, "a.go", 1, true)},
+	}))
+
+	out := renderDash(d, &fakeClient{state: protocol.StateRunning})
+	if !strings.Contains(out, "  location: (not stopped)\n") {
+		t.Errorf("restart should forget the stop:\n%s", out)
+	}
+	if !strings.Contains(out, "    #5   a.go:1\n") || strings.Contains(out, "#1 ") {
+		t.Errorf("restart should replace the breakpoints with the reinstalled ones:\n%s", out)
+	}
+}
+
+func TestDashboardLocationFollowsStops(t *testing.T) {
+	d := newDashboard()
+	loc := protocol.Location{File: "/no/such/main.go", Line: 7, Function: "main.main"}
+
+	// The welcome state carries the last-known view; later plain
+	// transitions don't, and must not wipe it.
+	d.observe(protocol.MustEvent(protocol.EventSessionState, 1, protocol.SessionStatePayload{
+		State: protocol.StateSuspended, Location: &loc, Breakpoints: []protocol.Breakpoint{bp(1, "main.go", 7, true)},
+	}))
+	d.observe(stateEvent(protocol.StateSuspended))
+	out := renderDash(d, &fakeClient{state: protocol.StateSuspended})
+	if !strings.Contains(out, "  location: /no/such/main.go:7 in main.main\n") {
+		t.Errorf("welcome location lost:\n%s", out)
+	}
+	if !strings.Contains(out, "    (source not available locally: ") {
+		t.Errorf("missing source should be reported:\n%s", out)
+	}
+	if !strings.Contains(out, "    #1   main.go:7\n") {
+		t.Errorf("welcome breakpoints lost:\n%s", out)
+	}
+
+	d.observe(protocol.MustEvent(protocol.EventContinued, 2, protocol.ContinuedPayload{}))
+	if out := renderDash(d, &fakeClient{state: protocol.StateRunning}); !strings.Contains(out, "  location: (not stopped)\n") {
+		t.Errorf("continue should clear the location:\n%s", out)
+	}
+
+	d.observe(protocol.MustEvent(protocol.EventStepped, 3, protocol.SteppedPayload{
+		Location: protocol.Location{File: "/no/such/util.go", Line: 3, Function: "main.util"},
+	}))
+	if out := renderDash(d, &fakeClient{state: protocol.StateSuspended}); !strings.Contains(out, "  location: /no/such/util.go:3 in main.util\n") {
+		t.Errorf("step should move the location:\n%s", out)
+	}
+
+	d.observe(protocol.MustEvent(protocol.EventProcessExited, 4, protocol.ProcessExitedPayload{}))
+	if out := renderDash(d, &fakeClient{state: protocol.StateExited}); !strings.Contains(out, "  location: (not stopped)\n") {
+		t.Errorf("exit should clear the location:\n%s", out)
+	}
+}
+
+func TestDashboardRendersSourceAndGoroutines(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "main.go")
+	var src strings.Builder
+	for _, l := range []string{"package main", "", "import \"fmt\"", "", "func main() {", "\tx := 1", "\tfmt.Println(x)", "}", "", "// end"} {
+		src.WriteString(l + "\n")
+	}
+	if err := os.WriteFile(file, []byte(src.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newDashboard()
+	d.observe(protocol.MustEvent(protocol.EventBreakpointHit, 1, protocol.BreakpointHitPayload{
+		Breakpoint: protocol.Breakpoint{ID: 1, Location: protocol.Location{File: file, Line: 6, Function: "main.main"}, Enabled: true},
+	}))
+	fc := &fakeClient{
+		state: protocol.StateSuspended,
+		goroutines: []protocol.Goroutine{
+			{ID: 1, Status: "running", CurrentLoc: protocol.Location{File: file, Line: 6}},
+			{ID: 17, Status: "waiting", CurrentLoc: protocol.Location{File: "proc.go", Line: 400}},
+		},
+	}
+	out := renderDash(d, fc)
+
+	wantSource := "" +
+		"          3  import \"fmt\"\n" +
+		"          4  \n" +
+		"          5  func main() {\n" +
+		"    =>    6  \tx := 1\n" +
+		"          7  \tfmt.Println(x)\n" +
+		"          8  }\n" +
+		"          9  \n" +
+		"\n"
+	if !strings.Contains(out, wantSource) {
+		t.Errorf("render =\n%s\nwant source context\n%s", out, wantSource)
+	}
+	wantGoroutines := "  goroutines: 2\n" +
+		"    G1    running    " + file + ":6\n" +
+		"    G17   waiting    proc.go:400\n"
+	if !strings.HasSuffix(out, wantGoroutines) {
+		t.Errorf("render =\n%s\nwant goroutines\n%s", out, wantGoroutines)
+	}
+	if !strings.HasPrefix(out, "  session test-session  state suspended\n") {
+		t.Errorf("render should open with the session header:\n%s", out)
+	}
+}
+
+func TestDashboardGoroutinesOnlyWhileSuspended(t *testing.T) {
+	d := newDashboard()
+	fc := &fakeClient{state: protocol.StateRunning}
+	out := renderDash(d, fc)
+	if !strings.HasSuffix(out, "  goroutines: (available while suspended)\n") {
+		t.Errorf("render =\n%s", out)
+	}
+	if len(fc.recordedCalls()) != 0 {
+		t.Errorf("running: goroutines fetched anyway: %q", fc.recordedCalls())
+	}
+
+	fc = &fakeClient{state: protocol.StateSuspended, fail: map[string]error{"Goroutines": errors.New("engine busy")}}
+	if out := renderDash(d, fc); !strings.HasSuffix(out, "  error: engine busy\n") {
+		t.Errorf("a failed fetch should be reported:\n%s", out)
+	}
+}
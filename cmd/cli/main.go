@@ -1,49 +1,118 @@
 // Command cli is an interactive terminal client for the bingo debug server.
 //
-//	cli [-addr host:port] [-session id]
+//	cli [-addr host:port] [-session id] [-tls] [-insecure] [-command "cmd; cmd..."]
+//
+// With -command the CLI needs no terminal: it runs the commands in order,
+// waiting after each resuming one (launch, c, n, ...) until the debuggee
+// stops, and exits 1 on the first command that fails.
 package main
 
 import (
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/bingosuite/bingo/pkg/client"
 	"github.com/bingosuite/bingo/pkg/protocol"
 	"github.com/chzyer/readline"
 )
 
-//nolint:gocognit,gocyclo // The CLI keeps command routing in one switch while commands are still small.
 func main() {
 	addr := flag.String("addr", "localhost:6060", "server address (host:port)")
 	sessionID := flag.String("session", "", "session ID to join (omit to create)")
+	useTLS := flag.Bool("tls", false, "connect over wss/https")
+	insecure := flag.Bool("insecure", false, "with -tls, skip server certificate verification (self-signed dev certs)")
+	script := flag.String("command", "", "run these ';'-separated commands without a prompt, then exit (non-zero on the first failure)")
+	stopTimeout := flag.Duration("stop-timeout", 30*time.Second, "with -command, how long to wait for the debuggee to stop after a resuming command")
+	initialStop := flag.String("initial-stop", "", "what a launched process does at its first instruction: stop, continue-to-main or run (default: the server's choice)")
+	maxRunTime := flag.Duration("max-run-time", 0, "pause a launched process once it has run this long, not counting time stopped (0: no limit)")
 	flag.Parse()
 
-	var c client.Client
-	var err error
+	var opts client.Options
+	if *useTLS {
+		opts.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+		opts.InsecureSkipVerify = *insecure
+	}
 
-	if *sessionID != "" {
-		fmt.Printf("joining session %s on %s...\n", *sessionID, *addr)
-		c, err = client.Join(*addr, *sessionID)
-	} else {
-		fmt.Printf("creating new session on %s...\n", *addr)
-		c, err = client.Create(*addr)
+	if *script != "" {
+		prompt = ""
 	}
+	c, err := connect(*addr, *sessionID, opts, bannerWriter(*script != ""))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
+
+	// At the prompt readline has the terminal in raw mode and Ctrl-C arrives
+	// as a key (handled in interactive). A SIGINT means a command was in
+	// flight, or this is a script: leave the session cleanly rather than let
+	// the default handler drop the connection mid-command.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		fmt.Fprintln(os.Stderr, "\ninterrupted")
+		_ = c.Close()
+		os.Exit(130)
+	}()
+
+	s := &session{c: c, addr: *addr, opts: opts, dash: newDashboard(), maxRunTime: *maxRunTime, initialStop: protocol.InitialStop(*initialStop)}
+	var updates chan protocol.Event
+	if *script != "" {
+		updates = make(chan protocol.Event, 64)
+	}
+	go eventPrinter(c.Events(), s.dash, updates)
+
+	if *script != "" {
+		err := runScript(s, *script, updates, *stopTimeout)
+		_ = c.Close()
+		os.Exit(finishScript(err, os.Stderr))
+	}
 	defer func() { _ = c.Close() }()
+	interactive(s)
+}
 
-	fmt.Printf("connected — session %s (state: %s)\n\n", c.SessionID(), c.State())
+// bannerWriter is where the connection banners go. Script output is meant
+// to be parsed or diffed, so there they go to stderr.
+func bannerWriter(scripted bool) io.Writer {
+	if scripted {
+		return os.Stderr
+	}
+	return os.Stdout
+}
 
-	go eventPrinter(c.Events())
+// connect joins sessionID on addr, or creates a session when it is empty,
+// announcing each step on banner.
+func connect(addr, sessionID string, opts client.Options, banner io.Writer) (client.Client, error) {
+	var c client.Client
+	var err error
+	if sessionID != "" {
+		_, _ = fmt.Fprintf(banner, "joining session %s on %s...\n", sessionID, addr)
+		c, err = client.JoinWithOptions(addr, sessionID, opts)
+	} else {
+		_, _ = fmt.Fprintf(banner, "creating new session on %s...\n", addr)
+		c, err = client.CreateWithOptions(addr, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, _ = fmt.Fprintf(banner, "connected — session %s (state: %s)\n\n", c.SessionID(), c.State())
+	return c, nil
+}
 
+// interactive is the readline prompt loop.
+func interactive(s *session) {
 	rl, err := readline.NewEx(&readline.Config{
-		Prompt:          "bingo> ",
+		Prompt:          prompt,
 		HistoryFile:     os.ExpandEnv("$HOME/.bingo_history"),
 		InterruptPrompt: "^C",
 		EOFPrompt:       "exit",
@@ -57,211 +126,147 @@ func main() {
 	printHelp()
 	for {
 		line, err := rl.Readline()
+		if err == readline.ErrInterrupt {
+			// Ctrl-C with something typed abandons the line, like a shell.
+			// On an empty line it stops a running debuggee, and only
+			// quits when there is nothing to interrupt.
+			if strings.TrimSpace(line) != "" {
+				continue
+			}
+			if ierr := s.c.Interrupt(); ierr == nil {
+				fmt.Println("  interrupting...")
+				continue
+			} else if !errors.Is(ierr, client.ErrNotRunning) {
+				printErr(ierr)
+				continue
+			}
+		}
 		if err != nil {
 			if err == readline.ErrInterrupt || err == io.EOF {
 				fmt.Println("bye")
-				return
 			}
-			break
+			return
 		}
 
-		line = strings.TrimSpace(line)
-		if line == "" {
+		args := strings.Fields(line)
+		if len(args) == 0 {
 			continue
 		}
 
-		args := strings.Fields(line)
-		cmd := args[0]
-
-		switch cmd {
-
-		case "sessions", "ls":
-			sessions, err := client.ListSessions(*addr)
-			if err != nil {
-				printErr(err)
-				continue
-			}
-			if len(sessions) == 0 {
-				fmt.Println("  (no active sessions)")
-				continue
-			}
-			for _, s := range sessions {
-				fmt.Printf("  %s  state=%-10s clients=%d  created=%s\n",
-					s.ID, s.State, s.Clients, s.CreatedAt.Format("15:04:05"))
-			}
-
-		case "state":
-			fmt.Printf("  session=%s  state=%s\n", c.SessionID(), c.State())
-
-		case "launch":
-			if len(args) < 2 {
-				fmt.Println("  usage: launch <binary> [args...]")
-				continue
-			}
-			var launchArgs []string
-			if len(args) > 2 {
-				launchArgs = args[2:]
-			}
-			if err := c.Launch(args[1], launchArgs, nil); err != nil {
-				printErr(err)
-			}
-
-		case "attach":
-			if len(args) < 2 {
-				fmt.Println("  usage: attach <pid> [binary-path]")
-				continue
-			}
-			pid, err := strconv.Atoi(args[1])
-			if err != nil {
-				fmt.Printf("  invalid pid: %s\n", args[1])
-				continue
-			}
-			var binPath string
-			if len(args) > 2 {
-				binPath = args[2]
-			}
-			if err := c.Attach(pid, binPath); err != nil {
-				printErr(err)
-			}
-
-		case "kill":
-			if err := c.Kill(); err != nil {
-				printErr(err)
-			}
-
-		case "restart":
-			p, err := c.Restart(nil, nil)
-			if err != nil {
-				printErr(err)
-				continue
-			}
-			fmt.Printf("  restarted %s\n", p.Program)
-			for _, bp := range p.Breakpoints {
-				fmt.Printf("  breakpoint %d reinstalled at %s:%d\n", bp.ID, bp.Location.File, bp.Location.Line)
-			}
-			for _, d := range p.Discarded {
-				fmt.Printf("  breakpoint at %s:%d discarded: %s\n", d.Location.File, d.Location.Line, d.Reason)
-			}
-
-		case "c", "continue":
-			if err := c.Continue(); err != nil {
-				printErr(err)
-			}
+		var usage usageError
+		switch err := s.exec(args); {
+		case err == nil:
+		case errors.Is(err, errQuit):
+			fmt.Println("bye")
+			return
+		case errors.As(err, &usage):
+			fmt.Printf("  %s\n", usage)
+		default:
+			printErr(err)
+		}
+	}
+}
 
-		case "n", "next":
-			if err := c.StepOver(); err != nil {
-				printErr(err)
-			}
+// runScript runs the ';'-separated commands in script in order and stops at
+// the first failure. After each resuming command it waits for the session to
+// settle, so "launch ./app; b main.go:10; c; locals" inspects the stop at
+// line 10 rather than racing the running process.
+func runScript(s *session, script string, updates <-chan protocol.Event, stopTimeout time.Duration) error {
+	for _, line := range strings.Split(script, ";") {
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+		// Drop updates from earlier commands so the wait below only
+		// sees what this one causes.
+		drainUpdates(updates)
 
-		case "s", "step":
-			if err := c.StepInto(); err != nil {
-				printErr(err)
+		err := s.exec(args)
+		if errors.Is(err, errQuit) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", strings.TrimSpace(line), err)
+		}
+		if resuming[args[0]] {
+			if err := waitForStop(updates, stopTimeout); err != nil {
+				return fmt.Errorf("%s: %w", strings.TrimSpace(line), err)
 			}
+		}
+	}
+	return nil
+}
 
-		case "out", "finish":
-			if err := c.StepOut(); err != nil {
-				printErr(err)
-			}
+// finishScript reports runScript's result on stderr and returns the exit
+// status -command promises: 1 if a command failed, else 0.
+func finishScript(err error, stderr io.Writer) int {
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
 
-		case "p", "pause":
-			if err := c.Pause(); err != nil {
-				printErr(err)
-			}
+func drainUpdates(updates <-chan protocol.Event) {
+	for {
+		select {
+		case <-updates:
+		default:
+			return
+		}
+	}
+}
 
-		case "b", "break":
-			if len(args) < 2 {
-				fmt.Println("  usage: break <file>:<line>")
-				continue
-			}
-			file, line, ok := parseFileLine(args[1])
+// waitForStop blocks until the session has gone running and come back out
+// of it. Requiring the running transition first keeps a late copy of the
+// previous state from ending the wait early. An error event means the
+// command failed server-side and nothing is going to stop.
+func waitForStop(updates <-chan protocol.Event, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ran := false
+	for {
+		select {
+		case evt, ok := <-updates:
 			if !ok {
-				fmt.Println("  usage: break <file>:<line>  (e.g. main.go:42)")
-				continue
+				return errors.New("connection closed while waiting for the debuggee to stop")
 			}
-			bp, err := c.SetBreakpoint(file, line)
-			if err != nil {
-				printErr(err)
-				continue
-			}
-			fmt.Printf("  breakpoint %d set at %s:%d\n",
-				bp.ID, bp.Location.File, bp.Location.Line)
-
-		case "clear":
-			if len(args) < 2 {
-				fmt.Println("  usage: clear <breakpoint-id>")
-				continue
-			}
-			id, err := strconv.Atoi(args[1])
-			if err != nil {
-				fmt.Printf("  invalid breakpoint id: %s\n", args[1])
-				continue
-			}
-			if err := c.ClearBreakpoint(id); err != nil {
-				printErr(err)
-				continue
-			}
-			fmt.Printf("  breakpoint %d cleared\n", id)
-
-		case "locals":
-			frame := 0
-			if len(args) > 1 {
-				frame, _ = strconv.Atoi(args[1])
-			}
-			vars, err := c.Locals(frame)
-			if err != nil {
-				printErr(err)
-				continue
-			}
-			if len(vars) == 0 {
-				fmt.Println("  (no locals)")
-				continue
+			if evt.Kind == protocol.EventError {
+				var p protocol.ErrorPayload
+				_ = protocol.DecodeEventPayload(evt, &p)
+				return errors.New(p.Message)
 			}
-			for _, v := range vars {
-				fmt.Printf("  %s %s = %s\n", v.Name, v.Type, v.Value)
-			}
-
-		case "bt", "backtrace":
-			frames, err := c.StackFrames()
-			if err != nil {
-				printErr(err)
+			var p protocol.SessionStatePayload
+			if protocol.DecodeEventPayload(evt, &p) != nil {
 				continue
 			}
-			for _, f := range frames {
-				fmt.Printf("  #%d  %s at %s:%d\n",
-					f.Index, f.Location.Function, f.Location.File, f.Location.Line)
+			if p.State == protocol.StateRunning {
+				ran = true
+			} else if ran {
+				return nil
 			}
-
-		case "goroutines", "grs":
-			grs, err := c.Goroutines()
-			if err != nil {
-				printErr(err)
-				continue
-			}
-			for _, g := range grs {
-				loc := fmt.Sprintf("%s:%d", g.CurrentLoc.File, g.CurrentLoc.Line)
-				if g.WaitReason != "" {
-					fmt.Printf("  G%-4d %-10s %s  (%s)\n", g.ID, g.Status, loc, g.WaitReason)
-				} else {
-					fmt.Printf("  G%-4d %-10s %s\n", g.ID, g.Status, loc)
-				}
-			}
-
-		case "help", "h", "?":
-			printHelp()
-
-		case "quit", "q", "exit":
-			fmt.Println("bye")
-			return
-
-		default:
-			fmt.Printf("  unknown command: %s (type 'help' for usage)\n", cmd)
+		case <-timer.C:
+			return fmt.Errorf("debuggee did not stop within %s", timeout)
 		}
 	}
 }
 
-func eventPrinter(events <-chan protocol.Event) {
+// prompt is reprinted after each asynchronous event so the user can see the
+// CLI is ready again. Script mode clears it.
+var prompt = "bingo> "
+
+// eventPrinter prints events as they arrive. When updates is non-nil it also
+// forwards state transitions and errors to it, for script mode's waits.
+func eventPrinter(events <-chan protocol.Event, dash *dashboard, updates chan<- protocol.Event) {
 	for evt := range events {
+		dash.observe(evt)
 		printEvent(evt)
+		if updates != nil && (evt.Kind == protocol.EventSessionState || evt.Kind == protocol.EventError) {
+			updates <- evt
+		}
+	}
+	if updates != nil {
+		close(updates)
 	}
 }
 
@@ -271,67 +276,191 @@ func printEvent(evt protocol.Event) {
 	case protocol.EventSessionState:
 		var p protocol.SessionStatePayload
 		if protocol.DecodeEventPayload(evt, &p) == nil {
-			fmt.Printf("\n  [state] %s (clients: %d)\nbingo> ", p.State, p.Clients)
+			fmt.Printf("\n  [state] %s (clients: %d)\n", p.State, p.Clients)
 		}
 
 	case protocol.EventBreakpointHit:
 		var p protocol.BreakpointHitPayload
 		if protocol.DecodeEventPayload(evt, &p) == nil {
-			fmt.Printf("\n  [hit] breakpoint %d at %s:%d\nbingo> ",
+			fmt.Printf("\n  [hit] breakpoint %d at %s:%d\n",
+				p.Breakpoint.ID, p.Breakpoint.Location.File, p.Breakpoint.Location.Line)
+			if r := p.Registers; r != nil {
+				fmt.Printf("  pc=%#x sp=%#x bp=%#x\n", r.PC, r.SP, r.BP)
+			}
+			for _, s := range p.OtherStops {
+				switch {
+				case s.Breakpoint != 0:
+					fmt.Printf("  also stopped: thread %d at breakpoint %d (%s:%d)\n", s.TID, s.Breakpoint, s.Location.File, s.Location.Line)
+				case s.Signal != 0:
+					fmt.Printf("  also stopped: thread %d by signal %d (%s:%d)\n", s.TID, s.Signal, s.Location.File, s.Location.Line)
+				default:
+					fmt.Printf("  also stopped: thread %d at %s:%d\n", s.TID, s.Location.File, s.Location.Line)
+				}
+			}
+			if len(p.StoppedThreads) > 0 {
+				fmt.Printf("  %d threads stopped:", len(p.StoppedThreads))
+				for _, s := range p.StoppedThreads {
+					fmt.Printf(" %d@%s:%d", s.TID, s.Location.File, s.Location.Line)
+				}
+				fmt.Println()
+			}
+		}
+
+	case protocol.EventBreakpointActions:
+		var p protocol.BreakpointActionsPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			fmt.Printf("\n  [breakpoint %d] %s:%d\n",
 				p.Breakpoint.ID, p.Breakpoint.Location.File, p.Breakpoint.Location.Line)
+			for _, line := range p.Output {
+				fmt.Printf("    %s\n", line)
+			}
 		}
 
 	case protocol.EventPanic:
 		var p protocol.PanicPayload
 		if protocol.DecodeEventPayload(evt, &p) == nil {
-			fmt.Printf("\n  [panic] %s\nbingo> ", p.Message)
+			fmt.Printf("\n  [panic] %s\n", p.Message)
 		}
 
 	case protocol.EventOutput:
 		var p protocol.OutputPayload
 		if protocol.DecodeEventPayload(evt, &p) == nil {
-			fmt.Printf("\n  [%s] %s\nbingo> ", p.Stream, p.Content)
+			fmt.Printf("\n  [%s] %s\n", p.Stream, p.Content)
 		}
 
 	case protocol.EventProcessExited:
 		var p protocol.ProcessExitedPayload
 		if protocol.DecodeEventPayload(evt, &p) == nil {
-			fmt.Printf("\n  [exited] code=%d reason=%s\nbingo> ", p.ExitCode, p.Reason)
+			fmt.Printf("\n  [exited] code=%d reason=%s\n", p.ExitCode, p.Reason)
 		}
 
 	case protocol.EventStepped:
 		var p protocol.SteppedPayload
 		if protocol.DecodeEventPayload(evt, &p) == nil {
-			fmt.Printf("\n  [stepped] %s:%d in %s\nbingo> ",
+			// The entry stop and continue-to-main's stop arrive as Stepped
+			// too; say which rather than claim a step nobody asked for.
+			label := "stepped"
+			if p.Reason != "" && p.Reason != protocol.StopReasonStep {
+				label = string(p.Reason)
+			}
+			fmt.Printf("\n  [%s] %s:%d in %s", label,
 				p.Location.File, p.Location.Line, p.Location.Function)
+			if p.Steps > 0 {
+				fmt.Printf(" (%d steps)", p.Steps)
+			}
+			if p.From != nil {
+				fmt.Printf(" from %s:%d, pc %+d bytes", p.From.File, p.From.Line, p.PCDelta)
+				if p.Instructions > 0 {
+					fmt.Printf(" in %d instructions", p.Instructions)
+				}
+			}
+			fmt.Println()
 		}
 
 	case protocol.EventPaused:
 		var p protocol.PausedPayload
 		if protocol.DecodeEventPayload(evt, &p) == nil {
-			fmt.Printf("\n  [paused] %s:%d in %s\nbingo> ",
+			fmt.Printf("\n  [paused] %s:%d in %s\n",
 				p.Location.File, p.Location.Line, p.Location.Function)
 		}
 
 	case protocol.EventContinued:
-		fmt.Print("\n  [continued]\nbingo> ")
+		fmt.Print("\n  [continued]\n")
+
+	case protocol.EventResumed:
+		var p protocol.ResumedPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil && p.Location != nil {
+			fmt.Printf("\n  [resumed] %s from %s:%d\n", p.Command, p.Location.File, p.Location.Line)
+		}
+
+	case protocol.EventDebuggerReady:
+		var p protocol.DebuggerReadyPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			fmt.Printf("\n  [ready] pid=%d pgid=%d %s\n", p.PID, p.PGID, p.Program)
+		}
+
+	case protocol.EventThreadStarted, protocol.EventThreadExited:
+		var p protocol.ThreadPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			what := "started"
+			if evt.Kind == protocol.EventThreadExited {
+				what = "exited"
+			}
+			fmt.Printf("\n  [thread %s] tid=%d\n", what, p.TID)
+		}
 
 	case protocol.EventError:
 		var p protocol.ErrorPayload
 		if protocol.DecodeEventPayload(evt, &p) == nil {
-			fmt.Printf("\n  [error] %s: %s\nbingo> ", p.Command, p.Message)
+			fmt.Printf("\n  [error] %s: %s\n", p.Command, p.Message)
+		}
+
+	case protocol.EventBreakpointLost:
+		var p protocol.BreakpointLostPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			fmt.Printf("\n  [breakpoint lost] #%d at %s:%d: %s\n",
+				p.Breakpoint.ID, p.Breakpoint.Location.File, p.Breakpoint.Location.Line, p.Message)
+		}
+
+	case protocol.EventConditionError:
+		var p protocol.ConditionErrorPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			fmt.Printf("\n  [condition error] breakpoint #%d at %s:%d stops regardless: %s\n",
+				p.Breakpoint.ID, p.Breakpoint.Location.File, p.Breakpoint.Location.Line, p.Message)
+		}
+
+	case protocol.EventBreakpointWarning:
+		var p protocol.BreakpointWarningPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			fmt.Printf("\n  [warning] breakpoint #%d at %s:%d: %s\n",
+				p.Breakpoint.ID, p.Breakpoint.Location.File, p.Breakpoint.Location.Line, p.Message)
+		}
+
+	case protocol.EventBreakpointError:
+		var p protocol.BreakpointErrorPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			fmt.Printf("\n  [breakpoint error] %s:%d: %s\n", p.Location.File, p.Location.Line, p.Message)
+		}
+
+	case protocol.EventNotice:
+		var p protocol.NoticePayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			fmt.Printf("\n  [notice] %s: %s\n", p.Command, p.Message)
 		}
 
 	case protocol.EventRestarted:
 		var p protocol.RestartedPayload
 		if protocol.DecodeEventPayload(evt, &p) == nil {
-			fmt.Printf("\n  [restarted] %s (%d breakpoint(s), %d discarded)\nbingo> ",
+			fmt.Printf("\n  [restarted] %s (%d breakpoint(s), %d discarded)\n",
 				p.Program, len(p.Breakpoints), len(p.Discarded))
 		}
 
+	case protocol.EventGoroutineSnapshot:
+		var p protocol.GoroutineSnapshotPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			fmt.Printf("\n  [goroutines] %d goroutine(s) at %s\n", len(p.Goroutines), p.Trigger)
+			for _, g := range p.Goroutines {
+				fmt.Printf("  G%-4d %-10s %s:%d\n", g.ID, g.Status, g.CurrentLoc.File, g.CurrentLoc.Line)
+			}
+		}
+
+	case protocol.EventRunTimeout:
+		var p protocol.RunTimeoutPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			fmt.Printf("\n  [run timeout] ran for %s without stopping, pausing\n", p.MaxRunTime)
+		}
+
+	case protocol.EventGoroutineLeak:
+		var p protocol.GoroutineLeakPayload
+		if protocol.DecodeEventPayload(evt, &p) == nil {
+			fmt.Printf("\n  [goroutine leak?] count grew by %d over the last %d stops: %v\n",
+				p.Growth, len(p.Counts), p.Counts)
+		}
+
 	default:
-		fmt.Printf("\n  [%s] seq=%d\nbingo> ", evt.Kind, evt.Seq)
+		fmt.Printf("\n  [%s] seq=%d\n", evt.Kind, evt.Seq)
 	}
+	fmt.Print(prompt)
 }
 
 func parseFileLine(s string) (string, int, bool) {
@@ -346,32 +475,84 @@ func parseFileLine(s string) (string, int, bool) {
 	return s[:idx], line, true
 }
 
+// parseFuncLine parses a line named relative to a function: "main.worker"
+// (the line it is declared on), "main.worker+3" (three lines further on)
+// or "main.worker:return" (its last line with code).
+func parseFuncLine(s string) (fn string, offset int, atReturn, ok bool) {
+	if fn, found := strings.CutSuffix(s, ":return"); found {
+		return fn, 0, true, fn != ""
+	}
+	if strings.Contains(s, ":") {
+		return "", 0, false, false
+	}
+	if i := strings.LastIndex(s, "+"); i >= 0 {
+		n, err := strconv.Atoi(s[i+1:])
+		if err != nil || n < 0 || i == 0 {
+			return "", 0, false, false
+		}
+		return s[:i], n, false, true
+	}
+	return s, 0, false, s != ""
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func printErr(err error) {
 	fmt.Printf("  error: %v\n", err)
 }
 
 func printHelp() {
 	fmt.Println(`commands:
-  sessions / ls              list active sessions on the server
+  sessions / ls              list sessions on the server, with retained finished ones
   state                      show current session state
+  status                     ask the server for the process's actual state and stop PC
+  ping                       measure the round trip to the session
+  dash / d                   overview: state, source at the stop, breakpoints, goroutines
 
   launch <binary> [args...]  start a process under the debugger
   attach <pid> [binary]      attach to a running process  (find pid: pgrep <name>)
   kill                       terminate the debuggee
   restart                    kill and relaunch, reinstalling breakpoints
 
-  c / continue               resume execution
-  n / next                   step over
-  s / step                   step into
+  c / continue [thread|all]  resume execution (thread: leave other stopped threads stopped)
+  n / next [count]           step over (count times, reporting the last stop)
+  s / step [count]           step into
   out / finish               step out (run until function returns)
-  p / pause                  interrupt a running process and suspend it
-
-  b / break <file>:<line>    set breakpoint  (e.g. break main.go:42)
+  si / stepi                 step a single machine instruction
+  p / pause                  interrupt a running process and suspend it (or Ctrl-C)
+  input <text>               type a line at the process's terminal (server run with -pty)
+
+  b / break <file>:<line> [actions]
+                             set breakpoint  (e.g. break main.go:42)
+  b / break <func>[+<n>|:return] [actions]
+                             set breakpoint n lines into a function, or at its last line
+                             (e.g. break main.worker+3, break main.worker:return)
+                             actions run on each hit: break main.go:42 print x, continue
   clear <id>                 remove breakpoint by ID
+  clear all                  remove every breakpoint
+  ignore <id> <n>            let breakpoint <id> go by its next n hits before stopping
+  disable / enable           lift every breakpoint so the program runs free / re-arm them
+  resolve <file>:<line>      show the PC a line maps to, without a breakpoint
+  resolve <func>[+<n>|:return]
+                             the same for a line named relative to a function
+  addr <pc>                  show the source location of a hex address
+  file <file>                print a source file of the program, with line numbers
+  str <addr>                 show the Go string whose header is at a hex address
+  slice <addr> <size>        show a Go slice header and its elements as size-byte hex
 
   locals [frame]             show local variables (default frame 0)
+  args                       show the arguments of the function stopped in
   bt / backtrace             show call stack
   goroutines / grs           list goroutines
+  logs [n]                   show the session's recent log lines (default all)
+  caps / capabilities        list the commands the session's state allows now
 
   help / h / ?               show this help
   quit / q / exit            disconnect and exit`)
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bingosuite/bingo/pkg/client"
+	"github.com/bingosuite/bingo/pkg/protocol"
+	"github.com/gorilla/websocket"
+)
+
+// fakeClient is the client.Client the CLI's commands run against. Embedding
+// the interface leaves every method a test doesn't need nil, so a command
+// reaching one it didn't expect panics instead of passing quietly.
+type fakeClient struct {
+	client.Client
+
+	mu    sync.Mutex
+	calls []string
+	// fail makes the named method return its error.
+	fail map[string]error
+	// onResume runs after a resuming method succeeds, standing in for the
+	// state events the server would send.
+	onResume func()
+
+	state      protocol.SessionState
+	goroutines []protocol.Goroutine
+}
+
+func (f *fakeClient) record(call string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+	return f.fail[call]
+}
+
+func (f *fakeClient) resume(call string) error {
+	if err := f.record(call); err != nil {
+		return err
+	}
+	if f.onResume != nil {
+		f.onResume()
+	}
+	return nil
+}
+
+func (f *fakeClient) recordedCalls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.calls)
+}
+
+func (f *fakeClient) SessionID() string            { return "test-session" }
+func (f *fakeClient) State() protocol.SessionState { return f.state }
+func (f *fakeClient) Continue() error              { return f.resume("Continue") }
+func (f *fakeClient) StepOverN(n int) error        { return f.resume(fmt.Sprintf("StepOverN(%d)", n)) }
+func (f *fakeClient) Pause() error                 { return f.record("Pause") }
+func (f *fakeClient) Kill() error                  { return f.record("Kill") }
+func (f *fakeClient) Goroutines() ([]protocol.Goroutine, error) {
+	return f.goroutines, f.record("Goroutines")
+}
+
+func stateEvent(state protocol.SessionState) protocol.Event {
+	return protocol.MustEvent(protocol.EventSessionState, 0, protocol.SessionStatePayload{State: state})
+}
+
+// newScriptSession returns a session on a fakeClient whose resuming
+// commands report a run and a stop on the returned updates channel, as
+// eventPrinter would forward them.
+func newScriptSession() (*session, *fakeClient, chan protocol.Event) {
+	updates := make(chan protocol.Event, 64)
+	fc := &fakeClient{fail: map[string]error{}}
+	fc.onResume = func() {
+		updates <- stateEvent(protocol.StateRunning)
+		updates <- stateEvent(protocol.StateSuspended)
+	}
+	return &session{c: fc, dash: newDashboard()}, fc, updates
+}
+
+func TestRunScriptSplitsOnSemicolons(t *testing.T) {
+	s, fc, updates := newScriptSession()
+	if err := runScript(s, "p; ;kill;  p  ;", updates, time.Second); err != nil {
+		t.Fatalf("runScript: %v", err)
+	}
+	if got, want := fc.recordedCalls(), []string{"Pause", "Kill", "Pause"}; !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestRunScriptWaitsForStopAfterResuming(t *testing.T) {
+	s, fc, updates := newScriptSession()
+	// A stale suspended state from before the command must not end the
+	// wait: runScript drops it, and waitForStop needs a run first.
+	updates <- stateEvent(protocol.StateSuspended)
+	if err := runScript(s, "c; n 3; kill", updates, time.Second); err != nil {
+		t.Fatalf("runScript: %v", err)
+	}
+	if got, want := fc.recordedCalls(), []string{"Continue", "StepOverN(3)", "Kill"}; !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	s, fc, updates = newScriptSession()
+	fc.onResume = func() { updates <- stateEvent(protocol.StateRunning) }
+	err := runScript(s, "c; kill", updates, 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "did not stop within 50ms") {
+		t.Fatalf("runScript with no stop = %v, want a timeout", err)
+	}
+	if slices.Contains(fc.recordedCalls(), "Kill") {
+		t.Error("kill ran before the debuggee stopped")
+	}
+}
+
+func TestRunScriptFailsOnServerError(t *testing.T) {
+	s, fc, updates := newScriptSession()
+	fc.onResume = func() {
+		updates <- protocol.MustEvent(protocol.EventError, 0, protocol.ErrorPayload{
+			Command: protocol.CmdContinue, Message: "no process",
+		})
+	}
+	err := runScript(s, "c; kill", updates, time.Second)
+	if err == nil || err.Error() != "c: no process" {
+		t.Fatalf("runScript = %v, want %q", err, "c: no process")
+	}
+	if slices.Contains(fc.recordedCalls(), "Kill") {
+		t.Error("kill ran after continue failed")
+	}
+}
+
+func TestRunScriptStopsAtFirstFailure(t *testing.T) {
+	s, fc, updates := newScriptSession()
+	fc.fail["Kill"] = errors.New("not attached")
+	err := runScript(s, "p; kill; p", updates, time.Second)
+	if err == nil || err.Error() != "kill: not attached" {
+		t.Fatalf("runScript = %v, want %q", err, "kill: not attached")
+	}
+	if got, want := fc.recordedCalls(), []string{"Pause", "Kill"}; !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	var stderr bytes.Buffer
+	if code := finishScript(err, &stderr); code != 1 {
+		t.Errorf("exit status = %d, want 1", code)
+	}
+	if got := stderr.String(); got != "error: kill: not attached\n" {
+		t.Errorf("stderr = %q", got)
+	}
+	stderr.Reset()
+	if code := finishScript(nil, &stderr); code != 0 || stderr.Len() != 0 {
+		t.Errorf("success: exit status %d, stderr %q", code, stderr.String())
+	}
+}
+
+func TestRunScriptQuitEndsEarly(t *testing.T) {
+	s, fc, updates := newScriptSession()
+	if err := runScript(s, "p; quit; kill", updates, time.Second); err != nil {
+		t.Fatalf("runScript: %v", err)
+	}
+	if got, want := fc.recordedCalls(), []string{"Pause"}; !slices.Equal(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestBannersGoToStderrInScripts(t *testing.T) {
+	if bannerWriter(true) != os.Stderr {
+		t.Error("script banners should go to stderr")
+	}
+	if bannerWriter(false) != os.Stdout {
+		t.Error("interactive banners should go to stdout")
+	}
+}
+
+func TestConnectAnnouncesOnBanner(t *testing.T) {
+	up := websocket.Upgrader{Subprotocols: []string{protocol.Subprotocol}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := up.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		welcome, _ := protocol.MarshalEvent(protocol.MustEvent(protocol.EventSessionState, 1,
+			protocol.SessionStatePayload{SessionID: "test-session", State: protocol.StateIdle, Clients: 1}))
+		_ = conn.WriteMessage(websocket.TextMessage, welcome)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer ts.Close()
+	addr := strings.TrimPrefix(ts.URL, "http://")
+
+	var banner bytes.Buffer
+	c, err := connect(addr, "", client.Options{}, &banner)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	_ = c.Close()
+	want := "creating new session on " + addr + "...\n" +
+		"connected — session test-session (state: idle)\n\n"
+	if got := banner.String(); got != want {
+		t.Errorf("banner = %q, want %q", got, want)
+	}
+}
@@ -1,39 +1,197 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Exit codes: lint failures and hook errors are kept apart so a wrapper can
+// tell "fix your message" from "the hook itself is broken".
+const (
+	exitLint  = 1
+	exitError = 2
 )
 
+// defaultMaxSubjectLength is the usual Conventional Commits / git log limit.
+const defaultMaxSubjectLength = 72
+
+// configFile is looked up in the working directory, which is the repository
+// root when the hook runs under lefthook.
+const configFile = ".commitlintrc.yml"
+
+// defaultTypes is the built-in type list, in the order the help text shows it.
+var defaultTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "chore", "wip"}
+
+var typeDescriptions = map[string]string{
+	"feat":     "a new feature",
+	"fix":      "a bug fix",
+	"docs":     "documentation only changes",
+	"style":    "changes that do not affect meaning (whitespace, formatting)",
+	"refactor": "code change that neither fixes a bug nor adds a feature",
+	"perf":     "performance improvement",
+	"test":     "adding or correcting tests",
+	"chore":    "other changes that don't modify src or test files",
+	"wip":      "work in progress",
+	"ci":       "CI configuration and scripts",
+	"build":    "build system or dependency changes",
+	"revert":   "reverts a previous commit",
+}
+
+// config is the optional .commitlintrc.yml. An omitted or empty Types keeps
+// the defaults; an empty Scopes allows any scope; a zero MaxSubjectLength
+// means defaultMaxSubjectLength.
+type config struct {
+	Types            []string `yaml:"types"`
+	Scopes           []string `yaml:"scopes"`
+	MaxSubjectLength int      `yaml:"maxSubjectLength"`
+}
+
+// violation is one broken rule. header marks the format failure, which also
+// gets the full usage help.
+type violation struct {
+	msg    string
+	header bool
+}
+
 func main() {
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		fmt.Printf("\033[1;31m✗ Error reading %s: %v\033[0m\n", configFile, err)
+		os.Exit(exitError)
+	}
+
 	data, err := os.ReadFile(os.Args[1])
 	if err != nil {
 		fmt.Printf("\033[1;31m✗ Error reading commit message: %v\033[0m\n", err)
-		os.Exit(1)
-	}
-	msg := string(data)
-
-	re := regexp.MustCompile(`^(feat|fix|docs|style|refactor|perf|test|chore|wip)(\([^)]+\))?: .+`)
-	if !re.MatchString(msg) {
-		fmt.Println("\033[1;31m─────────────────────────────────────────────────────────────\033[0m")
-		fmt.Println("\033[1;31m✗ Commit message does not follow Conventional Commits format.\033[0m")
-		fmt.Println("\033[1;31m─────────────────────────────────────────────────────────────\033[0m")
-		fmt.Println("\033[1;33mFormat:\033[0m <type>(<scope>): <description>")
-		fmt.Println("\033[1;33mOptions for <type>:\033[0m")
-		fmt.Println("  feat      - a new feature")
-		fmt.Println("  fix       - a bug fix")
-		fmt.Println("  docs      - documentation only changes")
-		fmt.Println("  style     - changes that do not affect meaning (whitespace, formatting)")
-		fmt.Println("  refactor  - code change that neither fixes a bug nor adds a feature")
-		fmt.Println("  perf      - performance improvement")
-		fmt.Println("  test      - adding or correcting tests")
-		fmt.Println("  chore     - other changes that don't modify src or test files")
-		fmt.Println("\033[1;33mExamples:\033[0m")
-		fmt.Println("  feat(parser): add ability to parse arrays")
-		fmt.Println("  fix(auth): handle expired tokens")
-		fmt.Println("  docs: update README with usage examples")
-		os.Exit(1)
+		os.Exit(exitError)
+	}
+
+	violations := lint(string(data), cfg)
+	if len(violations) > 0 {
+		for _, v := range violations {
+			if v.header {
+				printUsage(cfg)
+				continue
+			}
+			fmt.Printf("\033[1;31m✗ %s\033[0m\n", v.msg)
+		}
+		os.Exit(exitLint)
 	}
 	fmt.Println("\033[1;32m✓ Commit message format looks good!\033[0m")
 }
+
+// loadConfig reads path, falling back to the defaults when it doesn't exist.
+// A file that exists but doesn't parse is an error: silently linting against
+// the defaults would reject commits the team meant to allow.
+func loadConfig(path string) (config, error) {
+	cfg := config{Types: defaultTypes}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	var file config
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return cfg, fmt.Errorf("parse: %w", err)
+	}
+	if len(file.Types) > 0 {
+		cfg.Types = file.Types
+	}
+	cfg.Scopes = file.Scopes
+	cfg.MaxSubjectLength = file.MaxSubjectLength
+	return cfg, nil
+}
+
+// lint checks msg against every rule and returns all violations, so one
+// round trip through the editor can fix them together.
+func lint(msg string, cfg config) []violation {
+	lines := messageLines(msg)
+	subject := ""
+	if len(lines) > 0 {
+		subject = lines[0]
+	}
+
+	var out []violation
+	if !cfg.headerPattern().MatchString(subject) {
+		out = append(out, violation{msg: "header does not follow Conventional Commits format", header: true})
+	}
+	limit := cfg.MaxSubjectLength
+	if limit <= 0 {
+		limit = defaultMaxSubjectLength
+	}
+	if n := utf8.RuneCountInString(subject); n > limit {
+		out = append(out, violation{msg: fmt.Sprintf("subject is %d characters; keep it to %d", n, limit)})
+	}
+	if strings.HasSuffix(strings.TrimSpace(subject), ".") {
+		out = append(out, violation{msg: "subject must not end with a period"})
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		out = append(out, violation{msg: "leave a blank line between the subject and the body"})
+	}
+	return out
+}
+
+// messageLines splits msg into lines, dropping the "#" comment lines git
+// leaves in the editor template and any trailing blank lines.
+func messageLines(msg string) []string {
+	var lines []string
+	for _, l := range strings.Split(strings.ReplaceAll(msg, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(l, "#") {
+			continue
+		}
+		lines = append(lines, l)
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// headerPattern builds the Conventional Commits header regex for cfg. Names
+// are quoted so a config entry can't inject regex syntax.
+func (c config) headerPattern() *regexp.Regexp {
+	scope := `(\([^)]+\))?`
+	if len(c.Scopes) > 0 {
+		scope = `(\((` + alternation(c.Scopes) + `)\))?`
+	}
+	return regexp.MustCompile(`^(` + alternation(c.Types) + `)` + scope + `: .+`)
+}
+
+func alternation(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = regexp.QuoteMeta(n)
+	}
+	return strings.Join(quoted, "|")
+}
+
+func printUsage(cfg config) {
+	fmt.Println("\033[1;31m─────────────────────────────────────────────────────────────\033[0m")
+	fmt.Println("\033[1;31m✗ Commit message does not follow Conventional Commits format.\033[0m")
+	fmt.Println("\033[1;31m─────────────────────────────────────────────────────────────\033[0m")
+	fmt.Println("\033[1;33mFormat:\033[0m <type>(<scope>): <description>")
+	fmt.Println("\033[1;33mOptions for <type>:\033[0m")
+	for _, t := range cfg.Types {
+		if d, ok := typeDescriptions[t]; ok {
+			fmt.Printf("  %-9s - %s\n", t, d)
+		} else {
+			fmt.Printf("  %s\n", t)
+		}
+	}
+	if len(cfg.Scopes) > 0 {
+		fmt.Printf("\033[1;33mAllowed <scope>:\033[0m %s\n", strings.Join(cfg.Scopes, ", "))
+	}
+	fmt.Println("\033[1;33mExamples:\033[0m")
+	fmt.Println("  feat(parser): add ability to parse arrays")
+	fmt.Println("  fix(auth): handle expired tokens")
+	fmt.Println("  docs: update README with usage examples")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHeaderPattern(t *testing.T) {
+	defaults := config{Types: defaultTypes}
+	custom := config{Types: []string{"feat", "fix", "ci", "build", "revert"}}
+	scoped := config{Types: defaultTypes, Scopes: []string{"hub", "debugger"}}
+
+	tests := []struct {
+		name string
+		cfg  config
+		msg  string
+		want bool
+	}{
+		{"default type", defaults, "feat: add thing", true},
+		{"default type with scope", defaults, "fix(hub): close race", true},
+		{"unknown type", defaults, "ci: bump runner", false},
+		{"missing description", defaults, "feat: ", false},
+		{"custom type", custom, "ci: bump runner", true},
+		{"custom list drops defaults", custom, "docs: typo", false},
+		{"allowed scope", scoped, "feat(hub): x", true},
+		{"no scope with allowlist", scoped, "feat: x", true},
+		{"scope outside allowlist", scoped, "feat(client): x", false},
+		{"scope prefix is not a match", scoped, "feat(hubx): x", false},
+		{"regex syntax is quoted", config{Types: []string{"a.c"}}, "abc: x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.headerPattern().MatchString(tt.msg); got != tt.want {
+				t.Errorf("match(%q) = %v, want %v", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := loadConfig(filepath.Join(dir, "missing.yml"))
+	if err != nil {
+		t.Fatalf("missing file: %v", err)
+	}
+	if len(cfg.Types) != len(defaultTypes) || cfg.Scopes != nil {
+		t.Errorf("missing file should give defaults, got %+v", cfg)
+	}
+
+	path := filepath.Join(dir, ".commitlintrc.yml")
+	if err := os.WriteFile(path, []byte("scopes: [hub, dap]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = loadConfig(path)
+	if err != nil {
+		t.Fatalf("scopes only: %v", err)
+	}
+	if len(cfg.Types) != len(defaultTypes) || len(cfg.Scopes) != 2 {
+		t.Errorf("scopes-only file should keep default types, got %+v", cfg)
+	}
+
+	if err := os.WriteFile(path, []byte("types: [feat, ci]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = loadConfig(path)
+	if err != nil {
+		t.Fatalf("types: %v", err)
+	}
+	if len(cfg.Types) != 2 || cfg.Types[1] != "ci" {
+		t.Errorf("types should replace the defaults, got %+v", cfg.Types)
+	}
+
+	if err := os.WriteFile(path, []byte("types: [feat\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(path); err == nil {
+		t.Error("malformed file should be an error")
+	}
+}
+
+func TestLint(t *testing.T) {
+	defaults := config{Types: defaultTypes}
+	tests := []struct {
+		name string
+		cfg  config
+		msg  string
+		want []string
+	}{
+		{"clean subject", defaults, "feat(hub): add idle timeout\n", nil},
+		{"clean with body", defaults, "fix: close race\n\nThe reader could outlive the conn.\n", nil},
+		{"git comments ignored", defaults, "fix: close race\n# Please enter the commit message\n# On branch main\n", nil},
+		{"bad header", defaults, "added stuff\n", []string{"header does not follow Conventional Commits format"}},
+		{"trailing period", defaults, "docs: fix typo.\n", []string{"subject must not end with a period"}},
+		{"no blank line", defaults, "feat: x\nbody straight after\n", []string{"leave a blank line between the subject and the body"}},
+		{"too long", defaults, "feat: " + strings.Repeat("a", 70) + "\n", []string{"subject is 76 characters; keep it to 72"}},
+		{"configured limit", config{Types: defaultTypes, MaxSubjectLength: 10}, "feat: abcde\n", []string{"subject is 11 characters; keep it to 10"}},
+		{"multibyte counts runes", config{Types: defaultTypes, MaxSubjectLength: 10}, "feat: ✓✓✓✓\n", nil},
+		{"every violation reported", config{Types: defaultTypes, MaxSubjectLength: 10}, "oops: too long here.\nbody\n", []string{
+			"header does not follow Conventional Commits format",
+			"subject is 20 characters; keep it to 10",
+			"subject must not end with a period",
+			"leave a blank line between the subject and the body",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range lint(tt.msg, tt.cfg) {
+				got = append(got, v.msg)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("lint(%q) = %q, want %q", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
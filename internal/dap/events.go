@@ -1,6 +1,8 @@
 package dap
 
 import (
+	"fmt"
+
 	godap "github.com/google/go-dap"
 
 	"github.com/bingosuite/bingo/pkg/protocol"
@@ -22,6 +24,8 @@ func (h *Handler) translateEvent(evt protocol.Event) {
 		h.onBreakpointSet(evt)
 	case protocol.EventBreakpointCleared:
 		h.onBreakpointCleared()
+	case protocol.EventBreakpointLost:
+		h.onBreakpointLost(evt)
 	case protocol.EventLocals:
 		h.onLocals(evt)
 	case protocol.EventFrames:
@@ -32,6 +36,12 @@ func (h *Handler) translateEvent(evt protocol.Event) {
 		h.onRestarted()
 	case protocol.EventError:
 		h.onError(evt)
+	case protocol.EventNotice:
+		h.onNotice(evt)
+	case protocol.EventBreakpointError:
+		h.onBreakpointError(evt)
+	case protocol.EventBreakpointWarning:
+		h.onBreakpointWarning(evt)
 	case protocol.EventSessionState:
 		// For a JOINING connection, the hub's welcome state seeds the joiner's
 		// initial DAP state. For the normal launch/attach path it is
@@ -191,6 +201,64 @@ func (h *Handler) onOutput(evt protocol.Event) {
 	h.send(&godap.OutputEvent{Event: h.event("output"), Body: godap.OutputEventBody{Category: category, Output: p.Content}})
 }
 
+// onBreakpointLost marks the breakpoint unverified in the IDE. DAP breakpoint
+// ids are bingo ids, so a "changed" event can name it directly.
+func (h *Handler) onBreakpointLost(evt protocol.Event) {
+	var p protocol.BreakpointLostPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return
+	}
+	h.send(&godap.BreakpointEvent{
+		Event: h.event("breakpoint"),
+		Body: godap.BreakpointEventBody{
+			Reason: "changed",
+			Breakpoint: godap.Breakpoint{
+				Id:       p.Breakpoint.ID,
+				Verified: false,
+				Line:     p.Breakpoint.Location.Line,
+				Source:   dapSource(p.Breakpoint.Location),
+				Message:  p.Message,
+			},
+		},
+	})
+}
+
+// onNotice echoes a no-op notice to the debug console.
+func (h *Handler) onNotice(evt protocol.Event) {
+	var p protocol.NoticePayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return
+	}
+	h.send(&godap.OutputEvent{Event: h.event("output"), Body: godap.OutputEventBody{Category: "console", Output: p.Message + "\n"}})
+}
+
+// onBreakpointError surfaces a failed server-side default breakpoint on the
+// console. DAP has no breakpoint for it to mark unverified: the client never
+// asked for it.
+func (h *Handler) onBreakpointError(evt protocol.Event) {
+	var p protocol.BreakpointErrorPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return
+	}
+	h.send(&godap.OutputEvent{Event: h.event("output"), Body: godap.OutputEventBody{
+		Category: "stderr",
+		Output:   fmt.Sprintf("breakpoint %s:%d not set: %s\n", p.Location.File, p.Location.Line, p.Message),
+	}})
+}
+
+// onBreakpointWarning surfaces a risky breakpoint on the console. The
+// breakpoint itself is set and verified as usual.
+func (h *Handler) onBreakpointWarning(evt protocol.Event) {
+	var p protocol.BreakpointWarningPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return
+	}
+	h.send(&godap.OutputEvent{Event: h.event("output"), Body: godap.OutputEventBody{
+		Category: "console",
+		Output:   fmt.Sprintf("breakpoint %s:%d: %s\n", p.Breakpoint.Location.File, p.Breakpoint.Location.Line, p.Message),
+	}})
+}
+
 func (h *Handler) onBreakpointSet(evt protocol.Event) {
 	var p protocol.BreakpointSetPayload
 	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
@@ -97,8 +97,11 @@ func (h *Handler) onLaunch(req *godap.LaunchRequest) {
 
 	h.announceSession()
 
+	// The handshake above waits on the entry stop, so the server's initial
+	// stop mode must not let the process run past it.
 	cmd, err := marshalCommand(protocol.CmdLaunch, protocol.LaunchPayload{
 		Program: cfg.Program, Args: cfg.Args, Env: cfg.Env,
+		InitialStop: protocol.InitialStopEntry,
 	})
 	if err != nil {
 		h.send(h.errorResponse(req.Seq, "launch", err.Error()))
@@ -277,12 +280,16 @@ func (h *Handler) onContinue(req *godap.ContinueRequest) {
 	h.suspended = false
 	h.mu.Unlock()
 
-	if cmd, err := marshalCommand(protocol.CmdContinue, nil); err == nil {
+	var payload any
+	if req.Arguments.SingleThread {
+		payload = protocol.ContinuePayload{Scope: protocol.ScopeThread}
+	}
+	if cmd, err := marshalCommand(protocol.CmdContinue, payload); err == nil {
 		h.enqueue(cmd)
 	}
 	h.send(&godap.ContinueResponse{
 		Response: h.response(req.Seq, "continue"),
-		Body:     godap.ContinueResponseBody{AllThreadsContinued: true},
+		Body:     godap.ContinueResponseBody{AllThreadsContinued: !req.Arguments.SingleThread},
 	})
 }
 
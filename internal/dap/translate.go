@@ -100,8 +100,12 @@ func dapThreads(gs []protocol.Goroutine) []godap.Thread {
 	}
 	out := make([]godap.Thread, 0, len(gs))
 	for _, g := range gs {
+		// What a blocked goroutine waits on says more than that it waits.
 		name := "goroutine " + strconv.Itoa(g.ID)
-		if g.Status != "" {
+		switch {
+		case g.WaitReason != "":
+			name += " (" + g.WaitReason + ")"
+		case g.Status != "":
 			name += " (" + g.Status + ")"
 		}
 		out = append(out, godap.Thread{Id: threadID(g.ID), Name: name})
@@ -92,6 +92,19 @@ func TestDapThreadsMapsGoroutines(t *testing.T) {
 	}
 }
 
+func TestDapThreadsNamesWaitReason(t *testing.T) {
+	out := dapThreads([]protocol.Goroutine{
+		{ID: 1, Status: "running"},
+		{ID: 7, Status: "waiting", WaitReason: "chan receive"},
+	})
+	if out[0].Name != "goroutine 1 (running)" {
+		t.Errorf("running goroutine name = %q", out[0].Name)
+	}
+	if out[1].Name != "goroutine 7 (chan receive)" {
+		t.Errorf("blocked goroutine name = %q, want its wait reason", out[1].Name)
+	}
+}
+
 func TestDapVariables(t *testing.T) {
 	out := dapVariables([]protocol.Variable{{Name: "x", Value: "0x1", Type: "int"}})
 	if len(out) != 1 || out[0].Name != "x" || out[0].Value != "0x1" || out[0].VariablesReference != 0 {
@@ -1,5 +1,7 @@
 package debugger
 
+import "os"
+
 // Backend is the OS-level contract every platform must satisfy. The engine
 // never calls ptrace/Mach directly — only Backend. Except for Wait, methods are
 // called from the engine's event-loop goroutine. Wait runs in a separate locked
@@ -43,6 +45,53 @@ func setPID(b Backend, pid int) {
 	}
 }
 
+// threadEventer is implemented by backends that can report thread creation
+// and exit while the tracee runs (currently linux). Off by default: each
+// report costs a round trip through the engine loop per clone/exit.
+type threadEventer interface {
+	setThreadEvents(on bool)
+}
+
+// threadParker is implemented by backends whose stops are per-thread
+// (currently linux). While the engine is suspended at one thread's stop the
+// others keep running, so several threads can hit breakpoints at once; the
+// engine collects those stops and holds the threads until the next Continue.
+type threadParker interface {
+	// pollStops returns the stops already pending, without blocking.
+	pollStops() ([]StopEvent, error)
+	// continueThread resumes one held thread.
+	continueThread(tid int) error
+}
+
+// threadStater is implemented by backends that can tell which threads are
+// stopped without stopping them (currently linux, from /proc).
+type threadStater interface {
+	stoppedThreads() ([]int, error)
+}
+
+// dwarfRegisterer is implemented by backends that can read a thread's
+// general-purpose registers (currently linux), returned indexed by DWARF
+// register number: where the Go ABI passes arguments, which InspectArgs
+// reads. Registers only carries what the engine itself steers by.
+type dwarfRegisterer interface {
+	dwarfRegisters(tid int) ([]uint64, error)
+}
+
+// terminalBackend is implemented by backends that can launch the tracee on a
+// pseudo-terminal instead of the server's own stdio (currently linux).
+type terminalBackend interface {
+	setPTY(on bool)
+	// terminal returns the PTY master of the launched tracee, or nil when it
+	// was not launched on one. The engine owns it from then on.
+	terminal() *os.File
+}
+
+// stdinBackend is implemented by backends that can choose what a launched
+// tracee reads as stdin: the null device unless told to inherit the server's.
+type stdinBackend interface {
+	setInheritStdin(on bool)
+}
+
 type StopReason uint8
 
 const (
@@ -51,6 +100,18 @@ const (
 	StopSignal                       // any other signal
 	StopExited                       // process exit()
 	StopKilled                       // killed externally
+
+	// StopGone: the tracee vanished without an observable exit status —
+	// wait4 reported ECHILD (nothing left to wait for) or the process was
+	// reaped elsewhere. Treated as an exit with an unknown code.
+	StopGone
+
+	// StopThreadStarted and StopThreadExited are not stops: with thread
+	// events enabled the backend reports a tracee thread being created or
+	// exiting after it has already let that thread run on, so the process
+	// is still running when the engine sees them.
+	StopThreadStarted
+	StopThreadExited
 )
 
 // StopEvent is what Backend.Wait returns. PC may be zero; the engine resolves
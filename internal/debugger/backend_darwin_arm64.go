@@ -57,6 +57,10 @@ type darwinBackend struct {
 	// yields no *exec.Cmd, so this flag replaces the old cmd!=nil check.
 	launched bool
 
+	// inheritStdin gives the tracee the server's stdin instead of /dev/null.
+	// Set at construction.
+	inheritStdin bool
+
 	// Mach exception-port machinery (issue #92). portSet is the receive set Wait
 	// blocks on; excPort receives EXC_BREAKPOINT (software BRK and hardware
 	// single-step both raise it); notePort receives the dead-name notification
@@ -129,6 +133,8 @@ type darwinBackend struct {
 	suspendProbeOn bool
 }
 
+func (b *darwinBackend) setInheritStdin(on bool) { b.inheritStdin = on }
+
 // darwinPauseSignal is the sentinel PauseSignal the engine matches a manual-stop
 // StopSignal against. Under the Mach model no real signal is sent for Pause —
 // StopProcess posts to ctrlPort and Wait synthesises StopSignal{darwinPauseSignal}
@@ -213,7 +219,11 @@ func startTracedProcess(b Backend, binaryPath string, args []string, env []strin
 	envp = append(envp, nil)
 
 	var cpid C.int
-	rc := C.bingo_posix_spawn(cpath, &argv[0], &envp[0], &cpid)
+	var nullStdin C.int
+	if !db.inheritStdin {
+		nullStdin = 1
+	}
+	rc := C.bingo_posix_spawn(cpath, &argv[0], &envp[0], nullStdin, &cpid)
 	if rc != 0 {
 		return 0, nil, fmt.Errorf("posix_spawn %q: %s", binaryPath, C.GoString(C.strerror(rc)))
 	}
@@ -910,8 +920,8 @@ func (b *darwinBackend) stopTheWorld() (died bool, err error) {
 }
 
 // reap collects the tracee's exit status after a dead-name notification. A
-// concurrent kill path may have already reaped it (ECHILD), which is treated as
-// a normal exit.
+// concurrent kill path may have already reaped it (ECHILD), in which case the
+// status is lost and the stop is reported as StopGone.
 func (b *darwinBackend) reap() (StopEvent, error) {
 	var ws syscall.WaitStatus
 	for {
@@ -920,7 +930,7 @@ func (b *darwinBackend) reap() (StopEvent, error) {
 			break
 		}
 		if isNoChildProcess(err) {
-			return StopEvent{Reason: StopExited, TID: b.pid}, nil
+			return StopEvent{Reason: StopGone, TID: b.pid}, nil
 		}
 		if errors.Is(err, syscall.EINTR) {
 			continue
@@ -3,6 +3,7 @@
 package debugger
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
@@ -10,6 +11,8 @@ import (
 	"runtime"
 	"sync"
 	"syscall"
+
+	"github.com/creack/pty"
 )
 
 func newBackend() Backend {
@@ -95,8 +98,38 @@ type linuxBackend struct {
 	stepTID     int  // the exact thread SingleStep was issued against
 	lastStopTID int
 	tracer      *tracerThread
+
+	// threadEvents makes Wait report thread creation and exit; set once at
+	// construction, before Wait can run.
+	threadEvents bool
+
+	// usePTY launches the tracee on a fresh pseudo-terminal; tty is its
+	// master once launched. Both are only touched on the engine loop.
+	usePTY bool
+	tty    *os.File
+
+	// inheritStdin gives the tracee the server's stdin instead of the null
+	// device. Set at construction.
+	inheritStdin bool
+}
+
+func (b *linuxBackend) setThreadEvents(on bool) { b.threadEvents = on }
+
+// TextSlide returns how far the tracee's PIE binary was loaded from its
+// link-time addresses, or 0 for a fixed-address binary or on any error.
+func (b *linuxBackend) TextSlide(binaryPath string) int64 {
+	slide, err := elfSlide(b.pid, binaryPath)
+	if err != nil {
+		return 0
+	}
+	return slide
 }
 
+func (b *linuxBackend) setInheritStdin(on bool) { b.inheritStdin = on }
+
+func (b *linuxBackend) setPTY(on bool)     { b.usePTY = on }
+func (b *linuxBackend) terminal() *os.File { return b.tty }
+
 func (b *linuxBackend) execPtrace(fn func()) { b.tracer.execPtrace(fn) }
 
 // closeTracer releases the dedicated tracer thread. The engine calls this after
@@ -121,7 +154,11 @@ func startTracedProcess(b Backend, binaryPath string, args []string, env []strin
 
 	// codeql-suppress[go/command-injection]: The debugger intentionally launches the local binary selected by the operator.
 	cmd := exec.Command(binaryPath, args...)
-	cmd.Stdin = os.Stdin
+	// A nil Stdin is the null device: a tracee that reads stdin gets EOF
+	// rather than competing with the server for its input.
+	if lb, ok := b.(*linuxBackend); ok && lb.inheritStdin {
+		cmd.Stdin = os.Stdin
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.SysProcAttr = &syscall.SysProcAttr{Ptrace: true}
@@ -129,6 +166,23 @@ func startTracedProcess(b Backend, binaryPath string, args []string, env []strin
 		cmd.Env = append(os.Environ(), env...)
 	}
 
+	// On a PTY the tracee gets its own session with the slave as controlling
+	// terminal, so it sees a real TTY (line editing, isatty) rather than the
+	// server's stdio. Only the master stays open here once it has started.
+	var master *os.File
+	if lb, ok := b.(*linuxBackend); ok && lb.usePTY {
+		m, slave, err := pty.Open()
+		if err != nil {
+			return 0, nil, fmt.Errorf("open pty: %w", err)
+		}
+		defer func() { _ = slave.Close() }()
+		master = m
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+		cmd.SysProcAttr.Setsid = true
+		cmd.SysProcAttr.Setctty = true
+		cmd.SysProcAttr.Ctty = 0 // the child's stdin, i.e. the slave
+	}
+
 	var startErr error
 	tracer.execPtrace(func() {
 		if err := cmd.Start(); err != nil {
@@ -137,14 +191,18 @@ func startTracedProcess(b Backend, binaryPath string, args []string, env []strin
 		}
 		pid := cmd.Process.Pid
 		var ws syscall.WaitStatus
-		if _, err := syscall.Wait4(pid, &ws, 0, nil); err != nil {
+		if _, err := wait4Retrying(pid, &ws, 0); err != nil {
 			_ = cmd.Process.Kill()
 			startErr = fmt.Errorf("wait for execve stop: %w", err)
 			return
 		}
-		if !ws.Stopped() || ws.StopSignal() != syscall.SIGTRAP {
-			_ = cmd.Process.Kill()
-			startErr = fmt.Errorf("unexpected initial stop: %v", ws)
+		if err := initialStopErr(ws); err != nil {
+			// An exited child was just reaped by the Wait4 above; signalling
+			// its PID now could hit an unrelated process that reused it.
+			if !ws.Exited() && !ws.Signaled() {
+				_ = cmd.Process.Kill()
+			}
+			startErr = err
 			return
 		}
 		if err := syscall.PtraceSetOptions(pid, linuxPtraceOptions); err != nil {
@@ -154,12 +212,34 @@ func startTracedProcess(b Backend, binaryPath string, args []string, env []strin
 		}
 	})
 	if startErr != nil {
+		if master != nil {
+			_ = master.Close()
+		}
 		return 0, nil, startErr
 	}
+	if master != nil {
+		b.(*linuxBackend).tty = master
+	}
 
 	return cmd.Process.Pid, cmd, nil
 }
 
+// initialStopErr reports why ws is not the execve SIGTRAP a traced child
+// stops with before its first instruction. A child that dies first (a failed
+// exec or loader) must abort the launch: the engine would otherwise go on to
+// set breakpoints against a PID that no longer exists.
+func initialStopErr(ws syscall.WaitStatus) error {
+	switch {
+	case ws.Exited():
+		return fmt.Errorf("process exited with status %d before its first instruction", ws.ExitStatus())
+	case ws.Signaled():
+		return fmt.Errorf("process killed by %v before its first instruction", ws.Signal())
+	case !ws.Stopped() || ws.StopSignal() != syscall.SIGTRAP:
+		return fmt.Errorf("unexpected initial stop: %v", ws)
+	}
+	return nil
+}
+
 func attachToProcess(b Backend, pid int) error {
 	tracer, ok := b.(tracerExecer)
 	if !ok {
@@ -172,7 +252,7 @@ func attachToProcess(b Backend, pid int) error {
 			return
 		}
 		var ws syscall.WaitStatus
-		if _, err := syscall.Wait4(pid, &ws, 0, nil); err != nil {
+		if _, err := wait4Retrying(pid, &ws, 0); err != nil {
 			attachErr = fmt.Errorf("wait after PTRACE_ATTACH: %w", err)
 			return
 		}
@@ -259,7 +339,7 @@ func (b *linuxBackend) ContinueProcess() error {
 	b.stepTID = 0
 	tid := b.traceTID()
 	var err error
-	b.execPtrace(func() { err = syscall.PtraceCont(tid, 0) })
+	b.execPtrace(func() { err = ptraceContRetrying(tid, 0) })
 	if err != nil {
 		return fmt.Errorf("PTRACE_CONT tid %d: %w", tid, err)
 	}
@@ -305,11 +385,58 @@ func (b *linuxBackend) StopProcess() error {
 // that the engine turns into EventPaused. See Backend.PauseSignal.
 func (b *linuxBackend) PauseSignal() int { return int(syscall.SIGSTOP) }
 
+// ptracePeekData and ptracePokeData are the syscalls behind ReadMemory and
+// WriteMemory, variables so tests can make them transfer less than asked.
+// wait4 and ptraceCont are variables so tests can interrupt them.
+var (
+	ptracePeekData = syscall.PtracePeekData
+	ptracePokeData = syscall.PtracePokeData
+	wait4          = syscall.Wait4
+	ptraceCont     = syscall.PtraceCont
+)
+
+// ignoringEINTR calls fn until it returns something other than EINTR. The
+// Go runtime signals its threads (preemption, profiling) and the tracee's
+// own signals reach us too; any of them can interrupt a blocking wait4 or
+// a ptrace request, which then did nothing and only needs asking again.
+func ignoringEINTR(fn func() error) error {
+	for {
+		if err := fn(); !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+	}
+}
+
+// wait4Retrying is wait4 with EINTR retried.
+func wait4Retrying(pid int, ws *syscall.WaitStatus, options int) (int, error) {
+	var wpid int
+	err := ignoringEINTR(func() error {
+		var err error
+		wpid, err = wait4(pid, ws, options, nil)
+		return err
+	})
+	return wpid, err
+}
+
+// ptraceContRetrying is ptraceCont with EINTR retried. Call on the tracer
+// thread.
+func ptraceContRetrying(tid, signal int) error {
+	return ignoringEINTR(func() error { return ptraceCont(tid, signal) })
+}
+
+// ReadMemory fills all of dst or fails: a partly filled buffer saved as a
+// breakpoint's original bytes would be written back over the code later.
 func (b *linuxBackend) ReadMemory(addr uint64, dst []byte) error {
 	tid := b.traceTID()
 	var n int
 	var err error
-	b.execPtrace(func() { n, err = syscall.PtracePeekData(tid, uintptr(addr), dst) })
+	b.execPtrace(func() {
+		err = ignoringEINTR(func() error {
+			var err error
+			n, err = ptracePeekData(tid, uintptr(addr), dst)
+			return err
+		})
+	})
 	if err != nil {
 		return fmt.Errorf("PTRACE_PEEKDATA tid %d 0x%x: %w", tid, addr, err)
 	}
@@ -319,11 +446,18 @@ func (b *linuxBackend) ReadMemory(addr uint64, dst []byte) error {
 	return nil
 }
 
+// WriteMemory writes all of src or fails, for the same reason.
 func (b *linuxBackend) WriteMemory(addr uint64, src []byte) error {
 	tid := b.traceTID()
 	var n int
 	var err error
-	b.execPtrace(func() { n, err = syscall.PtracePokeData(tid, uintptr(addr), src) })
+	b.execPtrace(func() {
+		err = ignoringEINTR(func() error {
+			var err error
+			n, err = ptracePokeData(tid, uintptr(addr), src)
+			return err
+		})
+	})
 	if err != nil {
 		return fmt.Errorf("PTRACE_POKEDATA tid %d 0x%x: %w", tid, addr, err)
 	}
@@ -349,6 +483,22 @@ func (b *linuxBackend) GetRegisters(tid int) (Registers, error) {
 	}, nil
 }
 
+// dwarfRegisters returns the general-purpose registers in DWARF's amd64
+// numbering: RAX, RDX, RCX, RBX, RSI, RDI, RBP, RSP, R8-R15, then RIP.
+func (b *linuxBackend) dwarfRegisters(tid int) ([]uint64, error) {
+	var r syscall.PtraceRegs
+	var err error
+	b.execPtrace(func() { err = syscall.PtraceGetRegs(tid, &r) })
+	if err != nil {
+		return nil, fmt.Errorf("PTRACE_GETREGS tid %d: %w", tid, err)
+	}
+	return []uint64{
+		r.Rax, r.Rdx, r.Rcx, r.Rbx, r.Rsi, r.Rdi, r.Rbp, r.Rsp,
+		r.R8, r.R9, r.R10, r.R11, r.R12, r.R13, r.R14, r.R15,
+		r.Rip,
+	}, nil
+}
+
 // SetRegisters writes back the engine-owned fields, preserving everything else
 // by reading the full register set first.
 func (b *linuxBackend) SetRegisters(tid int, reg Registers) error {
@@ -391,6 +541,37 @@ func (b *linuxBackend) Threads() ([]int, error) {
 	return tids, nil
 }
 
+// stoppedThreads lists the threads /proc shows in tracing stop (t) or job
+// control stop (T). A thread that exits mid-scan is skipped.
+func (b *linuxBackend) stoppedThreads() ([]int, error) {
+	tids, err := b.Threads()
+	if err != nil {
+		return nil, err
+	}
+	var stopped []int
+	for _, tid := range tids {
+		stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/task/%d/stat", b.pid, tid))
+		if err != nil {
+			continue
+		}
+		if s := procState(stat); s == 't' || s == 'T' {
+			stopped = append(stopped, tid)
+		}
+	}
+	return stopped, nil
+}
+
+// procState returns the state letter of a /proc stat line. The command name
+// before it is parenthesised and may itself contain spaces and parentheses,
+// so the state is found after the last ')'.
+func procState(stat []byte) byte {
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 || i+2 >= len(stat) {
+		return 0
+	}
+	return stat[i+2]
+}
+
 // Wait blocks until the tracee produces a meaningful debug stop. Single-step
 // vs breakpoint disambiguation uses b.stepping AND b.stepTID: only a cause==0
 // SIGTRAP on the exact thread we stepped is the step's completion; the same
@@ -401,169 +582,237 @@ func (b *linuxBackend) Threads() ([]int, error) {
 // wait4 runs on the calling (waitLoop) thread, NOT the tracer thread: waiting
 // for a tracee is legal from any thread of the tracer process, and keeping it
 // off the tracer thread lets the engine issue control ops concurrently. Every
-// ptrace CONTROL op below, however, is funnelled through b.execPtrace so it
-// executes on the one thread the kernel accepts ptrace requests from.
-//
-//nolint:gocognit,gocyclo // The wait loop is one serialized ptrace state machine.
+// ptrace CONTROL op in classify, however, is funnelled through b.execPtrace so
+// it executes on the one thread the kernel accepts ptrace requests from.
 func (b *linuxBackend) Wait() (StopEvent, error) {
 	for {
 		var ws syscall.WaitStatus
 		// WALL includes clone()d threads.
-		tid, err := syscall.Wait4(-1, &ws, syscall.WALL, nil)
+		tid, err := wait4Retrying(-1, &ws, syscall.WALL)
 		if err != nil {
 			if isNoChildProcess(err) {
-				return StopEvent{Reason: StopExited, TID: b.pid}, nil
+				return StopEvent{Reason: StopGone, TID: b.pid}, nil
 			}
 			return StopEvent{}, fmt.Errorf("wait4: %w", err)
 		}
-
-		if ws.Exited() {
-			if tid == b.pid {
-				b.recordStop(tid)
-				return StopEvent{Reason: StopExited, TID: tid, ExitCode: ws.ExitStatus()}, nil
-			}
-			continue
+		evt, report, err := b.classify(tid, ws)
+		if err != nil {
+			return StopEvent{}, err
 		}
+		if report {
+			return evt, nil
+		}
+	}
+}
 
-		if ws.Signaled() {
-			if tid != b.pid {
-				continue
-			}
+// classify turns one wait4 status into the stop the engine should see, and
+// reports false for stops handled here (the thread already resumed).
+//
+//nolint:gocognit,gocyclo // One serialized ptrace state machine.
+func (b *linuxBackend) classify(tid int, ws syscall.WaitStatus) (StopEvent, bool, error) {
+	if ws.Exited() {
+		if tid == b.pid {
 			b.recordStop(tid)
-			return StopEvent{Reason: StopKilled, TID: tid}, nil
+			return StopEvent{Reason: StopExited, TID: tid, ExitCode: ws.ExitStatus()}, true, nil
 		}
+		return StopEvent{}, false, nil
+	}
 
-		if !ws.Stopped() {
-			continue
+	if ws.Signaled() {
+		if tid != b.pid {
+			return StopEvent{}, false, nil
 		}
+		b.recordStop(tid)
+		return StopEvent{Reason: StopKilled, TID: tid}, true, nil
+	}
+
+	if !ws.Stopped() {
+		return StopEvent{}, false, nil
+	}
 
-		sig := ws.StopSignal()
+	sig := ws.StopSignal()
 
-		// PTRACE_EVENT stops are encoded as SIGTRAP | (event << 8).
-		if sig == syscall.SIGTRAP {
-			cause := ws.TrapCause()
+	// PTRACE_EVENT stops are encoded as SIGTRAP | (event << 8).
+	if sig == syscall.SIGTRAP {
+		cause := ws.TrapCause()
 
-			switch cause {
-			case syscall.PTRACE_EVENT_CLONE:
+		switch cause {
+		case syscall.PTRACE_EVENT_CLONE:
+			// The new thread's TID is only readable while the parent is
+			// still parked at the clone stop.
+			var child uint
+			var msgErr error
+			if b.threadEvents {
+				b.execPtrace(func() { child, msgErr = syscall.PtraceGetEventMsg(tid) })
+			}
+			if err := b.continueIfTraceeExists(tid, 0); err != nil {
+				return StopEvent{}, false, fmt.Errorf("PTRACE_CONT clone parent tid %d: %w", tid, err)
+			}
+			if b.threadEvents && msgErr == nil {
+				return StopEvent{Reason: StopThreadStarted, TID: int(child)}, true, nil
+			}
+			return StopEvent{}, false, nil
+
+		case syscall.PTRACE_EVENT_EXIT:
+			if tid != b.pid {
 				if err := b.continueIfTraceeExists(tid, 0); err != nil {
-					return StopEvent{}, fmt.Errorf("PTRACE_CONT clone parent tid %d: %w", tid, err)
+					return StopEvent{}, false, fmt.Errorf("PTRACE_CONT exiting thread tid %d: %w", tid, err)
 				}
-				continue
-
-			case syscall.PTRACE_EVENT_EXIT:
-				if tid != b.pid {
-					if err := b.continueIfTraceeExists(tid, 0); err != nil {
-						return StopEvent{}, fmt.Errorf("PTRACE_CONT exiting thread tid %d: %w", tid, err)
-					}
-					continue
+				if b.threadEvents {
+					return StopEvent{Reason: StopThreadExited, TID: tid}, true, nil
 				}
-				// Main thread is about to exit. PTRACE_O_TRACEEXIT stops it here
-				// BEFORE it dies, and the engine tears down on this StopExited, so
-				// the real status never resurfaces as a later wait4 Exited()/
-				// Signaled(). Read it now via PTRACE_GETEVENTMSG (a wait(2)-encoded
-				// status) so a non-zero exit or a fatal signal isn't misreported as
-				// a clean exit 0. GETEVENTMSG must run before we resume the thread —
-				// once continued it is gone and the message is unreadable.
-				var msg uint
-				var msgErr error
-				b.execPtrace(func() { msg, msgErr = syscall.PtraceGetEventMsg(tid) })
-				if err := b.continueIfTraceeExists(tid, 0); err != nil {
-					return StopEvent{}, fmt.Errorf("PTRACE_CONT exiting process tid %d: %w", tid, err)
-				}
-				b.recordStop(tid)
-				if msgErr == nil {
-					status := syscall.WaitStatus(msg)
-					switch {
-					case status.Signaled():
-						return StopEvent{Reason: StopKilled, TID: tid}, nil
-					case status.Exited():
-						return StopEvent{Reason: StopExited, TID: tid, ExitCode: status.ExitStatus()}, nil
-					}
+				return StopEvent{}, false, nil
+			}
+			// Main thread is about to exit. PTRACE_O_TRACEEXIT stops it here
+			// BEFORE it dies, and the engine tears down on this StopExited, so
+			// the real status never resurfaces as a later wait4 Exited()/
+			// Signaled(). Read it now via PTRACE_GETEVENTMSG (a wait(2)-encoded
+			// status) so a non-zero exit or a fatal signal isn't misreported as
+			// a clean exit 0. GETEVENTMSG must run before we resume the thread —
+			// once continued it is gone and the message is unreadable.
+			var msg uint
+			var msgErr error
+			b.execPtrace(func() { msg, msgErr = syscall.PtraceGetEventMsg(tid) })
+			if err := b.continueIfTraceeExists(tid, 0); err != nil {
+				return StopEvent{}, false, fmt.Errorf("PTRACE_CONT exiting process tid %d: %w", tid, err)
+			}
+			b.recordStop(tid)
+			if msgErr == nil {
+				status := syscall.WaitStatus(msg)
+				switch {
+				case status.Signaled():
+					return StopEvent{Reason: StopKilled, TID: tid}, true, nil
+				case status.Exited():
+					return StopEvent{Reason: StopExited, TID: tid, ExitCode: status.ExitStatus()}, true, nil
 				}
-				// Status unreadable (e.g. ESRCH racing a Kill) or unexpected shape:
-				// fall back to a clean exit rather than inventing a code.
-				return StopEvent{Reason: StopExited, TID: tid, ExitCode: 0}, nil
+			}
+			// Status unreadable (e.g. ESRCH racing a Kill) or unexpected shape:
+			// fall back to a clean exit rather than inventing a code.
+			return StopEvent{Reason: StopExited, TID: tid, ExitCode: 0}, true, nil
 
-			case syscall.PTRACE_EVENT_EXEC:
-				if err := b.continueIfTraceeExists(tid, 0); err != nil {
-					return StopEvent{}, fmt.Errorf("PTRACE_CONT exec tid %d: %w", tid, err)
-				}
-				continue
-
-			case 0:
-				b.recordStop(tid)
-
-				// Only the exact thread we single-stepped produces a
-				// single-step SIGTRAP. A cause==0 SIGTRAP on any OTHER thread
-				// while a step is in flight is that thread hitting a software
-				// breakpoint (INT3), not the step completing — classify it as a
-				// breakpoint so the engine's step-over state machine isn't fed a
-				// bogus StopSingleStep for the wrong thread.
-				if b.stepping && tid == b.stepTID {
-					b.stepping = false
-					b.stepTID = 0
-					return StopEvent{Reason: StopSingleStep, TID: tid}, nil
-				}
+		case syscall.PTRACE_EVENT_EXEC:
+			if err := b.continueIfTraceeExists(tid, 0); err != nil {
+				return StopEvent{}, false, fmt.Errorf("PTRACE_CONT exec tid %d: %w", tid, err)
+			}
+			return StopEvent{}, false, nil
 
-				return StopEvent{
-					Reason: StopBreakpoint,
-					TID:    tid,
-				}, nil
+		case 0:
+			b.recordStop(tid)
 
-			default:
-				if err := b.continueIfTraceeExists(tid, 0); err != nil {
-					return StopEvent{}, fmt.Errorf("PTRACE_CONT trap cause %d tid %d: %w", cause, tid, err)
-				}
-				continue
+			// Only the exact thread we single-stepped produces a
+			// single-step SIGTRAP. A cause==0 SIGTRAP on any OTHER thread
+			// while a step is in flight is that thread hitting a software
+			// breakpoint (INT3), not the step completing — classify it as a
+			// breakpoint so the engine's step-over state machine isn't fed a
+			// bogus StopSingleStep for the wrong thread.
+			if b.stepping && tid == b.stepTID {
+				b.stepping = false
+				b.stepTID = 0
+				return StopEvent{Reason: StopSingleStep, TID: tid}, true, nil
 			}
-		}
 
-		if sig == syscall.SIGSTOP && tid != b.pid {
-			// A newly cloned thread's initial group-stop. With
-			// PTRACE_O_TRACECLONE the kernel auto-attaches it and it inherits
-			// our ptrace options, so we just resume THIS thread. Crucially we
-			// must NOT touch the rest of the group: another thread may be
-			// stopped at a breakpoint waiting for the engine, and a
-			// group-continue here would let it run away (the exact "parking the
-			// thread group" hazard that kept clone tracing disabled before).
+			return StopEvent{
+				Reason: StopBreakpoint,
+				TID:    tid,
+			}, true, nil
+
+		default:
 			if err := b.continueIfTraceeExists(tid, 0); err != nil {
-				return StopEvent{}, fmt.Errorf("PTRACE_CONT new thread tid %d: %w", tid, err)
+				return StopEvent{}, false, fmt.Errorf("PTRACE_CONT trap cause %d tid %d: %w", cause, tid, err)
 			}
-			continue
+			return StopEvent{}, false, nil
 		}
+	}
 
-		// SIGURG is Go's goroutine-preemption signal; it must be re-delivered
-		// transparently during both step and continue or scheduling breaks.
-		if sig == syscall.SIGURG {
-			// Re-issue the single-step only for the thread actually being
-			// stepped; a SIGURG on any other thread must be re-delivered and
-			// the thread continued, never single-stepped.
-			if b.stepping && tid == b.stepTID {
-				if err := b.singleStepIfTraceeExists(tid); err != nil {
-					return StopEvent{}, fmt.Errorf("PTRACE_SINGLESTEP after SIGURG tid %d: %w", tid, err)
-				}
-			} else {
-				if err := b.continueIfTraceeExists(tid, int(sig)); err != nil {
-					return StopEvent{}, fmt.Errorf("PTRACE_CONT SIGURG tid %d: %w", tid, err)
-				}
+	if sig == syscall.SIGSTOP && tid != b.pid {
+		// A newly cloned thread's initial group-stop. With
+		// PTRACE_O_TRACECLONE the kernel auto-attaches it and it inherits
+		// our ptrace options, so we just resume THIS thread. Crucially we
+		// must NOT touch the rest of the group: another thread may be
+		// stopped at a breakpoint waiting for the engine, and a
+		// group-continue here would let it run away (the exact "parking the
+		// thread group" hazard that kept clone tracing disabled before).
+		// The kernel may report this stop before or after the parent's
+		// PTRACE_EVENT_CLONE stop; each is resumed on its own, so neither
+		// waits on the other.
+		if err := b.continueIfTraceeExists(tid, 0); err != nil {
+			return StopEvent{}, false, fmt.Errorf("PTRACE_CONT new thread tid %d: %w", tid, err)
+		}
+		return StopEvent{}, false, nil
+	}
+
+	// SIGURG is Go's goroutine-preemption signal; it must be re-delivered
+	// transparently during both step and continue or scheduling breaks.
+	if sig == syscall.SIGURG {
+		// Re-issue the single-step only for the thread actually being
+		// stepped; a SIGURG on any other thread must be re-delivered and
+		// the thread continued, never single-stepped.
+		if b.stepping && tid == b.stepTID {
+			if err := b.singleStepIfTraceeExists(tid); err != nil {
+				return StopEvent{}, false, fmt.Errorf("PTRACE_SINGLESTEP after SIGURG tid %d: %w", tid, err)
+			}
+		} else {
+			if err := b.continueIfTraceeExists(tid, int(sig)); err != nil {
+				return StopEvent{}, false, fmt.Errorf("PTRACE_CONT SIGURG tid %d: %w", tid, err)
 			}
-			continue
 		}
+		return StopEvent{}, false, nil
+	}
 
-		if sig == syscall.SIGCONT {
-			if err := b.continueIfTraceeExists(tid, 0); err != nil {
-				return StopEvent{}, fmt.Errorf("PTRACE_CONT SIGCONT tid %d: %w", tid, err)
+	if sig == syscall.SIGCONT {
+		if err := b.continueIfTraceeExists(tid, 0); err != nil {
+			return StopEvent{}, false, fmt.Errorf("PTRACE_CONT SIGCONT tid %d: %w", tid, err)
+		}
+		return StopEvent{}, false, nil
+	}
+
+	b.recordStop(tid)
+	return StopEvent{
+		Reason: StopSignal,
+		TID:    tid,
+		Signal: int(sig),
+	}, true, nil
+
+}
+
+// pollStops reaps, without blocking, the stops of threads that stopped while
+// the engine was suspended at another thread's. Stops Wait would handle
+// silently are handled the same way here. lastStopTID is left alone: the
+// thread the engine reported is still the one ContinueProcess resumes.
+func (b *linuxBackend) pollStops() ([]StopEvent, error) {
+	last := b.lastStopTID
+	defer func() { b.lastStopTID = last }()
+
+	var stops []StopEvent
+	for {
+		var ws syscall.WaitStatus
+		tid, err := wait4Retrying(-1, &ws, syscall.WALL|syscall.WNOHANG)
+		if err != nil {
+			if isNoChildProcess(err) {
+				return stops, nil
 			}
-			continue
+			return stops, fmt.Errorf("wait4: %w", err)
+		}
+		if tid <= 0 {
+			return stops, nil
 		}
+		evt, report, err := b.classify(tid, ws)
+		if err != nil {
+			return stops, err
+		}
+		if report {
+			stops = append(stops, evt)
+		}
+	}
+}
 
-		b.recordStop(tid)
-		return StopEvent{
-			Reason: StopSignal,
-			TID:    tid,
-			Signal: int(sig),
-		}, nil
+// continueThread resumes one thread parked by the engine, discarding any
+// signal it stopped with, as ContinueProcess does.
+func (b *linuxBackend) continueThread(tid int) error {
+	if err := b.continueIfTraceeExists(tid, 0); err != nil {
+		return fmt.Errorf("PTRACE_CONT tid %d: %w", tid, err)
 	}
+	return nil
 }
 
 var _ Backend = (*linuxBackend)(nil)
@@ -599,7 +848,7 @@ func (b *linuxBackend) continueIfTraceeExists(tid int, signal int) error {
 		return nil
 	}
 	var err error
-	b.execPtrace(func() { err = syscall.PtraceCont(tid, signal) })
+	b.execPtrace(func() { err = ptraceContRetrying(tid, signal) })
 	if err != nil && !isNoSuchProcess(err) {
 		return err
 	}
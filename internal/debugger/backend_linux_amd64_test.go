@@ -2,7 +2,26 @@
 
 package debugger
 
-import "testing"
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestProcStateSkipsParenthesisedName(t *testing.T) {
+	for stat, want := range map[string]byte{
+		"1001 (target) t 1 1001":        't',
+		"1002 (a) (b) ) T 1 1001":       'T',
+		"1003 (worker pool) R 1 1001 0": 'R',
+		"garbage":                       0,
+	} {
+		if got := procState([]byte(stat)); got != want {
+			t.Errorf("procState(%q) = %q, want %q", stat, got, want)
+		}
+	}
+}
 
 func TestLinuxBackendTraceTIDDefaultsToPID(t *testing.T) {
 	const pid = 1001
@@ -37,3 +56,155 @@ func TestLinuxBackendSetPIDSeedsLastStoppedTID(t *testing.T) {
 		t.Fatalf("traceTID() = %d, want pid %d", got, pid)
 	}
 }
+
+func TestInitialStopErrAcceptsExecveTrap(t *testing.T) {
+	ws := syscall.WaitStatus(0x7f | uint32(syscall.SIGTRAP)<<8)
+	if err := initialStopErr(ws); err != nil {
+		t.Fatalf("initialStopErr(SIGTRAP stop) = %v, want nil", err)
+	}
+}
+
+func TestInitialStopErrRejectsEarlyExit(t *testing.T) {
+	cases := []struct {
+		name string
+		ws   syscall.WaitStatus
+		want string
+	}{
+		{"exited", syscall.WaitStatus(127 << 8), "exited with status 127"},
+		{"signaled", syscall.WaitStatus(syscall.SIGSEGV), "killed by segmentation fault"},
+		{"other stop", syscall.WaitStatus(0x7f | uint32(syscall.SIGSTOP)<<8), "unexpected initial stop"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := initialStopErr(tc.ws)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("initialStopErr() = %v, want error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestLinuxBackendRejectsShortTransfers(t *testing.T) {
+	peek, poke := ptracePeekData, ptracePokeData
+	t.Cleanup(func() { ptracePeekData, ptracePokeData = peek, poke })
+	ptracePeekData = func(_ int, _ uintptr, out []byte) (int, error) {
+		copy(out, []byte{0xcc})
+		return 1, nil
+	}
+	ptracePokeData = func(int, uintptr, []byte) (int, error) { return 1, nil }
+
+	b := &linuxBackend{pid: 1001, tracer: newTracerThread()}
+	t.Cleanup(b.closeTracer)
+
+	if err := b.ReadMemory(0x1000, make([]byte, 8)); err == nil || !strings.Contains(err.Error(), "short read 1/8") {
+		t.Errorf("ReadMemory = %v, want a short read error", err)
+	}
+	if err := b.WriteMemory(0x1000, make([]byte, 8)); err == nil || !strings.Contains(err.Error(), "short write 1/8") {
+		t.Errorf("WriteMemory = %v, want a short write error", err)
+	}
+}
+
+// interruptOnce returns a counter and reports EINTR on the first call, as a
+// syscall a signal landed in would.
+func interruptOnce() (calls *int, interrupted func() bool) {
+	calls = new(int)
+	return calls, func() bool {
+		*calls++
+		return *calls == 1
+	}
+}
+
+func TestLinuxBackendRetriesInterruptedSyscalls(t *testing.T) {
+	peek, poke, wait, cont := ptracePeekData, ptracePokeData, wait4, ptraceCont
+	t.Cleanup(func() { ptracePeekData, ptracePokeData, wait4, ptraceCont = peek, poke, wait, cont })
+
+	peeks, peekEINTR := interruptOnce()
+	ptracePeekData = func(_ int, _ uintptr, out []byte) (int, error) {
+		if peekEINTR() {
+			return 0, syscall.EINTR
+		}
+		return copy(out, []byte{1, 2, 3, 4}), nil
+	}
+	pokes, pokeEINTR := interruptOnce()
+	ptracePokeData = func(_ int, _ uintptr, data []byte) (int, error) {
+		if pokeEINTR() {
+			return 0, syscall.EINTR
+		}
+		return len(data), nil
+	}
+	conts, contEINTR := interruptOnce()
+	ptraceCont = func(int, int) error {
+		if contEINTR() {
+			return syscall.EINTR
+		}
+		return nil
+	}
+	waits, waitEINTR := interruptOnce()
+	wait4 = func(pid int, _ *syscall.WaitStatus, _ int, _ *syscall.Rusage) (int, error) {
+		if waitEINTR() {
+			return 0, syscall.EINTR
+		}
+		return pid, nil
+	}
+
+	b := &linuxBackend{pid: 1001, tracer: newTracerThread()}
+	t.Cleanup(b.closeTracer)
+
+	if err := b.ReadMemory(0x1000, make([]byte, 4)); err != nil || *peeks != 2 {
+		t.Errorf("ReadMemory = %v after %d calls, want success on the retry", err, *peeks)
+	}
+	if err := b.WriteMemory(0x1000, make([]byte, 4)); err != nil || *pokes != 2 {
+		t.Errorf("WriteMemory = %v after %d calls, want success on the retry", err, *pokes)
+	}
+	if err := b.ContinueProcess(); err != nil || *conts != 2 {
+		t.Errorf("ContinueProcess = %v after %d calls, want success on the retry", err, *conts)
+	}
+	var ws syscall.WaitStatus
+	if wpid, err := wait4Retrying(1001, &ws, 0); err != nil || wpid != 1001 || *waits != 2 {
+		t.Errorf("wait4Retrying = %d, %v after %d calls, want 1001 on the retry", wpid, err, *waits)
+	}
+}
+
+// launchedStdin launches /bin/true on b, stopped at its execve, and returns
+// what its fd 0 points at.
+func launchedStdin(t *testing.T, b Backend) string {
+	t.Helper()
+	defer b.(*linuxBackend).closeTracer()
+	pid, cmd, err := startTracedProcess(b, "/bin/true", nil, nil)
+	if err != nil {
+		t.Fatalf("startTracedProcess: %v", err)
+	}
+	defer func() { _ = killProcess(b, pid, cmd, false) }()
+	link, err := os.Readlink(fmt.Sprintf("/proc/%d/fd/0", pid))
+	if err != nil {
+		t.Fatalf("readlink tracee stdin: %v", err)
+	}
+	return link
+}
+
+func TestStartTracedProcessDefaultsStdinToNullDevice(t *testing.T) {
+	if got := launchedStdin(t, newBackend()); got != os.DevNull {
+		t.Fatalf("tracee stdin = %q, want %q", got, os.DevNull)
+	}
+}
+
+func TestStartTracedProcessInheritsStdinWhenAsked(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close(); _ = w.Close() }()
+	want, err := os.Readlink(fmt.Sprintf("/proc/self/fd/%d", r.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = saved }()
+
+	b := newBackend()
+	b.(*linuxBackend).setInheritStdin(true)
+	if got := launchedStdin(t, b); got != want {
+		t.Fatalf("tracee stdin = %q, want the server's %q", got, want)
+	}
+}
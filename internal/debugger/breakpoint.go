@@ -1,15 +1,14 @@
 package debugger
 
 import (
-	"errors"
+	"bytes"
 	"fmt"
+	"sort"
 	"sync/atomic"
 
 	"github.com/bingosuite/bingo/pkg/protocol"
 )
 
-var errBreakpointExists = errors.New("breakpoint already installed at address")
-
 type breakpointEntry struct {
 	id            int
 	addr          uint64
@@ -17,6 +16,15 @@ type breakpointEntry struct {
 	line          int
 	originalBytes []byte
 	enabled       bool
+	// ignore is how many more hits resume without stopping (gdb's ignore
+	// count). handleStop decrements it on each hit it skips.
+	ignore int
+	// goroutineCond, when set, is checked at each hit once ignore is used
+	// up; a hit where it does not hold resumes. condErrReported records
+	// that a failure to evaluate it has been reported, which only the first
+	// one is.
+	goroutineCond   *protocol.GoroutineCountCondition
+	condErrReported bool
 }
 
 func (b *breakpointEntry) toProtocol() protocol.Breakpoint {
@@ -27,7 +35,22 @@ func (b *breakpointEntry) toProtocol() protocol.Breakpoint {
 			File: b.file,
 			Line: b.line,
 		},
+		IgnoreCount:    b.ignore,
+		GoroutineCount: b.goroutineCond,
+	}
+}
+
+// where describes the entry's address for an error message.
+func (b *breakpointEntry) where() string { return where(b.addr, b.file, b.line) }
+
+// where describes addr for an error message: the address and, when it is a
+// known source line, that line. A failed ptrace read or write only says
+// which call failed; the line says which breakpoint it was for.
+func where(addr uint64, file string, line int) string {
+	if line <= 0 {
+		return fmt.Sprintf("0x%x", addr)
 	}
+	return fmt.Sprintf("0x%x (%s:%d)", addr, file, line)
 }
 
 // breakpointTable owns installed breakpoints for one debug session.
@@ -36,6 +59,9 @@ type breakpointTable struct {
 	byID   map[int]*breakpointEntry
 	byAddr map[uint64]*breakpointEntry
 	nextID atomic.Int64
+
+	// verify is Options.VerifyTraps: read every trap back after writing it.
+	verify bool
 }
 
 func newBreakpointTable() *breakpointTable {
@@ -46,20 +72,23 @@ func newBreakpointTable() *breakpointTable {
 }
 
 // set patches addr with the trap instruction, saves the overwritten bytes,
-// and records the entry. Returns errBreakpointExists if already installed.
+// and records the entry. Returns ErrBreakpointExists if already installed,
+// leaving the installed entry and the bytes it saved untouched.
 func (t *breakpointTable) set(b Backend, file string, line int, addr uint64) (*breakpointEntry, error) {
 	if _, exists := t.byAddr[addr]; exists {
-		return nil, fmt.Errorf("%w: 0x%x (%s:%d)", errBreakpointExists, addr, file, line)
+		return nil, fmt.Errorf("%w: %s", ErrBreakpointExists, where(addr, file, line))
 	}
 
 	trap := archTrapInstruction()
 	orig := make([]byte, len(trap))
 
 	if err := b.ReadMemory(addr, orig); err != nil {
-		return nil, fmt.Errorf("breakpoint set: read original bytes at 0x%x: %w", addr, err)
+		return nil, fmt.Errorf("breakpoint set: read original bytes at %s: %w", where(addr, file, line), err)
 	}
-	if err := b.WriteMemory(addr, trap); err != nil {
-		return nil, fmt.Errorf("breakpoint set: write trap at 0x%x: %w", addr, err)
+	if err := t.writeTrap(b, addr, file, line); err != nil {
+		// A write that didn't stick may still have landed partly.
+		_ = b.WriteMemory(addr, orig)
+		return nil, fmt.Errorf("breakpoint set: %w", err)
 	}
 
 	id := int(t.nextID.Add(1))
@@ -76,13 +105,36 @@ func (t *breakpointTable) set(b Backend, file string, line int, addr uint64) (*b
 	return entry, nil
 }
 
+// writeTrap patches addr with the trap instruction and, with verify on, reads
+// it back. A write can report success and still not take — a read-only
+// mapping the kernel quietly COWs elsewhere, code that is rewritten behind us
+// — and then the breakpoint simply never fires; verification turns that into
+// an error at set time.
+func (t *breakpointTable) writeTrap(b Backend, addr uint64, file string, line int) error {
+	trap := archTrapInstruction()
+	if err := b.WriteMemory(addr, trap); err != nil {
+		return fmt.Errorf("write trap at %s: %w", where(addr, file, line), err)
+	}
+	if !t.verify {
+		return nil
+	}
+	got := make([]byte, len(trap))
+	if err := b.ReadMemory(addr, got); err != nil {
+		return fmt.Errorf("verify trap at %s: %w", where(addr, file, line), err)
+	}
+	if !bytes.Equal(got, trap) {
+		return fmt.Errorf("verify trap at %s: read back % x, want % x", where(addr, file, line), got, trap)
+	}
+	return nil
+}
+
 func (t *breakpointTable) clear(b Backend, id int) error {
 	entry, ok := t.byID[id]
 	if !ok {
 		return fmt.Errorf("breakpoint %d not found", id)
 	}
 	if err := b.WriteMemory(entry.addr, entry.originalBytes); err != nil {
-		return fmt.Errorf("breakpoint clear: restore bytes at 0x%x: %w", entry.addr, err)
+		return fmt.Errorf("breakpoint clear: restore bytes at %s: %w", entry.where(), err)
 	}
 	delete(t.byID, id)
 	delete(t.byAddr, entry.addr)
@@ -105,15 +157,65 @@ func (t *breakpointTable) addToTable(entry *breakpointEntry) {
 	t.byAddr[entry.addr] = entry
 }
 
+// reinstall leaves a disabled entry's original bytes in place: it was
+// disabled while the step-over was pending and must stay unarmed.
 func (t *breakpointTable) reinstall(b Backend, entry *breakpointEntry) error {
-	trap := archTrapInstruction()
-	if err := b.WriteMemory(entry.addr, trap); err != nil {
-		return fmt.Errorf("breakpoint reinstall at 0x%x: %w", entry.addr, err)
+	if entry.enabled {
+		if err := t.writeTrap(b, entry.addr, entry.file, entry.line); err != nil {
+			return fmt.Errorf("breakpoint reinstall: %w", err)
+		}
 	}
 	t.addToTable(entry)
 	return nil
 }
 
+// disable restores entry's original bytes but keeps it in the table, so its
+// ID and location survive until enable re-arms it. No-op if already disabled.
+func (t *breakpointTable) disable(b Backend, entry *breakpointEntry) error {
+	if !entry.enabled {
+		return nil
+	}
+	if err := b.WriteMemory(entry.addr, entry.originalBytes); err != nil {
+		return fmt.Errorf("breakpoint disable: restore bytes at %s: %w", entry.where(), err)
+	}
+	entry.enabled = false
+	return nil
+}
+
+// enable writes the trap back over a disabled entry. No-op if already enabled.
+func (t *breakpointTable) enable(b Backend, entry *breakpointEntry) error {
+	if entry.enabled {
+		return nil
+	}
+	if err := t.writeTrap(b, entry.addr, entry.file, entry.line); err != nil {
+		return fmt.Errorf("breakpoint enable: %w", err)
+	}
+	entry.enabled = true
+	return nil
+}
+
+// userEntries returns the user-visible breakpoints in ascending ID order,
+// leaving out the engine's internal step/entry sentinels.
+func (t *breakpointTable) userEntries() []*breakpointEntry {
+	out := make([]*breakpointEntry, 0, len(t.byID))
+	for _, entry := range t.byID {
+		if isInternalBreakpoint(entry) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].id < out[j].id })
+	return out
+}
+
+func isInternalBreakpoint(entry *breakpointEntry) bool {
+	switch entry.file {
+	case stepOverNextFile, stepOutReturnFile, entryFile:
+		return true
+	}
+	return false
+}
+
 // clearAll best-effort restores all breakpoints during Kill; ignores per-entry
 // failures so a bad write doesn't block shutdown.
 func (t *breakpointTable) clearAll(b Backend) {
@@ -0,0 +1,20 @@
+//go:build amd64
+
+package debugger
+
+// archFrameCFA returns the canonical frame address of the innermost frame,
+// the frame base Go's DWARF offsets arguments from: the stack pointer before
+// the CALL pushed the return address. At the function's first instruction
+// that is SP+8; once the prologue has pushed and set BP it is BP+16. The one
+// instruction between the two, and the rare function built without a frame
+// pointer, get the wrong answer; DWARF's call frame information would say,
+// but bingo does not read it.
+func archFrameCFA(regs Registers, atEntry bool) (uint64, bool) {
+	if atEntry {
+		return regs.SP + 8, true
+	}
+	if regs.BP == 0 {
+		return 0, false
+	}
+	return regs.BP + 16, true
+}
@@ -0,0 +1,16 @@
+//go:build arm64
+
+package debugger
+
+// archFrameCFA returns the canonical frame address of the innermost frame,
+// the frame base Go's DWARF offsets arguments from. The link register holds
+// the return address, so at the function's first instruction that is SP
+// itself. Past the prologue it depends on the frame size, which only DWARF's
+// call frame information records and bingo does not read, so stack-passed
+// arguments are unreadable there; register-passed ones still are.
+func archFrameCFA(regs Registers, atEntry bool) (uint64, bool) {
+	if atEntry {
+		return regs.SP, true
+	}
+	return 0, false
+}
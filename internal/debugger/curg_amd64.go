@@ -0,0 +1,12 @@
+//go:build amd64
+
+package debugger
+
+// archCurrentG returns the address of the g the thread is running. On amd64
+// the Go runtime keeps it in the thread's TLS block, one word below FS_BASE.
+func archCurrentG(b Backend, regs Registers) (uint64, error) {
+	if regs.TLS == 0 {
+		return 0, nil
+	}
+	return readWord(b, regs.TLS-ptrSize)
+}
@@ -0,0 +1,9 @@
+//go:build arm64
+
+package debugger
+
+// archCurrentG returns the address of the g the thread is running. On arm64
+// the Go ABI keeps it in X28, which GetRegisters reports as TLS.
+func archCurrentG(_ Backend, regs Registers) (uint64, error) {
+	return regs.TLS, nil
+}
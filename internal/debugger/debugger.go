@@ -16,6 +16,17 @@ var (
 	ErrAlreadyRunning = errors.New("debugger: process already running")
 	ErrNoProcess      = errors.New("debugger: no process")
 	ErrNotRunning     = errors.New("debugger: process is not running")
+	ErrNotGoBinary    = errors.New("debugger: not a Go binary")
+	ErrNoTerminal     = errors.New("debugger: process has no terminal")
+
+	// ErrBreakpointExists is returned by SetBreakpoint, along with the
+	// breakpoint already there, when the line's address already has one.
+	ErrBreakpointExists = errors.New("debugger: breakpoint already set")
+
+	// ErrRuntimeCritical is returned by SetBreakpoint, with
+	// Options.BlockRuntimeBreakpoints, when the line is in a runtime function
+	// where stopping a thread can deadlock the whole program.
+	ErrRuntimeCritical = errors.New("debugger: breakpoint in runtime-critical function")
 )
 
 // Debugger is the interface consumed by the hub. All methods are goroutine-safe.
@@ -25,6 +36,16 @@ type Debugger interface {
 	// loaded automatically. env is appended to the server's environment.
 	Launch(binaryPath string, args []string, env []string) error
 
+	// SetInitialStop overrides Options.InitialStop for the next Launch. It
+	// fails, changing nothing, for a mode protocol.InitialStop doesn't define.
+	SetInitialStop(mode protocol.InitialStop) error
+
+	// SetInitialBreakpoints makes every later Launch and Attach set locs
+	// (File and Line only) before the process first runs. Each is reported
+	// with EventBreakpointSet, or EventBreakpointError when the target can't
+	// resolve it, ahead of any stop.
+	SetInitialBreakpoints(locs []protocol.Location) error
+
 	// Attach connects to a running PID and stops it. binaryPath is optional but
 	// required for breakpoints/locals/frames (DWARF source).
 	Attach(pid int, binaryPath string) error
@@ -32,14 +53,56 @@ type Debugger interface {
 	// Kill terminates the tracee. Idempotent.
 	Kill() error
 
+	// SetBreakpoint installs a breakpoint on file:line. If one is already
+	// installed there, it is left as it is and returned together with an
+	// error wrapping ErrBreakpointExists.
 	SetBreakpoint(file string, line int) (protocol.Breakpoint, error)
 	ClearBreakpoint(id int) error
 
+	// SetIgnoreCount makes breakpoint id let its next count hits go by
+	// without stopping; 0 makes it stop on the next one again. It returns
+	// the breakpoint with its new count.
+	SetIgnoreCount(id, count int) (protocol.Breakpoint, error)
+
+	// SetGoroutineCondition makes breakpoint id stop only on hits where
+	// the live goroutine count meets cond; nil removes the condition. A hit
+	// where the count can't be taken stops, and the first one is reported
+	// with EventConditionError.
+	SetGoroutineCondition(id int, cond *protocol.GoroutineCountCondition) (protocol.Breakpoint, error)
+
+	// DisableAllBreakpoints lifts every breakpoint's trap without forgetting
+	// it, so the process runs free; EnableAllBreakpoints re-arms them under
+	// the same IDs. Both return the breakpoints with their new Enabled flag.
+	DisableAllBreakpoints() ([]protocol.Breakpoint, error)
+	EnableAllBreakpoints() ([]protocol.Breakpoint, error)
+
+	// ClearAllBreakpoints clears every breakpoint and returns their IDs in
+	// ascending order, none if there were none. It stops at the first
+	// failed write, returning the IDs cleared before it with the error.
+	ClearAllBreakpoints() ([]int, error)
+
 	Continue() error
 	StepOver() error
 	StepInto() error
 	StepOut() error
 
+	// ContinueThread resumes only the current thread. Threads that stopped
+	// alongside the last breakpoint hit stay stopped until a later Continue;
+	// with none parked it is Continue.
+	ContinueThread() error
+
+	// StepInstruction executes a single machine instruction on the current
+	// thread and reports the new PC's location via EventStepped, with
+	// Instructions = 1. StepOver, by contrast, runs to the next source line.
+	StepInstruction() error
+
+	// StepOverN and StepIntoN run n steps and emit a single EventStepped
+	// (with Steps = n) at the end. A breakpoint, pause or exit on the way
+	// ends the run early: that stop is reported as usual, preceded by an
+	// EventNotice saying how many steps completed.
+	StepOverN(n int) error
+	StepIntoN(n int) error
+
 	// Pause asynchronously interrupts a running tracee, forcing it to suspend.
 	// It returns ErrNotRunning if the process is not currently running. The
 	// suspend itself is reported asynchronously via EventPaused, so a nil
@@ -48,18 +111,143 @@ type Debugger interface {
 
 	// Locals: frame 0 is innermost.
 	Locals(frameIndex int) ([]protocol.Variable, error)
+	// InspectArgs returns the arguments of the function the process is
+	// stopped in, read from registers or the stack as the Go ABI placed
+	// them. Most useful at a function-entry breakpoint.
+	InspectArgs() (protocol.ArgsPayload, error)
 	StackFrames() ([]protocol.Frame, error)
 	Goroutines() ([]protocol.Goroutine, error)
 
+	// ResolveLine reports the PC file:line maps to, without touching the
+	// tracee. When the line has no code the next executable line is used,
+	// and the returned Location carries the line actually resolved. It does
+	// not require the process to be suspended.
+	ResolveLine(file string, line int) (uint64, protocol.Location, error)
+
+	// ResolveFunction is ResolveLine for a line named relative to a
+	// function: offset lines past where a breakpoint on function stops, or
+	// with atReturn its last line. It errors when the line would fall
+	// outside the function.
+	ResolveFunction(function string, offset int, atReturn bool) (uint64, protocol.Location, error)
+
+	// SourcePath returns the path the binary's DWARF records for the source
+	// file named file, which may be a path suffix as for SetBreakpoint. It
+	// errors when no file, or more than one, matches.
+	SourcePath(file string) (string, error)
+
+	// AddrToLine maps a runtime address back to its source location. An
+	// address outside any function is not an error: it resolves to a
+	// Location whose Function is "unknown".
+	AddrToLine(pc uint64) (protocol.Location, error)
+
+	// ReadString decodes the Go string header at addr and returns its value
+	// and full length. At most 64 KiB of data is read, so len(value) < length
+	// means the value was truncated. Requires the process to be suspended.
+	ReadString(addr uint64) (value string, length uint64, err error)
+
+	// ReadSlice decodes the Go slice header at addr and returns its leading
+	// elements, elemSize raw bytes each, up to Options.MaxSliceElements.
+	// Requires the process to be suspended.
+	ReadSlice(addr uint64, elemSize int) (protocol.SliceValuePayload, error)
+
+	// WriteInput sends data to the process's terminal, as if typed at it.
+	// Only a process launched with Options.PTY has one; otherwise it returns
+	// ErrNoTerminal. Works whether the process is running or suspended.
+	WriteInput(data string) error
+
+	// Status reports the process's state as the engine sees it, and where it
+	// is stopped. Works in any state; the Session field is left zero.
+	Status() (protocol.StatusPayload, error)
+
 	// Events delivers async notifications. Closed on shutdown; caller must drain.
 	Events() <-chan protocol.Event
 }
 
+// Options configures optional engine behaviour. The zero value matches New.
+type Options struct {
+	// InitialStop is what Launch does at the initial exec stop, which lands
+	// deep in runtime startup code: report it (InitialStopEntry, also what
+	// empty means), run to the entry of main.main and report that instead,
+	// or let the process run on. Continue-to-main falls back to the initial
+	// stop when the binary has no DWARF or no main.main. SetInitialStop
+	// overrides it for one launch.
+	InitialStop protocol.InitialStop
+
+	// HitContext adds the stopping thread's PC/SP/BP to every
+	// EventBreakpointHit, for UIs that always show them and would otherwise
+	// need a round trip. Off by default to keep the event small.
+	HitContext bool
+
+	// MaxSliceElements caps how many elements ReadSlice returns; the slice's
+	// real len and cap are reported regardless. 0 means 256.
+	MaxSliceElements int
+
+	// ThreadEvents streams EventThreadStarted/EventThreadExited while the
+	// process runs, instead of threads only being visible at stops. Backends
+	// without thread tracing (darwin) ignore it.
+	ThreadEvents bool
+
+	// PTY launches the process on its own pseudo-terminal instead of the
+	// server's stdio. Everything it writes arrives as EventOutput (stream
+	// "tty") and WriteInput feeds its input. Attach is unaffected; backends
+	// without PTY support (darwin) ignore it.
+	PTY bool
+
+	// VerifyTraps reads every breakpoint trap back after writing it and fails
+	// the set (or reports the breakpoint lost, on re-arm) when it didn't
+	// take. Costs one extra memory read per write; meant for chasing
+	// breakpoints that never fire on unusual memory layouts.
+	VerifyTraps bool
+
+	// StoppedThreads lists every thread that is stopped at a breakpoint hit,
+	// and where, in EventBreakpointHit, for UIs that show all parked threads
+	// at once. Costs a /proc read per thread plus a register read and line
+	// lookup per stopped one; backends that stop every thread together
+	// (darwin) ignore it.
+	StoppedThreads bool
+
+	// BlockRuntimeBreakpoints makes SetBreakpoint refuse, with
+	// ErrRuntimeCritical, a line inside a runtime function known to deadlock
+	// the program when a thread stops there (scheduler, GC, allocator, runtime
+	// locks). Without it such a breakpoint is set and EventBreakpointWarning
+	// says why it is risky.
+	BlockRuntimeBreakpoints bool
+
+	// InheritStdin launches the process reading the server's own stdin. By
+	// default it reads the null device instead: the server's stdin belongs
+	// to whoever runs the server, and a target reading it would take input
+	// meant for them. PTY launches ignore it; their stdin is the terminal.
+	InheritStdin bool
+}
+
 // New returns a Debugger backed by the platform-native OS backend. log is the
 // single sink for all debugger logging; pass nil to fall back to
 // slog.Default().
 func New(log *slog.Logger) Debugger {
-	return newEngine(newBackend(), log)
+	return NewWithOptions(Options{}, log)
+}
+
+// NewWithOptions is New with explicit Options.
+func NewWithOptions(opts Options, log *slog.Logger) Debugger {
+	e := newEngine(newBackend(), log)
+	e.initialStop = opts.InitialStop
+	e.hitContext = opts.HitContext
+	if opts.MaxSliceElements > 0 {
+		e.maxSliceElements = opts.MaxSliceElements
+	}
+	if te, ok := e.backend.(threadEventer); ok {
+		te.setThreadEvents(opts.ThreadEvents)
+	}
+	if sb, ok := e.backend.(stdinBackend); ok {
+		sb.setInheritStdin(opts.InheritStdin)
+	}
+	if tb, ok := e.backend.(terminalBackend); ok {
+		tb.setPTY(opts.PTY)
+	}
+	e.bps.verify = opts.VerifyTraps
+	e.stoppedThreads = opts.StoppedThreads
+	e.blockRuntimeBPs = opts.BlockRuntimeBreakpoints
+	return e
 }
 
 // NewWithBackend returns a Debugger using the supplied Backend. Tests only.
@@ -0,0 +1,77 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// DebugInfo answers source-level questions about a binary from its DWARF
+// alone, with no process behind it. Addresses are the ones the binary was
+// linked at until AttachPID relocates them to a running copy. It is the
+// offline half of what the engine does with a live process, exported for
+// pkg/debuginfo the way Validate is for bingo validate.
+type DebugInfo struct {
+	r    *dwarfReader
+	path string
+}
+
+// NewDebugInfoFromFile loads the DWARF of the binary at path. It reads only
+// the file: no process needs to exist.
+func NewDebugInfoFromFile(path string) (*DebugInfo, error) {
+	r, err := openDWARF(path)
+	if err != nil {
+		return nil, err
+	}
+	return &DebugInfo{r: r, path: path}, nil
+}
+
+// AttachPID relocates every address d reports to where process pid, running
+// this binary, loaded it. Only a PIE binary moves. Linux only: the load
+// address is read from /proc/<pid>/maps.
+func (d *DebugInfo) AttachPID(pid int) error {
+	slide, err := elfSlide(pid, d.path)
+	if err != nil {
+		return fmt.Errorf("attach pid %d: %w", pid, err)
+	}
+	d.r.slide = slide
+	return nil
+}
+
+// LineToPC returns the address a breakpoint on file:line would be placed
+// at. file may be a path suffix, as with SetBreakpoint.
+func (d *DebugInfo) LineToPC(file string, line int) (uint64, error) {
+	return d.r.PCForFileLine(file, line)
+}
+
+// PCToLine returns the source location of pc. The function name alone is
+// filled in when pc is in code with no line table row.
+func (d *DebugInfo) PCToLine(pc uint64) (protocol.Location, error) {
+	loc := d.r.locationForPC(pc)
+	if loc.File == "" && loc.Function == "" {
+		return loc, fmt.Errorf("no source location for %#x", pc)
+	}
+	return loc, nil
+}
+
+// LookupFunc returns the address a breakpoint on the function named name
+// would be placed at: past its prologue, where the frame is set up.
+func (d *DebugInfo) LookupFunc(name string) (uint64, error) {
+	pc, ok := d.r.funcEntryPC(name)
+	if !ok {
+		return 0, fmt.Errorf("no function %q", name)
+	}
+	return pc, nil
+}
+
+// FuncLine returns the address and location of a line named relative to
+// the function named name: offset lines past its declaration, or with
+// atReturn its last line with code.
+func (d *DebugInfo) FuncLine(name string, offset int, atReturn bool) (uint64, protocol.Location, error) {
+	return d.r.funcLine(name, offset, atReturn)
+}
+
+// Files returns every source file the line tables cover, sorted.
+func (d *DebugInfo) Files() []string {
+	return d.r.sourceFiles()
+}
@@ -5,6 +5,7 @@ import (
 	"debug/elf"
 	"debug/macho"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"runtime"
@@ -24,6 +25,7 @@ const optimizedOut = "<optimized out>"
 type dwarfReader struct {
 	data  *dwarf.Data
 	slide int64
+	loc   locSections
 
 	// funcIndex is a lazily-built, lowpc-sorted table of every subprogram's
 	// [low,high) DWARF PC range and name. functionAt binary-searches it instead
@@ -33,6 +35,12 @@ type dwarfReader struct {
 	// wedge the single-threaded engine loop past the client's timeout.
 	funcIndexOnce sync.Once
 	funcIndex     []funcRange
+
+	// gLayout is where the runtime keeps its goroutines, found on the first
+	// goroutine listing; see goroutineLayout.
+	gLayoutOnce sync.Once
+	gLayout     *goroutineLayout
+	gLayoutErr  error
 }
 
 // funcRange is one subprogram's DWARF PC range (unslid) and name.
@@ -42,33 +50,46 @@ type funcRange struct {
 }
 
 func openDWARF(binaryPath string) (*dwarfReader, error) {
-	data, err := loadDWARFData(binaryPath)
+	data, loc, err := loadDWARFData(binaryPath)
 	if err != nil {
 		return nil, fmt.Errorf("openDWARF %q: %w", binaryPath, err)
 	}
-	return &dwarfReader{data: data}, nil
+	return &dwarfReader{data: data, loc: loc}, nil
 }
 
-func loadDWARFData(binaryPath string) (*dwarf.Data, error) {
+// loadDWARFData goes through the object file's DWARF method rather than
+// reading sections by hand: it transparently inflates both legacy .zdebug_*
+// sections and SHF_COMPRESSED ones, and the Go linker compresses by default.
+// The location-list sections, which debug/dwarf does not decode, are read
+// alongside.
+func loadDWARFData(binaryPath string) (*dwarf.Data, locSections, error) {
 	switch runtime.GOOS {
 	case "linux":
 		f, err := elf.Open(binaryPath)
 		if err != nil {
-			return nil, fmt.Errorf("elf.Open: %w", err)
+			return nil, locSections{}, fmt.Errorf("elf.Open: %w", err)
 		}
 		defer func() { _ = f.Close() }()
-		return f.DWARF()
+		data, err := f.DWARF()
+		if err != nil {
+			return nil, locSections{}, err
+		}
+		return data, readELFLocSections(f), nil
 
 	case "darwin":
 		f, err := macho.Open(binaryPath)
 		if err != nil {
-			return nil, fmt.Errorf("macho.Open: %w", err)
+			return nil, locSections{}, fmt.Errorf("macho.Open: %w", err)
 		}
 		defer func() { _ = f.Close() }()
-		return f.DWARF()
+		data, err := f.DWARF()
+		if err != nil {
+			return nil, locSections{}, err
+		}
+		return data, readMachOLocSections(f), nil
 
 	default:
-		return nil, fmt.Errorf("unsupported OS: %s", runtime.GOOS)
+		return nil, locSections{}, fmt.Errorf("unsupported OS: %s", runtime.GOOS)
 	}
 }
 
@@ -182,24 +203,17 @@ func (r *dwarfReader) locationForPC(pc uint64) protocol.Location {
 			continue
 		}
 
-		// Keep the entry whose address is the greatest <= dwarfPC.
-		var best dwarf.LineEntry
-		var found bool
+		// SeekPC finds the row covering dwarfPC within its own sequence. A
+		// linear "last address <= pc" scan is wrong here: Go CUs carry
+		// DW_AT_ranges rather than low/high PC, so cuContainsPC lets every CU
+		// through and the first one's rows would claim any higher address.
 		var le dwarf.LineEntry
-		for {
-			if err := lr.Next(&le); err != nil {
-				break
-			}
-			if le.Address <= dwarfPC {
-				best = le
-				found = true
-			} else {
-				break
-			}
+		if err := lr.SeekPC(dwarfPC, &le); err != nil {
+			continue
 		}
-		if found && best.File != nil {
-			loc.File = best.File.Name
-			loc.Line = best.Line
+		if le.File != nil {
+			loc.File = le.File.Name
+			loc.Line = le.Line
 			return loc
 		}
 	}
@@ -270,6 +284,213 @@ func (r *dwarfReader) functionAt(pc uint64) string {
 	return ""
 }
 
+// hasFunction reports whether a subprogram named name has code in the binary.
+func (r *dwarfReader) hasFunction(name string) bool {
+	r.funcIndexOnce.Do(r.buildFuncIndex)
+	for _, fn := range r.funcIndex {
+		if fn.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// funcEntryPC returns the runtime address at which a breakpoint on function
+// name should be placed: the first prologue_end row in its range, so the stop
+// lands past the stack-growth check with the frame set up, or the function's
+// low PC when the line table marks no prologue end.
+func (r *dwarfReader) funcEntryPC(name string) (uint64, bool) {
+	r.funcIndexOnce.Do(r.buildFuncIndex)
+	var fn funcRange
+	found := false
+	for _, f := range r.funcIndex {
+		if f.name == name {
+			fn, found = f, true
+			break
+		}
+	}
+	if !found {
+		return 0, false
+	}
+	entryPC := fn.low
+
+	rd := r.data.Reader()
+	for {
+		entry, err := rd.Next()
+		if err != nil || entry == nil {
+			break
+		}
+		if entry.Tag != dwarf.TagCompileUnit {
+			continue
+		}
+		if !cuContainsPC(entry, fn.low) {
+			rd.SkipChildren()
+			continue
+		}
+		lr, err := r.data.LineReader(entry)
+		if err != nil || lr == nil {
+			continue
+		}
+		var le dwarf.LineEntry
+		if err := lr.SeekPC(fn.low, &le); err != nil {
+			continue
+		}
+		for le.Address < fn.high {
+			if le.PrologueEnd {
+				entryPC = le.Address
+				break
+			}
+			if err := lr.Next(&le); err != nil {
+				break
+			}
+		}
+		break
+	}
+	return uint64(int64(entryPC) + r.slide), true
+}
+
+// funcLine resolves a location relative to function name: offset source
+// lines past the line it is declared on (where a breakpoint on the function
+// stops), or with atReturn its last line with code, the final return. A
+// target line with no code moves forward to the next one that has some.
+// Only lines of the function's own code count: an offset past its last
+// line, or a negative one, is an error rather than a breakpoint in whatever
+// follows.
+func (r *dwarfReader) funcLine(name string, offset int, atReturn bool) (uint64, protocol.Location, error) {
+	if offset < 0 {
+		return 0, protocol.Location{}, fmt.Errorf("%s%+d: offset must not be negative", name, offset)
+	}
+	entryPC, ok := r.funcEntryPC(name)
+	if !ok {
+		return 0, protocol.Location{}, fmt.Errorf("no function %q", name)
+	}
+	var fn funcRange
+	for _, f := range r.funcIndex {
+		if f.name == name {
+			fn = f
+			break
+		}
+	}
+	entry := r.locationForPC(entryPC)
+	if entry.File == "" {
+		return 0, protocol.Location{}, fmt.Errorf("no line information for %s", name)
+	}
+	// Count from the declaration: the prologue_end row funcEntryPC stops at
+	// is on the declaration line or the first body line, depending on how
+	// the function was compiled.
+	if decl, ok := r.funcDeclLine(fn.low); ok {
+		entry.Line = decl
+	}
+
+	// The statement rows of the function's own file, by line: inlined calls
+	// bring rows from other files that say nothing about its extent.
+	stmts := map[int]uint64{}
+	rd := r.data.Reader()
+	for {
+		cu, err := rd.Next()
+		if err != nil || cu == nil {
+			break
+		}
+		if cu.Tag != dwarf.TagCompileUnit {
+			continue
+		}
+		rd.SkipChildren()
+		if !cuContainsPC(cu, fn.low) {
+			continue
+		}
+		lr, err := r.data.LineReader(cu)
+		if err != nil || lr == nil {
+			continue
+		}
+		var le dwarf.LineEntry
+		if err := lr.SeekPC(fn.low, &le); err != nil {
+			continue
+		}
+		for le.Address < fn.high {
+			if le.IsStmt && le.File != nil && le.File.Name == entry.File {
+				if pc, seen := stmts[le.Line]; !seen || le.Address < pc {
+					stmts[le.Line] = le.Address
+				}
+			}
+			if err := lr.Next(&le); err != nil {
+				break
+			}
+		}
+		break
+	}
+	last := 0
+	for line := range stmts {
+		last = max(last, line)
+	}
+
+	target := entry.Line + offset
+	if atReturn {
+		target = last
+	}
+	if target > last {
+		return 0, protocol.Location{}, fmt.Errorf("%s+%d is line %d, past the function's last line %d", name, offset, target, last)
+	}
+	for line := target; line <= last; line++ {
+		if pc, ok := stmts[line]; ok {
+			return uint64(int64(pc) + r.slide), protocol.Location{File: entry.File, Line: line, Function: name}, nil
+		}
+	}
+	return 0, protocol.Location{}, fmt.Errorf("no code in %s from line %d on", name, target)
+}
+
+// funcDeclLine returns DW_AT_decl_line of the subprogram starting at
+// lowpc, an unslid DWARF address.
+func (r *dwarfReader) funcDeclLine(lowpc uint64) (int, bool) {
+	rd := r.data.Reader()
+	for {
+		entry, err := rd.Next()
+		if err != nil || entry == nil {
+			return 0, false
+		}
+		if entry.Tag != dwarf.TagSubprogram {
+			continue
+		}
+		if low, _ := entry.Val(dwarf.AttrLowpc).(uint64); low == lowpc {
+			line, ok := entry.Val(dwarf.AttrDeclLine).(int64)
+			return int(line), ok && line > 0
+		}
+		rd.SkipChildren()
+	}
+}
+
+// sourceFiles returns the distinct source files referenced by any line table,
+// i.e. the files breakpoints can be set in.
+func (r *dwarfReader) sourceFiles() []string {
+	seen := make(map[string]struct{})
+	rd := r.data.Reader()
+	for {
+		entry, err := rd.Next()
+		if err != nil || entry == nil {
+			break
+		}
+		if entry.Tag != dwarf.TagCompileUnit {
+			continue
+		}
+		rd.SkipChildren()
+		lr, err := r.data.LineReader(entry)
+		if err != nil || lr == nil {
+			continue
+		}
+		var le dwarf.LineEntry
+		for lr.Next(&le) == nil {
+			if le.File != nil && le.File.Name != "" {
+				seen[le.File.Name] = struct{}{}
+			}
+		}
+	}
+	files := make([]string, 0, len(seen))
+	for f := range seen {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
 // highPCValue extracts DW_AT_high_pc as an absolute address. The attribute may
 // be uint64 (DWARF v2 absolute) or int64 (v4+ offset from low_pc).
 func highPCValue(entry *dwarf.Entry, lowpc uint64) (uint64, bool) {
@@ -358,6 +579,118 @@ func (r *dwarfReader) LocalsForFrame(b Backend, pc, frameBase uint64) ([]protoco
 	return nil, nil
 }
 
+// ArgsForFrame returns the name of the function the stopped thread is in and
+// its parameters, results excluded. regs are the thread's registers and gpr
+// its general-purpose ones by DWARF number, nil where the backend can't read
+// them. It only describes the innermost frame: callers' argument registers
+// are long reused. Unlike
+// LocalsForFrame it follows location lists, so an argument is read from the
+// register the Go ABI passed it in until the function spills it, and from
+// the stack after. An argument that can't be read carries the reason as its
+// value; only a pc outside every function is an error.
+func (r *dwarfReader) ArgsForFrame(b Backend, regs Registers, gpr []uint64) (string, []protocol.Variable, error) {
+	dwarfPC := uint64(int64(regs.PC) - r.slide)
+	rd := r.data.Reader()
+	var cu *dwarf.Entry
+	for {
+		entry, err := rd.Next()
+		if err != nil {
+			return "", nil, fmt.Errorf("DWARF ArgsForFrame: %w", err)
+		}
+		if entry == nil {
+			break
+		}
+		if entry.Tag == dwarf.TagCompileUnit {
+			cu = entry
+			continue
+		}
+		if entry.Tag != dwarf.TagSubprogram {
+			continue
+		}
+
+		lowpc, hasLow := entry.Val(dwarf.AttrLowpc).(uint64)
+		if !hasLow {
+			rd.SkipChildren()
+			continue
+		}
+		highpc, ok := highPCValue(entry, lowpc)
+		if !ok || dwarfPC < lowpc || dwarfPC >= highpc {
+			rd.SkipChildren()
+			continue
+		}
+
+		fn, _ := entry.Val(dwarf.AttrName).(string)
+		base := cuLocBaseOf(cu)
+		fr := argFrame{gpr: gpr}
+		fr.cfa, fr.cfaOK = archFrameCFA(regs, dwarfPC == lowpc)
+		args := []protocol.Variable{}
+		for {
+			child, err := rd.Next()
+			if err == io.EOF || child == nil {
+				break
+			}
+			if err != nil {
+				return "", nil, fmt.Errorf("DWARF child read: %w", err)
+			}
+			if child.Tag == 0 {
+				break
+			}
+			if child.Children {
+				rd.SkipChildren()
+			}
+			if child.Tag != dwarf.TagFormalParameter {
+				continue
+			}
+			if result, _ := child.Val(dwarf.AttrVarParam).(bool); result {
+				continue
+			}
+			name, _ := child.Val(dwarf.AttrName).(string)
+			args = append(args, protocol.Variable{
+				Name:  name,
+				Type:  r.typeName(child),
+				Value: r.argValue(b, child, base, dwarfPC, fr),
+			})
+		}
+		return fn, args, nil
+	}
+	return "", nil, fmt.Errorf("no function at %#x", regs.PC)
+}
+
+// argValue reads and formats one parameter, or says why it can't.
+func (r *dwarfReader) argValue(b Backend, entry *dwarf.Entry, cu cuLocBase, dwarfPC uint64, fr argFrame) string {
+	expr, ok, err := r.locationExpr(entry, cu, dwarfPC)
+	if err != nil {
+		return fmt.Sprintf("<unreadable: %v>", err)
+	}
+	if !ok {
+		return optimizedOut
+	}
+	off, ok := entry.Val(dwarf.AttrType).(dwarf.Offset)
+	if !ok {
+		return "<unreadable: no type>"
+	}
+	t, err := r.data.Type(off)
+	if err != nil {
+		return fmt.Sprintf("<unreadable: %v>", err)
+	}
+	size := int(t.Size())
+	if size <= 0 {
+		return "<unreadable: unsized type>"
+	}
+	pieces, err := r.evalPieces(expr, fr)
+	if err != nil {
+		return fmt.Sprintf("<unreadable: %v>", err)
+	}
+	raw, err := readPieces(b, pieces, fr, size)
+	if err != nil {
+		if errors.Is(err, errOptimizedOut) {
+			return optimizedOut
+		}
+		return fmt.Sprintf("<unreadable: %v>", err)
+	}
+	return formatValue(b, t, raw)
+}
+
 func (r *dwarfReader) typeName(entry *dwarf.Entry) string {
 	off, ok := entry.Val(dwarf.AttrType).(dwarf.Offset)
 	if !ok {
@@ -1,10 +1,19 @@
 package debugger_test
 
 import (
+	"debug/elf"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/pkg/protocol"
 )
 
 var _ = Describe("decodeSLEB128", func() {
@@ -65,3 +74,420 @@ var _ = Describe("fileMatches", func() {
 		Entry("empty target", "/home/x/main.go", "", false),
 	)
 })
+
+var _ = Describe("Validate", func() {
+	It("reports a debuggable Go binary as OK", func() {
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+
+		report, err := debugger.Validate(bin)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Problems).To(BeEmpty())
+		Expect(report.HasDebugInfo).To(BeTrue())
+		Expect(report.HasMainMain).To(BeTrue())
+		Expect(report.GoVersion).To(HavePrefix("go"))
+		Expect(report.SourceFiles).To(BeNumerically(">", 0))
+	})
+
+	It("errors on a path that does not exist", func() {
+		_, err := debugger.Validate("/nonexistent/bingo-validate-target")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("errors on a file that is not an object file", func() {
+		f, err := os.CreateTemp("", "bingo-validate")
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = os.Remove(f.Name()) }()
+		_, _ = f.WriteString("#!/bin/sh\necho hi\n")
+		_ = f.Close()
+
+		_, err = debugger.Validate(f.Name())
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ResolveLine", func() {
+	var (
+		fb *fakeBackend
+		d  debugger.Debugger
+	)
+
+	BeforeEach(func() {
+		fb = newFakeBackend()
+		d = debugger.NewWithBackend(fb, nil)
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		debugger.ExportedLoadDWARF(d, bin)
+	})
+
+	AfterEach(func() {
+		_ = d.Kill()
+		if !fb.stopped {
+			close(fb.stopCh)
+			fb.stopped = true
+		}
+	})
+
+	It("resolves an executable line to the breakpoint PC", func() {
+		line := inspectMarkerLine("alpha-marker")
+		want, err := debugger.ExportedPCForFileLine(d, "fix.go", line)
+		Expect(err).NotTo(HaveOccurred())
+
+		pc, loc, err := d.ResolveLine("fix.go", line)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pc).To(Equal(want))
+		Expect(loc.Line).To(Equal(line))
+		Expect(loc.Function).To(Equal("main.alpha"))
+	})
+
+	It("moves a line without code to the next executable one", func() {
+		// Line 2 of the fixture is blank; func alpha starts on line 3.
+		pc, loc, err := d.ResolveLine("fix.go", 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pc).NotTo(BeZero())
+		Expect(loc.Line).To(Equal(3))
+		Expect(loc.Function).To(Equal("main.alpha"))
+	})
+
+	It("errors past the end of the file", func() {
+		_, _, err := d.ResolveLine("fix.go", 1000)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ResolveFunction", func() {
+	var (
+		fb *fakeBackend
+		d  debugger.Debugger
+	)
+
+	BeforeEach(func() {
+		fb = newFakeBackend()
+		d = debugger.NewWithBackend(fb, nil)
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		debugger.ExportedLoadDWARF(d, bin)
+	})
+
+	AfterEach(func() {
+		_ = d.Kill()
+		if !fb.stopped {
+			close(fb.stopCh)
+			fb.stopped = true
+		}
+	})
+
+	It("counts lines from the function's declaration", func() {
+		marker := inspectMarkerLine("alpha-marker")
+
+		pc, loc, err := d.ResolveFunction("main.alpha", 0, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pc).NotTo(BeZero())
+		Expect(loc.Line).To(Equal(marker-1), "func alpha")
+		Expect(loc.Function).To(Equal("main.alpha"))
+		Expect(loc.File).To(HaveSuffix("fix.go"))
+
+		_, loc, err = d.ResolveFunction("main.alpha", 1, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loc.Line).To(Equal(marker))
+	})
+
+	It("resolves the return site to the function's last line", func() {
+		pc, loc, err := d.ResolveFunction("main.alpha", 0, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pc).NotTo(BeZero())
+		// alpha ends in a return statement, so its closing brace has no code.
+		Expect(loc.Line).To(Equal(inspectMarkerLine("alpha-marker")+1), "return a")
+		Expect(loc.Function).To(Equal("main.alpha"))
+	})
+
+	It("refuses an offset past the function's end", func() {
+		_, _, err := d.ResolveFunction("main.alpha", 4, false)
+		Expect(err).To(MatchError(ContainSubstring("past the function's last line")))
+	})
+
+	It("refuses a negative offset and an unknown function", func() {
+		_, _, err := d.ResolveFunction("main.alpha", -1, false)
+		Expect(err).To(HaveOccurred())
+		_, _, err = d.ResolveFunction("main.nope", 0, false)
+		Expect(err).To(MatchError(ContainSubstring(`no function "main.nope"`)))
+	})
+})
+
+var _ = Describe("SetBreakpoint twice on one line", func() {
+	var (
+		fb *fakeBackend
+		d  debugger.Debugger
+	)
+
+	BeforeEach(func() {
+		fb = newFakeBackend()
+		d = debugger.NewWithBackend(fb, nil)
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		debugger.ExportedLoadDWARF(d, bin)
+	})
+
+	AfterEach(func() {
+		_ = d.Kill()
+		if !fb.stopped {
+			close(fb.stopCh)
+			fb.stopped = true
+		}
+	})
+
+	It("reports the first breakpoint as already set and keeps its saved bytes", func() {
+		line := inspectMarkerLine("alpha-marker")
+		pc, err := debugger.ExportedPCForFileLine(d, "fix.go", line)
+		Expect(err).NotTo(HaveOccurred())
+		orig := []byte{0x48, 0x89, 0xC0, 0x90}
+		fb.seedMem(pc, orig)
+
+		first, err := d.SetBreakpoint("fix.go", line)
+		Expect(err).NotTo(HaveOccurred())
+
+		again, err := d.SetBreakpoint("fix.go", line)
+		Expect(errors.Is(err, debugger.ErrBreakpointExists)).To(BeTrue(), "got %v", err)
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("already set at fix.go:%d", line)))
+		Expect(again).To(Equal(first))
+
+		// Had the second set saved the trap as the "original" bytes,
+		// clearing would leave the trap in place.
+		Expect(d.ClearBreakpoint(first.ID)).To(Succeed())
+		trap := debugger.ExportedTrapInstruction()
+		Expect(fb.peekMem(pc, len(trap))).To(Equal(orig[:len(trap)]))
+	})
+})
+
+var _ = Describe("runtimeCritical", func() {
+
+	DescribeTable("classifies by function name",
+		func(fn, want string) {
+			subsystem, ok := debugger.ExportedRuntimeCritical(fn)
+			Expect(ok).To(Equal(want != ""))
+			Expect(subsystem).To(Equal(want))
+		},
+		Entry("scheduler", "runtime.schedule", "scheduler"),
+		Entry("allocator", "runtime.mallocgc", "memory allocator"),
+		Entry("allocator method", "runtime.(*mheap).alloc", "memory allocator"),
+		Entry("runtime lock", "runtime.lock2", "runtime lock"),
+		Entry("GC family", "runtime.gcBgMarkWorker", "garbage collector"),
+		Entry("closure of a critical function", "runtime.schedule.func1", "scheduler"),
+		Entry("assembly ABI wrapper", "runtime.systemstack.abi0", "scheduler"),
+		Entry("signal handling", "runtime.sighandler", "signal handling"),
+		Entry("internal/runtime", "internal/runtime/atomic.Cas", "runtime internals"),
+		Entry("harmless runtime function", "runtime.gopanic", ""),
+		Entry("user function", "main.schedule", ""),
+		Entry("look-alike package", "runtimex.mallocgc", ""),
+		Entry("unknown", "", ""),
+	)
+})
+
+var _ = Describe("SetBreakpoint in a runtime-critical function", func() {
+	var (
+		fb   *fakeBackend
+		d    debugger.Debugger
+		file string
+		line int
+		pc   uint64
+	)
+
+	BeforeEach(func() {
+		fb = newFakeBackend()
+		d = debugger.NewWithBackend(fb, nil)
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		debugger.ExportedLoadDWARF(d, bin)
+
+		// Every Go binary links mallocgc; its entry line is as good a
+		// runtime line as any.
+		entry, ok := debugger.ExportedFuncEntryPC(d, "runtime.mallocgc")
+		Expect(ok).To(BeTrue())
+		loc, err := d.AddrToLine(entry)
+		Expect(err).NotTo(HaveOccurred())
+		file, line = loc.File, loc.Line
+		pc, err = debugger.ExportedPCForFileLine(d, file, line)
+		Expect(err).NotTo(HaveOccurred())
+		fb.seedMem(pc, []byte{0x48, 0x89, 0xC0, 0x90})
+	})
+
+	AfterEach(func() {
+		_ = d.Kill()
+		if !fb.stopped {
+			close(fb.stopCh)
+			fb.stopped = true
+		}
+	})
+
+	It("sets the breakpoint and warns why it is risky", func() {
+		bp, err := d.SetBreakpoint(file, line)
+		Expect(err).NotTo(HaveOccurred())
+
+		evt := mustNextEvent(d)
+		Expect(evt.Kind).To(Equal(protocol.EventBreakpointWarning))
+		var p protocol.BreakpointWarningPayload
+		Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+		Expect(p.Breakpoint).To(Equal(bp))
+		Expect(p.Function).To(Equal("runtime.mallocgc"))
+		Expect(p.Message).To(ContainSubstring("memory allocator"))
+		Expect(p.Message).To(ContainSubstring("deadlock"))
+	})
+
+	It("refuses the breakpoint when runtime breakpoints are blocked", func() {
+		debugger.ExportedEnableBlockRuntimeBreakpoints(d)
+		_, err := d.SetBreakpoint(file, line)
+		Expect(errors.Is(err, debugger.ErrRuntimeCritical)).To(BeTrue(), "got %v", err)
+		Expect(err.Error()).To(ContainSubstring("runtime.mallocgc"))
+		Expect(fb.peekMem(pc, 1)[0]).To(Equal(byte(0x48)), "no trap should be written")
+		Consistently(d.Events(), "50ms").ShouldNot(Receive())
+	})
+
+	It("does not warn about a breakpoint in user code", func() {
+		_, err := d.SetBreakpoint("fix.go", inspectMarkerLine("alpha-marker"))
+		Expect(err).NotTo(HaveOccurred())
+		Consistently(d.Events(), "50ms").ShouldNot(Receive())
+	})
+})
+
+var _ = Describe("SourcePath", func() {
+	var (
+		fb *fakeBackend
+		d  debugger.Debugger
+	)
+
+	BeforeEach(func() {
+		fb = newFakeBackend()
+		d = debugger.NewWithBackend(fb, nil)
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		debugger.ExportedLoadDWARF(d, bin)
+	})
+
+	AfterEach(func() {
+		_ = d.Kill()
+		if !fb.stopped {
+			close(fb.stopCh)
+			fb.stopped = true
+		}
+	})
+
+	It("finds the full path of a file named by its base name", func() {
+		path, err := d.SourcePath("fix.go")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.IsAbs(path)).To(BeTrue())
+		Expect(filepath.Base(path)).To(Equal("fix.go"))
+	})
+
+	It("errors on a file the binary was not built from", func() {
+		_, err := d.SourcePath("nosuch.go")
+		Expect(err).To(MatchError(ContainSubstring("no source file")))
+	})
+})
+
+var _ = Describe("AddrToLine", func() {
+	var (
+		fb *fakeBackend
+		d  debugger.Debugger
+	)
+
+	BeforeEach(func() {
+		fb = newFakeBackend()
+		d = debugger.NewWithBackend(fb, nil)
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		debugger.ExportedLoadDWARF(d, bin)
+	})
+
+	AfterEach(func() {
+		_ = d.Kill()
+		if !fb.stopped {
+			close(fb.stopCh)
+			fb.stopped = true
+		}
+	})
+
+	It("maps a line's PC back to the same file and line", func() {
+		line := inspectMarkerLine("beta-marker")
+		pc, err := debugger.ExportedPCForFileLine(d, "fix.go", line)
+		Expect(err).NotTo(HaveOccurred())
+
+		loc, err := d.AddrToLine(pc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loc.File).To(HaveSuffix("fix.go"))
+		Expect(loc.Line).To(Equal(line))
+		Expect(loc.Function).To(Equal("main.beta"))
+	})
+
+	It("reports an address outside any function as unknown", func() {
+		loc, err := d.AddrToLine(0x10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loc).To(Equal(protocol.Location{Function: "unknown"}))
+	})
+})
+
+var _ = Describe("Go binary check", func() {
+	// /bin/sh stands in for any non-Go ELF; it is present on every platform
+	// the engine supports.
+	const nonGoBinary = "/bin/sh"
+
+	It("accepts a Go binary", func() {
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(debugger.ExportedCheckGoBinary(bin)).To(Succeed())
+	})
+
+	It("rejects a non-Go binary", func() {
+		Expect(debugger.ExportedCheckGoBinary(nonGoBinary)).To(MatchError(debugger.ErrNotGoBinary))
+	})
+
+	It("refuses to Launch a non-Go binary before starting it", func() {
+		d := debugger.New(nil)
+		defer func() { _ = d.Kill() }()
+		Expect(d.Launch(nonGoBinary, nil, nil)).To(MatchError(debugger.ErrNotGoBinary))
+	})
+
+	It("refuses to Attach with a non-Go binary path before attaching", func() {
+		d := debugger.New(nil)
+		defer func() { _ = d.Kill() }()
+		Expect(d.Attach(os.Getpid(), nonGoBinary)).To(MatchError(debugger.ErrNotGoBinary))
+	})
+})
+
+var _ = Describe("compressed DWARF", func() {
+	It("reads line tables from SHF_COMPRESSED debug sections", func() {
+		if runtime.GOOS != "linux" {
+			Skip("DWARF compression is an ELF linker option")
+		}
+		dir := GinkgoT().TempDir()
+		src := filepath.Join(dir, "fix.go")
+		Expect(os.WriteFile(src, []byte(inspectFixtureSrc), 0o600)).To(Succeed())
+		bin := filepath.Join(dir, "fix")
+		cmd := exec.Command("go", "build", "-gcflags=all=-N -l", "-ldflags=-compressdwarf=true", "-o", bin, src)
+		cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+		out, err := cmd.CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+
+		f, err := elf.Open(bin)
+		Expect(err).NotTo(HaveOccurred())
+		info := f.Section(".debug_info")
+		_ = f.Close()
+		Expect(info).NotTo(BeNil())
+		Expect(info.Flags&elf.SHF_COMPRESSED).NotTo(BeZero(), "fixture should carry compressed DWARF")
+
+		fb := newFakeBackend()
+		d := debugger.NewWithBackend(fb, nil)
+		defer func() {
+			_ = d.Kill()
+			if !fb.stopped {
+				close(fb.stopCh)
+				fb.stopped = true
+			}
+		}()
+		debugger.ExportedLoadDWARF(d, bin)
+		line := inspectMarkerLine("alpha-marker")
+		pc, loc, err := d.ResolveLine("fix.go", line)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pc).NotTo(BeZero())
+		Expect(loc.Function).To(Equal("main.alpha"))
+	})
+})
@@ -0,0 +1,480 @@
+package debugger
+
+import (
+	"bytes"
+	"compress/zlib"
+	"debug/dwarf"
+	"debug/elf"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// maxArgStringRead caps how much of a string argument InspectArgs shows. It
+// is a one-line summary, not ReadString: the full value is a ReadString away.
+const maxArgStringRead = 256
+
+// locSections are the raw DWARF sections location lists live in, which
+// debug/dwarf parses no further than the offset in DW_AT_location. Go writes
+// DWARF 5 lists (loclists, indexing addr) and older toolchains DWARF 4 ones
+// (loc); whichever the binary lacks is nil.
+type locSections struct {
+	loclists []byte
+	loc      []byte
+	addr     []byte
+}
+
+// readELFLocSections reads the location sections of f. Section.Data inflates
+// SHF_COMPRESSED sections, which the Go linker writes by default.
+func readELFLocSections(f *elf.File) locSections {
+	read := func(name string) []byte {
+		s := f.Section(name)
+		if s == nil {
+			return nil
+		}
+		data, err := s.Data()
+		if err != nil {
+			return nil
+		}
+		return data
+	}
+	return locSections{
+		loclists: read(".debug_loclists"),
+		loc:      read(".debug_loc"),
+		addr:     read(".debug_addr"),
+	}
+}
+
+// readMachOLocSections reads the location sections of f, inflating the
+// __zdebug_ form ("ZLIB", a big-endian length, a zlib stream) the Go linker
+// writes on darwin.
+func readMachOLocSections(f *macho.File) locSections {
+	read := func(name string) []byte {
+		if s := f.Section("__debug_" + name); s != nil {
+			data, err := s.Data()
+			if err != nil {
+				return nil
+			}
+			return data
+		}
+		s := f.Section("__zdebug_" + name)
+		if s == nil {
+			return nil
+		}
+		data, err := s.Data()
+		if err != nil || len(data) < 12 || string(data[:4]) != "ZLIB" {
+			return nil
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(data[12:]))
+		if err != nil {
+			return nil
+		}
+		out := make([]byte, binary.BigEndian.Uint64(data[4:12]))
+		if _, err := io.ReadFull(zr, out); err != nil {
+			return nil
+		}
+		return out
+	}
+	return locSections{
+		loclists: read("loclists"),
+		loc:      read("loc"),
+		addr:     read("addr"),
+	}
+}
+
+// cuLocBase is what a compile unit contributes to reading its location
+// lists: the base address offsets start from and, for DWARF 5, where its
+// entries in .debug_addr begin.
+type cuLocBase struct {
+	lowpc    uint64
+	addrBase int64
+}
+
+func cuLocBaseOf(cu *dwarf.Entry) cuLocBase {
+	var b cuLocBase
+	if cu == nil {
+		return b
+	}
+	b.lowpc, _ = cu.Val(dwarf.AttrLowpc).(uint64)
+	b.addrBase, _ = cu.Val(dwarf.AttrAddrBase).(int64)
+	return b
+}
+
+// locationExpr returns the location expression that holds for entry at
+// dwarfPC: its DW_AT_location itself, or the matching entry of the location
+// list it points to. Go describes every parameter with a list, since one
+// moves from its argument register to a stack slot as the function runs.
+// ok is false when no entry covers dwarfPC: the value does not exist there.
+func (r *dwarfReader) locationExpr(entry *dwarf.Entry, cu cuLocBase, dwarfPC uint64) (expr []byte, ok bool, err error) {
+	f := entry.AttrField(dwarf.AttrLocation)
+	if f == nil {
+		return nil, false, nil
+	}
+	switch v := f.Val.(type) {
+	case []byte:
+		return v, len(v) > 0, nil
+	case int64:
+		if f.Class != dwarf.ClassLocListPtr {
+			return nil, false, fmt.Errorf("location list index %d: DW_FORM_loclistx is not supported", v)
+		}
+		if r.loc.loclists != nil {
+			return r.loclistsExpr(v, cu, dwarfPC)
+		}
+		return r.locExpr(v, cu, dwarfPC)
+	}
+	return nil, false, fmt.Errorf("unexpected DW_AT_location %T", f.Val)
+}
+
+// loclistsExpr walks the DWARF 5 location list at off in .debug_loclists.
+func (r *dwarfReader) loclistsExpr(off int64, cu cuLocBase, pc uint64) ([]byte, bool, error) {
+	buf := r.loc.loclists
+	if off < 0 || off >= int64(len(buf)) {
+		return nil, false, fmt.Errorf("location list offset %#x outside .debug_loclists", off)
+	}
+	base := cu.lowpc
+	addrx := func(i uint64) (uint64, error) {
+		at := uint64(cu.addrBase) + i*ptrSize
+		if at+ptrSize > uint64(len(r.loc.addr)) {
+			return 0, fmt.Errorf("address index %d outside .debug_addr", i)
+		}
+		return binary.LittleEndian.Uint64(r.loc.addr[at:]), nil
+	}
+	d := &locDecoder{buf: buf, pos: int(off)}
+	for {
+		kind := d.byte()
+		var lo, hi uint64
+		var err error
+		switch kind {
+		case 0x00: // DW_LLE_end_of_list
+			return nil, false, d.err
+		case 0x01: // DW_LLE_base_addressx
+			if base, err = addrx(d.uleb()); err != nil {
+				return nil, false, err
+			}
+			continue
+		case 0x02: // DW_LLE_startx_endx
+			if lo, err = addrx(d.uleb()); err == nil {
+				hi, err = addrx(d.uleb())
+			}
+		case 0x03: // DW_LLE_startx_length
+			if lo, err = addrx(d.uleb()); err == nil {
+				hi = lo + d.uleb()
+			}
+		case 0x04: // DW_LLE_offset_pair
+			lo = base + d.uleb()
+			hi = base + d.uleb()
+		case 0x05: // DW_LLE_default_location
+			lo, hi = 0, math.MaxUint64
+		case 0x06: // DW_LLE_base_address
+			base = d.u64()
+			continue
+		case 0x07: // DW_LLE_start_end
+			lo = d.u64()
+			hi = d.u64()
+		case 0x08: // DW_LLE_start_length
+			lo = d.u64()
+			hi = lo + d.uleb()
+		default:
+			return nil, false, fmt.Errorf("location list at %#x: unknown entry kind %#x", off, kind)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		expr := d.bytes(int(d.uleb()))
+		if d.err != nil {
+			return nil, false, fmt.Errorf("location list at %#x: %w", off, d.err)
+		}
+		if pc >= lo && pc < hi {
+			return expr, len(expr) > 0, nil
+		}
+	}
+}
+
+// locExpr walks the DWARF 4 location list at off in .debug_loc.
+func (r *dwarfReader) locExpr(off int64, cu cuLocBase, pc uint64) ([]byte, bool, error) {
+	buf := r.loc.loc
+	if off < 0 || off >= int64(len(buf)) {
+		return nil, false, fmt.Errorf("location list offset %#x outside .debug_loc", off)
+	}
+	base := cu.lowpc
+	d := &locDecoder{buf: buf, pos: int(off)}
+	for {
+		lo, hi := d.u64(), d.u64()
+		if d.err != nil {
+			return nil, false, fmt.Errorf("location list at %#x: %w", off, d.err)
+		}
+		switch {
+		case lo == 0 && hi == 0:
+			return nil, false, nil
+		case lo == math.MaxUint64:
+			base = hi
+			continue
+		}
+		expr := d.bytes(int(d.u16()))
+		if d.err != nil {
+			return nil, false, fmt.Errorf("location list at %#x: %w", off, d.err)
+		}
+		if pc >= base+lo && pc < base+hi {
+			return expr, len(expr) > 0, nil
+		}
+	}
+}
+
+// locDecoder reads the little-endian encodings of location lists and
+// expressions. The first read past the end sets err and every later one
+// returns zero, so a caller checks err once per entry.
+type locDecoder struct {
+	buf []byte
+	pos int
+	err error
+}
+
+var (
+	errLocTruncated = errors.New("truncated")
+	errOptimizedOut = errors.New(optimizedOut)
+)
+
+func (d *locDecoder) done() bool { return d.pos >= len(d.buf) }
+
+func (d *locDecoder) bytes(n int) []byte {
+	if d.err != nil || n < 0 || d.pos+n > len(d.buf) {
+		d.err = errLocTruncated
+		return nil
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+func (d *locDecoder) byte() byte {
+	if b := d.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *locDecoder) u16() uint16 {
+	if b := d.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *locDecoder) u64() uint64 {
+	if b := d.bytes(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *locDecoder) uleb() uint64 {
+	var v uint64
+	var shift uint
+	for {
+		b := d.byte()
+		if d.err != nil {
+			return 0
+		}
+		if shift < 64 {
+			v |= uint64(b&0x7f) << shift
+		}
+		shift += 7
+		if b&0x80 == 0 {
+			return v
+		}
+	}
+}
+
+func (d *locDecoder) sleb() int64 {
+	if d.err != nil || d.done() {
+		d.err = errLocTruncated
+		return 0
+	}
+	v, n := decodeSLEB128(d.buf[d.pos:])
+	d.pos += n
+	return v
+}
+
+// locPiece is where one piece of a value lives: in memory at addr, in
+// register reg (a DWARF register number), or nowhere, when the compiler
+// dropped that part. size 0 means the whole value.
+type locPiece struct {
+	size  int
+	inReg bool
+	reg   int
+	addr  uint64
+	none  bool
+}
+
+// argFrame is what a location expression is evaluated against: the frame's
+// canonical frame address (a Go function's DW_AT_frame_base) and the stopped
+// thread's general-purpose registers by DWARF number, nil where the backend
+// can't read them.
+type argFrame struct {
+	cfa   uint64
+	cfaOK bool
+	gpr   []uint64
+}
+
+// evalPieces evaluates the operations Go emits for variables: an address, a
+// frame-base or CFA offset, a register, and DW_OP_piece to split a string or
+// other multi-word value across several of them. Anything else is reported
+// rather than guessed at.
+func (r *dwarfReader) evalPieces(expr []byte, fr argFrame) ([]locPiece, error) {
+	var pieces []locPiece
+	var cur *locPiece
+	d := &locDecoder{buf: expr}
+	for !d.done() {
+		op := d.byte()
+		switch {
+		case op == 0x03: // DW_OP_addr
+			cur = &locPiece{addr: uint64(int64(d.u64()) + r.slide)}
+		case op == 0x91: // DW_OP_fbreg
+			off := d.sleb()
+			if !fr.cfaOK {
+				return nil, errors.New("frame address unknown at this PC")
+			}
+			cur = &locPiece{addr: uint64(int64(fr.cfa) + off)}
+		case op == 0x9c: // DW_OP_call_frame_cfa
+			if !fr.cfaOK {
+				return nil, errors.New("frame address unknown at this PC")
+			}
+			cur = &locPiece{addr: fr.cfa}
+		case op >= 0x50 && op <= 0x6f: // DW_OP_reg0..31
+			cur = &locPiece{inReg: true, reg: int(op - 0x50)}
+		case op == 0x90: // DW_OP_regx
+			cur = &locPiece{inReg: true, reg: int(d.uleb())}
+		case op == 0x93: // DW_OP_piece
+			p := locPiece{none: true}
+			if cur != nil {
+				p = *cur
+			}
+			p.size = int(d.uleb())
+			pieces = append(pieces, p)
+			cur = nil
+		default:
+			return nil, fmt.Errorf("unsupported location op %#x", op)
+		}
+		if d.err != nil {
+			return nil, fmt.Errorf("location expression: %w", d.err)
+		}
+	}
+	if cur != nil {
+		pieces = append(pieces, *cur)
+	}
+	return pieces, nil
+}
+
+// readPieces assembles the size bytes of a value from its pieces. A register
+// piece takes the low bytes of the register, as the Go ABI passes them.
+func readPieces(b Backend, pieces []locPiece, fr argFrame, size int) ([]byte, error) {
+	out := make([]byte, size)
+	off := 0
+	for _, p := range pieces {
+		n := p.size
+		if n == 0 {
+			n = size - off
+		}
+		if n < 0 || off+n > size {
+			return nil, fmt.Errorf("location pieces exceed the %d-byte value", size)
+		}
+		switch {
+		case p.none:
+			return nil, errOptimizedOut
+		case p.inReg:
+			if p.reg >= len(fr.gpr) {
+				return nil, fmt.Errorf("register %d not readable", p.reg)
+			}
+			if n > ptrSize {
+				return nil, fmt.Errorf("%d-byte piece in register %d", n, p.reg)
+			}
+			var word [ptrSize]byte
+			binary.LittleEndian.PutUint64(word[:], fr.gpr[p.reg])
+			copy(out[off:off+n], word[:n])
+		default:
+			if err := b.ReadMemory(p.addr, out[off:off+n]); err != nil {
+				return nil, err
+			}
+		}
+		off += n
+	}
+	return out, nil
+}
+
+// formatValue renders raw, the bytes of a value of type t, the way Go would
+// print it: scalars in decimal, bools by name, strings quoted after reading
+// their data. Any other type is shown as its raw bytes in hex.
+func formatValue(b Backend, t dwarf.Type, raw []byte) string {
+	for {
+		td, ok := t.(*dwarf.TypedefType)
+		if !ok {
+			break
+		}
+		t = td.Type
+	}
+	switch t := t.(type) {
+	case *dwarf.IntType:
+		if v, ok := littleEndianInt(raw); ok {
+			return strconv.FormatInt(v, 10)
+		}
+	case *dwarf.UintType:
+		if v, ok := littleEndianUint(raw); ok {
+			return strconv.FormatUint(v, 10)
+		}
+	case *dwarf.BoolType:
+		if len(raw) == 1 {
+			return strconv.FormatBool(raw[0] != 0)
+		}
+	case *dwarf.FloatType:
+		switch len(raw) {
+		case 4:
+			return strconv.FormatFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(raw))), 'g', -1, 32)
+		case 8:
+			return strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(raw)), 'g', -1, 64)
+		}
+	case *dwarf.StructType:
+		if t.StructName == "string" && len(raw) == 2*ptrSize {
+			ptr := binary.LittleEndian.Uint64(raw)
+			n := binary.LittleEndian.Uint64(raw[ptrSize:])
+			s, err := readStringData(b, ptr, n, maxArgStringRead)
+			if err != nil {
+				return fmt.Sprintf("<unreadable: %v>", err)
+			}
+			if uint64(len(s)) < n {
+				return fmt.Sprintf("%s... (len %d)", strconv.Quote(s), n)
+			}
+			return strconv.Quote(s)
+		}
+	}
+	if v, ok := littleEndianUint(raw); ok {
+		return fmt.Sprintf("0x%x", v)
+	}
+	return fmt.Sprintf("%x", raw)
+}
+
+func littleEndianUint(raw []byte) (uint64, bool) {
+	switch len(raw) {
+	case 1:
+		return uint64(raw[0]), true
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(raw)), true
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(raw)), true
+	case 8:
+		return binary.LittleEndian.Uint64(raw), true
+	}
+	return 0, false
+}
+
+func littleEndianInt(raw []byte) (int64, bool) {
+	v, ok := littleEndianUint(raw)
+	if !ok {
+		return 0, false
+	}
+	shift := 64 - 8*uint(len(raw))
+	return int64(v<<shift) >> shift, true
+}
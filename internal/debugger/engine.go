@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"runtime"
+	"slices"
+	"strings"
 	"sync"
+	"syscall"
 
 	"github.com/bingosuite/bingo/pkg/protocol"
 )
@@ -17,8 +21,22 @@ const (
 
 	stepOverNextFile  = "<stepover-next>"
 	stepOutReturnFile = "<stepout-return>"
+	entryFile         = "<entry>"
 )
 
+// ProcessExitedPayload.Reason values.
+const (
+	exitReasonExited = "exited"
+	exitReasonKilled = "killed"
+	exitReasonGone   = "gone"
+)
+
+// targetGone reports whether err means the tracee no longer exists: ECHILD
+// (no children left to wait for) or ESRCH (no such process).
+func targetGone(err error) bool {
+	return errors.Is(err, syscall.ECHILD) || errors.Is(err, syscall.ESRCH)
+}
+
 type engineState uint8
 
 const (
@@ -37,6 +55,7 @@ type bpResumeAction uint8
 
 const (
 	bpResumeContinue   bpResumeAction = iota // ContinueProcess and keep running
+	bpResumeThread                           // as bpResumeContinue, leaving parked threads stopped
 	bpResumeStep                             // emit EventStepped (machine-instruction)
 	bpResumeSourceStep                       // set temp BP at next source line, then continue
 	bpResumeStepOut                          // set return-addr BP, then continue
@@ -47,6 +66,8 @@ type engine struct {
 	proc    process
 	bps     *breakpointTable
 	dw      *dwarfReader
+	// image is where the binary was loaded, for Status; set with dw.
+	image imageLayout
 
 	events chan protocol.Event
 	cmdCh  chan engineCmd
@@ -60,6 +81,11 @@ type engine struct {
 	state engineState
 	mu    sync.Mutex
 
+	// tty is the PTY master of a process launched with Options.PTY. Set on
+	// the loop at Launch and closed when the loop exits; WriteInput reads it
+	// from the caller's goroutine, hence mu.
+	tty *os.File
+
 	// Software-breakpoint step-over state. lastBP is the BP the process
 	// stopped at; on next resume we restore bytes, single-step, reinstall
 	// the trap, then perform bpResumeAction. steppingOverBP is non-nil
@@ -94,6 +120,47 @@ type engine struct {
 	// the single engine loop thread. See AGENTS.md → Pause.
 	manualStopPending bool
 
+	// Counted-step state (StepOverN/StepIntoN). While stepsTotal > 0 a
+	// completed step is not reported: emitStepped issues stepRepeat again
+	// until stepsLeft runs out, and any other stop ends the run early. Loop
+	// thread only, like manualStopPending.
+	stepsCmd   protocol.CommandKind
+	stepsTotal int
+	stepsLeft  int
+	stepsDone  int
+	stepRepeat func() error
+
+	// stepFrom is where the step being reported started, for the delta in
+	// SteppedPayload. Set when a step command starts, consumed by
+	// emitStepped. Loop thread only.
+	stepFrom *stepOrigin
+
+	// stopReason is why the process is suspended, as the stop event that
+	// suspended it reported it, for Status. Cleared on every resume, so a
+	// suspend no event gives a reason for (an error, a lost breakpoint)
+	// leaves it empty. Loop thread only: only the loop resumes.
+	stopReason protocol.StopReason
+
+	// parked are the threads other than curTID that stopped alongside the
+	// last breakpoint hit and are held until the next Continue (threadParker
+	// backends only). Loop thread only.
+	parked []int
+
+	// initialStop is Options.InitialStop, or what SetInitialStop last set.
+	// Loop goroutine only.
+	initialStop protocol.InitialStop
+	// initialBPs is what SetInitialBreakpoints last set. Loop goroutine only.
+	initialBPs []protocol.Location
+	// hitContext is Options.HitContext; fixed at construction.
+	hitContext bool
+	// stoppedThreads is Options.StoppedThreads; fixed at construction.
+	stoppedThreads bool
+	// blockRuntimeBPs is Options.BlockRuntimeBreakpoints; fixed at
+	// construction.
+	blockRuntimeBPs bool
+	// maxSliceElements is Options.MaxSliceElements with the default applied.
+	maxSliceElements int
+
 	// log is the single sink for all engine logging. Never call the
 	// package-level slog functions directly — they bypass the per-session
 	// logger the hub/server configure, producing duplicate, uncorrelated
@@ -170,6 +237,8 @@ func newEngine(b Backend, log *slog.Logger) *engine {
 		done:    make(chan struct{}),
 		state:   stateNoProcess,
 		log:     log,
+
+		maxSliceElements: defaultMaxSliceElements,
 	}
 	go e.loop()
 	return e
@@ -183,17 +252,106 @@ func (e *engine) Launch(binaryPath string, args []string, env []string) error {
 			return err
 		}
 		setPID(e.backend, e.proc.pid)
-		e.loadDWARF(binaryPath)
-		// startTracedProcess already consumed the initial SIGTRAP. The process
-		// is stopped — no waitLoop needed.
-		e.setState(stateSuspended)
-		e.emitStoppedAtCurrentPC()
+		e.startTerminal()
+		e.launched(binaryPath)
 		return nil
 	})
 }
 
+// launched takes a process just started, parked at its initial exec stop,
+// from there: DWARF, EventDebuggerReady, then the initial stop mode.
+func (e *engine) launched(binaryPath string) {
+	e.loadDWARF(binaryPath)
+	e.emitDebuggerReady(binaryPath)
+	e.armInitialBreakpoints()
+	if e.leaveInitialStop() {
+		return
+	}
+	// startTracedProcess already consumed the initial SIGTRAP. The process
+	// is stopped — no waitLoop needed.
+	e.setState(stateSuspended)
+	e.emitStoppedAtCurrentPC()
+}
+
+// emitDebuggerReady announces the process just launched or attached, before
+// any stop is reported, so clients can tell setup worked without waiting for
+// a stop that a run-mode launch may never make.
+func (e *engine) emitDebuggerReady(program string) {
+	p := protocol.DebuggerReadyPayload{PID: e.proc.pid, Program: program}
+	if pgid, err := syscall.Getpgid(e.proc.pid); err == nil {
+		p.PGID = pgid
+	}
+	e.emit(protocol.EventDebuggerReady, p)
+}
+
+func (e *engine) SetInitialStop(mode protocol.InitialStop) error {
+	if !mode.Valid() {
+		return fmt.Errorf("SetInitialStop: unknown mode %q", mode)
+	}
+	return e.dispatch(func() error {
+		e.initialStop = mode
+		return nil
+	})
+}
+
+// startTerminal takes over the PTY master of a process just launched on one
+// and starts copying what the process writes to it into EventOutput.
+func (e *engine) startTerminal() {
+	tb, ok := e.backend.(terminalBackend)
+	if !ok {
+		return
+	}
+	tty := tb.terminal()
+	if tty == nil {
+		return
+	}
+	e.mu.Lock()
+	e.tty = tty
+	e.mu.Unlock()
+	go e.pumpTerminal(tty)
+}
+
+// pumpTerminal forwards terminal output until the process closes its end (a
+// read error, EIO on linux) or the engine shuts down. Each chunk is emitted
+// through dispatch so it is ordered with every other event from the loop.
+func (e *engine) pumpTerminal(tty *os.File) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := tty.Read(buf)
+		if n > 0 {
+			chunk := string(buf[:n])
+			if e.dispatch(func() error { e.emitOutput("tty", chunk); return nil }) != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (e *engine) WriteInput(data string) error {
+	e.mu.Lock()
+	tty := e.tty
+	e.mu.Unlock()
+	if tty == nil {
+		return ErrNoTerminal
+	}
+	// Written off the loop: a process that isn't reading can fill the PTY
+	// buffer, and that must block this caller, not the engine.
+	if _, err := tty.WriteString(data); err != nil {
+		return fmt.Errorf("input: %w", err)
+	}
+	return nil
+}
+
 func (e *engine) Attach(pid int, binaryPath string) error {
 	return e.dispatch(func() error {
+		if binaryPath != "" {
+			if err := checkGoBinary(binaryPath); err != nil {
+				return fmt.Errorf("attach: %w", err)
+			}
+		}
 		if err := e.proc.attach(e.backend, pid); err != nil {
 			return err
 		}
@@ -201,6 +359,8 @@ func (e *engine) Attach(pid int, binaryPath string) error {
 		if binaryPath != "" {
 			e.loadDWARF(binaryPath)
 		}
+		e.emitDebuggerReady(binaryPath)
+		e.armInitialBreakpoints()
 		e.setState(stateSuspended)
 		e.emitStoppedAtCurrentPC()
 		return nil
@@ -242,22 +402,199 @@ func (e *engine) Kill() error {
 
 func (e *engine) SetBreakpoint(file string, line int) (protocol.Breakpoint, error) {
 	var bp protocol.Breakpoint
+	err := e.dispatch(func() error {
+		var err error
+		bp, err = e.setBreakpoint(file, line)
+		return err
+	})
+	return bp, err
+}
+
+// setBreakpoint is SetBreakpoint on the loop goroutine.
+func (e *engine) setBreakpoint(file string, line int) (protocol.Breakpoint, error) {
+	if e.dw == nil {
+		return protocol.Breakpoint{}, fmt.Errorf("SetBreakpoint: no DWARF info — was a binary path provided to Launch/Attach?")
+	}
+	addr, err := e.dw.PCForFileLine(file, line)
+	if err != nil {
+		return protocol.Breakpoint{}, err
+	}
+	// A second set is a no-op the user should hear about, not a new
+	// breakpoint. The engine's own step breakpoints are not the user's
+	// to be told about, so those still fail as an ordinary clash.
+	if existing := e.bps.atAddr(addr); existing != nil && !isInternalBreakpoint(existing) {
+		return existing.toProtocol(), fmt.Errorf("%w at %s:%d (breakpoint %d)", ErrBreakpointExists, existing.file, existing.line, existing.id)
+	}
+	fn := e.dw.functionAt(addr)
+	subsystem, critical := runtimeCritical(fn)
+	if critical && e.blockRuntimeBPs {
+		return protocol.Breakpoint{}, fmt.Errorf("%w: %s:%d is in %s (%s)", ErrRuntimeCritical, file, line, fn, subsystem)
+	}
+	entry, err := e.bps.set(e.backend, file, line, addr)
+	if err != nil {
+		return protocol.Breakpoint{}, err
+	}
+	bp := entry.toProtocol()
+	if critical {
+		e.emit(protocol.EventBreakpointWarning, protocol.BreakpointWarningPayload{
+			Breakpoint: bp,
+			Function:   fn,
+			Message: fmt.Sprintf("%s is part of the runtime %s; stopping a thread there can deadlock the whole program",
+				fn, subsystem),
+		})
+	}
+	return bp, nil
+}
+
+func (e *engine) SetInitialBreakpoints(locs []protocol.Location) error {
+	locs = slices.Clone(locs)
+	return e.dispatch(func() error {
+		e.initialBPs = locs
+		return nil
+	})
+}
+
+// armInitialBreakpoints sets the SetInitialBreakpoints locations on a
+// process parked at its first stop. Launch calls it before leaveInitialStop,
+// so under continue-to-main and run they are armed before any user code
+// runs. Each is announced like the hub announces a client's; one that can't
+// be set is reported and skipped, and a location given twice keeps the
+// first.
+func (e *engine) armInitialBreakpoints() {
+	for _, loc := range e.initialBPs {
+		bp, err := e.setBreakpoint(loc.File, loc.Line)
+		if errors.Is(err, ErrBreakpointExists) {
+			continue
+		}
+		if err != nil {
+			e.log.Info("initial breakpoint skipped", "file", loc.File, "line", loc.Line, "err", err)
+			e.emit(protocol.EventBreakpointError, protocol.BreakpointErrorPayload{
+				Location: protocol.Location{File: loc.File, Line: loc.Line},
+				Message:  err.Error(),
+			})
+			continue
+		}
+		e.emit(protocol.EventBreakpointSet, protocol.BreakpointSetPayload{Breakpoint: bp})
+	}
+}
+
+func (e *engine) ResolveLine(file string, line int) (uint64, protocol.Location, error) {
+	var (
+		pc  uint64
+		loc protocol.Location
+	)
 	err := e.dispatch(func() error {
 		if e.dw == nil {
-			return fmt.Errorf("SetBreakpoint: no DWARF info — was a binary path provided to Launch/Attach?")
+			return fmt.Errorf("ResolveLine: no DWARF info — was a binary path provided to Launch/Attach?")
 		}
+		resolved := line
 		addr, err := e.dw.PCForFileLine(file, line)
 		if err != nil {
+			// Blank lines, comments and declarations have no statement of
+			// their own; execution next stops on the following line that
+			// does, so that is the useful answer.
+			next, nextLine, ok := e.dw.NextLinePC(file, line)
+			if !ok {
+				return err
+			}
+			addr, resolved = next, nextLine
+		}
+		pc = addr
+		loc = protocol.Location{File: file, Line: resolved, Function: e.dw.functionAt(addr)}
+		return nil
+	})
+	return pc, loc, err
+}
+
+func (e *engine) ResolveFunction(function string, offset int, atReturn bool) (uint64, protocol.Location, error) {
+	var (
+		pc  uint64
+		loc protocol.Location
+	)
+	err := e.dispatch(func() error {
+		if e.dw == nil {
+			return fmt.Errorf("ResolveFunction: no DWARF info — was a binary path provided to Launch/Attach?")
+		}
+		var err error
+		pc, loc, err = e.dw.funcLine(function, offset, atReturn)
+		return err
+	})
+	return pc, loc, err
+}
+
+func (e *engine) SourcePath(file string) (string, error) {
+	var path string
+	err := e.dispatch(func() error {
+		if e.dw == nil {
+			return fmt.Errorf("SourcePath: no DWARF info — was a binary path provided to Launch/Attach?")
+		}
+		var matches []string
+		for _, f := range e.dw.sourceFiles() {
+			if fileMatches(f, file) {
+				matches = append(matches, f)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return fmt.Errorf("SourcePath: no source file %q in the binary", file)
+		case 1:
+			path = matches[0]
+			return nil
+		default:
+			return fmt.Errorf("SourcePath: %q is ambiguous: %s", file, strings.Join(matches, ", "))
+		}
+	})
+	return path, err
+}
+
+func (e *engine) AddrToLine(pc uint64) (protocol.Location, error) {
+	var loc protocol.Location
+	err := e.dispatch(func() error {
+		if e.dw == nil {
+			return fmt.Errorf("AddrToLine: no DWARF info — was a binary path provided to Launch/Attach?")
+		}
+		loc = e.dw.locationForPC(pc)
+		if loc.Function == "" {
+			loc = protocol.Location{Function: "unknown"}
+		}
+		return nil
+	})
+	return loc, err
+}
+
+func (e *engine) ReadString(addr uint64) (string, uint64, error) {
+	var (
+		s string
+		n uint64
+	)
+	err := e.dispatch(func() error {
+		if err := e.requireSuspended(); err != nil {
 			return err
 		}
-		entry, err := e.bps.set(e.backend, file, line, addr)
+		var err error
+		s, n, err = readGoString(e.backend, addr, maxStringRead)
 		if err != nil {
+			return fmt.Errorf("ReadString: %w", err)
+		}
+		return nil
+	})
+	return s, n, err
+}
+
+func (e *engine) ReadSlice(addr uint64, elemSize int) (protocol.SliceValuePayload, error) {
+	var p protocol.SliceValuePayload
+	err := e.dispatch(func() error {
+		if err := e.requireSuspended(); err != nil {
 			return err
 		}
-		bp = entry.toProtocol()
+		var err error
+		p, err = readGoSlice(e.backend, addr, elemSize, e.maxSliceElements)
+		if err != nil {
+			return fmt.Errorf("ReadSlice: %w", err)
+		}
 		return nil
 	})
-	return bp, err
+	return p, err
 }
 
 func (e *engine) ClearBreakpoint(id int) error {
@@ -266,13 +603,133 @@ func (e *engine) ClearBreakpoint(id int) error {
 	})
 }
 
+func (e *engine) SetIgnoreCount(id, count int) (protocol.Breakpoint, error) {
+	var bp protocol.Breakpoint
+	err := e.dispatch(func() error {
+		if count < 0 {
+			return fmt.Errorf("SetIgnoreCount: count %d is negative", count)
+		}
+		entry, ok := e.userBreakpoint(id)
+		if !ok {
+			return fmt.Errorf("SetIgnoreCount: breakpoint %d not found", id)
+		}
+		entry.ignore = count
+		bp = entry.toProtocol()
+		return nil
+	})
+	return bp, err
+}
+
+func (e *engine) SetGoroutineCondition(id int, cond *protocol.GoroutineCountCondition) (protocol.Breakpoint, error) {
+	var bp protocol.Breakpoint
+	err := e.dispatch(func() error {
+		if cond != nil && !cond.Valid() {
+			return fmt.Errorf("SetGoroutineCondition: %s is not a valid condition", cond)
+		}
+		entry, ok := e.userBreakpoint(id)
+		if !ok {
+			return fmt.Errorf("SetGoroutineCondition: breakpoint %d not found", id)
+		}
+		if cond != nil {
+			c := *cond
+			cond = &c
+		}
+		entry.goroutineCond = cond
+		entry.condErrReported = false
+		bp = entry.toProtocol()
+		return nil
+	})
+	return bp, err
+}
+
+// userBreakpoint finds the user breakpoint id. A breakpoint being stepped
+// over is briefly out of the table; it is still the user's to adjust.
+func (e *engine) userBreakpoint(id int) (*breakpointEntry, bool) {
+	entry, ok := e.bps.byID[id]
+	if !ok && e.steppingOverBP != nil && e.steppingOverBP.id == id {
+		entry, ok = e.steppingOverBP, true
+	}
+	if !ok || isInternalBreakpoint(entry) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// ClearAllBreakpoints leaves the engine's own step and entry sentinels in
+// place: a step in flight still needs its return breakpoint.
+func (e *engine) ClearAllBreakpoints() ([]int, error) {
+	var ids []int
+	err := e.dispatch(func() error {
+		for _, entry := range e.bps.userEntries() {
+			if err := e.bps.clear(e.backend, entry.id); err != nil {
+				return err
+			}
+			ids = append(ids, entry.id)
+		}
+		return nil
+	})
+	return ids, err
+}
+
+func (e *engine) DisableAllBreakpoints() ([]protocol.Breakpoint, error) {
+	return e.setAllBreakpointsEnabled(false)
+}
+
+func (e *engine) EnableAllBreakpoints() ([]protocol.Breakpoint, error) {
+	return e.setAllBreakpointsEnabled(true)
+}
+
+// setAllBreakpointsEnabled toggles every user breakpoint, including the one
+// the process is parked on: that one is still in the table until the next
+// resume, and reinstall honours its flag after the step-over. It stops at the
+// first failed write, leaving earlier entries toggled; the returned list
+// reflects the state actually reached either way.
+func (e *engine) setAllBreakpointsEnabled(enabled bool) ([]protocol.Breakpoint, error) {
+	var bps []protocol.Breakpoint
+	err := e.dispatch(func() error {
+		entries := e.bps.userEntries()
+		var err error
+		for _, entry := range entries {
+			if enabled {
+				err = e.bps.enable(e.backend, entry)
+			} else {
+				err = e.bps.disable(e.backend, entry)
+			}
+			if err != nil {
+				break
+			}
+		}
+		bps = make([]protocol.Breakpoint, 0, len(entries))
+		for _, entry := range entries {
+			bps = append(bps, entry.toProtocol())
+		}
+		return err
+	})
+	return bps, err
+}
+
 func (e *engine) Continue() error {
+	return e.resume(true)
+}
+
+func (e *engine) ContinueThread() error {
+	return e.resume(false)
+}
+
+// resume continues the current thread and, if all, the threads parked at the
+// last breakpoint hit.
+func (e *engine) resume(all bool) error {
 	return e.dispatch(func() error {
 		if err := e.requireSuspended(); err != nil {
 			return err
 		}
+		e.stepFrom = nil
 		if e.lastBP != nil {
-			if err := e.resumeFromBreakpoint(bpResumeContinue, 0); err != nil {
+			action := bpResumeContinue
+			if !all {
+				action = bpResumeThread
+			}
+			if err := e.resumeFromBreakpoint(action, 0); err != nil {
 				return err
 			}
 			e.emitContinued()
@@ -281,6 +738,9 @@ func (e *engine) Continue() error {
 		if err := e.backend.ContinueProcess(); err != nil {
 			return err
 		}
+		if all {
+			e.resumeParked()
+		}
 		e.setState(stateRunning)
 		go e.waitLoop()
 		e.emitContinued()
@@ -289,43 +749,130 @@ func (e *engine) Continue() error {
 }
 
 func (e *engine) StepOver() error {
+	return e.StepOverN(1)
+}
+
+func (e *engine) StepOverN(n int) error {
 	return e.dispatch(func() error {
 		if err := e.requireSuspended(); err != nil {
 			return err
 		}
-		return e.stepOver()
+		return e.startSteps(protocol.CmdStepOver, n, e.stepOver)
 	})
 }
 
 func (e *engine) StepInto() error {
+	return e.StepIntoN(1)
+}
+
+func (e *engine) StepIntoN(n int) error {
 	return e.dispatch(func() error {
 		if err := e.requireSuspended(); err != nil {
 			return err
 		}
-		if e.lastBP != nil {
-			return e.resumeFromBreakpoint(bpResumeStep, 0)
-		}
-		tid, err := e.activeTID()
-		if err != nil {
-			return fmt.Errorf("StepInto: %w", err)
-		}
-		regs, err := e.backend.GetRegisters(tid)
-		if err != nil {
-			return fmt.Errorf("StepInto: get registers: %w", err)
-		}
-		// Step exactly one instruction on the user thread. On darwin this holds
-		// every other thread Mach-suspended and hardware-single-steps tid
-		// specifically: only the stepped thread runs during the step window, so
-		// the runtime's sysmon can't observe it and inject a preemption, and any
-		// Mach breakpoint exception seen mid-step is unambiguously this thread's
-		// (#92); elsewhere it degrades to a plain per-thread single-step.
-		if err := e.stepThreadOverBP(tid, regs.PC); err != nil {
+		return e.startSteps(protocol.CmdStepInto, n, e.stepInto)
+	})
+}
+
+func (e *engine) StepInstruction() error {
+	return e.dispatch(func() error {
+		if err := e.requireSuspended(); err != nil {
 			return err
 		}
-		e.setState(stateRunning)
-		go e.waitLoop()
-		return nil
+		return e.startSteps(protocol.CmdStepInstruction, 1, func() error {
+			return e.singleStep(protocol.CmdStepInstruction)
+		})
+	})
+}
+
+func (e *engine) stepInto() error {
+	return e.singleStep(protocol.CmdStepInto)
+}
+
+// singleStep executes one machine instruction on the user thread. StepInto
+// has no line-level implementation yet, so it shares this with
+// StepInstruction; cmd only names the failing command in errors.
+func (e *engine) singleStep(cmd protocol.CommandKind) error {
+	if e.lastBP != nil {
+		return e.resumeFromBreakpoint(bpResumeStep, 0)
+	}
+	tid, err := e.activeTID()
+	if err != nil {
+		return fmt.Errorf("%s: %w", cmd, err)
+	}
+	regs, err := e.backend.GetRegisters(tid)
+	if err != nil {
+		return fmt.Errorf("%s: get registers: %w", cmd, err)
+	}
+	// Step exactly one instruction on the user thread. On darwin this holds
+	// every other thread Mach-suspended and hardware-single-steps tid
+	// specifically: only the stepped thread runs during the step window, so
+	// the runtime's sysmon can't observe it and inject a preemption, and any
+	// Mach breakpoint exception seen mid-step is unambiguously this thread's
+	// (#92); elsewhere it degrades to a plain per-thread single-step.
+	if err := e.stepThreadOverBP(tid, regs.PC); err != nil {
+		return err
+	}
+	e.setState(stateRunning)
+	go e.waitLoop()
+	return nil
+}
+
+// startSteps runs step, arranging for emitStepped to repeat it until n steps
+// have completed. n == 1 is a plain step and reports no count.
+func (e *engine) startSteps(cmd protocol.CommandKind, n int, step func() error) error {
+	if n < 1 {
+		return fmt.Errorf("%s: step count must be at least 1, got %d", cmd, n)
+	}
+	e.resetSteps()
+	if n > 1 {
+		e.stepsCmd, e.stepsTotal, e.stepsLeft, e.stepRepeat = cmd, n, n-1, step
+	}
+	e.markStepOrigin()
+	if err := step(); err != nil {
+		e.resetSteps()
+		e.stepFrom = nil
+		return err
+	}
+	return nil
+}
+
+// continueSteps is called when a step of a counted run completes. It issues
+// the next one and reports true, or reports false when the run is over (or
+// the next step could not start) and the stop should be shown. done is the
+// number of steps that completed.
+func (e *engine) continueSteps() (more bool, done int) {
+	e.stepsDone++
+	if e.stepsLeft == 0 {
+		done = e.stepsDone
+		e.resetSteps()
+		return false, done
+	}
+	e.stepsLeft--
+	if err := e.stepRepeat(); err != nil {
+		e.log.Warn("counted step: next step failed", "cmd", e.stepsCmd, "err", err)
+		done = e.stepsDone
+		e.abortSteps(err.Error())
+		return false, done
+	}
+	return true, 0
+}
+
+// abortSteps ends a counted run on a stop other than its own step — a
+// breakpoint, a pause, an exit — and says how far it got. No-op otherwise.
+func (e *engine) abortSteps(why string) {
+	if e.stepsTotal == 0 {
+		return
+	}
+	e.emit(protocol.EventNotice, protocol.NoticePayload{
+		Command: e.stepsCmd,
+		Message: fmt.Sprintf("stopped after %d of %d steps: %s", e.stepsDone, e.stepsTotal, why),
 	})
+	e.resetSteps()
+}
+
+func (e *engine) resetSteps() {
+	e.stepsCmd, e.stepsTotal, e.stepsLeft, e.stepsDone, e.stepRepeat = "", 0, 0, 0, nil
 }
 
 func (e *engine) StepOut() error {
@@ -403,6 +950,41 @@ func (e *engine) Locals(frameIndex int) ([]protocol.Variable, error) {
 	return vars, err
 }
 
+func (e *engine) InspectArgs() (protocol.ArgsPayload, error) {
+	var p protocol.ArgsPayload
+	err := e.dispatch(func() error {
+		if err := e.requireSuspended(); err != nil {
+			return err
+		}
+		if e.dw == nil {
+			return fmt.Errorf("InspectArgs: no DWARF info")
+		}
+		tid, err := e.activeTID()
+		if err != nil {
+			return fmt.Errorf("InspectArgs: %w", err)
+		}
+		regs, err := e.backend.GetRegisters(tid)
+		if err != nil {
+			return fmt.Errorf("InspectArgs: get registers: %w", err)
+		}
+		// Without the general-purpose registers only stack-passed arguments
+		// can be read; the rest say which register they are in.
+		var gpr []uint64
+		if dr, ok := e.backend.(dwarfRegisterer); ok {
+			if gpr, err = dr.dwarfRegisters(tid); err != nil {
+				return fmt.Errorf("InspectArgs: %w", err)
+			}
+		}
+		fn, args, err := e.dw.ArgsForFrame(e.backend, regs, gpr)
+		if err != nil {
+			return fmt.Errorf("InspectArgs: %w", err)
+		}
+		p = protocol.ArgsPayload{Function: fn, Args: args}
+		return nil
+	})
+	return p, err
+}
+
 func (e *engine) StackFrames() ([]protocol.Frame, error) {
 	var frames []protocol.Frame
 	err := e.dispatch(func() error {
@@ -432,6 +1014,45 @@ func (e *engine) Goroutines() ([]protocol.Goroutine, error) {
 	return goroutines, err
 }
 
+// Status reads the engine's state on the loop, so it is exact as of the
+// moment it runs. Session is left for the hub to fill in.
+func (e *engine) Status() (protocol.StatusPayload, error) {
+	var st protocol.StatusPayload
+	err := e.dispatch(func() error {
+		switch e.getState() {
+		case stateNoProcess:
+			st.Process = protocol.StateIdle
+		case stateRunning:
+			st.Process = protocol.StateRunning
+		case stateSuspended:
+			st.Process = protocol.StateSuspended
+		case stateExited:
+			st.Process = protocol.StateExited
+		}
+		st.Pending = e.manualStopPending || e.stepsTotal > 0 ||
+			(st.Process == protocol.StateRunning && e.stepFrom != nil)
+		if st.Process == protocol.StateRunning || st.Process == protocol.StateSuspended {
+			st.PID = e.proc.pid
+			st.PIE, st.LoadBase = e.image.pie, e.image.base
+		}
+		if st.Process != protocol.StateSuspended {
+			return nil
+		}
+		tid, err := e.activeTID()
+		if err != nil {
+			return fmt.Errorf("Status: %w", err)
+		}
+		regs, err := e.backend.GetRegisters(tid)
+		if err != nil {
+			return fmt.Errorf("Status: %w", err)
+		}
+		st.TID, st.PC = tid, regs.PC
+		st.Reason = e.stopReason
+		return nil
+	})
+	return st, err
+}
+
 func (e *engine) loop() {
 	// Pin to one OS thread. On Darwin the backend issues ptrace/Mach calls
 	// directly from these dispatch closures, so they must stay on one thread.
@@ -446,6 +1067,11 @@ func (e *engine) loop() {
 		if c, ok := e.backend.(interface{ closeTracer() }); ok {
 			c.closeTracer()
 		}
+		e.mu.Lock()
+		if e.tty != nil {
+			_ = e.tty.Close()
+		}
+		e.mu.Unlock()
 	}()
 
 	for {
@@ -455,9 +1081,16 @@ func (e *engine) loop() {
 
 		case result := <-e.stopCh:
 			if result.err != nil {
-				if errors.Is(result.err, ErrProcessExited) {
-					e.emitProcessExited(0)
-				} else {
+				switch {
+				case errors.Is(result.err, ErrProcessExited):
+					e.emitProcessExited(0, exitReasonExited)
+				case targetGone(result.err):
+					// The tracee disappeared underneath a wait call. That is
+					// an end of session, not a debugger fault — report it as
+					// an exit rather than a scary EventError.
+					e.log.Info("target gone", "err", result.err)
+					e.emitProcessExited(-1, exitReasonGone)
+				default:
 					e.emitError(protocol.CmdNone, result.err)
 				}
 				e.drainCmds()
@@ -503,14 +1136,31 @@ func (e *engine) handleStop(stop StopEvent) {
 			return
 		}
 		e.setState(stateExited)
-		e.emitProcessExited(stop.ExitCode)
+		e.emitProcessExited(stop.ExitCode, exitReasonExited)
 
 	case StopKilled:
 		if e.getState() == stateExited {
 			return
 		}
 		e.setState(stateExited)
-		e.emitProcessExited(-1)
+		e.emitProcessExited(-1, exitReasonKilled)
+
+	case StopGone:
+		if e.getState() == stateExited {
+			return
+		}
+		e.setState(stateExited)
+		e.emitProcessExited(-1, exitReasonGone)
+
+	case StopThreadStarted, StopThreadExited:
+		// The backend already resumed the thread; nothing stopped, so the
+		// state is unchanged and the wait simply goes on.
+		kind := protocol.EventThreadStarted
+		if stop.Reason == StopThreadExited {
+			kind = protocol.EventThreadExited
+		}
+		e.emit(kind, protocol.ThreadPayload{TID: stop.TID})
+		go e.waitLoop()
 
 	case StopBreakpoint:
 		e.setState(stateSuspended)
@@ -543,23 +1193,39 @@ func (e *engine) handleStop(stop StopEvent) {
 		e.log.Debug("StopBreakpoint matched", "file", bp.file, "line", bp.line,
 			"addr", fmt.Sprintf("0x%x", bp.addr))
 		e.rewindToBreakpoint(stop)
-		if bp.file == stepOverNextFile {
+		if bp.file == stepOverNextFile || bp.file == stepOutReturnFile {
 			_ = e.bps.clear(e.backend, bp.id)
 			e.lastBP = nil
-			e.emitStepped(stop)
+			e.noteStep(false)
+			e.emitStepped(stop, protocol.StopReasonStep)
 			return
 		}
-		if bp.file == stepOutReturnFile {
+		if bp.file == entryFile {
 			_ = e.bps.clear(e.backend, bp.id)
 			e.lastBP = nil
-			e.emitStepped(stop)
+			e.noteStep(false)
+			e.emitStepped(stop, protocol.StopReasonTemp)
 			return
 		}
 		e.lastBP = bp
 		e.lastBPTID = stop.TID
+		if bp.ignore > 0 {
+			bp.ignore--
+			if e.skipBreakpointHit(bp, stop.TID) {
+				return
+			}
+			bp.ignore++
+		}
+		if !e.goroutineConditionHolds(bp) && e.skipBreakpointHit(bp, stop.TID) {
+			return
+		}
 		e.stepOverFile = ""
 		e.stepOverLine = 0
-		e.emitBreakpointHit(bp, stop)
+		others, end := e.parkOtherStops()
+		e.emitBreakpointHit(bp, stop, others)
+		if end != nil {
+			e.handleStop(*end)
+		}
 
 	case StopSingleStep:
 		var err error
@@ -587,7 +1253,7 @@ func (e *engine) handleStop(stop StopEvent) {
 				e.log.Error("breakpoint reinstall failed — suspending to prevent runaway process",
 					"addr", fmt.Sprintf("0x%x", sob.addr), "err", rerr)
 				e.setState(stateSuspended)
-				e.emitError(protocol.CmdNone, fmt.Errorf("reinstall breakpoint 0x%x: %w", sob.addr, rerr))
+				e.emitBreakpointLost(sob, fmt.Errorf("reinstall breakpoint at %s: %w", sob.where(), rerr))
 				return
 			}
 			// The trap byte is back in place; only now is it safe to release
@@ -595,13 +1261,17 @@ func (e *engine) handleStop(stop StopEvent) {
 			e.endThreadStep()
 			e.log.Debug("breakpoint reinstalled", "addr", fmt.Sprintf("0x%x", sob.addr))
 			switch e.bpResume {
-			case bpResumeContinue:
+			case bpResumeContinue, bpResumeThread:
 				_ = e.backend.ContinueProcess()
+				if e.bpResume == bpResumeContinue {
+					e.resumeParked()
+				}
 				e.setState(stateRunning)
 				go e.waitLoop()
 			case bpResumeStep:
 				e.setState(stateSuspended)
-				e.emitStepped(stop)
+				e.noteStep(true)
+				e.emitStepped(stop, protocol.StopReasonStep)
 			case bpResumeSourceStep:
 				// Use sob.file/sob.line (the BP's known location) rather than
 				// a DWARF lookup from stop.PC: stop.PC is one instruction past
@@ -612,7 +1282,7 @@ func (e *engine) handleStop(stop StopEvent) {
 							"from", fmt.Sprintf("%s:%d", sob.file, sob.line),
 							"nextPC", fmt.Sprintf("0x%x", nextPC), "nextLine", nextLine)
 						entry, setErr := e.bps.set(e.backend, stepOverNextFile, 0, nextPC)
-						if setErr == nil || errors.Is(setErr, errBreakpointExists) {
+						if setErr == nil || errors.Is(setErr, ErrBreakpointExists) {
 							e.stepOverFile = sob.file
 							e.stepOverLine = nextLine
 							if cerr := e.backend.ContinueProcess(); cerr == nil {
@@ -635,11 +1305,12 @@ func (e *engine) handleStop(stop StopEvent) {
 				}
 				e.log.Debug("sourceStepOver fallback: emitting Stepped")
 				e.setState(stateSuspended)
-				e.emitStepped(stop)
+				e.noteStep(true)
+				e.emitStepped(stop, protocol.StopReasonStep)
 			case bpResumeStepOut:
 				_, setErr := e.bps.set(e.backend, stepOutReturnFile, 0, e.bpRetAddr)
-				if setErr != nil && !errors.Is(setErr, errBreakpointExists) {
-					e.emitError(protocol.CmdStepOut, fmt.Errorf("StepOut: set return breakpoint: %w", setErr))
+				if setErr != nil && !errors.Is(setErr, ErrBreakpointExists) {
+					e.emitError(protocol.CmdStepOut, fmt.Errorf("StepOut: set return breakpoint at %s: %w", e.where(e.bpRetAddr), setErr))
 					return
 				}
 				_ = e.backend.ContinueProcess()
@@ -650,7 +1321,8 @@ func (e *engine) handleStop(stop StopEvent) {
 		}
 		e.endThreadStep()
 		e.setState(stateSuspended)
-		e.emitStepped(stop)
+		e.noteStep(true)
+		e.emitStepped(stop, protocol.StopReasonStep)
 
 	case StopSignal:
 		// Reinstall any in-flight step-over BP before resuming or suspending.
@@ -659,7 +1331,7 @@ func (e *engine) handleStop(stop StopEvent) {
 			if rerr := e.bps.reinstall(e.backend, sob); rerr != nil {
 				e.endThreadStep()
 				e.setState(stateSuspended)
-				e.emitError(protocol.CmdNone, fmt.Errorf("reinstall breakpoint 0x%x after signal: %w", sob.addr, rerr))
+				e.emitBreakpointLost(sob, fmt.Errorf("reinstall breakpoint at %s after signal: %w", sob.where(), rerr))
 				return
 			}
 			e.endThreadStep()
@@ -814,6 +1486,125 @@ func (e *engine) populateBreakpointStop(stop StopEvent) (StopEvent, error) {
 	return stop, fmt.Errorf("find breakpoint thread: read registers: %w", firstErr)
 }
 
+// skipBreakpointHit lets a hit go by that an ignore count or a condition
+// says not to stop on: it resumes exactly as a Continue right after the hit
+// would, without any event, and reports whether it did. Threads that
+// stopped alongside are parked and released with it, so their own hits are
+// taken again. If the resume fails the hit is reported after all, rather
+// than leaving the process stopped with nobody told.
+func (e *engine) skipBreakpointHit(bp *breakpointEntry, tid int) bool {
+	_, end := e.parkOtherStops()
+	if end != nil {
+		e.handleStop(*end)
+		return true
+	}
+	if err := e.resumeFromBreakpoint(bpResumeContinue, 0); err != nil {
+		e.log.Warn("resume past skipped breakpoint hit failed — stopping there",
+			"id", bp.id, "err", err)
+		e.lastBP = bp
+		e.lastBPTID = tid
+		return false
+	}
+	return true
+}
+
+// goroutineConditionHolds evaluates bp's goroutine count condition at a
+// hit; a breakpoint without one always holds. A count that can't be taken
+// holds too, so the hit stops, and the first such failure is reported with
+// EventConditionError: a condition that quietly never held would look like
+// a breakpoint that is never reached.
+func (e *engine) goroutineConditionHolds(bp *breakpointEntry) bool {
+	if bp.goroutineCond == nil {
+		return true
+	}
+	n, err := e.countGoroutines()
+	if err != nil {
+		if !bp.condErrReported {
+			bp.condErrReported = true
+			e.emit(protocol.EventConditionError, protocol.ConditionErrorPayload{
+				Breakpoint: bp.toProtocol(),
+				Message:    fmt.Sprintf("%s: %v", bp.goroutineCond, err),
+			})
+		}
+		e.log.Debug("goroutine count condition unevaluated — stopping", "id", bp.id, "err", err)
+		return true
+	}
+	return bp.goroutineCond.Holds(n)
+}
+
+// parkOtherStops collects the stops of threads that stopped alongside the one
+// about to be reported, and holds each where it is until the next Continue. A
+// breakpoint hit among them is cancelled: its PC goes back onto the trap, so
+// the thread hits it again once it runs instead of being reported later
+// against a breakpoint the user may have cleared in the meantime, which would
+// resume it mid-instruction. A process exit found on the way is returned for
+// the caller to handle after its own event.
+func (e *engine) parkOtherStops() ([]protocol.ThreadStop, *StopEvent) {
+	tp, ok := e.backend.(threadParker)
+	if !ok {
+		return nil, nil
+	}
+	stops, err := tp.pollStops()
+	if err != nil {
+		e.log.Warn("collect other thread stops failed", "err", err)
+	}
+	var (
+		others []protocol.ThreadStop
+		end    *StopEvent
+	)
+	for _, s := range stops {
+		switch s.Reason {
+		case StopExited, StopKilled, StopGone:
+			if end == nil {
+				end = &s
+			}
+			continue
+		case StopThreadStarted:
+			e.emit(protocol.EventThreadStarted, protocol.ThreadPayload{TID: s.TID})
+			continue
+		case StopThreadExited:
+			e.emit(protocol.EventThreadExited, protocol.ThreadPayload{TID: s.TID})
+			continue
+		}
+		e.parked = append(e.parked, s.TID)
+		if s.Reason == StopSignal && s.Signal == e.backend.PauseSignal() {
+			// A Pause that lost the race to this hit; see emitBreakpointHit.
+			continue
+		}
+		ts := protocol.ThreadStop{TID: s.TID, Signal: s.Signal}
+		if populated, err := e.populateStopPC(s, s.Reason == StopBreakpoint); err == nil {
+			s = populated
+			if bp := e.bps.atAddr(s.PC); s.Reason == StopBreakpoint && bp != nil {
+				e.rewindToBreakpoint(s)
+				if !isInternalBreakpoint(bp) {
+					ts.Breakpoint = bp.id
+				}
+			}
+			if e.dw != nil {
+				ts.Location = e.dw.locationForPC(s.PC)
+			}
+		}
+		others = append(others, ts)
+	}
+	return others, end
+}
+
+// resumeParked lets the threads parkOtherStops held run again. One parked on
+// a breakpoint that is still set stops there again at once and is reported
+// in its own right.
+func (e *engine) resumeParked() {
+	tp, ok := e.backend.(threadParker)
+	if !ok {
+		return
+	}
+	for _, tid := range e.parked {
+		if err := tp.continueThread(tid); err != nil {
+			e.log.Warn("resume parked thread failed", "tid", tid, "err", err)
+		}
+	}
+	e.parked = nil
+}
+
 func (e *engine) instructionAt(addr uint64, want []byte) bool {
 	buf := make([]byte, len(want))
 	if err := e.backend.ReadMemory(addr, buf); err != nil {
@@ -873,7 +1664,7 @@ func (e *engine) sourceStepOver() error {
 		if file != "" && line > 0 {
 			if nextPC, nextLine, ok := e.dw.NextLinePC(file, line); ok {
 				entry, setErr := e.bps.set(e.backend, stepOverNextFile, 0, nextPC)
-				if setErr == nil || errors.Is(setErr, errBreakpointExists) {
+				if setErr == nil || errors.Is(setErr, ErrBreakpointExists) {
 					e.stepOverFile = file
 					e.stepOverLine = nextLine
 					if cerr := e.backend.ContinueProcess(); cerr != nil {
@@ -930,18 +1721,19 @@ func (e *engine) stepOut() error {
 	}
 	var retBuf [8]byte
 	if err := e.backend.ReadMemory(regs.BP+8, retBuf[:]); err != nil {
-		return fmt.Errorf("StepOut: read return address: %w", err)
+		return fmt.Errorf("StepOut: read return address at 0x%x: %w", regs.BP+8, err)
 	}
 	retAddr := binary.LittleEndian.Uint64(retBuf[:])
 	if retAddr == 0 {
 		return fmt.Errorf("StepOut: null return address — at outermost frame?")
 	}
+	e.stepFrom = &stepOrigin{pc: regs.PC}
 	if e.lastBP != nil {
 		return e.resumeFromBreakpoint(bpResumeStepOut, retAddr)
 	}
 	_, setErr := e.bps.set(e.backend, stepOutReturnFile, 0, retAddr)
-	if setErr != nil && !errors.Is(setErr, errBreakpointExists) {
-		return fmt.Errorf("StepOut: set return breakpoint: %w", setErr)
+	if setErr != nil && !errors.Is(setErr, ErrBreakpointExists) {
+		return fmt.Errorf("StepOut: set return breakpoint at %s: %w", e.where(retAddr), setErr)
 	}
 	if err := e.backend.ContinueProcess(); err != nil {
 		return fmt.Errorf("StepOut: continue: %w", err)
@@ -951,6 +1743,73 @@ func (e *engine) stepOut() error {
 	return nil
 }
 
+// leaveInitialStop applies initialStop to a process just launched, parked at
+// its first instruction. Reports false when the process is to stay there,
+// by choice or because the mode couldn't be applied; the caller then
+// reports the initial stop as usual.
+func (e *engine) leaveInitialStop() bool {
+	switch e.initialStop {
+	case protocol.InitialStopMain:
+		return e.runToMain()
+	case protocol.InitialStopRun:
+		return e.runFromEntry()
+	default:
+		return false
+	}
+}
+
+// runToMain arms a one-shot breakpoint at main.main's entry and resumes from
+// the initial exec stop, so a continue-to-main launch first suspends in user
+// code. Reports false, leaving the process stopped where it was, when there
+// is no entry to run to.
+func (e *engine) runToMain() bool {
+	if e.dw == nil {
+		e.log.Warn("continue-to-main: no DWARF, stopping at process entry")
+		return false
+	}
+	pc, ok := e.dw.funcEntryPC("main.main")
+	if !ok {
+		e.log.Warn("continue-to-main: main.main not found, stopping at process entry")
+		return false
+	}
+	entry, err := e.bps.set(e.backend, entryFile, 0, pc)
+	if err != nil {
+		e.log.Warn("continue-to-main: set entry breakpoint failed",
+			"addr", fmt.Sprintf("0x%x", pc), "err", err)
+		return false
+	}
+	if err := e.backend.ContinueProcess(); err != nil {
+		_ = e.bps.clear(e.backend, entry.id)
+		e.log.Warn("continue-to-main: continue failed", "err", err)
+		return false
+	}
+	e.setState(stateRunning)
+	go e.waitLoop()
+	return true
+}
+
+// runFromEntry resumes from the initial exec stop without reporting it.
+// Reports false, leaving the process stopped, if it can't be resumed.
+func (e *engine) runFromEntry() bool {
+	if err := e.backend.ContinueProcess(); err != nil {
+		e.log.Warn("initial-stop run: continue failed, stopping at process entry", "err", err)
+		return false
+	}
+	e.setState(stateRunning)
+	go e.waitLoop()
+	return true
+}
+
+// where describes pc for an error message with the source line the DWARF
+// maps it to, when there is DWARF and a line.
+func (e *engine) where(pc uint64) string {
+	if e.dw == nil {
+		return where(pc, "", 0)
+	}
+	loc := e.dw.locationForPC(pc)
+	return where(pc, loc.File, loc.Line)
+}
+
 // resumeFromBreakpoint runs the step-over-software-BP sequence:
 // restore bytes → single-step → reinstall trap (in StopSingleStep handler)
 // → perform action.
@@ -965,7 +1824,7 @@ func (e *engine) resumeFromBreakpoint(action bpResumeAction, retAddr uint64) err
 	if err := e.backend.WriteMemory(bp.addr, bp.originalBytes); err != nil {
 		e.bps.addToTable(bp)
 		e.steppingOverBP = nil
-		return fmt.Errorf("resume BP: restore bytes: %w", err)
+		return fmt.Errorf("resume BP at %s: restore bytes: %w", bp.where(), err)
 	}
 
 	// Use the TID that hit the breakpoint. On Darwin task_threads returns
@@ -977,7 +1836,7 @@ func (e *engine) resumeFromBreakpoint(action bpResumeAction, retAddr uint64) err
 			_ = e.backend.WriteMemory(bp.addr, archTrapInstruction())
 			e.bps.addToTable(bp)
 			e.steppingOverBP = nil
-			return fmt.Errorf("resume BP: no threads")
+			return fmt.Errorf("resume BP at %s: no threads", bp.where())
 		}
 		tid = threads[0]
 	}
@@ -986,7 +1845,7 @@ func (e *engine) resumeFromBreakpoint(action bpResumeAction, retAddr uint64) err
 		_ = e.backend.WriteMemory(bp.addr, archTrapInstruction())
 		e.bps.addToTable(bp)
 		e.steppingOverBP = nil
-		return fmt.Errorf("resume BP: single step: %w", err)
+		return fmt.Errorf("resume BP at %s: single step: %w", bp.where(), err)
 	}
 	e.setState(stateRunning)
 	go e.waitLoop()
@@ -1035,6 +1894,9 @@ func (e *engine) walkStack(regs Registers) []uint64 {
 	return pcs
 }
 
+// readGoroutines lists every live goroutine, the one the stopped thread is
+// running first. When the runtime's goroutine list can't be read it reports
+// only that one.
 func (e *engine) readGoroutines() ([]protocol.Goroutine, error) {
 	// Report the stopped thread's location (curTID via activeTID); threads[0] may
 	// be an idle runtime M and would misreport where execution is paused.
@@ -1046,28 +1908,118 @@ func (e *engine) readGoroutines() ([]protocol.Goroutine, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Goroutines: %w", err)
 	}
-	loc := protocol.Location{}
-	if e.dw != nil {
-		loc = e.dw.locationForPC(regs.PC)
+	cur, curG := e.goroutineFor(regs)
+	if e.dw == nil {
+		return []protocol.Goroutine{cur}, nil
+	}
+	l, err := e.dw.goroutineLayout()
+	if err != nil {
+		return []protocol.Goroutine{cur}, nil
+	}
+	all, err := e.dw.readAllGoroutines(e.backend, l)
+	if err != nil || len(all) == 0 {
+		if err != nil {
+			e.log.Debug("goroutine list unreadable; reporting the stopped goroutine only", "err", err)
+		}
+		return []protocol.Goroutine{cur}, nil
+	}
+	// A thread stopped in the scheduler runs no goroutine of its own, and
+	// curG is 0: then there is none to put first.
+	goroutines := make([]protocol.Goroutine, 0, len(all)+1)
+	if curG != 0 {
+		goroutines = append(goroutines, cur)
 	}
-	return []protocol.Goroutine{{
-		ID:         1,
-		Status:     "waiting",
-		CurrentLoc: loc,
-	}}, nil
+	for _, lg := range all {
+		if lg.addr != curG {
+			goroutines = append(goroutines, lg.g)
+		}
+	}
+	return goroutines, nil
+}
+
+// countGoroutines counts the live goroutines for a goroutine count
+// condition. Unlike a listing it has no fallback to the stopped goroutine
+// alone: a count of one that is really unknown would answer the condition
+// wrongly, so it is an error instead.
+func (e *engine) countGoroutines() (int, error) {
+	if e.dw == nil {
+		return 0, fmt.Errorf("count goroutines: no DWARF info")
+	}
+	l, err := e.dw.goroutineLayout()
+	if err != nil {
+		return 0, fmt.Errorf("count goroutines: %w", err)
+	}
+	all, err := e.dw.readAllGoroutines(e.backend, l)
+	if err != nil {
+		return 0, fmt.Errorf("count goroutines: %w", err)
+	}
+	return len(all), nil
+}
+
+// currentGoroutine is the goroutine the stopped thread is running, without
+// enumerating the others: what every stop event carries.
+func (e *engine) currentGoroutine() protocol.Goroutine {
+	tid, err := e.activeTID()
+	if err != nil {
+		return protocol.Goroutine{}
+	}
+	regs, err := e.backend.GetRegisters(tid)
+	if err != nil {
+		return protocol.Goroutine{}
+	}
+	g, _ := e.goroutineFor(regs)
+	return g
+}
+
+// goroutineFor reads the goroutine the thread with regs is running, located
+// where the thread stopped, and returns its g's address. When that can't be
+// read (no DWARF, no goroutine layout, or a thread on its scheduler stack)
+// it falls back to a placeholder goroutine 1 at the thread's location, and
+// address 0.
+func (e *engine) goroutineFor(regs Registers) (protocol.Goroutine, uint64) {
+	g := protocol.Goroutine{ID: 1, Status: "waiting"}
+	if e.dw == nil {
+		return g, 0
+	}
+	g.CurrentLoc = e.dw.locationForPC(regs.PC)
+	l, err := e.dw.goroutineLayout()
+	if err != nil {
+		return g, 0
+	}
+	gp, err := archCurrentG(e.backend, regs)
+	if err != nil || gp == 0 {
+		return g, 0
+	}
+	rg, live, err := e.dw.readG(e.backend, l, gp, nil)
+	// The scheduler's g0 has goid 0 and is no goroutine a user started.
+	if err != nil || !live || rg.ID == 0 {
+		return g, 0
+	}
+	rg.CurrentLoc = g.CurrentLoc
+	return rg, gp
 }
 
 func (e *engine) loadDWARF(binaryPath string) {
+	// The DWARF is the binary's alone; where this process loaded it is the
+	// backend's to say (the Mach-O slide on darwin, a PIE's base on linux),
+	// so DWARF addresses match the actual load address.
+	var slide int64
+	if sg, ok := e.backend.(interface{ TextSlide(string) int64 }); ok {
+		slide = sg.TextSlide(binaryPath)
+	}
+	e.image = loadedImage(binaryPath, slide)
+
 	dr, err := openDWARF(binaryPath)
 	if err != nil {
+		// Not fatal: execution control still works, only source-level
+		// features (breakpoints, locals, frames) are lost. Say so once here,
+		// since the per-command "no DWARF info" errors don't say why.
+		e.log.Warn("no DWARF debug info; source-level features disabled",
+			"binary", binaryPath, "err", err)
 		e.dw = nil
 		return
 	}
-	// On platforms that support ASLR (Darwin ARM64), ask the backend for the
-	// slide so DWARF addresses match the actual load address.
-	if sg, ok := e.backend.(interface{ TextSlide(string) int64 }); ok {
-		dr.slide = sg.TextSlide(binaryPath)
-	}
+	dr.slide = slide
 	e.dw = dr
 }
 
@@ -1096,7 +2048,7 @@ func (e *engine) emit(kind protocol.EventKind, payload any) {
 	}
 }
 
-func (e *engine) emitBreakpointHit(bp *breakpointEntry, stop StopEvent) {
+func (e *engine) emitBreakpointHit(bp *breakpointEntry, stop StopEvent, others []protocol.ThreadStop) {
 	if stop.TID != 0 {
 		e.curTID = stop.TID
 	}
@@ -1105,17 +2057,77 @@ func (e *engine) emitBreakpointHit(bp *breakpointEntry, stop StopEvent) {
 	// to be suppressed (not reported as Paused) when it surfaces on the next
 	// resume.
 	e.manualStopPending = false
+	e.abortSteps(fmt.Sprintf("breakpoint %d hit", bp.id))
+	e.stepFrom = nil
 	frames, _ := e.collectFrames(stop.TID)
-	goroutines, _ := e.readGoroutines()
-	var g protocol.Goroutine
-	if len(goroutines) > 0 {
-		g = goroutines[0]
-	}
-	e.emit(protocol.EventBreakpointHit, protocol.BreakpointHitPayload{
+	g := e.currentGoroutine()
+	p := protocol.BreakpointHitPayload{
 		Breakpoint: bp.toProtocol(),
 		Goroutine:  g,
 		Frames:     frames,
-	})
+		OtherStops: others,
+		Reason:     protocol.StopReasonBreakpoint,
+	}
+	if e.hitContext {
+		p.Registers = e.hitRegisters()
+	}
+	if e.stoppedThreads {
+		p.StoppedThreads = e.listStoppedThreads(bp, stop, others)
+	}
+	e.stopReason = p.Reason
+	e.emit(protocol.EventBreakpointHit, p)
+}
+
+// hitRegisters reads the stopped thread's registers for a hit event, or nil
+// if they can't be read; the hit is still worth reporting without them.
+func (e *engine) hitRegisters() *protocol.Registers {
+	tid, err := e.activeTID()
+	if err != nil {
+		return nil
+	}
+	regs, err := e.backend.GetRegisters(tid)
+	if err != nil {
+		e.log.Debug("hit context: read registers failed", "tid", tid, "err", err)
+		return nil
+	}
+	return &protocol.Registers{PC: regs.PC, SP: regs.SP, BP: regs.BP}
+}
+
+// listStoppedThreads lists every thread stopped at a hit, for
+// Options.StoppedThreads: the one that hit bp first, then the rest in the
+// backend's order. Threads parked alongside the hit are already resolved in
+// others; any other stopped thread is resolved from its PC.
+func (e *engine) listStoppedThreads(bp *breakpointEntry, stop StopEvent, others []protocol.ThreadStop) []protocol.ThreadStop {
+	st, ok := e.backend.(threadStater)
+	if !ok {
+		return nil
+	}
+	tids, err := st.stoppedThreads()
+	if err != nil {
+		e.log.Debug("list stopped threads failed", "err", err)
+		return nil
+	}
+	known := make(map[int]protocol.ThreadStop, len(others))
+	for _, o := range others {
+		known[o.TID] = o
+	}
+	hit := bp.toProtocol()
+	out := []protocol.ThreadStop{{TID: stop.TID, Location: hit.Location, Breakpoint: hit.ID}}
+	for _, tid := range tids {
+		if tid == stop.TID {
+			continue
+		}
+		if ts, ok := known[tid]; ok {
+			out = append(out, ts)
+			continue
+		}
+		ts := protocol.ThreadStop{TID: tid}
+		if regs, err := e.backend.GetRegisters(tid); err == nil && e.dw != nil {
+			ts.Location = e.dw.locationForPC(regs.PC)
+		}
+		out = append(out, ts)
+	}
+	return out
 }
 
 // emitStoppedAtCurrentPC emits EventStepped at the current PC (used after
@@ -1129,31 +2141,87 @@ func (e *engine) emitStoppedAtCurrentPC() {
 			stop.PC = regs.PC
 		}
 	}
-	e.emitStepped(stop)
+	e.emitStepped(stop, protocol.StopReasonEntry)
 }
 
-func (e *engine) emitStepped(stop StopEvent) {
+func (e *engine) emitStepped(stop StopEvent, reason protocol.StopReason) {
 	if stop.TID != 0 {
 		e.curTID = stop.TID
 	}
 	// Completing a step suspends for a self-stop, which cancels any pending
 	// Pause the same way a breakpoint hit does (see emitBreakpointHit).
 	e.manualStopPending = false
-	frames, _ := e.collectFrames(stop.TID)
-	goroutines, _ := e.readGoroutines()
-	var g protocol.Goroutine
-	if len(goroutines) > 0 {
-		g = goroutines[0]
+	steps := 0
+	if e.stepsTotal > 0 {
+		var more bool
+		if more, steps = e.continueSteps(); more {
+			return
+		}
 	}
+	frames, _ := e.collectFrames(stop.TID)
+	g := e.currentGoroutine()
 	loc := protocol.Location{}
 	if e.dw != nil {
 		loc = e.dw.locationForPC(stop.PC)
 	}
-	e.emit(protocol.EventStepped, protocol.SteppedPayload{
+	payload := protocol.SteppedPayload{
 		Goroutine: g,
 		Location:  loc,
 		Frames:    frames,
-	})
+		Steps:     steps,
+		Reason:    reason,
+	}
+	if from := e.stepFrom; from != nil {
+		e.stepFrom = nil
+		fromLoc := protocol.Location{}
+		if e.dw != nil {
+			fromLoc = e.dw.locationForPC(from.pc)
+		}
+		payload.From = &fromLoc
+		payload.PCDelta = int64(stop.PC - from.pc)
+		if !from.ranFree {
+			payload.Instructions = from.insns
+		}
+	}
+	e.stopReason = reason
+	e.emit(protocol.EventStepped, payload)
+}
+
+// stepOrigin is where a step command started and how it got to its stop.
+// A counted step accumulates across all of its steps.
+type stepOrigin struct {
+	pc      uint64
+	insns   int
+	ranFree bool // some of it ran to a temporary breakpoint, uncounted
+}
+
+// markStepOrigin records the current PC as the start of a step. A register
+// read failure only costs the delta, never the step.
+func (e *engine) markStepOrigin() {
+	e.stepFrom = nil
+	tid, err := e.activeTID()
+	if err != nil {
+		return
+	}
+	regs, err := e.backend.GetRegisters(tid)
+	if err != nil {
+		e.log.Debug("step origin: read registers failed", "tid", tid, "err", err)
+		return
+	}
+	e.stepFrom = &stepOrigin{pc: regs.PC}
+}
+
+// noteStep accounts one completed step toward the reported delta: a single
+// instruction, or a free run to a step-over/step-out breakpoint.
+func (e *engine) noteStep(singleInsn bool) {
+	if e.stepFrom == nil {
+		return
+	}
+	if singleInsn {
+		e.stepFrom.insns++
+	} else {
+		e.stepFrom.ranFree = true
+	}
 }
 
 // emitPaused reports an asynchronous Pause halt. It mirrors emitStepped but
@@ -1163,20 +2231,20 @@ func (e *engine) emitPaused(stop StopEvent) {
 	if stop.TID != 0 {
 		e.curTID = stop.TID
 	}
+	e.abortSteps("paused")
+	e.stepFrom = nil
 	frames, _ := e.collectFrames(stop.TID)
-	goroutines, _ := e.readGoroutines()
-	var g protocol.Goroutine
-	if len(goroutines) > 0 {
-		g = goroutines[0]
-	}
+	g := e.currentGoroutine()
 	loc := protocol.Location{}
 	if e.dw != nil {
 		loc = e.dw.locationForPC(stop.PC)
 	}
+	e.stopReason = protocol.StopReasonPause
 	e.emit(protocol.EventPaused, protocol.PausedPayload{
 		Goroutine: g,
 		Location:  loc,
 		Frames:    frames,
+		Reason:    protocol.StopReasonPause,
 	})
 }
 
@@ -1191,19 +2259,37 @@ func (e *engine) emitContinued() {
 	e.emit(protocol.EventContinued, protocol.ContinuedPayload{})
 }
 
-func (e *engine) emitProcessExited(code int) {
-	e.emit(protocol.EventProcessExited, protocol.ProcessExitedPayload{ExitCode: code})
+func (e *engine) emitProcessExited(code int, reason string) {
+	e.abortSteps("process " + reason)
+	e.emit(protocol.EventProcessExited, protocol.ProcessExitedPayload{ExitCode: code, Reason: reason})
 }
 
 func (e *engine) emitOutput(stream, content string) {
 	e.emit(protocol.EventOutput, protocol.OutputPayload{Stream: stream, Content: content})
 }
 
+// emitBreakpointLost reports a breakpoint whose trap could not be re-armed.
+// The entry is already out of the table (reinstall only re-adds it after the
+// write succeeds), so the breakpoint is gone for good.
+func (e *engine) emitBreakpointLost(bp *breakpointEntry, err error) {
+	e.abortSteps("breakpoint lost")
+	lost := bp.toProtocol()
+	lost.Enabled = false
+	e.emit(protocol.EventBreakpointLost, protocol.BreakpointLostPayload{
+		Breakpoint: lost,
+		Message:    err.Error(),
+	})
+}
+
 func (e *engine) emitError(cmd protocol.CommandKind, err error) {
+	e.abortSteps(err.Error())
 	e.emit(protocol.EventError, protocol.ErrorPayload{Command: cmd, Message: err.Error()})
 }
 
 func (e *engine) setState(s engineState) {
+	if s == stateRunning {
+		e.stopReason = ""
+	}
 	e.mu.Lock()
 	e.state = s
 	e.mu.Unlock()
@@ -0,0 +1,126 @@
+package debugger_test
+
+import (
+	"encoding/binary"
+	"math"
+	"runtime"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+var _ = Describe("InspectArgs", func() {
+	const strAddr = uint64(0x6000)
+
+	var (
+		fb   *fakeBackend
+		d    debugger.Debugger
+		gprs []uint64
+	)
+
+	BeforeEach(func() {
+		if runtime.GOARCH != "amd64" {
+			Skip("the fixture's argument registers are amd64's")
+		}
+		fb = newFakeBackend()
+		d = nil
+		gprs = make([]uint64, 17)
+		fb.seedMem(strAddr, []byte("hi"))
+	})
+
+	AfterEach(func() {
+		if d != nil {
+			_ = d.Kill()
+		}
+		if !fb.stopped {
+			close(fb.stopCh)
+			fb.stopped = true
+		}
+	})
+
+	// load builds an engine over backend with the fixture's DWARF.
+	load := func(backend debugger.Backend) {
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		d = debugger.NewWithBackend(backend, nil)
+		debugger.ExportedLoadDWARF(d, bin)
+	}
+
+	// stopAtEntry suspends thread 1 where a breakpoint on gamma would.
+	stopAtEntry := func() {
+		entry, ok := debugger.ExportedFuncEntryPC(d, "main.gamma")
+		Expect(ok).To(BeTrue())
+		fb.regs[1] = debugger.Registers{PC: entry}
+		debugger.ExportedForceSuspended(d)
+	}
+
+	values := func(p protocol.ArgsPayload) map[string]string {
+		out := make(map[string]string, len(p.Args))
+		for _, v := range p.Args {
+			out[v.Name] = v.Value
+		}
+		return out
+	}
+
+	It("is rejected unless suspended", func() {
+		d = debugger.NewWithBackend(fb, nil)
+		_, err := d.InspectArgs()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reads arguments from the registers the ABI passed them in", func() {
+		// RAX, RBX+RCX, RDI in DWARF numbering.
+		gprs[0], gprs[3], gprs[2], gprs[5] = 7, strAddr, 2, 1
+		load(&debugger.ExportedRegisterBackend{
+			Backend:        fb,
+			DWARFRegisters: func(int) ([]uint64, error) { return gprs, nil },
+		})
+		stopAtEntry()
+
+		p, err := d.InspectArgs()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Function).To(Equal("main.gamma"))
+		Expect(varNames(p.Args)).To(Equal([]string{"n", "s", "ok", "f"}), "results are not arguments")
+		v := values(p)
+		Expect(v["n"]).To(Equal("7"))
+		Expect(v["s"]).To(Equal(`"hi"`))
+		Expect(v["ok"]).To(Equal("true"))
+		Expect(v["f"]).To(ContainSubstring("unreadable"), "X0 is not a general-purpose register")
+	})
+
+	It("reads arguments the function spilled to its caller's frame", func() {
+		load(fb)
+		pc, err := debugger.ExportedPCForFileLine(d, "fix.go", inspectMarkerLine("gamma-marker"))
+		Expect(err).NotTo(HaveOccurred())
+
+		// The spill slots start at the CFA, 16 bytes above the frame pointer.
+		const bp = uint64(0x7000)
+		cfa := bp + 16
+		word := func(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
+		fb.seedMem(cfa, word(7))
+		fb.seedMem(cfa+8, word(strAddr))
+		fb.seedMem(cfa+16, word(2))
+		fb.seedMem(cfa+24, []byte{1})
+		fb.seedMem(cfa+32, word(math.Float64bits(2.5)))
+		fb.regs[1] = debugger.Registers{PC: pc, BP: bp}
+		debugger.ExportedForceSuspended(d)
+
+		p, err := d.InspectArgs()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values(p)).To(Equal(map[string]string{
+			"n": "7", "s": `"hi"`, "ok": "true", "f": "2.5",
+		}))
+	})
+
+	It("says which register an argument is in when it can't read registers", func() {
+		load(fb)
+		stopAtEntry()
+
+		p, err := d.InspectArgs()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values(p)["n"]).To(Equal("<unreadable: register 0 not readable>"))
+	})
+})
@@ -17,8 +17,9 @@ import (
 
 // inspectFixtureSrc has two functions with distinctly-named locals so a
 // misdirected inspection (reading the wrong thread's frame) is detectable by
-// the variable/function names it returns. Built with -N -l so the locals are
-// present in DWARF and not optimized away.
+// the variable/function names it returns, and a third whose arguments cover
+// the scalar and string kinds InspectArgs formats. Built with -N -l so the
+// locals are present in DWARF and not optimized away.
 const inspectFixtureSrc = `package main
 
 func alpha(x int) int {
@@ -31,8 +32,17 @@ func beta(y int) int {
 	return b
 }
 
+func gamma(n int, s string, ok bool, f float64) (int, error) {
+	m := n + len(s) // gamma-marker
+	if ok && f > 0 {
+		m++
+	}
+	return m, nil
+}
+
 func main() {
-	println(alpha(1) + beta(2))
+	m, _ := gamma(7, "hi", true, 2.5)
+	println(alpha(1) + beta(2) + m)
 }
 `
 
@@ -0,0 +1,237 @@
+package debugger_test
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+var _ = Describe("Goroutines", func() {
+	// Fake tracee memory: the allgs backing array, the g structs, and the
+	// string a wait reason name points at.
+	const (
+		allgsData  = uint64(0x7000)
+		gBase      = uint64(0x8000)
+		gStride    = uint64(0x1000)
+		reasonText = uint64(0x6000)
+	)
+
+	var (
+		fb     *fakeBackend
+		d      debugger.Debugger
+		fields map[string]uint64
+		allgs  uint64
+		table  uint64
+		gamma  uint64
+	)
+
+	word := func(v uint64) []byte {
+		return binary.LittleEndian.AppendUint64(nil, v)
+	}
+
+	// seedG writes a g at the i'th slot.
+	seedG := func(i int, goid, status uint64, reason byte) uint64 {
+		gp := gBase + uint64(i)*gStride
+		fb.seedMem(gp+fields["goid"], word(goid))
+		fb.seedMem(gp+fields["atomicstatus"], binary.LittleEndian.AppendUint32(nil, uint32(status)))
+		fb.seedMem(gp+fields["waitreason"], []byte{reason})
+		fb.seedMem(gp+fields["sched.pc"], word(gamma))
+		return gp
+	}
+
+	// seedAllgs points runtime.allgs at gs.
+	seedAllgs := func(gs ...uint64) {
+		for i, gp := range gs {
+			fb.seedMem(allgsData+uint64(i)*8, word(gp))
+		}
+		fb.seedMem(allgs, word(allgsData))
+		fb.seedMem(allgs+8, word(uint64(len(gs))))
+		fb.seedMem(allgs+16, word(uint64(len(gs))))
+	}
+
+	// stopOn suspends thread 1 at gamma, running the goroutine whose g is
+	// at gp. TLS is gp itself, as on arm64, and the word below it holds gp,
+	// where amd64 finds it.
+	stopOn := func(gp uint64) {
+		fb.seedMem(gp-8, word(gp))
+		fb.regs[1] = debugger.Registers{PC: gamma, TLS: gp}
+		debugger.ExportedForceSuspended(d)
+	}
+
+	BeforeEach(func() {
+		fb = newFakeBackend()
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		d = debugger.NewWithBackend(fb, nil)
+		debugger.ExportedLoadDWARF(d, bin)
+		allgs, table, fields, err = debugger.ExportedGoroutineLayout(d)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(table).NotTo(BeZero(), "the fixture keeps runtime.waitReasonStrings")
+		var ok bool
+		gamma, ok = debugger.ExportedFuncEntryPC(d, "main.gamma")
+		Expect(ok).To(BeTrue())
+
+		// Wait reason 2 is "chan receive"; its header is the table's third.
+		fb.seedMem(reasonText, []byte("chan receive"))
+		fb.seedMem(table+2*16, word(reasonText))
+		fb.seedMem(table+2*16+8, word(uint64(len("chan receive"))))
+	})
+
+	AfterEach(func() {
+		_ = d.Kill()
+		if !fb.stopped {
+			close(fb.stopCh)
+			fb.stopped = true
+		}
+	})
+
+	// summary is what a listing says about each goroutine.
+	summary := func(gs []protocol.Goroutine) []string {
+		out := make([]string, len(gs))
+		for i, g := range gs {
+			out[i] = fmt.Sprintf("%d %s %s", g.ID, g.Status, g.WaitReason)
+		}
+		return out
+	}
+
+	It("lists live goroutines with their wait reasons, the stopped one first", func() {
+		main := seedG(0, 1, 4, 2)          // waiting on a channel
+		cur := seedG(1, 7, 2, 0)           // the stopped thread's
+		dead := seedG(2, 9, 6, 0)          // dead, kept in allgs for reuse
+		scanned := seedG(3, 12, 0x1004, 2) // waiting, its stack being scanned
+		seedAllgs(main, cur, dead, scanned)
+		stopOn(cur)
+
+		gs, err := d.Goroutines()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary(gs)).To(Equal([]string{
+			"7 running ",
+			"1 waiting chan receive",
+			"12 waiting chan receive",
+		}))
+		Expect(gs[0].CurrentLoc.Function).To(Equal("main.gamma"))
+		Expect(gs[1].CurrentLoc.Function).To(Equal("main.gamma"), "a parked goroutine is where it will resume")
+	})
+
+	It("reports a wait reason past the runtime's table by number", func() {
+		cur := seedG(0, 1, 2, 0)
+		seedAllgs(cur, seedG(1, 5, 4, 200))
+		stopOn(cur)
+
+		gs, err := d.Goroutines()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary(gs)).To(Equal([]string{"1 running ", "5 waiting unknown wait reason 200"}))
+	})
+
+	It("falls back to the stopped goroutine alone when allgs is empty", func() {
+		stopOn(seedG(0, 3, 2, 0))
+
+		gs, err := d.Goroutines()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary(gs)).To(Equal([]string{"3 running "}))
+	})
+
+	It("carries the stopped goroutine in breakpoint hits", func() {
+		cur := seedG(0, 4, 2, 0)
+		seedAllgs(cur)
+		fb.seedMem(cur-8, word(cur))
+		fb.regs[1] = debugger.Registers{PC: gamma, TLS: cur}
+		debugger.ExportedForceSuspended(d)
+		debugger.ExportedSetBreakpointAt(d, gamma)
+		continueAndConsumeContinued(d)
+		fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: gamma})
+
+		evt := mustNextEvent(d)
+		Expect(evt.Kind).To(Equal(protocol.EventBreakpointHit))
+		var p protocol.BreakpointHitPayload
+		Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+		Expect(p.Goroutine.ID).To(Equal(4))
+		Expect(p.Goroutine.Status).To(Equal("running"))
+	})
+
+	Describe("goroutine count conditions", func() {
+		var bpID int
+
+		// armOn sets a breakpoint at gamma, conditional on cond, and
+		// resumes the process suspended on the goroutine at gp.
+		armOn := func(gp uint64, cond protocol.GoroutineCountCondition) {
+			fb.seedMem(gp-8, word(gp))
+			fb.regs[1] = debugger.Registers{PC: gamma, TLS: gp}
+			debugger.ExportedForceSuspended(d)
+			bpID = debugger.ExportedSetBreakpointAt(d, gamma)
+			bp, err := d.SetGoroutineCondition(bpID, &cond)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bp.GoroutineCount).To(Equal(&cond))
+			continueAndConsumeContinued(d)
+		}
+
+		It("lets hits go by while the condition does not hold", func() {
+			cur := seedG(0, 1, 2, 0)
+			seedAllgs(cur, seedG(1, 2, 4, 2))
+			armOn(cur, protocol.GoroutineCountCondition{Op: ">", Count: 2})
+
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: gamma})
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: gamma + 1})
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopExited})
+
+			// The skipped hit reports nothing: the exit is the next event.
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventProcessExited))
+			Expect(fb.continueCalls).To(Equal(2))
+		})
+
+		It("stops on a hit where the condition holds", func() {
+			cur := seedG(0, 1, 2, 0)
+			seedAllgs(cur, seedG(1, 2, 4, 2), seedG(2, 3, 4, 2))
+			armOn(cur, protocol.GoroutineCountCondition{Op: ">", Count: 2})
+
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: gamma})
+
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventBreakpointHit))
+			var p protocol.BreakpointHitPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.Breakpoint.ID).To(Equal(bpID))
+			Expect(p.Breakpoint.GoroutineCount).To(Equal(&protocol.GoroutineCountCondition{Op: ">", Count: 2}))
+		})
+
+		It("stops where the count can't be taken, reporting only the first failure", func() {
+			cur := seedG(0, 1, 2, 0)
+			// allgs claims three goroutines but has no backing array.
+			fb.seedMem(allgs, word(0))
+			fb.seedMem(allgs+8, word(3))
+			armOn(cur, protocol.GoroutineCountCondition{Op: ">=", Count: 100})
+
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: gamma})
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventConditionError))
+			var p protocol.ConditionErrorPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.Breakpoint.ID).To(Equal(bpID))
+			Expect(p.Message).To(HavePrefix("goroutines >= 100: count goroutines:"))
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventBreakpointHit))
+
+			continueAndConsumeContinued(d)
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: gamma + 1})
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: gamma})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventBreakpointHit))
+		})
+
+		It("rejects an invalid condition or an unknown breakpoint", func() {
+			debugger.ExportedForceSuspended(d)
+			id := debugger.ExportedSetBreakpointAt(d, gamma)
+			_, err := d.SetGoroutineCondition(id, &protocol.GoroutineCountCondition{Op: "~", Count: 1})
+			Expect(err).To(MatchError(ContainSubstring("not a valid condition")))
+			_, err = d.SetGoroutineCondition(id+100, nil)
+			Expect(err).To(MatchError(ContainSubstring("not found")))
+
+			bp, err := d.SetGoroutineCondition(id, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bp.GoroutineCount).To(BeNil())
+		})
+	})
+})
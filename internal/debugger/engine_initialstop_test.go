@@ -0,0 +1,154 @@
+package debugger_test
+
+import (
+	"os"
+	"syscall"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+var _ = Describe("InitialStop", func() {
+	var (
+		fb *fakeBackend
+		d  debugger.Debugger
+	)
+
+	BeforeEach(func() {
+		fb = newFakeBackend()
+		d = debugger.NewWithBackend(fb, nil)
+	})
+
+	AfterEach(func() {
+		_ = d.Kill()
+		if !fb.stopped {
+			close(fb.stopCh)
+			fb.stopped = true
+		}
+	})
+
+	It("stays at the initial stop by default", func() {
+		debugger.ExportedForceSuspended(d)
+		Expect(debugger.ExportedLeaveInitialStop(d)).To(BeFalse())
+		Expect(fb.continueCalls).To(BeZero())
+	})
+
+	It("continue-to-main runs to main.main and reports the entry stop as Stepped", func() {
+		Expect(d.SetInitialStop(protocol.InitialStopMain)).To(Succeed())
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		debugger.ExportedLoadDWARF(d, bin)
+		debugger.ExportedForceSuspended(d)
+
+		entryPC, ok := debugger.ExportedFuncEntryPC(d, "main.main")
+		Expect(ok).To(BeTrue())
+		orig := []byte{0x90, 0x90, 0x90, 0x90}
+		fb.seedMem(entryPC, orig)
+
+		Expect(debugger.ExportedLeaveInitialStop(d)).To(BeTrue())
+		Expect(fb.continueCalls).To(Equal(1))
+		trap := debugger.ExportedTrapInstruction()
+		Expect(fb.peekMem(entryPC, len(trap))).To(Equal(trap))
+
+		fb.regs[1] = debugger.Registers{PC: entryPC}
+		fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: entryPC})
+		evt := mustNextEvent(d)
+		Expect(evt.Kind).To(Equal(protocol.EventStepped))
+		var p protocol.SteppedPayload
+		Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+		Expect(p.Location.Function).To(Equal("main.main"))
+		Expect(p.Reason).To(Equal(protocol.StopReasonTemp), "main.main's breakpoint is not one the user set")
+
+		// One-shot: the original bytes are back and Continue runs freely.
+		Expect(fb.peekMem(entryPC, len(trap))).To(Equal(orig[:len(trap)]))
+		continueAndConsumeContinued(d)
+	})
+
+	It("continue-to-main falls back to the initial stop without DWARF", func() {
+		Expect(d.SetInitialStop(protocol.InitialStopMain)).To(Succeed())
+		debugger.ExportedForceSuspended(d)
+		Expect(debugger.ExportedLeaveInitialStop(d)).To(BeFalse())
+		Expect(fb.continueCalls).To(BeZero())
+	})
+
+	It("run resumes from the initial stop without reporting a stop", func() {
+		Expect(d.SetInitialStop(protocol.InitialStopRun)).To(Succeed())
+		debugger.ExportedForceSuspended(d)
+		Expect(debugger.ExportedLeaveInitialStop(d)).To(BeTrue())
+		Expect(fb.continueCalls).To(Equal(1))
+		Consistently(d.Events(), "50ms").ShouldNot(Receive())
+	})
+
+	It("announces DebuggerReady ahead of the initial stop", func() {
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		debugger.ExportedLaunched(d, os.Getpid(), bin)
+
+		evt := mustNextEvent(d)
+		Expect(evt.Kind).To(Equal(protocol.EventDebuggerReady))
+		var p protocol.DebuggerReadyPayload
+		Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+		Expect(p.PID).To(Equal(os.Getpid()))
+		Expect(p.PGID).To(Equal(syscall.Getpgrp()))
+		Expect(p.Program).To(Equal(bin))
+
+		evt = mustNextEvent(d)
+		Expect(evt.Kind).To(Equal(protocol.EventStepped))
+		var stop protocol.SteppedPayload
+		Expect(protocol.DecodeEventPayload(evt, &stop)).To(Succeed())
+		Expect(stop.Reason).To(Equal(protocol.StopReasonEntry))
+	})
+
+	It("announces DebuggerReady under run, which reports no stop", func() {
+		Expect(d.SetInitialStop(protocol.InitialStopRun)).To(Succeed())
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		debugger.ExportedLaunched(d, os.Getpid(), bin)
+
+		Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventDebuggerReady))
+		Consistently(d.Events(), "50ms").ShouldNot(Receive())
+		Expect(fb.continueCalls).To(Equal(1))
+	})
+
+	It("arms initial breakpoints before run lets the process go", func() {
+		Expect(d.SetInitialStop(protocol.InitialStopRun)).To(Succeed())
+		line := inspectMarkerLine("alpha-marker")
+		Expect(d.SetInitialBreakpoints([]protocol.Location{
+			{File: "fix.go", Line: line},
+			{File: "fix.go", Line: line},
+			{File: "nope.go", Line: 1},
+		})).To(Succeed())
+		bin, err := inspectFixture()
+		Expect(err).NotTo(HaveOccurred())
+		debugger.ExportedLaunched(d, os.Getpid(), bin)
+
+		Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventDebuggerReady))
+		evt := mustNextEvent(d)
+		Expect(evt.Kind).To(Equal(protocol.EventBreakpointSet))
+		var set protocol.BreakpointSetPayload
+		Expect(protocol.DecodeEventPayload(evt, &set)).To(Succeed())
+		Expect(set.Breakpoint.Location.Line).To(Equal(line))
+		pc, err := debugger.ExportedPCForFileLine(d, "fix.go", line)
+		Expect(err).NotTo(HaveOccurred())
+		trap := debugger.ExportedTrapInstruction()
+		Expect(fb.peekMem(pc, len(trap))).To(Equal(trap))
+
+		// The duplicate is dropped; the unresolvable one is reported.
+		evt = mustNextEvent(d)
+		Expect(evt.Kind).To(Equal(protocol.EventBreakpointError))
+		var bad protocol.BreakpointErrorPayload
+		Expect(protocol.DecodeEventPayload(evt, &bad)).To(Succeed())
+		Expect(bad.Location).To(Equal(protocol.Location{File: "nope.go", Line: 1}))
+		Expect(fb.continueCalls).To(Equal(1))
+		Consistently(d.Events(), "50ms").ShouldNot(Receive())
+	})
+
+	It("rejects a mode it doesn't know", func() {
+		Expect(d.SetInitialStop("later")).To(MatchError(ContainSubstring(`unknown mode "later"`)))
+		debugger.ExportedForceSuspended(d)
+		Expect(debugger.ExportedLeaveInitialStop(d)).To(BeFalse())
+	})
+})
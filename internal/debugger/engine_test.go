@@ -3,6 +3,8 @@ package debugger_test
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"runtime"
 	"syscall"
 	"testing"
 	"time"
@@ -28,6 +30,15 @@ type fakeBackend struct {
 
 	stopCh  chan debugger.StopEvent
 	stopped bool
+	// waitErr, when set, is what Wait returns once stopCh is closed instead of
+	// ErrProcessExited — simulates wait4 failing with a raw errno.
+	waitErr error
+	// writeErr, when set, fails every WriteMemory — simulates a page that
+	// became unwritable.
+	writeErr error
+	// dropWrites makes WriteMemory report success without changing memory —
+	// a poke that silently didn't take.
+	dropWrites bool
 
 	continueCalls    int
 	singleStepCalls  []int
@@ -90,6 +101,12 @@ func (f *fakeBackend) ReadMemory(addr uint64, dst []byte) error {
 }
 
 func (f *fakeBackend) WriteMemory(addr uint64, src []byte) error {
+	if f.writeErr != nil {
+		return f.writeErr
+	}
+	if f.dropWrites {
+		return nil
+	}
 	cp := make([]byte, len(src))
 	copy(cp, src)
 	f.writtenAt[addr] = cp
@@ -111,6 +128,9 @@ func (f *fakeBackend) SetRegisters(tid int, reg debugger.Registers) error {
 func (f *fakeBackend) Wait() (debugger.StopEvent, error) {
 	evt, ok := <-f.stopCh
 	if !ok {
+		if f.waitErr != nil {
+			return debugger.StopEvent{}, f.waitErr
+		}
 		return debugger.StopEvent{}, debugger.ErrProcessExited
 	}
 	return evt, nil
@@ -318,6 +338,35 @@ var _ = Describe("Engine", func() {
 			Expect(fb.peekMem(bpAddr, len(trap))).To(Equal(trap))
 		})
 
+		It("does not notice a trap write that didn't take by default", func() {
+			fb.dropWrites = true
+			Expect(debugger.ExportedSetBreakpointAtErr(d, bpAddr)).To(Succeed())
+		})
+
+		It("fails the set when VerifyTraps reads back something other than the trap", func() {
+			debugger.ExportedEnableVerifyTraps(d)
+			fb.dropWrites = true
+			err := debugger.ExportedSetBreakpointAtErr(d, bpAddr)
+			Expect(err).To(MatchError(ContainSubstring("verify trap at 0x2000: read back 48")))
+
+			// Nothing was recorded: a later set at the same address is not
+			// refused as a duplicate.
+			fb.dropWrites = false
+			Expect(debugger.ExportedSetBreakpointAtErr(d, bpAddr)).To(Succeed())
+		})
+
+		It("names the address and source line when the trap write fails", func() {
+			fb.writeErr = errors.New("page not writable")
+			err := debugger.ExportedSetBreakpointAtLineErr(d, bpAddr, "main.go", 12)
+			Expect(err).To(MatchError(ContainSubstring("write trap at 0x2000 (main.go:12): page not writable")))
+		})
+
+		It("names the breakpoint's address when restoring its bytes fails", func() {
+			id := debugger.ExportedSetBreakpointAt(d, bpAddr)
+			fb.writeErr = errors.New("page not writable")
+			Expect(d.ClearBreakpoint(id)).To(MatchError(ContainSubstring("restore bytes at 0x2000")))
+		})
+
 		It("original bytes are saved and restored on ClearBreakpoint", func() {
 			trap := debugger.ExportedTrapInstruction()
 			id := debugger.ExportedSetBreakpointAt(d, bpAddr)
@@ -331,21 +380,77 @@ var _ = Describe("Engine", func() {
 			Expect(d.ClearBreakpoint(999)).To(HaveOccurred())
 		})
 
-		It("setting the same address twice returns an error wrapping errBreakpointExists", func() {
+		It("setting the same address twice returns an error wrapping ErrBreakpointExists", func() {
 			debugger.ExportedSetBreakpointAt(d, bpAddr)
 			err := debugger.ExportedSetBreakpointAtErr(d, bpAddr)
 			Expect(err).To(HaveOccurred())
-			Expect(errors.Is(err, debugger.ExportedErrBreakpointExists)).To(BeTrue())
+			Expect(errors.Is(err, debugger.ErrBreakpointExists)).To(BeTrue())
+		})
+
+		It("disables and re-enables every breakpoint without forgetting them", func() {
+			trap := debugger.ExportedTrapInstruction()
+			addrs := []uint64{bpAddr, bpAddr + 0x100, bpAddr + 0x200}
+			for _, a := range addrs[1:] {
+				fb.seedMem(a, []byte{origByte, 0x89, 0xC0})
+			}
+			ids := make([]int, 0, len(addrs))
+			for _, a := range addrs {
+				ids = append(ids, debugger.ExportedSetBreakpointAt(d, a))
+			}
+
+			bps, err := d.DisableAllBreakpoints()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bps).To(HaveLen(len(addrs)))
+			for i, bp := range bps {
+				Expect(bp.ID).To(Equal(ids[i]))
+				Expect(bp.Enabled).To(BeFalse())
+				Expect(fb.peekMem(addrs[i], 1)[0]).To(Equal(origByte))
+			}
+
+			bps, err = d.EnableAllBreakpoints()
+			Expect(err).NotTo(HaveOccurred())
+			for i, bp := range bps {
+				Expect(bp.ID).To(Equal(ids[i]))
+				Expect(bp.Enabled).To(BeTrue())
+				Expect(fb.peekMem(addrs[i], len(trap))).To(Equal(trap))
+			}
+
+			// The definitions survived: each ID still clears normally.
+			for _, id := range ids {
+				Expect(d.ClearBreakpoint(id)).To(Succeed())
+			}
+		})
+
+		It("clears every breakpoint at once and reports their IDs", func() {
+			addrs := []uint64{bpAddr, bpAddr + 0x100}
+			fb.seedMem(addrs[1], []byte{origByte, 0x89, 0xC0})
+			ids := make([]int, 0, len(addrs))
+			for _, a := range addrs {
+				ids = append(ids, debugger.ExportedSetBreakpointAt(d, a))
+			}
+
+			cleared, err := d.ClearAllBreakpoints()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cleared).To(Equal(ids))
+			for _, a := range addrs {
+				Expect(fb.peekMem(a, 1)[0]).To(Equal(origByte))
+			}
+			Expect(d.ClearBreakpoint(ids[0])).To(HaveOccurred())
+
+			cleared, err = d.ClearAllBreakpoints()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cleared).To(BeEmpty())
 		})
 	})
 
 	Describe("breakpoint hit event flow", func() {
 		const bpAddr = uint64(0x3000)
+		var bpID int
 
 		BeforeEach(func() {
 			fb.seedMem(bpAddr, []byte{0x90})
 			debugger.ExportedForceSuspended(d)
-			debugger.ExportedSetBreakpointAt(d, bpAddr)
+			bpID = debugger.ExportedSetBreakpointAt(d, bpAddr)
 		})
 
 		It("emits EventBreakpointHit when a breakpoint stop arrives", func() {
@@ -362,6 +467,24 @@ var _ = Describe("Engine", func() {
 			var p protocol.BreakpointHitPayload
 			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
 			Expect(p.Breakpoint.Location.File).To(Equal("<direct-addr>"))
+			Expect(p.Registers).To(BeNil())
+			Expect(p.Reason).To(Equal(protocol.StopReasonBreakpoint))
+		})
+
+		It("adds the stopped thread's registers with HitContext", func() {
+			debugger.ExportedEnableHitContext(d)
+			fb.regs[1] = debugger.Registers{PC: bpAddr + 1, SP: 0xc000100000, BP: 0xc000100040}
+
+			continueAndConsumeContinued(d)
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventBreakpointHit))
+			var p protocol.BreakpointHitPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.Registers).NotTo(BeNil())
+			Expect(p.Registers.SP).To(Equal(uint64(0xc000100000)))
+			Expect(p.Registers.BP).To(Equal(uint64(0xc000100040)))
 		})
 
 		It("resolves a raw trap stop to the thread parked on the breakpoint", func() {
@@ -384,6 +507,130 @@ var _ = Describe("Engine", func() {
 			Expect(fb.singleStepCalls).To(ContainElement(2))
 		})
 
+		It("emits EventBreakpointLost when the trap cannot be re-armed after stepping off it", func() {
+			continueAndConsumeContinued(d)
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventBreakpointHit))
+
+			// Stepping off restores the original bytes; the reinstall after the
+			// single-step is the write that fails.
+			continueAndConsumeContinued(d)
+			fb.writeErr = errors.New("page not writable")
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: bpAddr + 1})
+
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventBreakpointLost))
+			var p protocol.BreakpointLostPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.Breakpoint.Location.File).To(Equal("<direct-addr>"))
+			Expect(p.Breakpoint.Enabled).To(BeFalse())
+			Expect(p.Message).To(ContainSubstring("page not writable"))
+			Expect(p.Message).To(ContainSubstring("0x3000"))
+			// Only the first Continue resumed; the step-off must not continue
+			// the process without its trap.
+			Expect(fb.continueCalls).To(Equal(1))
+		})
+
+		It("names the breakpoint's address when Continue can't step off it", func() {
+			continueAndConsumeContinued(d)
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventBreakpointHit))
+
+			fb.writeErr = errors.New("page not writable")
+			Expect(d.Continue()).To(MatchError(ContainSubstring("resume BP at 0x3000: restore bytes")))
+		})
+
+		It("still fires a breakpoint set on the very next instruction while parked", func() {
+			const nextAddr = bpAddr + 1
+			trap := debugger.ExportedTrapInstruction()
+			fb.seedMem(nextAddr, []byte{0x90})
+
+			continueAndConsumeContinued(d)
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventBreakpointHit))
+
+			nextID := debugger.ExportedSetBreakpointAt(d, nextAddr)
+			continueAndConsumeContinued(d)
+			Expect(fb.singleStepCalls).To(ConsistOf(1), "stepping off the first trap")
+
+			// The step off the first trap lands on the second one without
+			// executing it; the process then runs on into that trap.
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: nextAddr})
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: nextAddr})
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventBreakpointHit))
+			var p protocol.BreakpointHitPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.Breakpoint.ID).To(Equal(nextID))
+
+			// The hit orders these checks after the step-off: the first trap
+			// was re-armed before the continue that reached the second.
+			Expect(fb.continueCalls).To(Equal(2))
+			Expect(fb.peekMem(bpAddr, len(trap))).To(Equal(trap))
+			Expect(fb.peekMem(nextAddr, len(trap))).To(Equal(trap))
+		})
+
+		It("leaves a breakpoint disabled while parked on it unarmed after stepping off", func() {
+			continueAndConsumeContinued(d)
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventBreakpointHit))
+
+			_, err := d.DisableAllBreakpoints()
+			Expect(err).NotTo(HaveOccurred())
+			continueAndConsumeContinued(d)
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: bpAddr + 1})
+
+			// The exit event orders the checks after the step-off completed.
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopExited})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventProcessExited))
+			Expect(fb.continueCalls).To(Equal(2))
+			Expect(fb.peekMem(bpAddr, 1)[0]).To(Equal(byte(0x90)))
+		})
+
+		It("lets the ignored hits go by and stops on the one after", func() {
+			bp, err := d.SetIgnoreCount(bpID, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bp.IgnoreCount).To(Equal(2))
+
+			continueAndConsumeContinued(d)
+			for skip := 1; skip <= 2; skip++ {
+				fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+				fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: bpAddr + 1})
+			}
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+
+			// Nothing is reported for the skipped hits: the first event is
+			// the third hit, after two silent step-offs and resumes.
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventBreakpointHit))
+			var p protocol.BreakpointHitPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.Breakpoint.ID).To(Equal(bpID))
+			Expect(p.Breakpoint.IgnoreCount).To(BeZero())
+			Expect(fb.singleStepCalls).To(HaveLen(2))
+			Expect(fb.continueCalls).To(Equal(3))
+		})
+
+		It("stops on the next hit once the ignore count is reset to 0", func() {
+			_, err := d.SetIgnoreCount(bpID, 5)
+			Expect(err).NotTo(HaveOccurred())
+			bp, err := d.SetIgnoreCount(bpID, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bp.IgnoreCount).To(BeZero())
+
+			continueAndConsumeContinued(d)
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventBreakpointHit))
+			Expect(fb.singleStepCalls).To(BeEmpty())
+		})
+
+		It("rejects an ignore count for an unknown breakpoint or below zero", func() {
+			_, err := d.SetIgnoreCount(bpID+100, 1)
+			Expect(err).To(MatchError(ContainSubstring("not found")))
+			_, err = d.SetIgnoreCount(bpID, -1)
+			Expect(err).To(MatchError(ContainSubstring("negative")))
+		})
+
 		It("emits nothing (resumes silently) for an unrecognised breakpoint PC", func() {
 			continueAndConsumeContinued(d)
 			fb.pushStop(debugger.StopEvent{
@@ -398,6 +645,129 @@ var _ = Describe("Engine", func() {
 		})
 	})
 
+	Describe("ReadString", func() {
+		const (
+			hdr  = uint64(0x5000)
+			data = uint64(0x6000)
+		)
+
+		BeforeEach(func() {
+			debugger.ExportedForceSuspended(d)
+		})
+
+		It("decodes the header and reads the bytes it points at", func() {
+			fb.seedMem(hdr, le8(data))
+			fb.seedMem(hdr+8, le8(5))
+			fb.seedMem(data, []byte("hello"))
+
+			s, n, err := d.ReadString(hdr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(Equal("hello"))
+			Expect(n).To(Equal(uint64(5)))
+		})
+
+		It("returns the empty string for a zero length, even with nil data", func() {
+			fb.seedMem(hdr, le8(0))
+			fb.seedMem(hdr+8, le8(0))
+
+			s, n, err := d.ReadString(hdr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s).To(BeEmpty())
+			Expect(n).To(BeZero())
+		})
+
+		It("rejects nil data with a non-zero length", func() {
+			fb.seedMem(hdr, le8(0))
+			fb.seedMem(hdr+8, le8(3))
+
+			_, _, err := d.ReadString(hdr)
+			Expect(err).To(MatchError(ContainSubstring("nil data")))
+		})
+
+		It("reads at most the bound but reports the full length", func() {
+			full := uint64(debugger.ExportedMaxStringRead) + 100
+			fb.seedMem(hdr, le8(data))
+			fb.seedMem(hdr+8, le8(full))
+
+			s, n, err := d.ReadString(hdr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(s)).To(Equal(debugger.ExportedMaxStringRead))
+			Expect(n).To(Equal(full))
+		})
+
+		It("requires the process to be suspended", func() {
+			debugger.ExportedForceRunning(d)
+			_, _, err := d.ReadString(hdr)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ReadSlice", func() {
+		const (
+			hdr  = uint64(0x5000)
+			data = uint64(0x6000)
+		)
+
+		seedSlice := func(ptr, length, capacity uint64) {
+			fb.seedMem(hdr, le8(ptr))
+			fb.seedMem(hdr+8, le8(length))
+			fb.seedMem(hdr+16, le8(capacity))
+		}
+
+		BeforeEach(func() {
+			debugger.ExportedForceSuspended(d)
+		})
+
+		It("decodes the header and returns each element's bytes", func() {
+			seedSlice(data, 3, 4)
+			for i := uint64(0); i < 3; i++ {
+				fb.seedMem(data+i*8, le8(i+1))
+			}
+
+			p, err := d.ReadSlice(hdr, 8)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Data).To(Equal(data))
+			Expect(p.Len).To(Equal(uint64(3)))
+			Expect(p.Cap).To(Equal(uint64(4)))
+			Expect(p.Elements).To(Equal([]string{"0100000000000000", "0200000000000000", "0300000000000000"}))
+			Expect(p.Truncated).To(BeFalse())
+		})
+
+		It("returns a nil slice with no elements", func() {
+			seedSlice(0, 0, 0)
+
+			p, err := d.ReadSlice(hdr, 8)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Data).To(BeZero())
+			Expect(p.Elements).To(BeEmpty())
+		})
+
+		It("stops at the configured element cap", func() {
+			debugger.ExportedSetMaxSliceElements(d, 2)
+			seedSlice(data, 5, 5)
+
+			p, err := d.ReadSlice(hdr, 4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Elements).To(HaveLen(2))
+			Expect(p.Len).To(Equal(uint64(5)))
+			Expect(p.Truncated).To(BeTrue())
+		})
+
+		It("rejects a header whose len exceeds its cap", func() {
+			seedSlice(data, 5, 2)
+
+			_, err := d.ReadSlice(hdr, 8)
+			Expect(err).To(MatchError(ContainSubstring("exceeds cap")))
+		})
+
+		It("rejects an out-of-range element size", func() {
+			seedSlice(data, 1, 1)
+
+			_, err := d.ReadSlice(hdr, 0)
+			Expect(err).To(MatchError(ContainSubstring("element size")))
+		})
+	})
+
 	Describe("process exit", func() {
 		It("emits EventProcessExited with exit code when StopExited arrives", func() {
 			fb2 := newFakeBackend()
@@ -427,6 +797,39 @@ var _ = Describe("Engine", func() {
 			Expect(p.ExitCode).To(Equal(42))
 		})
 
+		DescribeTable("reports a vanished target as an exit, not an error",
+			func(waitErr error) {
+				debugger.ExportedForceSuspended(d)
+				continueAndConsumeContinued(d)
+				fb.waitErr = waitErr
+				fb.closeStop()
+
+				evt := mustNextEvent(d)
+				Expect(evt.Kind).To(Equal(protocol.EventProcessExited))
+				var p protocol.ProcessExitedPayload
+				Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+				Expect(p.Reason).To(Equal("gone"))
+				Expect(p.ExitCode).To(Equal(-1))
+
+				_, open := nextEvent(d)
+				Expect(open).To(BeFalse(), "engine should shut down after the target is gone")
+			},
+			Entry("ECHILD", fmt.Errorf("wait4: %w", syscall.ECHILD)),
+			Entry("ESRCH", fmt.Errorf("wait4: %w", syscall.ESRCH)),
+		)
+
+		It("emits EventProcessExited with reason gone on StopGone", func() {
+			debugger.ExportedForceSuspended(d)
+			continueAndConsumeContinued(d)
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopGone, TID: 1})
+
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventProcessExited))
+			var p protocol.ProcessExitedPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.Reason).To(Equal("gone"))
+		})
+
 		It("closes the Events channel after process exits", func() {
 			fb2 := newFakeBackend()
 			d2 := debugger.NewWithBackend(fb2, nil)
@@ -474,6 +877,19 @@ var _ = Describe("Engine", func() {
 			var p protocol.SteppedPayload
 			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
 			Expect(p.Goroutine.Status).To(Equal("waiting"))
+			Expect(p.Reason).To(Equal(protocol.StopReasonStep))
+		})
+
+		It("reports how far the step moved the PC", func() {
+			fb.regs[1] = debugger.Registers{PC: 0x1230}
+			Expect(d.StepInto()).To(Succeed())
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: 0x1234})
+
+			var p protocol.SteppedPayload
+			Expect(protocol.DecodeEventPayload(mustNextEvent(d), &p)).To(Succeed())
+			Expect(p.From).NotTo(BeNil())
+			Expect(p.PCDelta).To(Equal(int64(4)))
+			Expect(p.Instructions).To(Equal(1))
 		})
 
 		It("puts the engine back into stateSuspended after the step", func() {
@@ -485,6 +901,87 @@ var _ = Describe("Engine", func() {
 		})
 	})
 
+	Describe("StepInstruction", func() {
+		It("is rejected unless suspended", func() {
+			Expect(d.StepInstruction()).To(MatchError(debugger.ErrNotSuspended))
+		})
+
+		It("single-steps one instruction and reports it as one", func() {
+			debugger.ExportedForceSuspended(d)
+			fb.regs[1] = debugger.Registers{PC: 0x1000}
+			Expect(d.StepInstruction()).To(Succeed())
+			Expect(fb.singleStepCalls).To(ConsistOf(1))
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: 0x1003})
+
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventStepped))
+			var p protocol.SteppedPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.PCDelta).To(Equal(int64(3)))
+			Expect(p.Instructions).To(Equal(1))
+		})
+	})
+
+	Describe("counted steps", func() {
+		BeforeEach(func() {
+			debugger.ExportedForceSuspended(d)
+		})
+
+		It("rejects a count below one", func() {
+			Expect(d.StepIntoN(0)).To(MatchError(ContainSubstring("at least 1")))
+		})
+
+		It("reports only the last of n steps, with the count", func() {
+			Expect(d.StepIntoN(3)).To(Succeed())
+			for i := 0; i < 3; i++ {
+				fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: 0x1234})
+			}
+
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventStepped))
+			var p protocol.SteppedPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.Steps).To(Equal(3))
+
+			// Suspended again, and nothing else was queued behind it.
+			Expect(d.Continue()).To(Succeed())
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventContinued))
+		})
+
+		It("reports the PC delta and instruction count across the whole run", func() {
+			fb.regs[1] = debugger.Registers{PC: 0x1000}
+			Expect(d.StepIntoN(3)).To(Succeed())
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: 0x1004})
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: 0x1008})
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: 0x0ff0})
+
+			var p protocol.SteppedPayload
+			Expect(protocol.DecodeEventPayload(mustNextEvent(d), &p)).To(Succeed())
+			Expect(p.From).NotTo(BeNil())
+			Expect(p.PCDelta).To(Equal(int64(-0x10)))
+			Expect(p.Instructions).To(Equal(3))
+		})
+
+		It("stops early at a breakpoint and says how far it got", func() {
+			const bpAddr = uint64(0x3000)
+			fb.seedMem(bpAddr, []byte{0x90})
+			debugger.ExportedSetBreakpointAt(d, bpAddr)
+
+			Expect(d.StepIntoN(5)).To(Succeed())
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: 0x1234})
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventNotice))
+			var n protocol.NoticePayload
+			Expect(protocol.DecodeEventPayload(evt, &n)).To(Succeed())
+			Expect(n.Command).To(Equal(protocol.CmdStepInto))
+			Expect(n.Message).To(ContainSubstring("after 1 of 5 steps"))
+
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventBreakpointHit))
+		})
+	})
+
 	Describe("StepOver", func() {
 		BeforeEach(func() {
 			debugger.ExportedForceSuspended(d)
@@ -538,6 +1035,9 @@ var _ = Describe("Engine", func() {
 			evt := mustNextEvent(d)
 			Expect(evt.Kind).To(Equal(protocol.EventStepped),
 				"StepOut should emit EventStepped, not EventBreakpointHit")
+			var p protocol.SteppedPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.Reason).To(Equal(protocol.StopReasonStep), "the return breakpoint is the step's own")
 		})
 
 		It("removes the one-shot breakpoint after it fires", func() {
@@ -580,6 +1080,37 @@ var _ = Describe("Engine", func() {
 		})
 	})
 
+	Describe("WriteInput", func() {
+		It("fails with ErrNoTerminal for a process not launched on a PTY", func() {
+			debugger.ExportedForceSuspended(d)
+			Expect(d.WriteInput("hi\n")).To(MatchError(debugger.ErrNoTerminal))
+		})
+	})
+
+	Describe("thread events", func() {
+		It("reports threads starting and exiting without suspending", func() {
+			debugger.ExportedForceSuspended(d)
+			continueAndConsumeContinued(d)
+
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopThreadStarted, TID: 7})
+			evt := mustNextEvent(d)
+			Expect(evt.Kind).To(Equal(protocol.EventThreadStarted))
+			var p protocol.ThreadPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.TID).To(Equal(7))
+
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopThreadExited, TID: 7})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventThreadExited))
+
+			// Still running: inspection is refused and the wait carried on,
+			// so the process exit is still observed.
+			_, err := d.Locals(0)
+			Expect(err).To(MatchError(debugger.ErrNotSuspended))
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopExited, TID: 1})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventProcessExited))
+		})
+	})
+
 	Describe("Pause", func() {
 		const sigstop = int(syscall.SIGSTOP)
 
@@ -617,6 +1148,7 @@ var _ = Describe("Engine", func() {
 
 			var p protocol.PausedPayload
 			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.Reason).To(Equal(protocol.StopReasonPause))
 
 			// The engine is now suspended: Continue must be accepted again.
 			Expect(d.Continue()).To(Succeed())
@@ -676,6 +1208,80 @@ var _ = Describe("Engine", func() {
 		})
 	})
 
+	Describe("Status", func() {
+		It("reports no process before a launch", func() {
+			st, err := d.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st).To(Equal(protocol.StatusPayload{Process: protocol.StateIdle}))
+		})
+
+		It("reports where a suspended process is stopped", func() {
+			fb.tids = []int{1}
+			fb.regs[1] = debugger.Registers{PC: 0x4010}
+			debugger.ExportedForceSuspended(d)
+
+			st, err := d.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.Process).To(Equal(protocol.StateSuspended))
+			Expect(st.PC).To(Equal(uint64(0x4010)))
+			Expect(st.TID).To(Equal(1))
+			Expect(st.Pending).To(BeFalse())
+		})
+
+		It("reports where the binary was loaded", func() {
+			if runtime.GOOS != "linux" {
+				Skip("the fixture's link address is the linux default")
+			}
+			bin, err := inspectFixture()
+			Expect(err).NotTo(HaveOccurred())
+			debugger.ExportedLoadDWARF(d, bin)
+			fb.tids = []int{1}
+			debugger.ExportedForceSuspended(d)
+
+			st, err := d.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.PIE).To(BeFalse())
+			Expect(st.LoadBase).To(Equal(uint64(0x400000)), "where a non-PIE amd64 Go binary is linked")
+		})
+
+		It("marks a Pause as pending until its stop is reported", func() {
+			debugger.ExportedForceSuspended(d)
+			continueAndConsumeContinued(d)
+			Expect(d.Pause()).To(Succeed())
+
+			st, err := d.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.Process).To(Equal(protocol.StateRunning))
+			Expect(st.Pending).To(BeTrue())
+			Expect(st.PC).To(BeZero(), "registers are not read while running")
+
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSignal, TID: 1, Signal: int(syscall.SIGSTOP)})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventPaused))
+			st, err = d.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.Process).To(Equal(protocol.StateSuspended))
+			Expect(st.Pending).To(BeFalse())
+			Expect(st.Reason).To(Equal(protocol.StopReasonPause))
+		})
+
+		It("forgets the stop reason once the process resumes", func() {
+			fb.tids = []int{1}
+			debugger.ExportedForceSuspended(d)
+			Expect(d.StepInto()).To(Succeed())
+			fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: 0x1234})
+			Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventStepped))
+			st, err := d.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.Reason).To(Equal(protocol.StopReasonStep))
+
+			continueAndConsumeContinued(d)
+			st, err = d.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(st.Process).To(Equal(protocol.StateRunning))
+			Expect(st.Reason).To(BeEmpty())
+		})
+	})
+
 	Describe("StackFrames", func() {
 		BeforeEach(func() {
 			debugger.ExportedForceSuspended(d)
@@ -0,0 +1,147 @@
+package debugger_test
+
+import (
+	"syscall"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+var _ = Describe("threads stopping together", func() {
+	const bpAddr = uint64(0x3000)
+
+	var (
+		fb      *fakeBackend
+		d       debugger.Debugger
+		pending []debugger.StopEvent
+		resumed []int
+		stopped []int
+		// armed records, per resumed thread, whether the breakpoint's trap
+		// was in place when it was let go.
+		armed map[int]bool
+		trap  []byte
+		bpID  int
+	)
+
+	BeforeEach(func() {
+		fb = newFakeBackend()
+		pending = nil
+		resumed = nil
+		stopped = []int{1, 2, 3}
+		armed = make(map[int]bool)
+		trap = debugger.ExportedTrapInstruction()
+		d = debugger.NewWithBackend(&debugger.ExportedParkingBackend{
+			Backend: fb,
+			PollStops: func() ([]debugger.StopEvent, error) {
+				stops := pending
+				pending = nil
+				return stops, nil
+			},
+			ContinueThread: func(tid int) error {
+				resumed = append(resumed, tid)
+				armed[tid] = string(fb.peekMem(bpAddr, len(trap))) == string(trap)
+				return nil
+			},
+			StoppedThreads: func() ([]int, error) { return stopped, nil },
+		}, nil)
+
+		fb.seedMem(bpAddr, []byte{0x90})
+		fb.tids = []int{1, 2, 3}
+		// Thread 2 trapped on the same breakpoint: on amd64 its PC is already
+		// past the INT3.
+		fb.regs[2] = debugger.Registers{PC: bpAddr + uint64(len(trap)%4)}
+		fb.regs[3] = debugger.Registers{PC: 0x4000}
+		debugger.ExportedForceSuspended(d)
+		bpID = debugger.ExportedSetBreakpointAt(d, bpAddr)
+		continueAndConsumeContinued(d)
+	})
+
+	AfterEach(func() {
+		_ = d.Kill()
+		if !fb.stopped {
+			close(fb.stopCh)
+			fb.stopped = true
+		}
+	})
+
+	hit := func() protocol.BreakpointHitPayload {
+		pending = []debugger.StopEvent{
+			{Reason: debugger.StopBreakpoint, TID: 2},
+			{Reason: debugger.StopSignal, TID: 3, Signal: int(syscall.SIGSEGV)},
+		}
+		fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+		evt := mustNextEvent(d)
+		ExpectWithOffset(1, evt.Kind).To(Equal(protocol.EventBreakpointHit))
+		var p protocol.BreakpointHitPayload
+		ExpectWithOffset(1, protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+		return p
+	}
+
+	It("reports every thread stopped alongside the hit", func() {
+		p := hit()
+		Expect(p.OtherStops).To(ConsistOf(
+			protocol.ThreadStop{TID: 2, Breakpoint: bpID},
+			protocol.ThreadStop{TID: 3, Signal: int(syscall.SIGSEGV)},
+		))
+		Expect(fb.regs[2].PC).To(Equal(bpAddr), "the second hit is cancelled, to be taken again")
+		Expect(resumed).To(BeEmpty())
+		Expect(p.StoppedThreads).To(BeNil(), "only listed with StoppedThreads")
+	})
+
+	It("lists every stopped thread with StoppedThreads, the hit thread first", func() {
+		debugger.ExportedEnableStoppedThreads(d)
+		// Thread 4 is stopped for a reason of its own, unrelated to the hit.
+		fb.regs[4] = debugger.Registers{PC: 0x5000}
+		stopped = []int{4, 3, 2, 1}
+
+		p := hit()
+		Expect(p.StoppedThreads).To(HaveLen(4))
+		Expect(p.StoppedThreads[0].TID).To(Equal(1))
+		Expect(p.StoppedThreads[0].Breakpoint).To(Equal(bpID))
+		Expect(p.StoppedThreads[1:]).To(Equal([]protocol.ThreadStop{
+			{TID: 4},
+			{TID: 3, Signal: int(syscall.SIGSEGV)},
+			{TID: 2, Breakpoint: bpID},
+		}))
+	})
+
+	It("holds the other threads through a step", func() {
+		hit()
+		Expect(d.StepInstruction()).To(Succeed())
+		fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: bpAddr + 1})
+		Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventStepped))
+		Expect(resumed).To(BeEmpty())
+	})
+
+	It("leaves the other threads stopped on ContinueThread, until a Continue", func() {
+		hit()
+		Expect(d.ContinueThread()).To(Succeed())
+		Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventContinued))
+		fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: bpAddr + 1})
+		fb.pushStop(debugger.StopEvent{Reason: debugger.StopBreakpoint, TID: 1, PC: bpAddr})
+		Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventBreakpointHit))
+		Expect(resumed).To(BeEmpty(), "only the hit thread ran")
+
+		continueAndConsumeContinued(d)
+		fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: bpAddr + 1})
+		fb.pushStop(debugger.StopEvent{Reason: debugger.StopExited})
+		Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventProcessExited))
+		Expect(resumed).To(ConsistOf(2, 3))
+	})
+
+	It("resumes the other threads on Continue, once the trap is back", func() {
+		hit()
+		continueAndConsumeContinued(d)
+		Expect(resumed).To(BeEmpty(), "held while the hit thread steps off the trap")
+
+		fb.pushStop(debugger.StopEvent{Reason: debugger.StopSingleStep, TID: 1, PC: bpAddr + 1})
+		// The exit orders the checks after the step-off completed.
+		fb.pushStop(debugger.StopEvent{Reason: debugger.StopExited})
+		Expect(mustNextEvent(d).Kind).To(Equal(protocol.EventProcessExited))
+		Expect(resumed).To(ConsistOf(2, 3))
+		Expect(armed).To(HaveKeyWithValue(2, true))
+	})
+})
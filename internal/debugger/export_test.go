@@ -38,11 +38,59 @@ func ExportedPCForFileLine(d Debugger, file string, line int) (uint64, error) {
 	return pc, err
 }
 
+func ExportedCheckGoBinary(path string) error {
+	return checkGoBinary(path)
+}
+
 func ExportedFileMatches(candidate, target string) bool {
 	return fileMatches(candidate, target)
 }
 
-var ExportedErrBreakpointExists = errBreakpointExists
+const ExportedMaxStringRead = maxStringRead
+
+// ExportedSetMaxSliceElements sets Options.MaxSliceElements for an engine
+// built with NewWithBackend.
+func ExportedSetMaxSliceElements(d Debugger, n int) {
+	e := d.(*engine)
+	_ = e.dispatch(func() error {
+		e.maxSliceElements = n
+		return nil
+	})
+}
+
+// ExportedEnableVerifyTraps turns on Options.VerifyTraps for an engine built
+// with NewWithBackend.
+func ExportedEnableVerifyTraps(d Debugger) {
+	e := d.(*engine)
+	_ = e.dispatch(func() error {
+		e.bps.verify = true
+		return nil
+	})
+}
+
+// ExportedEnableBlockRuntimeBreakpoints turns on
+// Options.BlockRuntimeBreakpoints for an engine built with NewWithBackend.
+func ExportedEnableBlockRuntimeBreakpoints(d Debugger) {
+	e := d.(*engine)
+	_ = e.dispatch(func() error {
+		e.blockRuntimeBPs = true
+		return nil
+	})
+}
+
+func ExportedRuntimeCritical(fn string) (string, bool) {
+	return runtimeCritical(fn)
+}
+
+// ExportedEnableHitContext turns on Options.HitContext for an engine built
+// with NewWithBackend.
+func ExportedEnableHitContext(d Debugger) {
+	e := d.(*engine)
+	_ = e.dispatch(func() error {
+		e.hitContext = true
+		return nil
+	})
+}
 
 // ExportedForceSuspended forces stateSuspended with proc.live=true so tests
 // can exercise suspended-state behaviour without launching a real process.
@@ -58,6 +106,23 @@ func ExportedForceSuspended(d Debugger) {
 	}
 }
 
+// ExportedLaunched runs the part of Launch that follows starting the
+// process, as though pid had just been started from binaryPath and was
+// parked at its initial exec stop. The engine forgets pid afterwards, so a
+// later Kill never signals it.
+func ExportedLaunched(d Debugger, pid int, binaryPath string) {
+	e := d.(*engine)
+	if err := e.dispatch(func() error {
+		e.proc.live = true
+		e.proc.pid = pid
+		e.launched(binaryPath)
+		e.proc.pid = 0
+		return nil
+	}); err != nil {
+		panic("ExportedLaunched: " + err.Error())
+	}
+}
+
 func ExportedForceRunning(d Debugger) {
 	e := d.(*engine)
 	if err := e.dispatch(func() error {
@@ -90,9 +155,104 @@ func ExportedSetBreakpointAt(d Debugger, addr uint64) int {
 }
 
 func ExportedSetBreakpointAtErr(d Debugger, addr uint64) error {
+	return ExportedSetBreakpointAtLineErr(d, addr, "<direct-addr>", 0)
+}
+
+// ExportedSetBreakpointAtLineErr is ExportedSetBreakpointAtErr for a
+// breakpoint that claims to be on file:line.
+func ExportedSetBreakpointAtLineErr(d Debugger, addr uint64, file string, line int) error {
 	e := d.(*engine)
 	return e.dispatch(func() error {
-		_, err := e.bps.set(e.backend, "<direct-addr>", 0, addr)
+		_, err := e.bps.set(e.backend, file, line, addr)
 		return err
 	})
 }
+
+// ExportedLeaveInitialStop runs the initial-stop launch step against the
+// loaded DWARF, as Launch would right after the initial exec stop.
+func ExportedLeaveInitialStop(d Debugger) bool {
+	e := d.(*engine)
+	var ok bool
+	if err := e.dispatch(func() error {
+		ok = e.leaveInitialStop()
+		return nil
+	}); err != nil {
+		panic("ExportedLeaveInitialStop: " + err.Error())
+	}
+	return ok
+}
+
+// ExportedFuncEntryPC resolves a function's breakpoint address via the
+// loaded DWARF.
+func ExportedFuncEntryPC(d Debugger, name string) (uint64, bool) {
+	e := d.(*engine)
+	var pc uint64
+	var ok bool
+	_ = e.dispatch(func() error {
+		if e.dw != nil {
+			pc, ok = e.dw.funcEntryPC(name)
+		}
+		return nil
+	})
+	return pc, ok
+}
+
+// ExportedParkingBackend gives a test Backend the per-thread hooks of a
+// backend whose stops are per-thread, like linux.
+type ExportedParkingBackend struct {
+	Backend
+	PollStops      func() ([]StopEvent, error)
+	ContinueThread func(tid int) error
+	StoppedThreads func() ([]int, error)
+}
+
+func (b *ExportedParkingBackend) pollStops() ([]StopEvent, error) { return b.PollStops() }
+func (b *ExportedParkingBackend) continueThread(tid int) error    { return b.ContinueThread(tid) }
+func (b *ExportedParkingBackend) stoppedThreads() ([]int, error)  { return b.StoppedThreads() }
+
+// ExportedRegisterBackend gives a test Backend the general-purpose register
+// read of a backend that has one, like linux.
+type ExportedRegisterBackend struct {
+	Backend
+	DWARFRegisters func(tid int) ([]uint64, error)
+}
+
+func (b *ExportedRegisterBackend) dwarfRegisters(tid int) ([]uint64, error) {
+	return b.DWARFRegisters(tid)
+}
+
+// ExportedEnableStoppedThreads turns on Options.StoppedThreads for an engine
+// built with NewWithBackend.
+func ExportedEnableStoppedThreads(d Debugger) {
+	e := d.(*engine)
+	_ = e.dispatch(func() error {
+		e.stoppedThreads = true
+		return nil
+	})
+}
+
+// ExportedGoroutineLayout returns where the loaded binary keeps
+// runtime.allgs and runtime.waitReasonStrings, and the offsets of the
+// runtime.g fields a goroutine listing reads, keyed by field name.
+func ExportedGoroutineLayout(d Debugger) (allgs, waitReasons uint64, fields map[string]uint64, err error) {
+	e := d.(*engine)
+	err = e.dispatch(func() error {
+		if e.dw == nil {
+			return fmt.Errorf("no DWARF loaded")
+		}
+		l, err := e.dw.goroutineLayout()
+		if err != nil {
+			return err
+		}
+		allgs, waitReasons = l.allgs, l.waitReasons
+		fields = map[string]uint64{
+			"goid":         l.goid.off,
+			"atomicstatus": l.status.off,
+			"waitreason":   l.waitReason.off,
+			"sched.pc":     l.schedPC.off,
+			"gopc":         l.goPC.off,
+		}
+		return nil
+	})
+	return allgs, waitReasons, fields, err
+}
@@ -0,0 +1,286 @@
+package debugger
+
+import (
+	"debug/dwarf"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// maxGoroutines caps how many goroutines one enumeration reads. A slice
+// header read from the wrong address can claim any length, and a program
+// with more goroutines than this is better served by a filter than a list.
+const maxGoroutines = 1 << 16
+
+// maxWaitReasonLen caps the read of one wait reason name; the runtime's
+// longest is under 40 bytes.
+const maxWaitReasonLen = 64
+
+// The runtime's goroutine status values (runtime2.go). gScan is or'ed in
+// while the GC scans a goroutine's stack; it says nothing about what the
+// goroutine itself is doing.
+const (
+	gIdle      = 0
+	gRunnable  = 1
+	gRunning   = 2
+	gSyscall   = 3
+	gWaiting   = 4
+	gDead      = 6
+	gCopystack = 8
+	gPreempted = 9
+	gScan      = 0x1000
+)
+
+// goroutineStatus maps a runtime status to the protocol's name for it.
+func goroutineStatus(status uint64) string {
+	switch status {
+	case gRunnable, gPreempted:
+		return "runnable"
+	case gRunning:
+		return "running"
+	case gSyscall:
+		return "syscall"
+	case gWaiting, gCopystack:
+		return "waiting"
+	default:
+		return "unknown"
+	}
+}
+
+// gField is one field of runtime.g: its offset and size in bytes.
+type gField struct {
+	off, size uint64
+}
+
+// read decodes the field from a copy of the g that starts at its offset 0.
+func (f gField) read(g []byte) uint64 {
+	v, _ := littleEndianUint(g[f.off : f.off+f.size])
+	return v
+}
+
+// goroutineLayout is where a binary's runtime keeps its goroutines and how
+// its g struct is laid out. It all comes from the binary's own DWARF types
+// rather than a table per Go version, so it is exact for whichever
+// toolchain built the target.
+type goroutineLayout struct {
+	// allgs is runtime.allgs, the []*g of every goroutine ever created,
+	// at its DWARF (unslid) address.
+	allgs uint64
+
+	// waitReasons is runtime.waitReasonStrings, the runtime's own names for
+	// its waitReason codes, and nWaitReasons its length. waitReasons is zero
+	// when the linker left it out.
+	waitReasons  uint64
+	nWaitReasons uint64
+
+	goid, status, waitReason, schedPC, goPC gField
+
+	// size is how much of a g to read to cover every field above.
+	size uint64
+}
+
+// goroutineLayout finds the runtime's goroutine bookkeeping in the DWARF,
+// once per binary.
+func (r *dwarfReader) goroutineLayout() (*goroutineLayout, error) {
+	r.gLayoutOnce.Do(func() { r.gLayout, r.gLayoutErr = r.loadGoroutineLayout() })
+	return r.gLayout, r.gLayoutErr
+}
+
+func (r *dwarfReader) loadGoroutineLayout() (*goroutineLayout, error) {
+	l := &goroutineLayout{}
+	var g *dwarf.StructType
+	rd := r.data.Reader()
+	for l.allgs == 0 || l.waitReasons == 0 || g == nil {
+		entry, err := rd.Next()
+		if err != nil {
+			return nil, fmt.Errorf("DWARF goroutine layout: %w", err)
+		}
+		if entry == nil {
+			break
+		}
+		name, _ := entry.Val(dwarf.AttrName).(string)
+		switch {
+		case entry.Tag == dwarf.TagVariable && name == "runtime.allgs":
+			l.allgs = staticAddr(entry)
+		case entry.Tag == dwarf.TagVariable && name == "runtime.waitReasonStrings":
+			if off, ok := entry.Val(dwarf.AttrType).(dwarf.Offset); ok {
+				if t, err := r.data.Type(off); err == nil {
+					if at, ok := t.(*dwarf.ArrayType); ok && at.Count > 0 {
+						l.waitReasons = staticAddr(entry)
+						l.nWaitReasons = uint64(at.Count)
+					}
+				}
+			}
+		case entry.Tag == dwarf.TagStructType && name == "runtime.g" && g == nil:
+			if t, err := r.data.Type(entry.Offset); err == nil {
+				g, _ = t.(*dwarf.StructType)
+			}
+		}
+		if entry.Children && entry.Tag != dwarf.TagCompileUnit {
+			rd.SkipChildren()
+		}
+	}
+	if l.allgs == 0 {
+		return nil, fmt.Errorf("no runtime.allgs in DWARF")
+	}
+	if g == nil {
+		return nil, fmt.Errorf("no runtime.g type in DWARF")
+	}
+
+	var err error
+	field := func(st *dwarf.StructType, name string) gField {
+		if err != nil {
+			return gField{}
+		}
+		for _, f := range st.Field {
+			if f.Name == name {
+				return gField{off: uint64(f.ByteOffset), size: uint64(f.Type.Size())}
+			}
+		}
+		err = fmt.Errorf("runtime.g has no %s field", name)
+		return gField{}
+	}
+	l.goid = field(g, "goid")
+	l.status = field(g, "atomicstatus")
+	l.waitReason = field(g, "waitreason")
+	l.goPC = field(g, "gopc")
+	sched := field(g, "sched")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range g.Field {
+		if f.Name != "sched" {
+			continue
+		}
+		t := f.Type
+		if td, ok := t.(*dwarf.TypedefType); ok {
+			t = td.Type
+		}
+		if gobuf, ok := t.(*dwarf.StructType); ok {
+			pc := field(gobuf, "pc")
+			l.schedPC = gField{off: sched.off + pc.off, size: pc.size}
+		} else {
+			err = fmt.Errorf("runtime.g.sched is a %s, not a struct", f.Type)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range []gField{l.goid, l.status, l.waitReason, l.schedPC, l.goPC} {
+		if f.size == 0 || f.size > 8 {
+			return nil, fmt.Errorf("runtime.g field at offset %d is %d bytes, want 1..8", f.off, f.size)
+		}
+		l.size = max(l.size, f.off+f.size)
+	}
+	return l, nil
+}
+
+// staticAddr returns a global variable's DWARF address, or 0 when its
+// location is not a plain DW_OP_addr.
+func staticAddr(entry *dwarf.Entry) uint64 {
+	expr, ok := entry.Val(dwarf.AttrLocation).([]byte)
+	if !ok || len(expr) != 9 || expr[0] != 0x03 {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(expr[1:])
+}
+
+// liveGoroutine is a goroutine read from its g, with the g's address so the
+// caller can tell which one a thread is running.
+type liveGoroutine struct {
+	addr uint64
+	g    protocol.Goroutine
+}
+
+// readAllGoroutines walks runtime.allgs and reads every goroutine that is
+// not dead. Locations come from each g's saved scheduling PC, which is where
+// a parked goroutine will resume; for one running on some thread it is
+// stale.
+func (r *dwarfReader) readAllGoroutines(b Backend, l *goroutineLayout) ([]liveGoroutine, error) {
+	allgs := uint64(int64(l.allgs) + r.slide)
+	ptr, err := readWord(b, allgs)
+	if err != nil {
+		return nil, fmt.Errorf("read runtime.allgs: %w", err)
+	}
+	n, err := readWord(b, allgs+ptrSize)
+	if err != nil {
+		return nil, fmt.Errorf("read runtime.allgs length: %w", err)
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	if ptr == 0 {
+		return nil, fmt.Errorf("runtime.allgs: nil data with length %d", n)
+	}
+	n = min(n, maxGoroutines)
+	ptrs := make([]byte, n*ptrSize)
+	if err := b.ReadMemory(ptr, ptrs); err != nil {
+		return nil, fmt.Errorf("read runtime.allgs data at 0x%x: %w", ptr, err)
+	}
+	reasons := map[uint64]string{}
+	var out []liveGoroutine
+	for i := uint64(0); i < n; i++ {
+		gp := binary.LittleEndian.Uint64(ptrs[i*ptrSize:])
+		if gp == 0 {
+			continue
+		}
+		g, live, err := r.readG(b, l, gp, reasons)
+		if err != nil {
+			return nil, err
+		}
+		if live {
+			out = append(out, liveGoroutine{addr: gp, g: g})
+		}
+	}
+	return out, nil
+}
+
+// readG reads the goroutine whose g is at gp. live is false for one that
+// is dead or not yet set up: allgs keeps those for reuse. reasons caches
+// wait reason names across one enumeration; nil disables it.
+func (r *dwarfReader) readG(b Backend, l *goroutineLayout, gp uint64, reasons map[uint64]string) (g protocol.Goroutine, live bool, err error) {
+	buf := make([]byte, l.size)
+	if err := b.ReadMemory(gp, buf); err != nil {
+		return g, false, fmt.Errorf("read g at 0x%x: %w", gp, err)
+	}
+	status := l.status.read(buf) &^ gScan
+	if status == gIdle || status == gDead {
+		return g, false, nil
+	}
+	g = protocol.Goroutine{
+		ID:         int(l.goid.read(buf)),
+		Status:     goroutineStatus(status),
+		CurrentLoc: r.locationForPC(l.schedPC.read(buf)),
+		GoLoc:      r.locationForPC(l.goPC.read(buf)),
+	}
+	if status == gWaiting {
+		g.WaitReason = r.waitReasonName(b, l, l.waitReason.read(buf), reasons)
+	}
+	return g, true, nil
+}
+
+// waitReasonName names a waitReason code with the runtime's own string for
+// it, so the names match what the target's Go version prints in a
+// traceback. Code 0 means no reason was recorded and names nothing. A code
+// past the table, or a table that can't be read, is still reported, by
+// number.
+func (r *dwarfReader) waitReasonName(b Backend, l *goroutineLayout, code uint64, reasons map[uint64]string) string {
+	if code == 0 {
+		return ""
+	}
+	if name, ok := reasons[code]; ok {
+		return name
+	}
+	name := fmt.Sprintf("unknown wait reason %d", code)
+	if l.waitReasons != 0 && code < l.nWaitReasons {
+		addr := uint64(int64(l.waitReasons)+r.slide) + code*2*ptrSize
+		if s, _, err := readGoString(b, addr, maxWaitReasonLen); err == nil && s != "" {
+			name = s
+		}
+	}
+	if reasons != nil {
+		reasons[code] = name
+	}
+	return name
+}
@@ -256,6 +256,7 @@ static inline kern_return_t bingo_set_single_step(mach_port_t thread, int on) {
 // thread-directed SIGURG reaches the exact M it targeted — this is what lets us
 // re-enable async preemption in the tracee (the #92 fix). See AGENTS.md.
 
+#include <fcntl.h>
 #include <spawn.h>
 #include <mach/notify.h>
 #include <mach/mig_errors.h>
@@ -265,18 +266,28 @@ extern char **environ;
 // bingo_posix_spawn launches path with POSIX_SPAWN_START_SUSPENDED: the child is
 // created and its image mapped, but left Mach-suspended at its entry point
 // (before dyld runs any user code) so we win the race to attach the exception
-// port. fds and cwd are inherited from the parent (matching the previous
-// exec.Command default). Returns 0 on success (pid in *pid_out) or the errno
-// posix_spawn reports.
+// port. fds and cwd are inherited from the parent, except that with
+// null_stdin set fd 0 is /dev/null instead. Returns 0 on success (pid in
+// *pid_out) or the errno posix_spawn reports.
 static inline int bingo_posix_spawn(
-    const char *path, char *const argv[], char *const envp[], int *pid_out)
+    const char *path, char *const argv[], char *const envp[], int null_stdin,
+    int *pid_out)
 {
     posix_spawnattr_t attr;
     if (posix_spawnattr_init(&attr) != 0) return -1;
     posix_spawnattr_setflags(&attr, POSIX_SPAWN_START_SUSPENDED);
+    posix_spawn_file_actions_t actions;
+    if (posix_spawn_file_actions_init(&actions) != 0) {
+        posix_spawnattr_destroy(&attr);
+        return -1;
+    }
+    if (null_stdin) {
+        posix_spawn_file_actions_addopen(&actions, 0, "/dev/null", O_RDONLY, 0);
+    }
     pid_t pid = 0;
-    int rc = posix_spawn(&pid, path, NULL, &attr, argv,
+    int rc = posix_spawn(&pid, path, &actions, &attr, argv,
                          envp ? envp : environ);
+    posix_spawn_file_actions_destroy(&actions);
     posix_spawnattr_destroy(&attr);
     if (rc != 0) return rc;
     *pid_out = (int)pid;
@@ -0,0 +1,112 @@
+package debugger
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// maxStringRead caps how many bytes ReadString copies out of the tracee. A
+// header read from the wrong address can claim any length, and one command
+// must not turn into a multi-gigabyte read.
+const maxStringRead = 64 << 10
+
+// defaultMaxSliceElements is Options.MaxSliceElements when left at zero.
+const defaultMaxSliceElements = 256
+
+// maxSliceElemSize bounds ReadSlice's element size. Together with the
+// element cap it bounds a single read; no inspectable Go element type a user
+// would dump as raw bytes comes near it.
+const maxSliceElemSize = 4096
+
+// ptrSize is the word size of every supported target (amd64, arm64): both are
+// 64-bit little-endian, which is what readWord assumes.
+const ptrSize = 8
+
+func readWord(b Backend, addr uint64) (uint64, error) {
+	var buf [ptrSize]byte
+	if err := b.ReadMemory(addr, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+// readGoString decodes the string header (data pointer, length) at addr and
+// reads at most limit bytes of its data. n is the string's full length, so
+// the caller can tell a truncated value from a short one.
+func readGoString(b Backend, addr, limit uint64) (s string, n uint64, err error) {
+	ptr, err := readWord(b, addr)
+	if err != nil {
+		return "", 0, fmt.Errorf("read string header at 0x%x: %w", addr, err)
+	}
+	n, err = readWord(b, addr+ptrSize)
+	if err != nil {
+		return "", 0, fmt.Errorf("read string length at 0x%x: %w", addr+ptrSize, err)
+	}
+	s, err = readStringData(b, ptr, n, limit)
+	if err != nil {
+		return "", n, fmt.Errorf("string at 0x%x: %w", addr, err)
+	}
+	return s, n, nil
+}
+
+// readStringData reads at most limit bytes of the string whose header is
+// (ptr, n), wherever that header was found: in memory, or in the registers
+// an argument was passed in.
+func readStringData(b Backend, ptr, n, limit uint64) (string, error) {
+	if n == 0 {
+		// The empty string; its data pointer may be anything, nil included.
+		return "", nil
+	}
+	if ptr == 0 {
+		return "", fmt.Errorf("nil data with length %d — not a string header?", n)
+	}
+	buf := make([]byte, min(n, limit))
+	if err := b.ReadMemory(ptr, buf); err != nil {
+		return "", fmt.Errorf("read string data at 0x%x: %w", ptr, err)
+	}
+	return string(buf), nil
+}
+
+// readGoSlice decodes the slice header (data pointer, len, cap) at addr and
+// reads up to limit elements of elemSize bytes each, hex-encoded in memory
+// order. A nil slice (nil data, zero len) comes back with no elements.
+func readGoSlice(b Backend, addr uint64, elemSize, limit int) (protocol.SliceValuePayload, error) {
+	p := protocol.SliceValuePayload{Addr: addr, ElemSize: elemSize}
+	if elemSize <= 0 || elemSize > maxSliceElemSize {
+		return p, fmt.Errorf("element size %d out of range 1..%d", elemSize, maxSliceElemSize)
+	}
+	var hdr [3]uint64
+	for i := range hdr {
+		w, err := readWord(b, addr+uint64(i)*ptrSize)
+		if err != nil {
+			return p, fmt.Errorf("read slice header at 0x%x: %w", addr, err)
+		}
+		hdr[i] = w
+	}
+	p.Data, p.Len, p.Cap = hdr[0], hdr[1], hdr[2]
+	if p.Len > p.Cap {
+		return p, fmt.Errorf("slice at 0x%x: len %d exceeds cap %d — not a slice header?", addr, p.Len, p.Cap)
+	}
+	if p.Len == 0 {
+		p.Elements = []string{}
+		return p, nil
+	}
+	if p.Data == 0 {
+		return p, fmt.Errorf("slice at 0x%x: nil data with len %d — not a slice header?", addr, p.Len)
+	}
+
+	n := min(p.Len, uint64(limit))
+	buf := make([]byte, n*uint64(elemSize))
+	if err := b.ReadMemory(p.Data, buf); err != nil {
+		return p, fmt.Errorf("read slice data at 0x%x: %w", p.Data, err)
+	}
+	p.Elements = make([]string, n)
+	for i := range p.Elements {
+		p.Elements[i] = hex.EncodeToString(buf[i*elemSize : (i+1)*elemSize])
+	}
+	p.Truncated = n < p.Len
+	return p, nil
+}
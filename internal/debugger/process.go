@@ -22,6 +22,9 @@ func (p *process) launch(b Backend, binaryPath string, args []string, env []stri
 	if _, err := os.Stat(binaryPath); err != nil {
 		return fmt.Errorf("launch: %w", err)
 	}
+	if err := checkGoBinary(binaryPath); err != nil {
+		return fmt.Errorf("launch: %w", err)
+	}
 
 	pid, cmd, err := startTracedProcess(b, binaryPath, args, env)
 	if err != nil {
@@ -0,0 +1,86 @@
+package debugger
+
+import (
+	"bufio"
+	"debug/elf"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// elfSlide returns how far the running process pid loaded the ELF binary at
+// binaryPath from the addresses it was linked at, read from
+// /proc/<pid>/maps. A fixed-address (ET_EXEC) binary is never moved, so it
+// answers 0 without looking at the process; a PIE one is wherever the
+// kernel put it.
+func elfSlide(pid int, binaryPath string) (int64, error) {
+	f, err := elf.Open(binaryPath)
+	if err != nil {
+		return 0, fmt.Errorf("elf.Open: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if f.Type != elf.ET_DYN {
+		return 0, nil
+	}
+
+	// The segment that maps file offset 0 holds the ELF header; its
+	// mapping's start is where the link-time address of that segment
+	// ended up.
+	var linked uint64
+	found := false
+	for _, p := range f.Progs {
+		if p.Type == elf.PT_LOAD && p.Off == 0 {
+			linked, found = p.Vaddr, true
+			break
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("%s: no PT_LOAD segment at offset 0", binaryPath)
+	}
+
+	base, err := loadBase(pid)
+	if err != nil {
+		return 0, err
+	}
+	return int64(base) - int64(linked&^(pageSize-1)), nil
+}
+
+// pageSize is the mapping granularity /proc/<pid>/maps reports in.
+const pageSize = 0x1000
+
+// loadBase returns the lowest address at which pid has its own executable
+// (/proc/<pid>/exe) mapped from file offset 0.
+func loadBase(pid int) (uint64, error) {
+	exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if err != nil {
+		return 0, fmt.Errorf("load base of pid %d: %w", pid, err)
+	}
+	maps, err := os.Open(fmt.Sprintf("/proc/%d/maps", pid))
+	if err != nil {
+		return 0, fmt.Errorf("load base of pid %d: %w", pid, err)
+	}
+	defer func() { _ = maps.Close() }()
+
+	// address perms offset dev inode pathname
+	sc := bufio.NewScanner(maps)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 6 || strings.Join(fields[5:], " ") != exe {
+			continue
+		}
+		if off, err := strconv.ParseUint(fields[2], 16, 64); err != nil || off != 0 {
+			continue
+		}
+		start, _, _ := strings.Cut(fields[0], "-")
+		base, err := strconv.ParseUint(start, 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("load base of pid %d: bad maps line %q", pid, sc.Text())
+		}
+		return base, nil
+	}
+	if err := sc.Err(); err != nil {
+		return 0, fmt.Errorf("load base of pid %d: %w", pid, err)
+	}
+	return 0, fmt.Errorf("load base of pid %d: %s is not mapped", pid, exe)
+}
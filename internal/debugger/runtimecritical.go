@@ -0,0 +1,136 @@
+package debugger
+
+import "strings"
+
+// Stopping a thread inside these runtime functions can wedge the whole
+// program, not just the goroutine: the thread may hold a runtime lock, be on
+// the system stack, or be in the middle of a GC phase every other thread is
+// waiting on. The lists are a heuristic on the function name, not an
+// exhaustive audit — they cover what a user is likely to hit by setting a
+// breakpoint on a runtime source line, and nothing outside runtime is
+// flagged.
+
+// runtimeCriticalFuncs are exact function names, mapped to the subsystem
+// they belong to.
+var runtimeCriticalFuncs = map[string]string{
+	// Scheduler.
+	"runtime.schedule":       "scheduler",
+	"runtime.findRunnable":   "scheduler",
+	"runtime.findrunnable":   "scheduler",
+	"runtime.execute":        "scheduler",
+	"runtime.gogo":           "scheduler",
+	"runtime.mcall":          "scheduler",
+	"runtime.systemstack":    "scheduler",
+	"runtime.gopark":         "scheduler",
+	"runtime.park_m":         "scheduler",
+	"runtime.goready":        "scheduler",
+	"runtime.ready":          "scheduler",
+	"runtime.goschedImpl":    "scheduler",
+	"runtime.gosched_m":      "scheduler",
+	"runtime.goexit0":        "scheduler",
+	"runtime.casgstatus":     "scheduler",
+	"runtime.mstart":         "scheduler",
+	"runtime.mstart0":        "scheduler",
+	"runtime.mstart1":        "scheduler",
+	"runtime.newproc1":       "scheduler",
+	"runtime.retake":         "scheduler",
+	"runtime.sysmon":         "scheduler",
+	"runtime.entersyscall":   "scheduler",
+	"runtime.exitsyscall":    "scheduler",
+	"runtime.reentersyscall": "scheduler",
+	"runtime.handoffp":       "scheduler",
+	"runtime.startm":         "scheduler",
+	"runtime.stopm":          "scheduler",
+	"runtime.wakep":          "scheduler",
+	"runtime.rt0_go":         "scheduler",
+
+	// Memory allocator.
+	"runtime.mallocgc":        "memory allocator",
+	"runtime.newobject":       "memory allocator",
+	"runtime.makeslice":       "memory allocator",
+	"runtime.growslice":       "memory allocator",
+	"runtime.persistentalloc": "memory allocator",
+
+	// Locks and low-level synchronisation.
+	"runtime.lock":           "runtime lock",
+	"runtime.lock2":          "runtime lock",
+	"runtime.lockWithRank":   "runtime lock",
+	"runtime.unlock":         "runtime lock",
+	"runtime.unlock2":        "runtime lock",
+	"runtime.unlockWithRank": "runtime lock",
+	"runtime.futex":          "runtime lock",
+	"runtime.futexsleep":     "runtime lock",
+	"runtime.futexwakeup":    "runtime lock",
+	"runtime.notesleep":      "runtime lock",
+	"runtime.notetsleep":     "runtime lock",
+	"runtime.notetsleepg":    "runtime lock",
+	"runtime.notewakeup":     "runtime lock",
+	"runtime.semacquire1":    "runtime lock",
+	"runtime.semrelease1":    "runtime lock",
+
+	// Stack growth.
+	"runtime.morestack":        "stack growth",
+	"runtime.morestack_noctxt": "stack growth",
+	"runtime.newstack":         "stack growth",
+	"runtime.copystack":        "stack growth",
+	"runtime.shrinkstack":      "stack growth",
+
+	// Preemption.
+	"runtime.asyncPreempt":  "preemption",
+	"runtime.asyncPreempt2": "preemption",
+	"runtime.preemptM":      "preemption",
+	"runtime.suspendG":      "preemption",
+	"runtime.resumeG":       "preemption",
+}
+
+// runtimeCriticalPrefixes catch whole families by name prefix: GC phases and
+// the allocator's internal types, signal handling, and stop-the-world.
+var runtimeCriticalPrefixes = []struct {
+	prefix, subsystem string
+}{
+	{"runtime.gc", "garbage collector"},
+	{"runtime.mark", "garbage collector"},
+	{"runtime.sweep", "garbage collector"},
+	{"runtime.bgsweep", "garbage collector"},
+	{"runtime.bgscavenge", "garbage collector"},
+	{"runtime.scanobject", "garbage collector"},
+	{"runtime.scanstack", "garbage collector"},
+	{"runtime.greyobject", "garbage collector"},
+	{"runtime.(*gcWork)", "garbage collector"},
+	{"runtime.(*gcControllerState)", "garbage collector"},
+	{"runtime.(*mheap)", "memory allocator"},
+	{"runtime.(*mcache)", "memory allocator"},
+	{"runtime.(*mcentral)", "memory allocator"},
+	{"runtime.(*mspan)", "memory allocator"},
+	{"runtime.(*pageAlloc)", "memory allocator"},
+	{"runtime.(*fixalloc)", "memory allocator"},
+	{"runtime.sig", "signal handling"},
+	{"runtime.stopTheWorld", "stop-the-world"},
+	{"runtime.startTheWorld", "stop-the-world"},
+	{"runtime.forEachP", "stop-the-world"},
+	{"internal/runtime/", "runtime internals"},
+}
+
+// runtimeCritical reports whether fn, a DWARF function name, is one where a
+// breakpoint risks deadlocking the program, and which runtime subsystem it
+// belongs to.
+func runtimeCritical(fn string) (subsystem string, ok bool) {
+	if !strings.HasPrefix(fn, "runtime.") && !strings.HasPrefix(fn, "internal/runtime/") {
+		return "", false
+	}
+	// Assembly functions carry an ABI suffix and closures a .funcN one;
+	// both are as dangerous as the function they belong to.
+	base := strings.TrimSuffix(fn, ".abi0")
+	if i := strings.Index(base, ".func"); i > 0 {
+		base = base[:i]
+	}
+	if s, ok := runtimeCriticalFuncs[base]; ok {
+		return s, true
+	}
+	for _, p := range runtimeCriticalPrefixes {
+		if strings.HasPrefix(fn, p.prefix) {
+			return p.subsystem, true
+		}
+	}
+	return "", false
+}
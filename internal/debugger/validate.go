@@ -0,0 +1,149 @@
+package debugger
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"debug/macho"
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// ValidationReport describes whether a binary is debuggable, gathered without
+// launching it. It answers the usual "why don't my breakpoints work" questions
+// up front: missing DWARF (built with -ldflags=-w), no main.main, and so on.
+type ValidationReport struct {
+	Path         string   `json:"path"`
+	Format       string   `json:"format"` // "elf" | "macho"
+	Arch         string   `json:"arch"`
+	PIE          bool     `json:"pie"`
+	GoVersion    string   `json:"goVersion,omitempty"`
+	HasDebugInfo bool     `json:"hasDebugInfo"`
+	HasMainMain  bool     `json:"hasMainMain"`
+	SourceFiles  int      `json:"sourceFiles"`
+	Problems     []string `json:"problems,omitempty"`
+}
+
+// OK reports whether no problems were found.
+func (r ValidationReport) OK() bool { return len(r.Problems) == 0 }
+
+// Validate inspects the binary at path and reports how well bingo can debug
+// it. Only failure to open the file at all is an error; everything else is
+// recorded in Problems so the caller gets the full picture in one pass.
+func Validate(path string) (ValidationReport, error) {
+	report := ValidationReport{Path: path}
+
+	if err := readObjectHeader(path, &report); err != nil {
+		return report, fmt.Errorf("validate %q: %w", path, err)
+	}
+
+	if bi, err := buildinfo.ReadFile(path); err != nil {
+		report.Problems = append(report.Problems, fmt.Sprintf("no Go build info: %v", err))
+	} else {
+		report.GoVersion = bi.GoVersion
+	}
+
+	dr, err := openDWARF(path)
+	if err != nil {
+		report.Problems = append(report.Problems,
+			fmt.Sprintf("no DWARF debug info (was it built with -ldflags=-w?): %v", err))
+		return report, nil
+	}
+	report.HasDebugInfo = true
+	report.HasMainMain = dr.hasFunction("main.main")
+	if !report.HasMainMain {
+		report.Problems = append(report.Problems, "main.main not found in DWARF")
+	}
+	report.SourceFiles = len(dr.sourceFiles())
+	if report.SourceFiles == 0 {
+		report.Problems = append(report.Problems, "DWARF line tables map no source files")
+	}
+	return report, nil
+}
+
+// checkGoBinary rejects a target with no Go build info before anything is
+// started. bingo leans on Go runtime layout (main.main, the g struct,
+// goroutine lists), so a C binary or shell script would otherwise get as far
+// as a breakpoint or goroutine read and fail there with a far less obvious
+// error.
+func checkGoBinary(path string) error {
+	if _, err := buildinfo.ReadFile(path); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrNotGoBinary, path, err)
+	}
+	return nil
+}
+
+// readObjectHeader fills in the object-format fields for the host's native
+// format, matching what loadDWARFData will open.
+func readObjectHeader(path string, report *ValidationReport) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	switch runtime.GOOS {
+	case "linux":
+		f, err := elf.Open(path)
+		if err != nil {
+			return fmt.Errorf("not an ELF binary: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		report.Format = "elf"
+		report.Arch = f.Machine.String()
+		report.PIE = f.Type == elf.ET_DYN
+
+	case "darwin":
+		f, err := macho.Open(path)
+		if err != nil {
+			return fmt.Errorf("not a Mach-O binary: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		report.Format = "macho"
+		report.Arch = f.Cpu.String()
+		report.PIE = f.Flags&macho.FlagPIE != 0
+
+	default:
+		return fmt.Errorf("unsupported OS: %s", runtime.GOOS)
+	}
+	return nil
+}
+
+// imageLayout is where a binary sits in its process: whether it is
+// position-independent, and the address its first segment is mapped at.
+type imageLayout struct {
+	pie  bool
+	base uint64
+}
+
+// loadedImage reads the layout of the binary at path from its header, with
+// base moved by slide, how far the process loaded it from its link-time
+// addresses. It is the zero layout when the header can't be read.
+func loadedImage(path string, slide int64) imageLayout {
+	var l imageLayout
+	switch runtime.GOOS {
+	case "linux":
+		f, err := elf.Open(path)
+		if err != nil {
+			return imageLayout{}
+		}
+		defer func() { _ = f.Close() }()
+		l.pie = f.Type == elf.ET_DYN
+		for _, p := range f.Progs {
+			if p.Type == elf.PT_LOAD && p.Off == 0 {
+				l.base = p.Vaddr &^ (pageSize - 1)
+				break
+			}
+		}
+
+	case "darwin":
+		f, err := macho.Open(path)
+		if err != nil {
+			return imageLayout{}
+		}
+		defer func() { _ = f.Close() }()
+		l.pie = f.Flags&macho.FlagPIE != 0
+		if seg := f.Segment("__TEXT"); seg != nil {
+			l.base = seg.Addr
+		}
+	}
+	l.base = uint64(int64(l.base) + slide)
+	return l
+}
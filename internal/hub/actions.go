@@ -0,0 +1,102 @@
+package hub
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// Breakpoint actions (gdb's breakpoint commands) run on the Run goroutine
+// while the engine is still parked at the hit, so they can inspect it with
+// the ordinary Debugger calls before anyone else sees the stop.
+const (
+	actionPrint    = "print"
+	actionContinue = "continue"
+)
+
+// validateActions checks a SetBreakpoint action list before the breakpoint is
+// installed, so a typo fails the command instead of every hit.
+func validateActions(actions []string) error {
+	for i, a := range actions {
+		f := strings.Fields(a)
+		switch {
+		case len(f) == 2 && f[0] == actionPrint:
+		case len(f) == 1 && f[0] == actionContinue:
+			if i != len(actions)-1 {
+				return fmt.Errorf("action %q: continue must be the last action", a)
+			}
+		default:
+			return fmt.Errorf("action %q: want \"print <name>\" or \"continue\"", a)
+		}
+	}
+	return nil
+}
+
+// runBreakpointActions runs the actions attached to the breakpoint in hit, if
+// any, and broadcasts their output. It reports whether they resumed the
+// process, in which case the hit must be neither broadcast nor treated as a
+// stop: to clients the breakpoint was a logpoint.
+func (h *Hub) runBreakpointActions(evt protocol.Event) bool {
+	var hit protocol.BreakpointHitPayload
+	if protocol.DecodeEventPayload(evt, &hit) != nil {
+		return false
+	}
+	actions := h.breakpointActions(hit.Breakpoint.ID)
+	if len(actions) == 0 {
+		return false
+	}
+
+	var (
+		output    []string
+		locals    []protocol.Variable
+		localsErr error
+		loaded    bool
+		resume    bool
+	)
+	for _, a := range actions {
+		f := strings.Fields(a)
+		switch f[0] {
+		case actionPrint:
+			if !loaded {
+				locals, localsErr = h.dbg.Locals(0)
+				loaded = true
+			}
+			output = append(output, printLocal(f[1], locals, localsErr))
+		case actionContinue:
+			resume = true
+		}
+	}
+	if resume {
+		if err := h.dbg.Continue(); err != nil {
+			output = append(output, fmt.Sprintf("continue: %v", err))
+			resume = false
+		}
+	}
+
+	bp := hit.Breakpoint
+	bp.Actions = actions
+	out, err := protocol.NewEvent(protocol.EventBreakpointActions, 0, protocol.BreakpointActionsPayload{
+		Breakpoint: bp,
+		Output:     output,
+		Resumed:    resume,
+	})
+	if err != nil {
+		h.log.Error("failed to create BreakpointActions event", "err", err)
+		return resume
+	}
+	h.broadcast(out)
+	return resume
+}
+
+func printLocal(name string, locals []protocol.Variable, err error) string {
+	if err != nil {
+		return fmt.Sprintf("print %s: %v", name, err)
+	}
+	for _, v := range locals {
+		if v.Name == name {
+			return fmt.Sprintf("%s = %s", v.Name, v.Value)
+		}
+	}
+	return fmt.Sprintf("print %s: no such local", name)
+}
@@ -0,0 +1,89 @@
+package hub
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// BenchmarkBroadcast measures the cost of one broadcast to n clients: stamp,
+// marshal, and a non-blocking queue per client. The queues are emptied with
+// the timer stopped before they fill, standing in for writePumps that keep
+// up, so the numbers are the hub's side of the fan-out alone and no client
+// is ever evicted as slow.
+//
+// The event is marshalled once per broadcast and every writePump writes the
+// same bytes, so a large payload (a goroutine snapshot of a busy program)
+// should cost its size once, not once per client: B/op stays flat as the
+// client count grows.
+func BenchmarkBroadcast(b *testing.B) {
+	payloads := []struct {
+		name string
+		evt  protocol.Event
+	}{
+		{"stepped", protocol.MustEvent(protocol.EventStepped, 0, protocol.SteppedPayload{
+			Location: protocol.Location{File: "main.go", Line: 42, Function: "main.main"},
+		})},
+		{"snapshot", protocol.MustEvent(protocol.EventGoroutineSnapshot, 0, benchSnapshot(2000))},
+	}
+	for _, p := range payloads {
+		for _, n := range []int{1, 10, 100, 1000} {
+			b.Run(fmt.Sprintf("%s/clients=%d", p.name, n), func(b *testing.B) {
+				benchmarkBroadcast(b, p.evt, n)
+			})
+		}
+	}
+}
+
+func benchmarkBroadcast(b *testing.B, evt protocol.Event, n int) {
+	h := newHub(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	clients := make([]*Client, n)
+	for i := range clients {
+		clients[i] = newClient(nil, h, h.log)
+		h.registry.add(clients[i])
+	}
+	drain := func() {
+		for _, c := range clients {
+			for len(c.send) > 0 {
+				<-c.send
+			}
+		}
+	}
+	batch := cap(clients[0].send)
+
+	b.SetBytes(int64(len(evt.Payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		if i > 0 && i%batch == 0 {
+			b.StopTimer()
+			drain()
+			b.StartTimer()
+		}
+		h.broadcast(evt)
+	}
+	b.StopTimer()
+
+	if got := h.registry.count(); got != n {
+		b.Fatalf("%d of %d clients were evicted", n-got, n)
+	}
+	h.registry.closeAll()
+}
+
+// benchSnapshot is a goroutine snapshot of n parked goroutines.
+func benchSnapshot(n int) protocol.GoroutineSnapshotPayload {
+	p := protocol.GoroutineSnapshotPayload{Trigger: protocol.EventBreakpointHit}
+	for i := range n {
+		p.Goroutines = append(p.Goroutines, protocol.Goroutine{
+			ID:         i + 1,
+			Status:     "waiting",
+			CurrentLoc: protocol.Location{File: "/usr/local/go/src/runtime/proc.go", Line: 435, Function: "runtime.gopark"},
+			GoLoc:      protocol.Location{File: "/src/app/worker.go", Line: 88, Function: "main.startWorkers"},
+			WaitReason: "chan receive",
+		})
+	}
+	return p
+}
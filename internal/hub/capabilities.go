@@ -0,0 +1,131 @@
+package hub
+
+import (
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// supportedCommands is every command kind the hub acts on, in protocol order.
+// A kind missing here is answered with "unknown command" by dispatch.
+var supportedCommands = []protocol.CommandKind{
+	protocol.CmdLaunch,
+	protocol.CmdAttach,
+	protocol.CmdKill,
+	protocol.CmdSetBreakpoint,
+	protocol.CmdSetBreakpoints,
+	protocol.CmdClearBreakpoint,
+	protocol.CmdClearAllBreakpoints,
+	protocol.CmdDisableAllBreakpoints,
+	protocol.CmdEnableAllBreakpoints,
+	protocol.CmdSetIgnoreCount,
+	protocol.CmdContinue,
+	protocol.CmdStepOver,
+	protocol.CmdStepInto,
+	protocol.CmdStepOut,
+	protocol.CmdStepInstruction,
+	protocol.CmdPause,
+	protocol.CmdLocals,
+	protocol.CmdInspectArgs,
+	protocol.CmdFrames,
+	protocol.CmdGoroutines,
+	protocol.CmdRestart,
+	protocol.CmdLogs,
+	protocol.CmdResolveLine,
+	protocol.CmdAddrToLine,
+	protocol.CmdReadString,
+	protocol.CmdReadSlice,
+	protocol.CmdInput,
+	protocol.CmdCapabilities,
+	protocol.CmdStatus,
+	protocol.CmdGetFile,
+	protocol.CmdEcho,
+	protocol.CmdSubscribe,
+}
+
+// liveCommands work on a launched process whether it runs or is stopped:
+// breakpoints are patched and DWARF is read without touching thread state.
+var liveCommands = []protocol.CommandKind{
+	protocol.CmdKill,
+	protocol.CmdSetBreakpoint,
+	protocol.CmdSetBreakpoints,
+	protocol.CmdClearBreakpoint,
+	protocol.CmdClearAllBreakpoints,
+	protocol.CmdDisableAllBreakpoints,
+	protocol.CmdEnableAllBreakpoints,
+	protocol.CmdSetIgnoreCount,
+	protocol.CmdResolveLine,
+	protocol.CmdAddrToLine,
+	protocol.CmdInput,
+	protocol.CmdGetFile,
+}
+
+// suspendedCommands additionally need the process stopped: they resume it
+// or read its registers and memory.
+var suspendedCommands = []protocol.CommandKind{
+	protocol.CmdContinue,
+	protocol.CmdStepOver,
+	protocol.CmdStepInto,
+	protocol.CmdStepOut,
+	protocol.CmdStepInstruction,
+	protocol.CmdLocals,
+	protocol.CmdInspectArgs,
+	protocol.CmdFrames,
+	protocol.CmdGoroutines,
+	protocol.CmdReadString,
+	protocol.CmdReadSlice,
+}
+
+// validCommands returns the commands that state lets through, in protocol
+// order. It mirrors the checks executeCommand and the engine make, so a UI
+// can grey out the rest; a command listed here can still fail for its own
+// reasons (a bad line, a process without a terminal). Run goroutine only:
+// it reads lastLaunch.
+func (h *Hub) validCommands(state protocol.SessionState) []protocol.CommandKind {
+	valid := map[protocol.CommandKind]bool{
+		protocol.CmdLogs:         true,
+		protocol.CmdCapabilities: true,
+		protocol.CmdStatus:       true,
+		protocol.CmdEcho:         true,
+		protocol.CmdSubscribe:    true,
+	}
+	add := func(kinds ...protocol.CommandKind) {
+		for _, k := range kinds {
+			valid[k] = true
+		}
+	}
+	switch state {
+	case protocol.StateIdle:
+		add(protocol.CmdLaunch, protocol.CmdAttach)
+	case protocol.StateRunning:
+		add(liveCommands...)
+		add(protocol.CmdPause)
+	case protocol.StateSuspended:
+		add(liveCommands...)
+		add(suspendedCommands...)
+	}
+	if h.lastLaunch != nil && state != protocol.StateIdle {
+		add(protocol.CmdRestart)
+	}
+
+	out := make([]protocol.CommandKind, 0, len(valid))
+	for _, k := range supportedCommands {
+		if valid[k] {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// handleCapabilities answers CmdCapabilities from the current state alone.
+func (h *Hub) handleCapabilities(cmd protocol.Command) {
+	state := h.State()
+	evt, err := protocol.NewEvent(protocol.EventCapabilities, 0, protocol.CapabilitiesPayload{
+		State:     state,
+		Supported: supportedCommands,
+		Valid:     h.validCommands(state),
+	})
+	if err != nil {
+		h.broadcastError(cmd.Kind, err)
+		return
+	}
+	h.broadcast(evt)
+}
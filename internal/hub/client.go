@@ -41,6 +41,15 @@ type Client struct {
 	hub  *Hub
 	log  *slog.Logger
 
+	// readOnly marks an observer (AddObserver). Set before the pumps start
+	// and never changed.
+	readOnly bool
+
+	// events is the set of event kinds broadcasts to this client are
+	// filtered to (CmdSubscribe); nil lets every kind through. Guarded by
+	// the hub's emitMu.
+	events map[protocol.EventKind]bool
+
 	// send is closed exactly once — by the registry on shutdown, or by
 	// deliver() on buffer overflow. sendMu guards close-vs-send races.
 	send   chan []byte
@@ -60,6 +69,12 @@ func newClient(conn WSConn, h *Hub, log *slog.Logger) *Client {
 	}
 }
 
+// wants reports whether a broadcast of kind goes to c. The caller holds the
+// hub's emitMu.
+func (c *Client) wants(kind protocol.EventKind) bool {
+	return c.events == nil || c.events[kind] || kind == protocol.EventError
+}
+
 func (c *Client) closeSend() {
 	c.sendMu.Lock()
 	defer c.sendMu.Unlock()
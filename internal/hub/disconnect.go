@@ -0,0 +1,63 @@
+package hub
+
+import (
+	"time"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// DisconnectAction is what a kept-alive session does once
+// Options.DisconnectGrace passes with no client connected.
+type DisconnectAction string
+
+const (
+	// DisconnectContinue resumes a process left suspended and keeps the
+	// session, so a client can still reconnect later.
+	DisconnectContinue DisconnectAction = "continue"
+	// DisconnectEnd shuts the session down and kills its process.
+	DisconnectEnd DisconnectAction = "end"
+)
+
+// disconnectWatch waits out grace after the session lost its last client,
+// then applies Options.DisconnectAction unless a client came back. orphan is
+// the h.orphaned count for this absence: a reconnect followed by another
+// disconnect starts a fresh watch, and this one must not act early for it.
+// Runs off the Run goroutine so it also fires while Run is in a suspended
+// wait.
+//
+// With DisconnectContinue it keeps watching for as long as nobody is
+// connected: a process still running when grace first ran out can stop at a
+// breakpoint later, and is resumed grace after that at the latest.
+func (h *Hub) disconnectWatch(orphan uint64, grace time.Duration) {
+	t := time.NewTimer(grace)
+	defer t.Stop()
+	for {
+		select {
+		case <-h.shutdownCh:
+			return
+		case <-t.C:
+		}
+		if h.orphaned.Load() != orphan || h.registry.count() > 0 {
+			return
+		}
+
+		if h.opts.DisconnectAction == DisconnectEnd {
+			h.log.Info("no client reconnected — ending session", "grace", grace)
+			h.shutdown()
+			return
+		}
+		if h.State() == protocol.StateSuspended {
+			h.log.Info("no client reconnected — resuming process", "grace", grace)
+			// Straight to resumeCh rather than through injectCommand, which
+			// would count this as client activity for the idle timeout.
+			select {
+			case h.resumeCh <- clientCommand{
+				cmd:      protocol.Command{Version: protocol.Version, Kind: protocol.CmdContinue},
+				received: time.Now(),
+			}:
+			default:
+			}
+		}
+		t.Reset(grace)
+	}
+}
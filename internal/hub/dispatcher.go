@@ -1,6 +1,7 @@
 package hub
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/bingosuite/bingo/internal/debugger"
@@ -26,6 +27,11 @@ func dispatch(dbg debugger.Debugger, cmd protocol.Command) (dispatchResult, erro
 		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
 			return dispatchResult{}, err
 		}
+		if p.InitialStop != "" {
+			if err := dbg.SetInitialStop(p.InitialStop); err != nil {
+				return dispatchResult{}, err
+			}
+		}
 		return dispatchResult{}, dbg.Launch(p.Program, p.Args, p.Env)
 
 	case protocol.CmdAttach:
@@ -43,12 +49,35 @@ func dispatch(dbg debugger.Debugger, cmd protocol.Command) (dispatchResult, erro
 		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
 			return dispatchResult{}, err
 		}
-		bp, err := dbg.SetBreakpoint(p.File, p.Line)
+		// Same path as one CmdSetBreakpoints entry; only the reporting
+		// differs, as an error or a single EventBreakpointSet.
+		res := setBreakpoint(dbg, p)
+		if res.Error != "" {
+			return dispatchResult{}, fmt.Errorf("set breakpoint: %s", res.Error)
+		}
+		evt, err := protocol.NewEvent(protocol.EventBreakpointSet, 0, protocol.BreakpointSetPayload{
+			Breakpoint: *res.Breakpoint,
+			AlreadySet: res.AlreadySet,
+		})
 		if err != nil {
 			return dispatchResult{}, err
 		}
-		evt, err := protocol.NewEvent(protocol.EventBreakpointSet, 0, protocol.BreakpointSetPayload{
-			Breakpoint: bp,
+		return dispatchResult{event: &evt}, nil
+
+	case protocol.CmdSetBreakpoints:
+		var p protocol.SetBreakpointsPayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return dispatchResult{}, err
+		}
+		if len(p.Breakpoints) == 0 {
+			return dispatchResult{}, fmt.Errorf("set breakpoints: no breakpoints given")
+		}
+		results := make([]protocol.BreakpointResult, len(p.Breakpoints))
+		for i, req := range p.Breakpoints {
+			results[i] = setBreakpoint(dbg, req)
+		}
+		evt, err := protocol.NewEvent(protocol.EventBreakpointsSet, 0, protocol.BreakpointsSetPayload{
+			Results: results,
 		})
 		if err != nil {
 			return dispatchResult{}, err
@@ -69,22 +98,97 @@ func dispatch(dbg debugger.Debugger, cmd protocol.Command) (dispatchResult, erro
 		}
 		return dispatchResult{event: &evt}, nil
 
+	case protocol.CmdSetIgnoreCount:
+		var p protocol.SetIgnoreCountPayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return dispatchResult{}, err
+		}
+		bp, err := dbg.SetIgnoreCount(p.ID, p.Count)
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		evt, err := protocol.NewEvent(protocol.EventIgnoreCountSet, 0, protocol.IgnoreCountSetPayload{Breakpoint: bp})
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		return dispatchResult{event: &evt}, nil
+
+	case protocol.CmdClearAllBreakpoints:
+		ids, err := dbg.ClearAllBreakpoints()
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		evt, err := protocol.NewEvent(protocol.EventBreakpointsCleared, 0, protocol.BreakpointsClearedPayload{
+			IDs:   ids,
+			Count: len(ids),
+		})
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		return dispatchResult{event: &evt}, nil
+
+	case protocol.CmdDisableAllBreakpoints, protocol.CmdEnableAllBreakpoints:
+		enabled := cmd.Kind == protocol.CmdEnableAllBreakpoints
+		toggle := dbg.DisableAllBreakpoints
+		if enabled {
+			toggle = dbg.EnableAllBreakpoints
+		}
+		bps, err := toggle()
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		evt, err := protocol.NewEvent(protocol.EventBreakpointsToggled, 0, protocol.BreakpointsToggledPayload{
+			Enabled:     enabled,
+			Breakpoints: bps,
+		})
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		return dispatchResult{event: &evt}, nil
+
 	// Execution control: no immediate event. The debugger emits Stepped /
 	// Continued asynchronously.
 	case protocol.CmdContinue:
-		return dispatchResult{}, dbg.Continue()
+		var p protocol.ContinuePayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return dispatchResult{}, fmt.Errorf("decode Continue payload: %w", err)
+		}
+		switch p.Scope {
+		case "", protocol.ScopeAll:
+			return dispatchResult{}, dbg.Continue()
+		case protocol.ScopeThread:
+			return dispatchResult{}, dbg.ContinueThread()
+		default:
+			return dispatchResult{}, fmt.Errorf("continue: unknown scope %q, want %q or %q", p.Scope, protocol.ScopeAll, protocol.ScopeThread)
+		}
 	case protocol.CmdStepOver:
+		if n := stepCount(cmd); n > 1 {
+			return dispatchResult{}, dbg.StepOverN(n)
+		}
 		return dispatchResult{}, dbg.StepOver()
 	case protocol.CmdStepInto:
+		if n := stepCount(cmd); n > 1 {
+			return dispatchResult{}, dbg.StepIntoN(n)
+		}
 		return dispatchResult{}, dbg.StepInto()
 	case protocol.CmdStepOut:
 		return dispatchResult{}, dbg.StepOut()
+	case protocol.CmdStepInstruction:
+		return dispatchResult{}, dbg.StepInstruction()
 
 	// Pause is fire-and-forget: it arms an async interrupt and returns. The
 	// debugger emits EventPaused once the SIGSTOP lands (no immediate event).
 	case protocol.CmdPause:
 		return dispatchResult{}, dbg.Pause()
 
+	// Input is fire-and-forget too: the process's reply is terminal output.
+	case protocol.CmdInput:
+		var p protocol.InputPayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return dispatchResult{}, err
+		}
+		return dispatchResult{}, dbg.WriteInput(p.Data)
+
 	case protocol.CmdLocals:
 		var p protocol.LocalsPayloadCmd
 		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
@@ -103,6 +207,17 @@ func dispatch(dbg debugger.Debugger, cmd protocol.Command) (dispatchResult, erro
 		}
 		return dispatchResult{event: &evt}, nil
 
+	case protocol.CmdInspectArgs:
+		args, err := dbg.InspectArgs()
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		evt, err := protocol.NewEvent(protocol.EventArgs, 0, args)
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		return dispatchResult{event: &evt}, nil
+
 	case protocol.CmdFrames:
 		frames, err := dbg.StackFrames()
 		if err != nil {
@@ -129,7 +244,193 @@ func dispatch(dbg debugger.Debugger, cmd protocol.Command) (dispatchResult, erro
 		}
 		return dispatchResult{event: &evt}, nil
 
+	case protocol.CmdResolveLine:
+		var p protocol.ResolveLinePayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return dispatchResult{}, err
+		}
+		var (
+			pc  uint64
+			loc protocol.Location
+			err error
+		)
+		if p.Function != "" {
+			if p.Offset < 0 {
+				return dispatchResult{}, fmt.Errorf("resolve line: offset %d is negative", p.Offset)
+			}
+			pc, loc, err = dbg.ResolveFunction(p.Function, p.Offset, p.Return)
+		} else {
+			pc, loc, err = dbg.ResolveLine(p.File, p.Line)
+		}
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		evt, err := protocol.NewEvent(protocol.EventLineResolved, 0, protocol.LineResolvedPayload{
+			File:     p.File,
+			Line:     p.Line,
+			PC:       pc,
+			Location: loc,
+		})
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		return dispatchResult{event: &evt}, nil
+
+	case protocol.CmdGetFile:
+		var p protocol.GetFilePayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return dispatchResult{}, err
+		}
+		path, err := dbg.SourcePath(p.File)
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		contents, err := readSourceChunk(path, p.Offset, p.Limit)
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		contents.File = p.File
+		evt, err := protocol.NewEvent(protocol.EventFileContents, 0, contents)
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		return dispatchResult{event: &evt}, nil
+
+	case protocol.CmdReadString:
+		var p protocol.ReadStringPayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return dispatchResult{}, err
+		}
+		s, n, err := dbg.ReadString(p.Addr)
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		evt, err := protocol.NewEvent(protocol.EventStringValue, 0, protocol.StringValuePayload{
+			Addr:      p.Addr,
+			Len:       n,
+			Value:     s,
+			Truncated: uint64(len(s)) < n,
+		})
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		return dispatchResult{event: &evt}, nil
+
+	case protocol.CmdReadSlice:
+		var p protocol.ReadSlicePayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return dispatchResult{}, err
+		}
+		v, err := dbg.ReadSlice(p.Addr, p.ElemSize)
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		evt, err := protocol.NewEvent(protocol.EventSliceValue, 0, v)
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		return dispatchResult{event: &evt}, nil
+
+	case protocol.CmdAddrToLine:
+		var p protocol.AddrToLinePayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return dispatchResult{}, err
+		}
+		loc, err := dbg.AddrToLine(p.PC)
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		evt, err := protocol.NewEvent(protocol.EventAddrResolved, 0, protocol.AddrResolvedPayload{
+			PC:       p.PC,
+			Location: loc,
+		})
+		if err != nil {
+			return dispatchResult{}, err
+		}
+		return dispatchResult{event: &evt}, nil
+
 	default:
 		return dispatchResult{}, fmt.Errorf("unknown command kind: %q", cmd.Kind)
 	}
 }
+
+// stepCount is the Count of a StepOver/StepInto payload. Older clients send an
+// empty (or no) payload, which is a single step; so is one that doesn't parse,
+// since stepping once is the command's meaning either way.
+func stepCount(cmd protocol.Command) int {
+	var p protocol.StepPayload
+	if protocol.DecodeCommandPayload(cmd, &p) != nil || p.Count < 1 {
+		return 1
+	}
+	return p.Count
+}
+
+// setBreakpoint sets one breakpoint for CmdSetBreakpoint or one
+// CmdSetBreakpoints entry. Its failure is recorded in the result rather than
+// returned, so the rest of a batch still goes in. A breakpoint that was
+// already there keeps its own actions, ignore count and condition; the hub
+// fills the actions in from what it remembers.
+func setBreakpoint(dbg debugger.Debugger, req protocol.SetBreakpointPayload) protocol.BreakpointResult {
+	res := protocol.BreakpointResult{File: req.File, Line: req.Line}
+	if err := validateActions(req.Actions); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if req.IgnoreCount < 0 {
+		res.Error = fmt.Sprintf("ignore count %d is negative", req.IgnoreCount)
+		return res
+	}
+	if c := req.GoroutineCount; c != nil && !c.Valid() {
+		res.Error = fmt.Sprintf("%s is not a valid goroutine count condition", c)
+		return res
+	}
+	bp, err := dbg.SetBreakpoint(req.File, req.Line)
+	switch {
+	case errors.Is(err, debugger.ErrBreakpointExists):
+		res.AlreadySet = true
+	case err != nil:
+		res.Error = err.Error()
+		return res
+	default:
+		if bp, err = applyIgnoreCount(dbg, bp, req.IgnoreCount); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		if bp, err = applyGoroutineCondition(dbg, bp, req.GoroutineCount); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		bp.Actions = req.Actions
+	}
+	res.Breakpoint = &bp
+	return res
+}
+
+// applyIgnoreCount gives a breakpoint just set its requested ignore count.
+// Should that fail the breakpoint is cleared again, so a failed set leaves
+// nothing behind that the client was never told about.
+func applyIgnoreCount(dbg debugger.Debugger, bp protocol.Breakpoint, count int) (protocol.Breakpoint, error) {
+	if count == 0 {
+		return bp, nil
+	}
+	withCount, err := dbg.SetIgnoreCount(bp.ID, count)
+	if err != nil {
+		_ = dbg.ClearBreakpoint(bp.ID)
+		return bp, err
+	}
+	return withCount, nil
+}
+
+// applyGoroutineCondition is applyIgnoreCount for a goroutine count
+// condition; nil leaves the breakpoint unconditional.
+func applyGoroutineCondition(dbg debugger.Debugger, bp protocol.Breakpoint, cond *protocol.GoroutineCountCondition) (protocol.Breakpoint, error) {
+	if cond == nil {
+		return bp, nil
+	}
+	withCond, err := dbg.SetGoroutineCondition(bp.ID, cond)
+	if err != nil {
+		_ = dbg.ClearBreakpoint(bp.ID)
+		return bp, err
+	}
+	return withCond, nil
+}
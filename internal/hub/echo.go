@@ -0,0 +1,30 @@
+package hub
+
+import (
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// handleEcho sends CmdEcho's payload back to the client that sent it. It is
+// answered on the Run goroutine like any other command, so a reply shows the
+// session loop is alive, not just the connection. Other clients are not
+// sent it: another client's ping is no news to them, and a client waiting
+// on its own Echo would take it for the reply.
+func (h *Hub) handleEcho(cc clientCommand) {
+	var p protocol.EchoPayload
+	if err := protocol.DecodeCommandPayload(cc.cmd, &p); err != nil {
+		h.broadcastError(cc.cmd.Kind, err)
+		return
+	}
+	evt, err := protocol.NewEvent(protocol.EventEcho, 0, p)
+	if err != nil {
+		h.broadcastError(cc.cmd.Kind, err)
+		return
+	}
+	if cc.from == nil {
+		h.broadcast(evt)
+		return
+	}
+	h.emitMu.Lock()
+	defer h.emitMu.Unlock()
+	h.sendLocked(cc.from, evt)
+}
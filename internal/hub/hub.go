@@ -5,6 +5,7 @@ package hub
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sort"
@@ -16,6 +17,10 @@ import (
 	"github.com/bingosuite/bingo/pkg/protocol"
 )
 
+// epoch anchors Event.Mono for every hub in the process, so monotonic
+// timestamps are comparable across sessions of one server.
+var epoch = time.Now()
+
 // suspendingEvents pause the hub and require a resuming command before the
 // process is allowed to continue. EventPaused is included: a Pause request
 // halts the tracee and suspends it exactly like a breakpoint hit, just
@@ -40,6 +45,107 @@ var resumingCommands = map[protocol.CommandKind]bool{
 	protocol.CmdStepOver: true,
 	protocol.CmdStepInto: true,
 	protocol.CmdStepOut:  true,
+
+	protocol.CmdStepInstruction: true,
+}
+
+// Options tunes optional, potentially expensive hub behaviour. The zero value
+// is the default: every feature here is opt-in.
+type Options struct {
+	// GoroutineSnapshots makes the hub enumerate all goroutines after every
+	// suspending event and broadcast them as EventGoroutineSnapshot.
+	GoroutineSnapshots bool
+
+	// LogBufferSize caps how many recent log entries the session keeps for
+	// CmdLogs. Zero means defaultLogBufferSize.
+	LogBufferSize int
+
+	// IdleTimeout shuts the session down once this long passes without client
+	// activity (a connection or a command). Zero disables idle shutdown: no
+	// timer runs at all, and the session lives until its last client leaves,
+	// the context is cancelled, or (raw hubs) the debugger goes away.
+	IdleTimeout time.Duration
+
+	// KeepAliveWithoutClients keeps the session (and its debuggee) alive after
+	// the last client disconnects, so a client can reconnect and pick up where
+	// it left off. The session then ends only on idle timeout, context
+	// cancellation, or the debugger going away.
+	KeepAliveWithoutClients bool
+
+	// DisconnectGrace, with KeepAliveWithoutClients, bounds how long a
+	// session without clients waits for one to come back before taking
+	// DisconnectAction. It is meant for the process left stopped at a
+	// breakpoint by an accidental disconnect. Zero waits indefinitely.
+	DisconnectGrace time.Duration
+
+	// DisconnectAction is what happens when DisconnectGrace runs out.
+	// Zero means DisconnectContinue.
+	DisconnectAction DisconnectAction
+
+	// DefaultBreakpoints are set on every process the session launches or
+	// attaches to, before it first runs. Only File and Line are used. A
+	// location the target can't resolve is skipped with an
+	// EventBreakpointError, since a server-wide list will often name files a
+	// given target doesn't have.
+	DefaultBreakpoints []protocol.Location
+
+	// LeakThreshold, when positive, broadcasts EventGoroutineLeak once the
+	// goroutine count has grown by at least this much without ever falling
+	// across the last LeakWindow snapshots. It reads the counts off goroutine
+	// snapshots, so it needs GoroutineSnapshots.
+	LeakThreshold int
+
+	// LeakWindow is how many consecutive snapshots LeakThreshold looks at.
+	// Zero means defaultLeakWindow.
+	LeakWindow int
+
+	// PIDDir, when set, is where the session records each process it
+	// launches, in a file removed once the process is gone, so bingo cleanup
+	// can find processes a crashed server left behind. See pidfile.go.
+	PIDDir string
+
+	// Metrics, when non-nil, records how long each command takes from the hub
+	// receiving it to the event that answers it. It is meant to be shared by
+	// every session of a server, which exposes it on /metrics.
+	Metrics *Metrics
+}
+
+// Validate rejects values that would otherwise be quietly reinterpreted: a
+// negative buffer size silently became the default, and a negative timeout
+// silently disabled idle shutdown.
+func (o Options) Validate() error {
+	if o.LogBufferSize < 0 {
+		return fmt.Errorf("hub options: log buffer size must not be negative, got %d", o.LogBufferSize)
+	}
+	if o.IdleTimeout < 0 {
+		return fmt.Errorf("hub options: idle timeout must not be negative, got %s", o.IdleTimeout)
+	}
+	if o.DisconnectGrace < 0 {
+		return fmt.Errorf("hub options: disconnect grace must not be negative, got %s", o.DisconnectGrace)
+	}
+	if o.DisconnectGrace > 0 && !o.KeepAliveWithoutClients {
+		return fmt.Errorf("hub options: disconnect grace needs keep-alive")
+	}
+	switch o.DisconnectAction {
+	case "", DisconnectContinue, DisconnectEnd:
+	default:
+		return fmt.Errorf("hub options: disconnect action must be %q or %q, got %q", DisconnectContinue, DisconnectEnd, o.DisconnectAction)
+	}
+	if o.LeakThreshold < 0 {
+		return fmt.Errorf("hub options: leak threshold must not be negative, got %d", o.LeakThreshold)
+	}
+	if o.LeakWindow < 0 || o.LeakWindow == 1 {
+		return fmt.Errorf("hub options: leak window must be 0 (default) or at least 2 snapshots, got %d", o.LeakWindow)
+	}
+	if o.LeakThreshold > 0 && !o.GoroutineSnapshots {
+		return fmt.Errorf("hub options: leak detection needs goroutine snapshots")
+	}
+	for _, loc := range o.DefaultBreakpoints {
+		if loc.File == "" || loc.Line <= 0 {
+			return fmt.Errorf("hub options: default breakpoint %s:%d needs a file and a positive line", loc.File, loc.Line)
+		}
+	}
+	return nil
 }
 
 // Hub owns one debug session. It bridges the Debugger with all connected
@@ -66,21 +172,38 @@ type Hub struct {
 	registry *registry
 	log      *slog.Logger
 
+	// logs captures everything logged through log (and, for server sessions,
+	// the debugger's logger — see Logger) so clients can fetch it via CmdLogs.
+	logs *logRing
+
 	// state guarded by stateMu — read from AddClient (HTTP goroutine), written
-	// from the Run loop.
+	// from the Run loop. stopLoc is where the process is suspended; it is only
+	// non-nil while state is suspended and is replayed in the welcome message.
+	// outcome is how the latest run ended (see Outcome).
 	stateMu sync.RWMutex
 	state   protocol.SessionState
+	stopLoc *protocol.Location
+	outcome Outcome
 
 	// cmdCh: non-resuming commands from client read-pumps to the main loop.
 	cmdCh chan clientCommand
 
 	// resumeCh: capacity 1, first-write-wins. Extras dropped in injectCommand.
-	resumeCh chan protocol.Command
+	resumeCh chan clientCommand
 
 	// seq is the single counter for ALL outbound events. The hub re-stamps
 	// debugger events with this counter, so clients see one monotonic stream
 	// and can detect gaps. The engine has its own seq.
-	seq atomic.Uint64
+	//
+	// emitMu guards seq and is held from stamping an event until it is in
+	// every client's send buffer, so each client receives events in seq
+	// order even when the welcome (AddClient, HTTP goroutine) races a
+	// broadcast (Run goroutine). State changes are made under it too: a stop
+	// event and the suspended transition it causes go out back to back, and
+	// a joining client's welcome is either entirely before or entirely after
+	// them — never a stale state following a newer one.
+	emitMu sync.Mutex
+	seq    uint64
 
 	// shutdownOnce: Kill and registry teardown must happen exactly once,
 	// even when ctx.Done() and last-client-disconnect race.
@@ -96,31 +219,82 @@ type Hub struct {
 	// sense for a process bingo itself started).
 	lastLaunch *protocol.LaunchPayload
 
+	// budget pauses the process once it has run for the launch's
+	// MaxRunTime. See runlimit.go.
+	budget runBudget
+
+	// pidFile is the PID file recording the launched process, empty when
+	// there is none. Run goroutine only.
+	pidFile string
+
 	// restartBreakpoints mirrors the breakpoints installed on the current
-	// debugger (id -> location), purely so Restart can reinstall them on the
+	// debugger (id -> breakpoint as last confirmed to clients, including its
+	// actions), purely so Restart can reinstall them on the
 	// relaunched process. The engine's breakpointTable remains the sole
 	// source of truth for the live process; this is bookkeeping the hub
 	// needs across a Kill+relaunch, when the old breakpointTable is gone.
-	restartBreakpoints map[int]protocol.Location
+	// It doubles as the breakpoint list in the welcome message, so writes
+	// (Run goroutine only) take bpMu to stay consistent with AddClient's read.
+	bpMu               sync.Mutex
+	restartBreakpoints map[int]protocol.Breakpoint
+
+	opts Options
+
+	// lastSnapshot is the most recent goroutine snapshot, replayed to clients
+	// that join after the stop it describes. Written on the Run goroutine,
+	// read from AddClient (HTTP goroutine), hence snapshotMu.
+	snapshotMu   sync.Mutex
+	lastSnapshot *protocol.GoroutineSnapshotPayload
+
+	// leaks tracks goroutine counts across snapshots for Options.LeakThreshold.
+	// Run goroutine only.
+	leaks leakWatch
+
+	// pendingStep is a step that resumed the process and whose latency is
+	// recorded once the stop it ends at is broadcast. Run goroutine only.
+	pendingStep *clientCommand
+
+	// createdAt is when the hub was made. Set once in newHub and never
+	// written again, so it needs no lock.
+	createdAt time.Time
+
+	// lastActivity is the UnixNano time of the latest client connection or
+	// command, touched from HTTP and read-pump goroutines; see idleWatch.
+	lastActivity atomic.Int64
+
+	// orphaned counts the times the session lost its last client, so a
+	// disconnectWatch can tell whether the absence it timed is still the
+	// current one.
+	orphaned atomic.Uint64
 }
 
+// clientCommand is a command as queued by injectCommand. received is when the
+// hub got it, the start of the latency Options.Metrics records.
 type clientCommand struct {
-	cmd protocol.Command
+	cmd      protocol.Command
+	received time.Time
+	// from is the client that sent cmd; nil for commands the hub makes up.
+	from *Client
 }
 
 func newHub(log *slog.Logger) *Hub {
 	if log == nil {
 		log = slog.Default()
 	}
-	return &Hub{
+	logs := newLogRing(defaultLogBufferSize)
+	h := &Hub{
+		logs:               logs,
 		registry:           newRegistry(),
 		cmdCh:              make(chan clientCommand, 32),
-		resumeCh:           make(chan protocol.Command, 1),
+		resumeCh:           make(chan clientCommand, 1),
 		shutdownCh:         make(chan struct{}),
 		done:               make(chan struct{}),
-		log:                log,
-		restartBreakpoints: make(map[int]protocol.Location),
+		log:                slog.New(newRingHandler(log.Handler(), logs)),
+		restartBreakpoints: make(map[int]protocol.Breakpoint),
+		createdAt:          time.Now(),
 	}
+	h.touch()
+	return h
 }
 
 // New creates a Hub wired to dbg. The debugger is already attached — no
@@ -142,6 +316,20 @@ func NewSession(sessionID string, newDebugger func() debugger.Debugger, log *slo
 	return h
 }
 
+// Configure applies opts. Must be called before Run.
+func (h *Hub) Configure(opts Options) {
+	h.opts = opts
+	h.logs.resize(opts.LogBufferSize)
+	h.leaks = leakWatch{threshold: opts.LeakThreshold, window: opts.LeakWindow}
+	if h.leaks.window == 0 {
+		h.leaks.window = defaultLeakWindow
+	}
+}
+
+// Logger returns the session logger. Components that log on behalf of the
+// session (the debugger) should use it so their lines reach CmdLogs.
+func (h *Hub) Logger() *slog.Logger { return h.log }
+
 func (h *Hub) SessionID() string { return h.sessionID }
 
 func (h *Hub) State() protocol.SessionState {
@@ -152,17 +340,30 @@ func (h *Hub) State() protocol.SessionState {
 
 func (h *Hub) ClientCount() int { return h.registry.count() }
 
+// CreatedAt is when the session was created. Safe from any goroutine.
+func (h *Hub) CreatedAt() time.Time { return h.createdAt }
+
+// LastActivity is when a client last connected or sent a command: the clock
+// the idle timeout runs on. Safe from any goroutine.
+func (h *Hub) LastActivity() time.Time { return time.Unix(0, h.lastActivity.Load()) }
+
 // Done is closed when Run returns.
 func (h *Hub) Done() <-chan struct{} { return h.done }
 
-// Run blocks until ctx is cancelled, shutdown() is called (last client left),
-// or — for raw hubs — the debugger's Events channel closes. Call exactly once.
+// Run blocks until ctx is cancelled, shutdown() is called (last client left
+// or Options.IdleTimeout elapsed), or — for raw hubs — the debugger's Events
+// channel closes. Call exactly once.
 func (h *Hub) Run(ctx context.Context) {
 	defer func() {
 		h.shutdown()
+		h.forgetTarget()
 		close(h.done)
 	}()
 
+	if h.opts.IdleTimeout > 0 {
+		go h.idleWatch(h.opts.IdleTimeout)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -183,7 +384,10 @@ func (h *Hub) Run(ctx context.Context) {
 			h.handleEvent(ctx, evt)
 
 		case cc := <-h.cmdCh:
-			h.executeCommand(cc.cmd)
+			h.runCommand(cc)
+
+		case <-h.budget.C():
+			h.handleRunTimeout()
 		}
 	}
 }
@@ -209,15 +413,27 @@ func (h *Hub) setDbg(d debugger.Debugger) {
 
 // AddClient registers conn as a new client. Safe from any goroutine.
 func (h *Hub) AddClient(conn WSConn, log *slog.Logger) *Client {
+	return h.addClient(conn, log, false)
+}
+
+func (h *Hub) addClient(conn WSConn, log *slog.Logger, readOnly bool) *Client {
 	c := newClient(conn, h, log)
-	h.registry.add(c)
+	c.readOnly = readOnly
+	h.touch()
 	go c.writePump()
 	go c.readPump()
-	h.log.Info("client connected", "total", h.registry.count())
 
+	// Registering and welcoming under emitMu means no broadcast can reach
+	// the client ahead of its welcome, and the welcome reflects every
+	// broadcast the client will not see.
+	h.emitMu.Lock()
+	h.registry.add(c)
 	if h.sessionID != "" {
-		h.sendStateTo(c)
+		h.sendStateToLocked(c)
 	}
+	h.sendSnapshotToLocked(c)
+	h.emitMu.Unlock()
+	h.log.Info("client connected", "total", h.registry.count())
 
 	return c
 }
@@ -231,6 +447,13 @@ func (h *Hub) removeClient(c *Client) {
 	remaining := h.registry.count()
 	h.log.Info("client disconnected", "remaining", remaining)
 	if remaining == 0 {
+		if h.opts.KeepAliveWithoutClients {
+			h.log.Info("last client disconnected — keeping session alive")
+			if h.opts.DisconnectGrace > 0 {
+				go h.disconnectWatch(h.orphaned.Add(1), h.opts.DisconnectGrace)
+			}
+			return
+		}
 		h.log.Info("last client disconnected — shutting down")
 		// Separate goroutine: readPump must not block on dbg.Kill().
 		go h.shutdown()
@@ -242,6 +465,12 @@ func (h *Hub) removeClient(c *Client) {
 // ends. Re-stamping is needed because the engine has its own seq and the hub
 // also synthesises errors/confirmations.
 func (h *Hub) handleEvent(ctx context.Context, evt protocol.Event) {
+	// A breakpoint whose actions resume the process never stops, as far as
+	// clients can tell: only the actions' output is broadcast.
+	if evt.Kind == protocol.EventBreakpointHit && h.runBreakpointActions(evt) {
+		return
+	}
+
 	suspending := suspendingEvents[evt.Kind]
 
 	// Discard any resuming command buffered while the process was still running
@@ -260,20 +489,39 @@ func (h *Hub) handleEvent(ctx context.Context, evt protocol.Event) {
 		h.drainResumeCh()
 	}
 
-	evt.Seq = h.seq.Add(1)
-	h.broadcast(evt)
+	// The debugger only announces a breakpoint it set itself: a default
+	// armed at the initial stop.
+	if evt.Kind == protocol.EventBreakpointSet {
+		h.rememberBreakpoint(dispatchResult{event: &evt})
+	}
 
+	// The event and the state change it causes are one atomic emission.
+	h.emitMu.Lock()
+	h.broadcastLocked(evt)
 	switch evt.Kind {
 	case protocol.EventBreakpointHit, protocol.EventPanic, protocol.EventStepped, protocol.EventPaused:
-		h.transitionState(protocol.StateSuspended)
+		h.setStopLocation(stopLocation(evt))
+		h.transitionStateLocked(protocol.StateSuspended)
 	case protocol.EventProcessExited:
-		h.transitionState(protocol.StateExited)
+		h.recordExit(evt)
+		h.transitionStateLocked(protocol.StateExited)
+	}
+	h.emitMu.Unlock()
+	if suspending || evt.Kind == protocol.EventProcessExited {
+		h.finishStep()
+	}
+	if evt.Kind == protocol.EventBreakpointLost {
+		h.forgetLostBreakpoint(evt)
 	}
 
 	if !suspending {
 		return
 	}
 
+	if h.opts.GoroutineSnapshots {
+		h.broadcastGoroutineSnapshot(evt.Kind)
+	}
+
 	h.log.Info("suspended — waiting for resuming command", "event", evt.Kind)
 
 	timeout := time.NewTimer(30 * time.Minute)
@@ -299,15 +547,21 @@ func (h *Hub) handleEvent(ctx context.Context, evt protocol.Event) {
 				}
 				return
 			}
-			nextEvt.Seq = h.seq.Add(1)
-			h.broadcast(nextEvt)
-			if nextEvt.Kind == protocol.EventProcessExited {
-				h.transitionState(protocol.StateExited)
+			h.emitMu.Lock()
+			h.broadcastLocked(nextEvt)
+			exited := nextEvt.Kind == protocol.EventProcessExited
+			if exited {
+				h.recordExit(nextEvt)
+				h.transitionStateLocked(protocol.StateExited)
+			}
+			h.emitMu.Unlock()
+			if exited {
+				h.finishStep()
 				return
 			}
 
-		case cmd := <-h.resumeCh:
-			h.log.Info("resuming", "command", cmd.Kind)
+		case cc := <-h.resumeCh:
+			h.log.Info("resuming", "command", cc.cmd.Kind)
 			// A resume ends the suspend only if it actually took effect. When
 			// the debugger rejects it — e.g. a transient backend error while
 			// reinstalling a software breakpoint (AGENTS.md → step-over flow),
@@ -318,7 +572,7 @@ func (h *Hub) handleEvent(ctx context.Context, evt protocol.Event) {
 			// so the process could never be resumed again. Keep waiting unless
 			// the resume advanced the session out of suspended (running on
 			// success, or exited if the process died mid-resume).
-			h.executeCommand(cmd)
+			h.runCommand(cc)
 			if h.State() != protocol.StateSuspended {
 				return
 			}
@@ -331,7 +585,7 @@ func (h *Hub) handleEvent(ctx context.Context, evt protocol.Event) {
 			// both cases the process we were waiting to resume no longer
 			// exists — return and let Run's outer loop pick up the new or
 			// closed debugger's events channel.
-			h.executeCommand(cc.cmd)
+			h.runCommand(cc)
 			if cc.cmd.Kind == protocol.CmdRestart || cc.cmd.Kind == protocol.CmdKill {
 				return
 			}
@@ -355,11 +609,63 @@ func (h *Hub) handleDebuggerClosed() {
 		h.transitionState(protocol.StateExited)
 	}
 	h.setDbg(nil)
+	h.forgetTarget()
+	h.resetSnapshots()
+	h.pendingStep = nil
 	h.transitionState(protocol.StateIdle)
 	h.log.Info("debugger closed — session idle, ready for re-launch")
 }
 
+// runCommand executes cc and records its latency. Most commands are answered
+// by the time executeCommand returns, but a step's answer is the stop it ends
+// at, so a step that set the process running is timed by finishStep instead.
+func (h *Hub) runCommand(cc clientCommand) {
+	// Echo and Subscribe are answered to their sender alone, so they need
+	// more than executeCommand's cmd.
+	switch cc.cmd.Kind {
+	case protocol.CmdEcho:
+		h.handleEcho(cc)
+	case protocol.CmdSubscribe:
+		h.handleSubscribe(cc)
+	default:
+		h.executeCommand(cc.cmd)
+	}
+	if h.opts.Metrics == nil {
+		return
+	}
+	if cc.cmd.Kind != protocol.CmdContinue && resumingCommands[cc.cmd.Kind] &&
+		h.State() == protocol.StateRunning {
+		h.pendingStep = &cc
+		return
+	}
+	h.opts.Metrics.observeCommand(cc.cmd.Kind, time.Since(cc.received))
+}
+
+// finishStep records the latency of the step, if any, that the stop or exit
+// just broadcast ends.
+func (h *Hub) finishStep() {
+	if h.pendingStep == nil {
+		return
+	}
+	h.opts.Metrics.observeCommand(h.pendingStep.cmd.Kind, time.Since(h.pendingStep.received))
+	h.pendingStep = nil
+}
+
 func (h *Hub) executeCommand(cmd protocol.Command) {
+	// Logs is answered from the hub's own buffer and needs no debugger.
+	if cmd.Kind == protocol.CmdLogs {
+		h.handleLogs(cmd)
+		return
+	}
+	if cmd.Kind == protocol.CmdCapabilities {
+		h.handleCapabilities(cmd)
+		return
+	}
+	if cmd.Kind == protocol.CmdStatus {
+		h.handleStatus(cmd)
+		return
+	}
+
 	// Restart doesn't fit the generic dispatch(dbg, cmd) shape below: it
 	// tears down h.dbg and replaces it with a brand new instance, which only
 	// the hub (holder of newDebugger) can do. See handleRestart.
@@ -392,7 +698,34 @@ func (h *Hub) executeCommand(cmd protocol.Command) {
 		return
 	}
 
-	result, err := dispatch(h.dbg, cmd)
+	// Pausing a process that is already stopped is harmless, so say so rather
+	// than fail: clients racing a breakpoint hit with a Pause should not see
+	// an error. The engine's ErrNotRunning covers the same race from its side,
+	// when the stop event is still on its way to the hub.
+	if cmd.Kind == protocol.CmdPause && h.State() == protocol.StateSuspended {
+		h.broadcastNotice(cmd.Kind, "process is already suspended")
+		return
+	}
+
+	var resumed *protocol.ResumedPayload
+	// Where a resume leaves from has to be read before the process moves,
+	// but it is only announced once dispatch succeeds: a resume that failed
+	// never happened.
+	if resumingCommands[cmd.Kind] && h.State() == protocol.StateSuspended {
+		p := h.resumePoint(cmd.Kind)
+		resumed = &p
+	}
+
+	var result dispatchResult
+	err := h.armDefaultBreakpoints(cmd.Kind)
+	if err == nil {
+		result, err = dispatch(h.dbg, cmd)
+	}
+	if cmd.Kind == protocol.CmdPause && errors.Is(err, debugger.ErrNotRunning) &&
+		h.State() != protocol.StateExited {
+		h.broadcastNotice(cmd.Kind, "process is already suspended")
+		return
+	}
 	if err != nil {
 		h.log.Warn("command failed", "kind", cmd.Kind, "err", err)
 		if h.sessionID != "" && (cmd.Kind == protocol.CmdLaunch || cmd.Kind == protocol.CmdAttach) {
@@ -401,28 +734,46 @@ func (h *Hub) executeCommand(cmd protocol.Command) {
 		h.broadcastError(cmd.Kind, err)
 		return
 	}
+	if resumed != nil {
+		h.broadcastResumed(*resumed)
+	}
+
+	h.log.Info("command executed", "kind", cmd.Kind)
 
 	switch cmd.Kind {
 	case protocol.CmdLaunch:
-		h.transitionState(protocol.StateRunning)
+		h.resetSnapshots()
 		h.rememberLaunch(cmd)
-		h.restartBreakpoints = make(map[int]protocol.Location)
+		h.recordTarget()
+		h.resetBudget()
+		h.transitionState(protocol.StateRunning)
+		h.resetBreakpoints(nil)
 	case protocol.CmdAttach:
+		h.resetSnapshots()
 		h.transitionState(protocol.StateRunning)
 		// Restart only makes sense for a process bingo itself launched —
 		// mirrors Delve's canRestart check.
 		h.lastLaunch = nil
-		h.restartBreakpoints = make(map[int]protocol.Location)
-	case protocol.CmdContinue, protocol.CmdStepOver, protocol.CmdStepInto, protocol.CmdStepOut:
+		h.resetBudget()
+		h.resetBreakpoints(nil)
+	case protocol.CmdContinue, protocol.CmdStepOver, protocol.CmdStepInto, protocol.CmdStepOut,
+		protocol.CmdStepInstruction:
 		h.transitionState(protocol.StateRunning)
 	case protocol.CmdSetBreakpoint:
 		h.rememberBreakpoint(result)
+	case protocol.CmdSetBreakpoints:
+		h.rememberBreakpoints(result)
 	case protocol.CmdClearBreakpoint:
 		h.forgetBreakpoint(cmd)
+	case protocol.CmdClearAllBreakpoints:
+		h.forgetClearedBreakpoints(result)
+	case protocol.CmdDisableAllBreakpoints, protocol.CmdEnableAllBreakpoints:
+		h.rememberToggledBreakpoints(result)
+	case protocol.CmdSetIgnoreCount:
+		h.rememberIgnoreCount(result)
 	}
 
 	if result.event != nil {
-		result.event.Seq = h.seq.Add(1)
 		h.broadcast(*result.event)
 	}
 }
@@ -438,6 +789,22 @@ func (h *Hub) teardownFailedStart() {
 	}
 }
 
+// armDefaultBreakpoints hands Options.DefaultBreakpoints to the debugger
+// ahead of a Launch or Attach. The engine sets them while the process is
+// still parked at its initial stop, before continue-to-main or run lets it
+// go, and reports each as EventBreakpointSet or EventBreakpointError;
+// handleEvent remembers the ones set like a client's, which is also why
+// Restart doesn't re-arm them: it already reinstalls everything remembered.
+func (h *Hub) armDefaultBreakpoints(kind protocol.CommandKind) error {
+	if kind != protocol.CmdLaunch && kind != protocol.CmdAttach {
+		return nil
+	}
+	if len(h.opts.DefaultBreakpoints) == 0 {
+		return nil
+	}
+	return h.dbg.SetInitialBreakpoints(h.opts.DefaultBreakpoints)
+}
+
 // rememberLaunch decodes cmd's LaunchPayload and stores a copy for a future
 // Restart. Decode failures are ignored — Launch has already succeeded by the
 // time this is called, so at worst Restart later reports "nothing to
@@ -450,8 +817,25 @@ func (h *Hub) rememberLaunch(cmd protocol.Command) {
 	h.lastLaunch = &p
 }
 
-// rememberBreakpoint records a successfully-set breakpoint's id -> location
-// so Restart can reinstall it later.
+// resetBudget gives a freshly started process the run budget of the launch
+// that started it. Attach leaves lastLaunch nil and so has no limit.
+func (h *Hub) resetBudget() {
+	var limit time.Duration
+	if h.lastLaunch != nil {
+		limit = h.lastLaunch.MaxRunTime
+	}
+	h.budget.reset(limit)
+	// Restart relaunches without leaving StateRunning, so no transition
+	// would start the new budget.
+	if h.State() == protocol.StateRunning {
+		h.budget.start()
+	}
+}
+
+// rememberBreakpoint records a successfully-set breakpoint so Restart can
+// reinstall it later. One that was already set is left as recorded, and its
+// recorded actions go into the outgoing event, which the engine (knowing
+// nothing of actions) left empty.
 func (h *Hub) rememberBreakpoint(result dispatchResult) {
 	if result.event == nil {
 		return
@@ -460,7 +844,92 @@ func (h *Hub) rememberBreakpoint(result dispatchResult) {
 	if err := protocol.DecodeEventPayload(*result.event, &p); err != nil {
 		return
 	}
-	h.restartBreakpoints[p.Breakpoint.ID] = p.Breakpoint.Location
+	h.bpMu.Lock()
+	defer h.bpMu.Unlock()
+	if !p.AlreadySet {
+		h.restartBreakpoints[p.Breakpoint.ID] = p.Breakpoint
+		return
+	}
+	p.Breakpoint.Actions = h.restartBreakpoints[p.Breakpoint.ID].Actions
+	if evt, err := protocol.NewEvent(result.event.Kind, 0, p); err == nil {
+		*result.event = evt
+	}
+}
+
+// rememberBreakpoints is rememberBreakpoint for each breakpoint a
+// CmdSetBreakpoints batch reports.
+func (h *Hub) rememberBreakpoints(result dispatchResult) {
+	if result.event == nil {
+		return
+	}
+	var p protocol.BreakpointsSetPayload
+	if err := protocol.DecodeEventPayload(*result.event, &p); err != nil {
+		return
+	}
+	h.bpMu.Lock()
+	defer h.bpMu.Unlock()
+	for _, r := range p.Results {
+		switch {
+		case r.Breakpoint == nil:
+		case r.AlreadySet:
+			r.Breakpoint.Actions = h.restartBreakpoints[r.Breakpoint.ID].Actions
+		default:
+			h.restartBreakpoints[r.Breakpoint.ID] = *r.Breakpoint
+		}
+	}
+	if evt, err := protocol.NewEvent(result.event.Kind, 0, p); err == nil {
+		*result.event = evt
+	}
+}
+
+// rememberToggledBreakpoints records the Enabled flags a disable/enable-all
+// reported, and fills the tracked actions into the outgoing event, which the
+// engine (knowing nothing of actions) left empty.
+func (h *Hub) rememberToggledBreakpoints(result dispatchResult) {
+	if result.event == nil {
+		return
+	}
+	var p protocol.BreakpointsToggledPayload
+	if err := protocol.DecodeEventPayload(*result.event, &p); err != nil {
+		return
+	}
+	h.bpMu.Lock()
+	for i, bp := range p.Breakpoints {
+		known, ok := h.restartBreakpoints[bp.ID]
+		if !ok {
+			continue
+		}
+		known.Enabled = bp.Enabled
+		h.restartBreakpoints[bp.ID] = known
+		p.Breakpoints[i].Actions = known.Actions
+	}
+	h.bpMu.Unlock()
+	if evt, err := protocol.NewEvent(result.event.Kind, 0, p); err == nil {
+		*result.event = evt
+	}
+}
+
+// rememberIgnoreCount records the ignore count a CmdSetIgnoreCount set, and
+// fills the tracked actions into the outgoing event, which the engine
+// (knowing nothing of actions) left empty.
+func (h *Hub) rememberIgnoreCount(result dispatchResult) {
+	if result.event == nil {
+		return
+	}
+	var p protocol.IgnoreCountSetPayload
+	if err := protocol.DecodeEventPayload(*result.event, &p); err != nil {
+		return
+	}
+	h.bpMu.Lock()
+	if known, ok := h.restartBreakpoints[p.Breakpoint.ID]; ok {
+		known.IgnoreCount = p.Breakpoint.IgnoreCount
+		h.restartBreakpoints[p.Breakpoint.ID] = known
+		p.Breakpoint.Actions = known.Actions
+	}
+	h.bpMu.Unlock()
+	if evt, err := protocol.NewEvent(result.event.Kind, 0, p); err == nil {
+		*result.event = evt
+	}
 }
 
 // forgetBreakpoint removes a cleared breakpoint from the Restart bookkeeping.
@@ -469,23 +938,68 @@ func (h *Hub) forgetBreakpoint(cmd protocol.Command) {
 	if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
 		return
 	}
+	h.bpMu.Lock()
 	delete(h.restartBreakpoints, p.ID)
+	h.bpMu.Unlock()
 }
 
-// sortedRestartLocations returns the tracked breakpoint locations in
-// ascending ID order, so Restart reinstalls them in a deterministic sequence
-// (and thus assigns deterministic new IDs) across runs.
-func (h *Hub) sortedRestartLocations() []protocol.Location {
-	ids := make([]int, 0, len(h.restartBreakpoints))
-	for id := range h.restartBreakpoints {
-		ids = append(ids, id)
+// forgetClearedBreakpoints removes every breakpoint a clear-all reported
+// from the Restart bookkeeping.
+func (h *Hub) forgetClearedBreakpoints(result dispatchResult) {
+	if result.event == nil {
+		return
+	}
+	var p protocol.BreakpointsClearedPayload
+	if err := protocol.DecodeEventPayload(*result.event, &p); err != nil {
+		return
 	}
-	sort.Ints(ids)
-	locs := make([]protocol.Location, 0, len(ids))
-	for _, id := range ids {
-		locs = append(locs, h.restartBreakpoints[id])
+	h.bpMu.Lock()
+	for _, id := range p.IDs {
+		delete(h.restartBreakpoints, id)
 	}
-	return locs
+	h.bpMu.Unlock()
+}
+
+// forgetLostBreakpoint drops a breakpoint the engine reports as lost, so the
+// welcome list stops advertising it. Restart will not bring it back either.
+func (h *Hub) forgetLostBreakpoint(evt protocol.Event) {
+	var p protocol.BreakpointLostPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return
+	}
+	h.bpMu.Lock()
+	delete(h.restartBreakpoints, p.Breakpoint.ID)
+	h.bpMu.Unlock()
+}
+
+// resetBreakpoints replaces the tracked breakpoint set; nil clears it.
+func (h *Hub) resetBreakpoints(bps map[int]protocol.Breakpoint) {
+	if bps == nil {
+		bps = make(map[int]protocol.Breakpoint)
+	}
+	h.bpMu.Lock()
+	h.restartBreakpoints = bps
+	h.bpMu.Unlock()
+}
+
+// breakpointActions returns the actions attached to breakpoint id, if any.
+func (h *Hub) breakpointActions(id int) []string {
+	h.bpMu.Lock()
+	defer h.bpMu.Unlock()
+	return h.restartBreakpoints[id].Actions
+}
+
+// knownBreakpoints returns the tracked breakpoints in ascending ID order.
+// Safe from any goroutine.
+func (h *Hub) knownBreakpoints() []protocol.Breakpoint {
+	h.bpMu.Lock()
+	defer h.bpMu.Unlock()
+	out := make([]protocol.Breakpoint, 0, len(h.restartBreakpoints))
+	for _, bp := range h.restartBreakpoints {
+		out = append(out, bp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
 }
 
 // handleRestart kills the current process (if any), relaunches the last
@@ -513,11 +1027,9 @@ func (h *Hub) handleRestart(cmd protocol.Command) {
 	}
 
 	var override protocol.RestartPayload
-	if len(cmd.Payload) > 0 {
-		if err := protocol.DecodeCommandPayload(cmd, &override); err != nil {
-			h.broadcastError(cmd.Kind, err)
-			return
-		}
+	if err := protocol.DecodeCommandPayload(cmd, &override); err != nil {
+		h.broadcastError(cmd.Kind, err)
+		return
 	}
 
 	program := h.lastLaunch.Program
@@ -530,38 +1042,62 @@ func (h *Hub) handleRestart(cmd protocol.Command) {
 		env = override.Env
 	}
 
-	saved := h.sortedRestartLocations()
+	saved := h.knownBreakpoints()
 
 	if h.dbg != nil {
 		_ = h.dbg.Kill()
 		h.setDbg(nil)
 	}
+	h.forgetTarget()
 
 	newDbg := h.newDebugger()
+	if mode := h.lastLaunch.InitialStop; mode != "" {
+		if err := newDbg.SetInitialStop(mode); err != nil {
+			h.broadcastError(cmd.Kind, fmt.Errorf("restart: %w", err))
+			h.transitionState(protocol.StateIdle)
+			return
+		}
+	}
 	if err := newDbg.Launch(program, args, env); err != nil {
 		h.broadcastError(cmd.Kind, fmt.Errorf("restart: relaunch failed: %w", err))
 		h.transitionState(protocol.StateIdle)
 		return
 	}
 	h.setDbg(newDbg)
-	h.lastLaunch = &protocol.LaunchPayload{Program: program, Args: args, Env: env}
+	h.resetSnapshots()
+	h.lastLaunch = &protocol.LaunchPayload{
+		Program: program, Args: args, Env: env,
+		MaxRunTime: h.lastLaunch.MaxRunTime, InitialStop: h.lastLaunch.InitialStop,
+	}
+	h.recordTarget()
+	h.resetBudget()
 	h.transitionState(protocol.StateRunning)
 
 	installed := make([]protocol.Breakpoint, 0, len(saved))
 	discarded := make([]protocol.DiscardedBreakpoint, 0)
-	newBreakpoints := make(map[int]protocol.Location, len(saved))
-	for _, loc := range saved {
+	// knownBreakpoints is in ascending ID order, so the reinstall sequence
+	// (and thus the new IDs) is deterministic across runs. Disabled
+	// breakpoints come back armed: a fresh process starts with them all on.
+	newBreakpoints := make(map[int]protocol.Breakpoint, len(saved))
+	for _, old := range saved {
+		loc := old.Location
 		bp, err := newDbg.SetBreakpoint(loc.File, loc.Line)
+		if err == nil {
+			// A condition is part of what the breakpoint is, unlike the
+			// ignore count, which is how far this run has got through it.
+			bp, err = applyGoroutineCondition(newDbg, bp, old.GoroutineCount)
+		}
 		if err != nil {
 			discarded = append(discarded, protocol.DiscardedBreakpoint{Location: loc, Reason: err.Error()})
 			continue
 		}
+		bp.Actions = old.Actions
 		installed = append(installed, bp)
-		newBreakpoints[bp.ID] = bp.Location
+		newBreakpoints[bp.ID] = bp
 	}
-	h.restartBreakpoints = newBreakpoints
+	h.resetBreakpoints(newBreakpoints)
 
-	evt, err := protocol.NewEvent(protocol.EventRestarted, h.seq.Add(1), protocol.RestartedPayload{
+	evt, err := protocol.NewEvent(protocol.EventRestarted, 0, protocol.RestartedPayload{
 		Program:     program,
 		Breakpoints: installed,
 		Discarded:   discarded,
@@ -573,26 +1109,74 @@ func (h *Hub) handleRestart(cmd protocol.Command) {
 	h.broadcast(evt)
 }
 
+func (h *Hub) handleLogs(cmd protocol.Command) {
+	var p protocol.LogsPayloadCmd
+	if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+		h.broadcastError(cmd.Kind, err)
+		return
+	}
+	evt, err := protocol.NewEvent(protocol.EventLogs, 0, protocol.LogsPayload{
+		Entries: h.logs.recent(p.Limit),
+	})
+	if err != nil {
+		h.broadcastError(cmd.Kind, err)
+		return
+	}
+	h.broadcast(evt)
+}
+
 // injectCommand is called by client read-pumps. Resuming commands (Continue,
 // Step*) go to resumeCh to directly unblock a suspended hub; everything else —
 // including Kill and Pause, which must act while the process is running — goes
 // to cmdCh, drained by Run's main loop and the suspended wait loop alike.
-func (h *Hub) injectCommand(_ *Client, cmd protocol.Command) {
+func (h *Hub) injectCommand(c *Client, cmd protocol.Command) {
+	h.touch()
+	if c != nil && c.readOnly && !observerCommands[cmd.Kind] {
+		h.refuseReadOnly(c, cmd.Kind)
+		return
+	}
+	cc := clientCommand{cmd: cmd, received: time.Now(), from: c}
 	if resumingCommands[cmd.Kind] {
 		select {
-		case h.resumeCh <- cmd:
+		case h.resumeCh <- cc:
 		default:
 			// First writer wins; later resumers are dropped.
 		}
 		return
 	}
 	select {
-	case h.cmdCh <- clientCommand{cmd: cmd}:
+	case h.cmdCh <- cc:
 	default:
 		h.log.Warn("command queue full — dropping", "kind", cmd.Kind)
 	}
 }
 
+// touch records client activity for idleWatch.
+func (h *Hub) touch() { h.lastActivity.Store(time.Now().UnixNano()) }
+
+// idleWatch shuts the hub down once timeout passes with no client activity.
+// It runs off the Run goroutine so it also fires while Run is parked in a
+// suspended wait. The timer is re-armed for exactly the remaining idle budget,
+// so it only wakes when a shutdown could actually be due.
+func (h *Hub) idleWatch(timeout time.Duration) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	for {
+		select {
+		case <-h.shutdownCh:
+			return
+		case <-t.C:
+			idle := time.Since(time.Unix(0, h.lastActivity.Load()))
+			if idle >= timeout {
+				h.log.Info("session idle — shutting down", "idle", idle.Round(time.Millisecond))
+				h.shutdown()
+				return
+			}
+			t.Reset(timeout - idle)
+		}
+	}
+}
+
 // drainResumeCh removes any single buffered resuming command without blocking.
 // resumeCh has capacity 1, so one non-blocking receive empties it.
 func (h *Hub) drainResumeCh() {
@@ -604,6 +1188,14 @@ func (h *Hub) drainResumeCh() {
 
 // transitionState updates state and, for managed sessions, broadcasts.
 func (h *Hub) transitionState(newState protocol.SessionState) {
+	h.emitMu.Lock()
+	defer h.emitMu.Unlock()
+	h.transitionStateLocked(newState)
+}
+
+// transitionStateLocked is transitionState for callers already holding
+// emitMu.
+func (h *Hub) transitionStateLocked(newState protocol.SessionState) {
 	h.stateMu.Lock()
 	old := h.state
 	if old == newState {
@@ -611,21 +1203,79 @@ func (h *Hub) transitionState(newState protocol.SessionState) {
 		return
 	}
 	h.state = newState
+	if newState != protocol.StateSuspended {
+		h.stopLoc = nil
+	}
+	if newState == protocol.StateRunning && (old == protocol.StateIdle || old == protocol.StateExited) {
+		// A new run: the last one's outcome no longer describes the process.
+		h.outcome = Outcome{}
+	}
 	h.stateMu.Unlock()
 
+	if newState == protocol.StateRunning {
+		h.budget.start()
+	} else {
+		h.budget.stop()
+	}
+
 	h.log.Info("state transition", "from", old, "to", newState)
 
 	if h.sessionID != "" {
-		h.broadcastSessionState()
+		h.broadcastSessionStateLocked()
+	}
+}
+
+func (h *Hub) setStopLocation(loc *protocol.Location) {
+	h.stateMu.Lock()
+	h.stopLoc = loc
+	if loc != nil {
+		h.outcome.LastStop = loc
 	}
+	h.stateMu.Unlock()
 }
 
-func (h *Hub) broadcastSessionState() {
+// stopLocation extracts where the process stopped from a suspending event.
+// Returns nil when the payload carries no usable location.
+func stopLocation(evt protocol.Event) *protocol.Location {
+	var loc protocol.Location
+	switch evt.Kind {
+	case protocol.EventBreakpointHit:
+		var p protocol.BreakpointHitPayload
+		if protocol.DecodeEventPayload(evt, &p) != nil {
+			return nil
+		}
+		loc = p.Breakpoint.Location
+	case protocol.EventStepped:
+		var p protocol.SteppedPayload
+		if protocol.DecodeEventPayload(evt, &p) != nil {
+			return nil
+		}
+		loc = p.Location
+	case protocol.EventPaused:
+		var p protocol.PausedPayload
+		if protocol.DecodeEventPayload(evt, &p) != nil {
+			return nil
+		}
+		loc = p.Location
+	case protocol.EventPanic:
+		var p protocol.PanicPayload
+		if protocol.DecodeEventPayload(evt, &p) != nil || len(p.Frames) == 0 {
+			return nil
+		}
+		loc = p.Frames[0].Location
+	}
+	if loc.File == "" {
+		return nil
+	}
+	return &loc
+}
+
+func (h *Hub) broadcastSessionStateLocked() {
 	h.stateMu.RLock()
 	state := h.state
 	h.stateMu.RUnlock()
 
-	evt, err := protocol.NewEvent(protocol.EventSessionState, h.seq.Add(1), protocol.SessionStatePayload{
+	evt, err := protocol.NewEvent(protocol.EventSessionState, 0, protocol.SessionStatePayload{
 		SessionID: h.sessionID,
 		State:     state,
 		Clients:   h.registry.count(),
@@ -634,49 +1284,134 @@ func (h *Hub) broadcastSessionState() {
 		h.log.Error("failed to create session state event", "err", err)
 		return
 	}
-	h.broadcast(evt)
+	h.broadcastLocked(evt)
 }
 
-// sendStateTo delivers the current state to a single client (welcome message).
-func (h *Hub) sendStateTo(c *Client) {
+// sendStateToLocked delivers the current state to a single client (welcome
+// message), together with the last-known stop location and breakpoint list so
+// a joining or reconnecting client can render the session immediately. The
+// caller holds emitMu.
+func (h *Hub) sendStateToLocked(c *Client) {
 	h.stateMu.RLock()
 	state := h.state
+	loc := h.stopLoc
 	h.stateMu.RUnlock()
 
-	evt, err := protocol.NewEvent(protocol.EventSessionState, h.seq.Add(1), protocol.SessionStatePayload{
-		SessionID: h.sessionID,
-		State:     state,
-		Clients:   h.registry.count(),
+	evt, err := protocol.NewEvent(protocol.EventSessionState, 0, protocol.SessionStatePayload{
+		SessionID:   h.sessionID,
+		State:       state,
+		Clients:     h.registry.count(),
+		Location:    loc,
+		Breakpoints: h.knownBreakpoints(),
 	})
 	if err != nil {
 		h.log.Error("failed to create welcome state event", "err", err)
 		return
 	}
-	wire, err := protocol.MarshalEvent(evt)
+	h.sendLocked(c, evt)
+}
+
+// broadcastGoroutineSnapshot enumerates goroutines on the just-suspended
+// process and broadcasts them, caching the result for late joiners. A failed
+// enumeration is logged and skipped: the snapshot is a convenience on top of
+// the suspending event, which clients have already received.
+func (h *Hub) broadcastGoroutineSnapshot(trigger protocol.EventKind) {
+	if h.dbg == nil {
+		return
+	}
+	goroutines, err := h.dbg.Goroutines()
 	if err != nil {
-		h.log.Error("failed to marshal welcome state event", "err", err)
+		h.log.Warn("goroutine snapshot failed", "trigger", trigger, "err", err)
 		return
 	}
-	if !c.deliver(wire) {
-		h.removeClient(c)
+	p := &protocol.GoroutineSnapshotPayload{Trigger: trigger, Goroutines: goroutines}
+	evt, err := protocol.NewEvent(protocol.EventGoroutineSnapshot, 0, p)
+	if err != nil {
+		h.log.Error("failed to create goroutine snapshot event", "err", err)
+		return
+	}
+	h.setLastSnapshot(p)
+	h.broadcast(evt)
+	h.checkGoroutineLeak(goroutines)
+}
+
+// resetSnapshots forgets the cached snapshot and the leak history: both
+// describe a process that is gone or about to be replaced.
+func (h *Hub) resetSnapshots() {
+	h.setLastSnapshot(nil)
+	h.leaks.reset()
+}
+
+func (h *Hub) setLastSnapshot(p *protocol.GoroutineSnapshotPayload) {
+	h.snapshotMu.Lock()
+	h.lastSnapshot = p
+	h.snapshotMu.Unlock()
+}
+
+// sendSnapshotToLocked replays the cached goroutine snapshot (if any) to a
+// single newly-joined client, re-stamped so it slots into the live seq
+// stream. The caller holds emitMu.
+func (h *Hub) sendSnapshotToLocked(c *Client) {
+	h.snapshotMu.Lock()
+	p := h.lastSnapshot
+	h.snapshotMu.Unlock()
+	if p == nil {
+		return
 	}
+
+	evt, err := protocol.NewEvent(protocol.EventGoroutineSnapshot, 0, p)
+	if err != nil {
+		h.log.Error("failed to create goroutine snapshot replay", "err", err)
+		return
+	}
+	h.sendLocked(c, evt)
 }
 
+// broadcast stamps evt with the next seq and queues it to every client.
+// Whatever Seq the caller set is overwritten.
 func (h *Hub) broadcast(evt protocol.Event) {
+	h.emitMu.Lock()
+	defer h.emitMu.Unlock()
+	h.broadcastLocked(evt)
+}
+
+func (h *Hub) broadcastLocked(evt protocol.Event) {
+	h.seq++
+	evt.Seq = h.seq
+	evt.Stamp(time.Now(), epoch)
 	wire, err := protocol.MarshalEvent(evt)
 	if err != nil {
 		h.log.Error("marshal event failed", "err", err)
 		return
 	}
 	for _, c := range h.registry.snapshot() {
+		if !c.wants(evt.Kind) {
+			continue
+		}
 		if !c.deliver(wire) {
 			h.removeClient(c)
 		}
 	}
 }
 
+// sendLocked queues evt to c alone. It carries the seq of the last
+// broadcast rather than a new one: every other client would otherwise see a
+// gap for an event that was never meant for them, and read it as a loss.
+func (h *Hub) sendLocked(c *Client, evt protocol.Event) {
+	evt.Seq = h.seq
+	evt.Stamp(time.Now(), epoch)
+	wire, err := protocol.MarshalEvent(evt)
+	if err != nil {
+		h.log.Error("marshal event failed", "err", err)
+		return
+	}
+	if !c.deliver(wire) {
+		h.removeClient(c)
+	}
+}
+
 func (h *Hub) broadcastError(kind protocol.CommandKind, err error) {
-	evt, e := protocol.NewEvent(protocol.EventError, h.seq.Add(1), protocol.ErrorPayload{
+	evt, e := protocol.NewEvent(protocol.EventError, 0, protocol.ErrorPayload{
 		Command: kind,
 		Message: err.Error(),
 	})
@@ -687,6 +1422,71 @@ func (h *Hub) broadcastError(kind protocol.CommandKind, err error) {
 	h.broadcast(evt)
 }
 
+// sendError tells client c alone that its command of kind failed, for a
+// failure that concerns nobody else.
+func (h *Hub) sendError(c *Client, kind protocol.CommandKind, err error) {
+	evt, e := protocol.NewEvent(protocol.EventError, 0, protocol.ErrorPayload{
+		Command: kind,
+		Message: err.Error(),
+	})
+	if e != nil {
+		h.log.Error("failed to marshal error event", "err", e, "cause", err)
+		return
+	}
+	h.emitMu.Lock()
+	defer h.emitMu.Unlock()
+	h.sendLocked(c, evt)
+}
+
+// resumePoint is where a resuming command of kind is about to leave from:
+// the session's last stop and the engine's PC. Call it before dispatch moves
+// the process.
+func (h *Hub) resumePoint(kind protocol.CommandKind) protocol.ResumedPayload {
+	p := protocol.ResumedPayload{Command: kind}
+	if st, err := h.dbg.Status(); err == nil {
+		p.PC = st.PC
+	}
+	h.stateMu.RLock()
+	if h.stopLoc != nil {
+		loc := *h.stopLoc
+		p.Location = &loc
+	}
+	h.stateMu.RUnlock()
+	return p
+}
+
+// broadcastResumed sends the EventResumed for a resuming command that has
+// been dispatched. It logs the resume too, so the session's log trace
+// (CmdLogs, the REST logs endpoint) has where each stop was left next to
+// where it was reached.
+func (h *Hub) broadcastResumed(p protocol.ResumedPayload) {
+	attrs := []any{"command", p.Command, "pc", fmt.Sprintf("%#x", p.PC)}
+	if p.Location != nil {
+		attrs = append(attrs, "file", p.Location.File, "line", p.Location.Line)
+	}
+	h.log.Info("resumed", attrs...)
+
+	evt, err := protocol.NewEvent(protocol.EventResumed, 0, p)
+	if err != nil {
+		h.log.Error("failed to marshal resumed event", "err", err)
+		return
+	}
+	h.broadcast(evt)
+}
+
+// broadcastNotice sends an EventNotice for a command that was a no-op.
+func (h *Hub) broadcastNotice(kind protocol.CommandKind, msg string) {
+	evt, err := protocol.NewEvent(protocol.EventNotice, 0, protocol.NoticePayload{
+		Command: kind,
+		Message: msg,
+	})
+	if err != nil {
+		h.log.Error("failed to marshal notice event", "err", err)
+		return
+	}
+	h.broadcast(evt)
+}
+
 // shutdown closes all clients and kills the debugger exactly once. Safe to
 // call concurrently from ctx.Done and last-client-disconnect.
 func (h *Hub) shutdown() {
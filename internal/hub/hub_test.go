@@ -5,7 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -31,15 +38,34 @@ type fakeDebugger struct {
 	attachErr        error
 	setBPResult      protocol.Breakpoint
 	setBPErr         error
+	setBPFileErrs    map[string]error // per-file SetBreakpoint failures
 	clearBPErr       error
+	ignoreErr        error
 	continueErr      error
 	stepOverErr      error
 	stepIntoErr      error
 	stepOutErr       error
 	pauseErr         error
 	localsResult     []protocol.Variable
+	argsResult       protocol.ArgsPayload
 	framesResult     []protocol.Frame
 	goroutinesResult []protocol.Goroutine
+	resolvePC        uint64
+	resolveLoc       protocol.Location
+	resolveErr       error
+	addrLoc          protocol.Location
+	toggleBPs        []protocol.Breakpoint
+	clearAllIDs      []int
+	stringResult     string
+	stringLen        uint64
+	sliceResult      protocol.SliceValuePayload
+	inputErr         error
+	statusResult     protocol.StatusPayload
+	goroutineCondErr error
+	statusErr        error
+	sourcePath       string
+	sourcePathErr    error
+	initialBPs       []protocol.Location
 }
 
 func newFakeDebugger() *fakeDebugger {
@@ -67,30 +93,117 @@ func (f *fakeDebugger) closeEvents() { close(f.events) }
 func (f *fakeDebugger) Events() <-chan protocol.Event { return f.events }
 func (f *fakeDebugger) Launch(p string, a []string, env []string) error {
 	f.record("Launch")
-	return f.launchErr
+	if f.launchErr != nil {
+		return f.launchErr
+	}
+	f.armInitialBreakpoints()
+	return nil
+}
+func (f *fakeDebugger) SetInitialBreakpoints(locs []protocol.Location) error {
+	f.record(fmt.Sprintf("SetInitialBreakpoints(%d)", len(locs)))
+	f.initialBPs = locs
+	return nil
+}
+
+// armInitialBreakpoints announces the SetInitialBreakpoints locations the
+// way the engine does from inside Launch and Attach.
+func (f *fakeDebugger) armInitialBreakpoints() {
+	for _, loc := range f.initialBPs {
+		bp, err := f.SetBreakpoint(loc.File, loc.Line)
+		if err != nil {
+			f.push(protocol.MustEvent(protocol.EventBreakpointError, 0,
+				protocol.BreakpointErrorPayload{Location: loc, Message: err.Error()}))
+			continue
+		}
+		f.push(protocol.MustEvent(protocol.EventBreakpointSet, 0, protocol.BreakpointSetPayload{Breakpoint: bp}))
+	}
+}
+func (f *fakeDebugger) SetInitialStop(mode protocol.InitialStop) error {
+	f.record(fmt.Sprintf("SetInitialStop(%s)", mode))
+	if !mode.Valid() {
+		return fmt.Errorf("unknown mode %q", mode)
+	}
+	return nil
 }
 func (f *fakeDebugger) Attach(pid int, binaryPath string) error {
 	f.record("Attach")
-	return f.attachErr
+	if f.attachErr != nil {
+		return f.attachErr
+	}
+	f.armInitialBreakpoints()
+	return nil
 }
 func (f *fakeDebugger) Kill() error     { f.record("Kill"); return nil }
 func (f *fakeDebugger) Continue() error { f.record("Continue"); return f.continueErr }
+func (f *fakeDebugger) ContinueThread() error {
+	f.record("ContinueThread")
+	return f.continueErr
+}
 func (f *fakeDebugger) StepOver() error { f.record("StepOver"); return f.stepOverErr }
 func (f *fakeDebugger) StepInto() error { f.record("StepInto"); return f.stepIntoErr }
 func (f *fakeDebugger) StepOut() error  { f.record("StepOut"); return f.stepOutErr }
 func (f *fakeDebugger) Pause() error    { f.record("Pause"); return f.pauseErr }
+func (f *fakeDebugger) StepInstruction() error {
+	f.record("StepInstruction")
+	return nil
+}
+func (f *fakeDebugger) StepOverN(n int) error {
+	f.record(fmt.Sprintf("StepOverN(%d)", n))
+	return f.stepOverErr
+}
+func (f *fakeDebugger) StepIntoN(n int) error {
+	f.record(fmt.Sprintf("StepIntoN(%d)", n))
+	return f.stepIntoErr
+}
 func (f *fakeDebugger) ClearBreakpoint(id int) error {
 	f.record("ClearBreakpoint")
 	return f.clearBPErr
 }
 func (f *fakeDebugger) SetBreakpoint(file string, line int) (protocol.Breakpoint, error) {
 	f.record("SetBreakpoint")
+	if err := f.setBPFileErrs[file]; err != nil {
+		return protocol.Breakpoint{}, err
+	}
 	return f.setBPResult, f.setBPErr
 }
+func (f *fakeDebugger) SetIgnoreCount(id, count int) (protocol.Breakpoint, error) {
+	f.record(fmt.Sprintf("SetIgnoreCount(%d, %d)", id, count))
+	if f.ignoreErr != nil {
+		return protocol.Breakpoint{}, f.ignoreErr
+	}
+	bp := f.setBPResult
+	bp.ID = id
+	bp.IgnoreCount = count
+	return bp, nil
+}
+func (f *fakeDebugger) ClearAllBreakpoints() ([]int, error) {
+	f.record("ClearAllBreakpoints")
+	return f.clearAllIDs, nil
+}
+func (f *fakeDebugger) DisableAllBreakpoints() ([]protocol.Breakpoint, error) {
+	f.record("DisableAllBreakpoints")
+	return f.toggled(false), nil
+}
+func (f *fakeDebugger) EnableAllBreakpoints() ([]protocol.Breakpoint, error) {
+	f.record("EnableAllBreakpoints")
+	return f.toggled(true), nil
+}
+func (f *fakeDebugger) toggled(enabled bool) []protocol.Breakpoint {
+	out := make([]protocol.Breakpoint, len(f.toggleBPs))
+	for i, bp := range f.toggleBPs {
+		bp.Enabled = enabled
+		out[i] = bp
+	}
+	return out
+}
 func (f *fakeDebugger) Locals(fi int) ([]protocol.Variable, error) {
 	f.record("Locals")
 	return f.localsResult, nil
 }
+func (f *fakeDebugger) InspectArgs() (protocol.ArgsPayload, error) {
+	f.record("InspectArgs")
+	return f.argsResult, nil
+}
 func (f *fakeDebugger) StackFrames() ([]protocol.Frame, error) {
 	f.record("StackFrames")
 	return f.framesResult, nil
@@ -99,6 +212,48 @@ func (f *fakeDebugger) Goroutines() ([]protocol.Goroutine, error) {
 	f.record("Goroutines")
 	return f.goroutinesResult, nil
 }
+func (f *fakeDebugger) SetGoroutineCondition(id int, cond *protocol.GoroutineCountCondition) (protocol.Breakpoint, error) {
+	f.record(fmt.Sprintf("SetGoroutineCondition(%d, %s)", id, cond))
+	if f.goroutineCondErr != nil {
+		return protocol.Breakpoint{}, f.goroutineCondErr
+	}
+	bp := f.setBPResult
+	bp.ID = id
+	bp.GoroutineCount = cond
+	return bp, nil
+}
+func (f *fakeDebugger) ResolveLine(file string, line int) (uint64, protocol.Location, error) {
+	f.record("ResolveLine")
+	return f.resolvePC, f.resolveLoc, f.resolveErr
+}
+func (f *fakeDebugger) ResolveFunction(function string, offset int, atReturn bool) (uint64, protocol.Location, error) {
+	f.record("ResolveFunction")
+	return f.resolvePC, f.resolveLoc, f.resolveErr
+}
+func (f *fakeDebugger) AddrToLine(pc uint64) (protocol.Location, error) {
+	f.record("AddrToLine")
+	return f.addrLoc, nil
+}
+func (f *fakeDebugger) ReadString(addr uint64) (string, uint64, error) {
+	f.record("ReadString")
+	return f.stringResult, f.stringLen, nil
+}
+func (f *fakeDebugger) WriteInput(data string) error {
+	f.record(fmt.Sprintf("WriteInput(%q)", data))
+	return f.inputErr
+}
+func (f *fakeDebugger) Status() (protocol.StatusPayload, error) {
+	f.record("Status")
+	return f.statusResult, f.statusErr
+}
+func (f *fakeDebugger) SourcePath(file string) (string, error) {
+	f.record("SourcePath")
+	return f.sourcePath, f.sourcePathErr
+}
+func (f *fakeDebugger) ReadSlice(addr uint64, elemSize int) (protocol.SliceValuePayload, error) {
+	f.record(fmt.Sprintf("ReadSlice(%d)", elemSize))
+	return f.sliceResult, nil
+}
 
 type fakeWSConn struct {
 	mu       sync.Mutex
@@ -127,6 +282,12 @@ func (f *fakeWSConn) recv() ([]byte, bool) {
 
 func (f *fakeWSConn) inject(cmd protocol.Command) {
 	data, _ := json.Marshal(cmd)
+	f.injectRaw(data)
+}
+
+// injectRaw queues data as sent, for messages json.Marshal of a Command
+// can't produce, such as one with no payload field at all.
+func (f *fakeWSConn) injectRaw(data []byte) {
 	f.mu.Lock()
 	closed := f.closed
 	f.mu.Unlock()
@@ -179,6 +340,12 @@ func mustCommand(kind protocol.CommandKind, payload any) protocol.Command {
 	return protocol.Command{Version: protocol.Version, Kind: kind, Payload: raw}
 }
 
+// bareCommand is a command message with no payload field, as a minimal
+// client writes one by hand.
+func bareCommand(kind protocol.CommandKind) []byte {
+	return []byte(fmt.Sprintf(`{"v":%q,"kind":%q}`, protocol.Version, kind))
+}
+
 func decodeEvent(data []byte) protocol.Event {
 	var evt protocol.Event
 	ExpectWithOffset(1, json.Unmarshal(data, &evt)).To(Succeed())
@@ -290,6 +457,46 @@ var _ = Describe("Hub", func() {
 				return count
 			}, "500ms", "10ms").Should(Equal(2))
 		})
+		It("starts one debugger when two clients race Launch", func() {
+			var factoryCalls atomic.Int32
+			managed := hub.NewSession("session", func() debugger.Debugger {
+				factoryCalls.Add(1)
+				return fd
+			}, nil)
+			cancelManaged := runHub(managed)
+			defer cancelManaged()
+
+			conn1, conn2 := newFakeWSConn(), newFakeWSConn()
+			managed.AddClient(conn1, nil)
+			managed.AddClient(conn2, nil)
+
+			launch := mustCommand(protocol.CmdLaunch, protocol.LaunchPayload{Program: "target"})
+			go conn1.inject(launch)
+			go conn2.inject(launch)
+
+			Eventually(func() bool {
+				for {
+					e, ok := recvEvent(conn1)
+					if !ok {
+						return false
+					}
+					if e.Kind != protocol.EventError {
+						continue
+					}
+					var p protocol.ErrorPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					return p.Command == protocol.CmdLaunch
+				}
+			}, "1s", "10ms").Should(BeTrue())
+			Expect(factoryCalls.Load()).To(Equal(int32(1)))
+			launches := 0
+			for _, call := range fd.recordedCalls() {
+				if call == "Launch" {
+					launches++
+				}
+			}
+			Expect(launches).To(Equal(1))
+		})
 	})
 
 	Describe("event sequence numbers", func() {
@@ -314,6 +521,21 @@ var _ = Describe("Hub", func() {
 				"hub seq must be strictly increasing")
 		})
 
+		It("stamps every outbound event with wall-clock and monotonic time", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+
+			before := time.Now()
+			fd.push(protocol.MustEvent(protocol.EventOutput, 1, protocol.OutputPayload{Content: "a"}))
+			fd.push(protocol.MustEvent(protocol.EventOutput, 2, protocol.OutputPayload{Content: "b"}))
+			e1, _ := recvEvent(conn)
+			e2, _ := recvEvent(conn)
+
+			Expect(e1.Time).NotTo(BeTemporally("<", before.Truncate(time.Millisecond)))
+			Expect(e1.Mono).To(BeNumerically(">", 0))
+			Expect(e2.Mono).To(BeNumerically(">=", e1.Mono))
+		})
+
 		It("interleaves debugger events and confirmation events in a single seq stream", func() {
 			conn := newFakeWSConn()
 			h.AddClient(conn, nil)
@@ -385,6 +607,62 @@ var _ = Describe("Hub", func() {
 				Should(ContainElement("Continue"))
 		})
 
+		It("announces where a resume leaves from before resuming", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.statusResult = protocol.StatusPayload{Process: protocol.StateSuspended, PC: 0x401000, TID: 7}
+			stop := protocol.Location{File: "main.go", Line: 12, Function: "main.main"}
+
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1, Location: stop}}))
+			waitForEventKind(conn, protocol.EventBreakpointHit, nil)
+
+			conn.inject(mustCommand(protocol.CmdStepOver, struct{}{}))
+
+			var p protocol.ResumedPayload
+			waitForEventKind(conn, protocol.EventResumed, &p)
+			Expect(p.Command).To(Equal(protocol.CmdStepOver))
+			Expect(p.PC).To(Equal(uint64(0x401000)))
+			Expect(p.Location).To(Equal(&stop))
+			Eventually(fd.recordedCalls, "500ms", "10ms").Should(ContainElement("StepOver"))
+			calls := fd.recordedCalls()
+			Expect(slices.Index(calls, "Status")).To(BeNumerically("<", slices.Index(calls, "StepOver")))
+
+			conn.inject(mustCommand(protocol.CmdLogs, protocol.LogsPayloadCmd{}))
+			var logs protocol.LogsPayload
+			waitForEventKind(conn, protocol.EventLogs, &logs)
+			i := slices.IndexFunc(logs.Entries, func(e protocol.LogEntry) bool { return e.Message == "resumed" })
+			Expect(i).NotTo(Equal(-1), "no resumed entry in the session log")
+			Expect(logs.Entries[i].Attrs).To(HaveKeyWithValue("command", "StepOver"))
+			Expect(logs.Entries[i].Attrs).To(HaveKeyWithValue("line", "12"))
+		})
+
+		It("announces no resume when the resuming command fails", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.continueErr = errors.New("engine refused")
+
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+			waitForEventKind(conn, protocol.EventBreakpointHit, nil)
+
+			conn.inject(mustCommand(protocol.CmdContinue, nil))
+			var kinds []protocol.EventKind
+			Eventually(func() []protocol.EventKind {
+				if e, ok := recvEvent(conn); ok {
+					kinds = append(kinds, e.Kind)
+				}
+				return kinds
+			}, "500ms", "5ms").Should(ContainElement(protocol.EventError))
+			Expect(kinds).NotTo(ContainElement(protocol.EventResumed))
+			Expect(h.State()).To(Equal(protocol.StateSuspended))
+
+			conn.inject(mustCommand(protocol.CmdLogs, protocol.LogsPayloadCmd{}))
+			var logs protocol.LogsPayload
+			waitForEventKind(conn, protocol.EventLogs, &logs)
+			Expect(logs.Entries).NotTo(ContainElement(HaveField("Message", "resumed")))
+		})
+
 		It("accepts StepOver as a resuming command", func() {
 			conn := newFakeWSConn()
 			h.AddClient(conn, nil)
@@ -413,6 +691,65 @@ var _ = Describe("Hub", func() {
 				Should(ContainElement("StepInto"))
 		})
 
+		It("accepts StepInstruction as a resuming command", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+			_, _ = recvEvent(conn)
+
+			conn.inject(mustCommand(protocol.CmdStepInstruction, struct{}{}))
+
+			Eventually(fd.recordedCalls, "500ms", "10ms").
+				Should(ContainElement("StepInstruction"))
+		})
+
+		It("passes a step count through to the debugger", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+			_, _ = recvEvent(conn)
+
+			conn.inject(mustCommand(protocol.CmdStepOver, protocol.StepPayload{Count: 3}))
+
+			Eventually(fd.recordedCalls, "500ms", "10ms").
+				Should(ContainElement("StepOverN(3)"))
+		})
+
+		It("continues just the stopped thread for scope thread", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+			_, _ = recvEvent(conn)
+
+			conn.inject(mustCommand(protocol.CmdContinue, protocol.ContinuePayload{Scope: protocol.ScopeThread}))
+
+			Eventually(fd.recordedCalls, "500ms", "10ms").
+				Should(ContainElement("ContinueThread"))
+			Expect(fd.recordedCalls()).NotTo(ContainElement("Continue"))
+		})
+
+		It("rejects an unknown continue scope and stays suspended", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+			_, _ = recvEvent(conn)
+
+			conn.inject(mustCommand(protocol.CmdContinue, protocol.ContinuePayload{Scope: "process"}))
+
+			var p protocol.ErrorPayload
+			waitForEventKind(conn, protocol.EventError, &p)
+			Expect(p.Message).To(ContainSubstring(`unknown scope "process"`))
+			Expect(fd.recordedCalls()).NotTo(ContainElements("Continue", "ContinueThread"))
+		})
+
 		It("accepts StepOut as a resuming command", func() {
 			conn := newFakeWSConn()
 			h.AddClient(conn, nil)
@@ -473,6 +810,25 @@ var _ = Describe("Hub", func() {
 			}, "500ms", "10ms").Should(Equal(protocol.EventLocals))
 		})
 
+		It("answers InspectArgs with the stopped function's arguments", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.argsResult = protocol.ArgsPayload{
+				Function: "main.gamma",
+				Args:     []protocol.Variable{{Name: "n", Type: "int", Value: "7"}},
+			}
+
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+			_, _ = recvEvent(conn)
+
+			conn.inject(mustCommand(protocol.CmdInspectArgs, struct{}{}))
+
+			var p protocol.ArgsPayload
+			waitForEventKind(conn, protocol.EventArgs, &p)
+			Expect(p).To(Equal(fd.argsResult))
+		})
+
 		It("only the first resuming command wins when multiple clients race", func() {
 			conn1 := newFakeWSConn()
 			conn2 := newFakeWSConn()
@@ -663,6 +1019,29 @@ var _ = Describe("Hub", func() {
 			Eventually(fd.recordedCalls, "500ms", "10ms").
 				Should(ContainElement("Continue"))
 		})
+
+		It("answers Pause while suspended with a notice, not an error", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+			_, _ = recvEvent(conn)
+
+			conn.inject(mustCommand(protocol.CmdPause, struct{}{}))
+			var p protocol.NoticePayload
+			waitForEventKind(conn, protocol.EventNotice, &p)
+			Expect(p.Command).To(Equal(protocol.CmdPause))
+			Expect(fd.recordedCalls()).NotTo(ContainElement("Pause"))
+		})
+
+		It("turns the engine's ErrNotRunning into a notice", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.pauseErr = debugger.ErrNotRunning
+
+			conn.inject(mustCommand(protocol.CmdPause, struct{}{}))
+			waitForEventKind(conn, protocol.EventNotice, nil)
+		})
 	})
 
 	Describe("SetBreakpoint confirmation", func() {
@@ -705,6 +1084,201 @@ var _ = Describe("Hub", func() {
 		})
 	})
 
+	Describe("ResolveLine", func() {
+		It("answers with the PC and the line actually resolved", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.resolvePC = 0x401000
+			fd.resolveLoc = protocol.Location{File: "main.go", Line: 12, Function: "main.main"}
+
+			conn.inject(mustCommand(protocol.CmdResolveLine,
+				protocol.ResolveLinePayload{File: "main.go", Line: 10}))
+
+			var evt protocol.Event
+			Eventually(func() protocol.EventKind {
+				e, ok := recvEvent(conn)
+				if !ok {
+					return ""
+				}
+				evt = e
+				return e.Kind
+			}, "500ms", "10ms").Should(Equal(protocol.EventLineResolved))
+
+			var p protocol.LineResolvedPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.File).To(Equal("main.go"))
+			Expect(p.Line).To(Equal(10))
+			Expect(p.PC).To(Equal(uint64(0x401000)))
+			Expect(p.Location.Line).To(Equal(12))
+		})
+
+		It("resolves a line named relative to a function", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.resolvePC = 0x401020
+			fd.resolveLoc = protocol.Location{File: "main.go", Line: 23, Function: "main.worker"}
+
+			conn.inject(mustCommand(protocol.CmdResolveLine,
+				protocol.ResolveLinePayload{Function: "main.worker", Offset: 3}))
+
+			var p protocol.LineResolvedPayload
+			waitForEventKind(conn, protocol.EventLineResolved, &p)
+			Expect(p.PC).To(Equal(uint64(0x401020)))
+			Expect(p.Location.Function).To(Equal("main.worker"))
+			Expect(fd.recordedCalls()).To(ContainElement("ResolveFunction"))
+			Expect(fd.recordedCalls()).NotTo(ContainElement("ResolveLine"))
+		})
+
+		It("refuses a negative offset", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+
+			conn.inject(mustCommand(protocol.CmdResolveLine,
+				protocol.ResolveLinePayload{Function: "main.worker", Offset: -1}))
+
+			var p protocol.ErrorPayload
+			waitForEventKind(conn, protocol.EventError, &p)
+			Expect(p.Message).To(ContainSubstring("offset -1 is negative"))
+			Expect(fd.recordedCalls()).NotTo(ContainElement("ResolveFunction"))
+		})
+	})
+
+	Describe("AddrToLine", func() {
+		It("answers with the source location of the address", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.addrLoc = protocol.Location{File: "main.go", Line: 7, Function: "main.main"}
+
+			conn.inject(mustCommand(protocol.CmdAddrToLine, protocol.AddrToLinePayload{PC: 0x401000}))
+
+			var evt protocol.Event
+			Eventually(func() protocol.EventKind {
+				e, ok := recvEvent(conn)
+				if !ok {
+					return ""
+				}
+				evt = e
+				return e.Kind
+			}, "500ms", "10ms").Should(Equal(protocol.EventAddrResolved))
+
+			var p protocol.AddrResolvedPayload
+			Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+			Expect(p.PC).To(Equal(uint64(0x401000)))
+			Expect(p.Location).To(Equal(fd.addrLoc))
+		})
+	})
+
+	Describe("GetFile", func() {
+		const src = "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"
+
+		var conn *fakeWSConn
+
+		getFile := func(p protocol.GetFilePayload) protocol.FileContentsPayload {
+			conn.inject(mustCommand(protocol.CmdGetFile, p))
+			var out protocol.FileContentsPayload
+			waitForEventKind(conn, protocol.EventFileContents, &out)
+			return out
+		}
+
+		BeforeEach(func() {
+			fd.sourcePath = filepath.Join(GinkgoT().TempDir(), "main.go")
+			Expect(os.WriteFile(fd.sourcePath, []byte(src), 0o600)).To(Succeed())
+			conn = newFakeWSConn()
+			h.AddClient(conn, nil)
+		})
+
+		It("returns a small file whole", func() {
+			p := getFile(protocol.GetFilePayload{File: "main.go"})
+			Expect(p).To(Equal(protocol.FileContentsPayload{
+				File: "main.go", Path: fd.sourcePath, Data: src, Size: int64(len(src)),
+			}))
+		})
+
+		It("pages through a file in chunks that end on a line", func() {
+			var got strings.Builder
+			var offset int64
+			for {
+				p := getFile(protocol.GetFilePayload{File: "main.go", Offset: offset, Limit: 16})
+				Expect(p.Offset).To(Equal(offset))
+				got.WriteString(p.Data)
+				if !p.More {
+					break
+				}
+				Expect(p.Data).To(HaveSuffix("\n"))
+				offset += int64(len(p.Data))
+			}
+			Expect(got.String()).To(Equal(src))
+		})
+
+		It("refuses a file over the size limit", func() {
+			Expect(os.Truncate(fd.sourcePath, 9<<20)).To(Succeed())
+			conn.inject(mustCommand(protocol.CmdGetFile, protocol.GetFilePayload{File: "main.go"}))
+			var p protocol.ErrorPayload
+			waitForEventKind(conn, protocol.EventError, &p)
+			Expect(p.Message).To(ContainSubstring("limit"))
+		})
+	})
+
+	Describe("ReadString", func() {
+		It("answers with the value and flags a truncated read", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.stringResult = "hello"
+			fd.stringLen = 1 << 20
+
+			conn.inject(mustCommand(protocol.CmdReadString, protocol.ReadStringPayload{Addr: 0xc000010000}))
+
+			var p protocol.StringValuePayload
+			waitForEventKind(conn, protocol.EventStringValue, &p)
+			Expect(p.Addr).To(Equal(uint64(0xc000010000)))
+			Expect(p.Value).To(Equal("hello"))
+			Expect(p.Len).To(Equal(uint64(1 << 20)))
+			Expect(p.Truncated).To(BeTrue())
+		})
+	})
+
+	Describe("ReadSlice", func() {
+		It("passes the element size through and answers with the slice", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.sliceResult = protocol.SliceValuePayload{
+				Addr: 0xc000010000, Data: 0xc000020000, Len: 2, Cap: 4, ElemSize: 8,
+				Elements: []string{"0100000000000000", "0200000000000000"},
+			}
+
+			conn.inject(mustCommand(protocol.CmdReadSlice, protocol.ReadSlicePayload{Addr: 0xc000010000, ElemSize: 8}))
+
+			var p protocol.SliceValuePayload
+			waitForEventKind(conn, protocol.EventSliceValue, &p)
+			Expect(p).To(Equal(fd.sliceResult))
+			Expect(fd.recordedCalls()).To(ContainElement("ReadSlice(8)"))
+		})
+	})
+
+	Describe("Input", func() {
+		It("hands the text to the debugger while the process runs", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+
+			conn.inject(mustCommand(protocol.CmdInput, protocol.InputPayload{Data: "yes\n"}))
+
+			Eventually(fd.recordedCalls, "500ms", "10ms").
+				Should(ContainElement(`WriteInput("yes\n")`))
+		})
+
+		It("reports a process without a terminal as an error", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.inputErr = debugger.ErrNoTerminal
+
+			conn.inject(mustCommand(protocol.CmdInput, protocol.InputPayload{Data: "x"}))
+
+			var p protocol.ErrorPayload
+			waitForEventKind(conn, protocol.EventError, &p)
+			Expect(p.Command).To(Equal(protocol.CmdInput))
+		})
+	})
+
 	Describe("command error propagation", func() {
 		It("broadcasts EventError when a command fails", func() {
 			conn := newFakeWSConn()
@@ -803,36 +1377,101 @@ var _ = Describe("Hub", func() {
 				return e.Kind
 			}, "500ms", "10ms").Should(Equal(protocol.EventProcessExited))
 		})
-	})
 
-	Describe("unknown command kind", func() {
-		It("broadcasts EventError without panicking", func() {
+		It("records the exit and the last stop as the session's outcome", func() {
 			conn := newFakeWSConn()
 			h.AddClient(conn, nil)
 
-			conn.inject(protocol.Command{
-				Version: protocol.Version,
-				Kind:    protocol.CommandKind("UnknownCmd"),
-			})
+			loc := protocol.Location{File: "main.go", Line: 12, Function: "main.main"}
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1, Location: loc}}))
+			_, _ = recvEvent(conn)
+			fd.push(protocol.MustEvent(protocol.EventProcessExited, 2,
+				protocol.ProcessExitedPayload{ExitCode: 3, Reason: "exited"}))
 
-			Eventually(func() protocol.EventKind {
-				e, ok := recvEvent(conn)
-				if !ok {
-					return ""
-				}
-				return e.Kind
-			}, "500ms", "10ms").Should(Equal(protocol.EventError))
+			Eventually(func() *protocol.ProcessExitedPayload { return h.Outcome().Exit }, "500ms", "10ms").
+				Should(Equal(&protocol.ProcessExitedPayload{ExitCode: 3, Reason: "exited"}))
+			Expect(h.Outcome().LastStop).To(Equal(&loc))
 		})
 	})
-})
 
-// newManagedRestartHub starts a managed session backed by fd, connects one
-// client, and drains the initial welcome/state event. The returned cancel
-// must be deferred by the caller.
-func newManagedRestartHub(fd *fakeDebugger) (*hub.Hub, *fakeWSConn, context.CancelFunc) {
-	managed := hub.NewSession("session", func() debugger.Debugger { return fd }, nil)
-	cancel := runHub(managed)
-	conn := newFakeWSConn()
+	Describe("commands without a payload", func() {
+		It("runs them with the payload's defaults", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+			fd.localsResult = []protocol.Variable{{Name: "x", Value: "1"}}
+
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+			waitForEventKind(conn, protocol.EventBreakpointHit, nil)
+
+			// Locals decodes a payload and used to reject a missing one.
+			conn.injectRaw(bareCommand(protocol.CmdLocals))
+			var locals protocol.LocalsPayload
+			waitForEventKind(conn, protocol.EventLocals, &locals)
+			Expect(locals.FrameIndex).To(Equal(0))
+			Expect(locals.Variables).To(HaveLen(1))
+
+			conn.injectRaw(bareCommand(protocol.CmdStepOver))
+			Eventually(fd.recordedCalls, "500ms", "10ms").Should(ContainElement("StepOver"))
+			Expect(fd.recordedCalls()).NotTo(ContainElement(HavePrefix("StepOverN")))
+		})
+
+		It("resumes on a bare Continue", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+
+			fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+				protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+			waitForEventKind(conn, protocol.EventBreakpointHit, nil)
+
+			conn.injectRaw(bareCommand(protocol.CmdContinue))
+			Eventually(fd.recordedCalls, "500ms", "10ms").Should(ContainElement("Continue"))
+		})
+
+		It("rejects a bare Launch or SetBreakpoint instead of running it", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+
+			for _, kind := range []protocol.CommandKind{protocol.CmdLaunch, protocol.CmdSetBreakpoint} {
+				conn.injectRaw(bareCommand(kind))
+				var p protocol.ErrorPayload
+				waitForEventKind(conn, protocol.EventError, &p)
+				Expect(p.Message).To(ContainSubstring("missing payload"))
+			}
+			Expect(fd.recordedCalls()).NotTo(ContainElement(HavePrefix("Launch")))
+			Expect(fd.recordedCalls()).NotTo(ContainElement(HavePrefix("SetBreakpoint")))
+		})
+	})
+
+	Describe("unknown command kind", func() {
+		It("broadcasts EventError without panicking", func() {
+			conn := newFakeWSConn()
+			h.AddClient(conn, nil)
+
+			conn.inject(protocol.Command{
+				Version: protocol.Version,
+				Kind:    protocol.CommandKind("UnknownCmd"),
+			})
+
+			Eventually(func() protocol.EventKind {
+				e, ok := recvEvent(conn)
+				if !ok {
+					return ""
+				}
+				return e.Kind
+			}, "500ms", "10ms").Should(Equal(protocol.EventError))
+		})
+	})
+})
+
+// newManagedRestartHub starts a managed session backed by fd, connects one
+// client, and drains the initial welcome/state event. The returned cancel
+// must be deferred by the caller.
+func newManagedRestartHub(fd *fakeDebugger) (*hub.Hub, *fakeWSConn, context.CancelFunc) {
+	managed := hub.NewSession("session", func() debugger.Debugger { return fd }, nil)
+	cancel := runHub(managed)
+	conn := newFakeWSConn()
 	managed.AddClient(conn, nil)
 	_, _ = recvEvent(conn) // welcome/state event
 	return managed, conn, cancel
@@ -954,6 +1593,92 @@ var _ = Describe("Restart", func() {
 		Expect(restarted.Discarded[0].Reason).To(Equal("no such line"))
 	})
 
+	It("reports each entry of a SetBreakpoints batch and reinstalls the ones set", func() {
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		fd.setBPResult = protocol.Breakpoint{ID: 1, Location: protocol.Location{File: "main.go", Line: 10}}
+		fd.setBPFileErrs = map[string]error{"ghost.go": fmt.Errorf("no code at ghost.go:3")}
+		conn.inject(mustCommand(protocol.CmdSetBreakpoints, protocol.SetBreakpointsPayload{
+			Breakpoints: []protocol.SetBreakpointPayload{
+				{File: "main.go", Line: 10, Actions: []string{"print x"}},
+				{File: "ghost.go", Line: 3},
+				{File: "main.go", Line: 11, Actions: []string{"jump"}},
+			},
+		}))
+		var set protocol.BreakpointsSetPayload
+		waitForEventKind(conn, protocol.EventBreakpointsSet, &set)
+
+		Expect(set.Results).To(HaveLen(3))
+		Expect(set.Results[0].Error).To(BeEmpty())
+		Expect(set.Results[0].Breakpoint).NotTo(BeNil())
+		Expect(set.Results[0].Breakpoint.Actions).To(Equal([]string{"print x"}))
+		Expect(set.Results[1].File).To(Equal("ghost.go"))
+		Expect(set.Results[1].Breakpoint).To(BeNil())
+		Expect(set.Results[1].Error).To(ContainSubstring("no code"))
+		Expect(set.Results[2].Line).To(Equal(11))
+		Expect(set.Results[2].Breakpoint).To(BeNil())
+		Expect(set.Results[2].Error).To(ContainSubstring("jump"))
+		Expect(countCalls(fd.recordedCalls(), "SetBreakpoint")).To(Equal(2),
+			"an entry with invalid actions must not reach the debugger")
+
+		conn.inject(mustCommand(protocol.CmdRestart, protocol.RestartPayload{}))
+		var restarted protocol.RestartedPayload
+		waitForEventKind(conn, protocol.EventRestarted, &restarted)
+		Expect(restarted.Breakpoints).To(HaveLen(1))
+	})
+
+	It("reports a repeated set as already set, keeping the first one's actions", func() {
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		fd.setBPResult = protocol.Breakpoint{ID: 1, Location: protocol.Location{File: "main.go", Line: 10}}
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{
+			File: "main.go", Line: 10, Actions: []string{"print x"},
+		}))
+		waitForEventKind(conn, protocol.EventBreakpointSet, nil)
+
+		fd.setBPErr = fmt.Errorf("%w at main.go:10 (breakpoint 1)", debugger.ErrBreakpointExists)
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{
+			File: "main.go", Line: 10, Actions: []string{"continue"},
+		}))
+		var again protocol.BreakpointSetPayload
+		waitForEventKind(conn, protocol.EventBreakpointSet, &again)
+		Expect(again.AlreadySet).To(BeTrue())
+		Expect(again.Breakpoint.ID).To(Equal(1))
+		Expect(again.Breakpoint.Actions).To(Equal([]string{"print x"}))
+
+		conn.inject(mustCommand(protocol.CmdSetBreakpoints, protocol.SetBreakpointsPayload{
+			Breakpoints: []protocol.SetBreakpointPayload{{File: "main.go", Line: 10}},
+		}))
+		var batch protocol.BreakpointsSetPayload
+		waitForEventKind(conn, protocol.EventBreakpointsSet, &batch)
+		Expect(batch.Results).To(HaveLen(1))
+		Expect(batch.Results[0].AlreadySet).To(BeTrue())
+		Expect(batch.Results[0].Error).To(BeEmpty())
+		Expect(batch.Results[0].Breakpoint.Actions).To(Equal([]string{"print x"}))
+
+		fd.setBPErr = nil
+		conn.inject(mustCommand(protocol.CmdRestart, protocol.RestartPayload{}))
+		var restarted protocol.RestartedPayload
+		waitForEventKind(conn, protocol.EventRestarted, &restarted)
+		Expect(restarted.Breakpoints).To(HaveLen(1))
+		Expect(restarted.Breakpoints[0].Actions).To(Equal([]string{"print x"}))
+	})
+
+	It("rejects an empty SetBreakpoints batch", func() {
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		conn.inject(mustCommand(protocol.CmdSetBreakpoints, protocol.SetBreakpointsPayload{}))
+		var errp protocol.ErrorPayload
+		waitForEventKind(conn, protocol.EventError, &errp)
+		Expect(errp.Command).To(Equal(protocol.CmdSetBreakpoints))
+	})
+
 	It("unblocks a suspended hub, same as Kill", func() {
 		_, conn, cancel := newManagedRestartHub(fd)
 		defer cancel()
@@ -968,6 +1693,51 @@ var _ = Describe("Restart", func() {
 	})
 })
 
+var _ = Describe("Initial stop", func() {
+	var fd *fakeDebugger
+
+	BeforeEach(func() {
+		fd = newFakeDebugger()
+	})
+
+	It("hands a launch's initial stop to the debugger, and again on Restart", func() {
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+
+		conn.inject(mustCommand(protocol.CmdLaunch, protocol.LaunchPayload{
+			Program: "myapp", InitialStop: protocol.InitialStopRun,
+		}))
+		Eventually(fd.recordedCalls, "500ms", "10ms").Should(ContainElement("Launch"))
+		Expect(fd.recordedCalls()).To(Equal([]string{"SetInitialStop(run)", "Launch"}))
+
+		conn.inject(mustCommand(protocol.CmdRestart, protocol.RestartPayload{}))
+		waitForEventKind(conn, protocol.EventRestarted, nil)
+		Expect(countCalls(fd.recordedCalls(), "SetInitialStop(run)")).To(Equal(2))
+	})
+
+	It("leaves the debugger's own mode alone when a launch names none", func() {
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		Expect(fd.recordedCalls()).To(Equal([]string{"Launch"}))
+	})
+
+	It("fails a launch with an unknown initial stop without launching", func() {
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+
+		conn.inject(mustCommand(protocol.CmdLaunch, protocol.LaunchPayload{
+			Program: "myapp", InitialStop: "later",
+		}))
+		var errp protocol.ErrorPayload
+		waitForEventKind(conn, protocol.EventError, &errp)
+		Expect(errp.Command).To(Equal(protocol.CmdLaunch))
+		Expect(errp.Message).To(ContainSubstring(`"later"`))
+		Expect(fd.recordedCalls()).NotTo(ContainElement("Launch"))
+	})
+})
+
 // This suite guards Finding 3 of #78: h.dbg is written on the Run goroutine
 // (Launch/Restart) but read by shutdown(), which runs on a separate goroutine
 // when the last client disconnects. Run under -race, the loop exercises that
@@ -992,3 +1762,1386 @@ var _ = Describe("dbg access during shutdown", func() {
 		}
 	})
 })
+
+var _ = Describe("goroutine snapshots", func() {
+	var fd *fakeDebugger
+
+	BeforeEach(func() {
+		fd = newFakeDebugger()
+		fd.goroutinesResult = []protocol.Goroutine{
+			{ID: 1, Status: "running", CurrentLoc: protocol.Location{File: "main.go", Line: 10}},
+			{ID: 7, Status: "waiting", CurrentLoc: protocol.Location{File: "worker.go", Line: 3}},
+		}
+	})
+
+	startHub := func(opts hub.Options) (*hub.Hub, context.CancelFunc) {
+		h := hub.New(fd, nil)
+		h.Configure(opts)
+		return h, runHub(h)
+	}
+
+	It("is off by default", func() {
+		h, cancel := startHub(hub.Options{})
+		defer cancel()
+		conn := newFakeWSConn()
+		h.AddClient(conn, nil)
+
+		fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+			protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+		e, ok := recvEvent(conn)
+		Expect(ok).To(BeTrue())
+		Expect(e.Kind).To(Equal(protocol.EventBreakpointHit))
+
+		_, ok = recvEvent(conn)
+		Expect(ok).To(BeFalse(), "no snapshot should follow the hit")
+		Expect(fd.recordedCalls()).NotTo(ContainElement("Goroutines"))
+	})
+
+	It("broadcasts a snapshot after each suspending event", func() {
+		h, cancel := startHub(hub.Options{GoroutineSnapshots: true})
+		defer cancel()
+		conn := newFakeWSConn()
+		h.AddClient(conn, nil)
+
+		fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+			protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+		hit, _ := recvEvent(conn)
+		Expect(hit.Kind).To(Equal(protocol.EventBreakpointHit))
+
+		snap, ok := recvEvent(conn)
+		Expect(ok).To(BeTrue())
+		Expect(snap.Kind).To(Equal(protocol.EventGoroutineSnapshot))
+		Expect(snap.Seq).To(BeNumerically(">", hit.Seq))
+
+		var p protocol.GoroutineSnapshotPayload
+		Expect(protocol.DecodeEventPayload(snap, &p)).To(Succeed())
+		Expect(p.Trigger).To(Equal(protocol.EventBreakpointHit))
+		Expect(p.Goroutines).To(Equal(fd.goroutinesResult))
+	})
+
+	It("replays the last snapshot to a client that joins later", func() {
+		h, cancel := startHub(hub.Options{GoroutineSnapshots: true})
+		defer cancel()
+		first := newFakeWSConn()
+		h.AddClient(first, nil)
+
+		fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+			protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+		waitForEventKind(first, protocol.EventGoroutineSnapshot, nil)
+
+		late := newFakeWSConn()
+		h.AddClient(late, nil)
+		var p protocol.GoroutineSnapshotPayload
+		waitForEventKind(late, protocol.EventGoroutineSnapshot, &p)
+		Expect(p.Goroutines).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("goroutine leak detection", func() {
+	var (
+		fd   *fakeDebugger
+		conn *fakeWSConn
+	)
+
+	BeforeEach(func() {
+		fd = newFakeDebugger()
+		h := hub.New(fd, nil)
+		h.Configure(hub.Options{GoroutineSnapshots: true, LeakThreshold: 3, LeakWindow: 3})
+		cancel := runHub(h)
+		DeferCleanup(cancel)
+		conn = newFakeWSConn()
+		h.AddClient(conn, nil)
+	})
+
+	// stopWith suspends the process with n live goroutines, resumes it once
+	// the snapshot is out, and returns the kinds of everything broadcast for
+	// the stop. goroutinesResult is only written after the hub has resumed,
+	// while it waits for the next stop, so it is never read concurrently.
+	stopWith := func(n int) []protocol.EventKind {
+		fd.goroutinesResult = make([]protocol.Goroutine, n)
+		fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+			protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+		var kinds []protocol.EventKind
+		for {
+			e, ok := recvEvent(conn)
+			Expect(ok).To(BeTrue(), "events so far: %v", kinds)
+			kinds = append(kinds, e.Kind)
+			if e.Kind == protocol.EventGoroutineSnapshot {
+				break
+			}
+		}
+		continues := countCalls(fd.recordedCalls(), "Continue")
+		conn.inject(mustCommand(protocol.CmdContinue, struct{}{}))
+		Eventually(func() int { return countCalls(fd.recordedCalls(), "Continue") }).Should(Equal(continues + 1))
+		// The leak warning, if any, was queued before the resume was taken.
+		for {
+			select {
+			case msg := <-conn.incoming:
+				kinds = append(kinds, decodeEvent(msg).Kind)
+			default:
+				return kinds
+			}
+		}
+	}
+
+	It("warns once the count only grows by the threshold across the window", func() {
+		Expect(stopWith(2)).NotTo(ContainElement(protocol.EventGoroutineLeak))
+		Expect(stopWith(4)).NotTo(ContainElement(protocol.EventGoroutineLeak))
+		Expect(stopWith(5)).To(ContainElement(protocol.EventGoroutineLeak))
+	})
+
+	It("stays quiet when the count dips inside the window", func() {
+		Expect(stopWith(2)).NotTo(ContainElement(protocol.EventGoroutineLeak))
+		Expect(stopWith(1)).NotTo(ContainElement(protocol.EventGoroutineLeak))
+		Expect(stopWith(8)).NotTo(ContainElement(protocol.EventGoroutineLeak))
+	})
+})
+
+// stallConn is a WSConn whose reads and writes block until it is closed, so
+// every message queued to it piles up in the client's send buffer until the
+// hub evicts it.
+type stallConn struct {
+	once sync.Once
+	done chan struct{}
+}
+
+func newStallConn() *stallConn { return &stallConn{done: make(chan struct{})} }
+
+func (s *stallConn) ReadMessage() (int, []byte, error) {
+	<-s.done
+	return 0, nil, &connClosedErr{}
+}
+
+func (s *stallConn) WriteMessage(int, []byte) error {
+	<-s.done
+	return &connClosedErr{}
+}
+
+func (s *stallConn) SetReadLimit(int64)                {}
+func (s *stallConn) SetReadDeadline(time.Time) error   { return nil }
+func (s *stallConn) SetWriteDeadline(time.Time) error  { return nil }
+func (s *stallConn) SetPongHandler(func(string) error) {}
+
+func (s *stallConn) Close() error {
+	s.once.Do(func() { close(s.done) })
+	return nil
+}
+
+var _ = Describe("client send channel", func() {
+	// A client's send channel can be closed by three paths that race each
+	// other: deliver evicting a client whose buffer is full, removeClient when
+	// its connection drops, and registry.closeAll at shutdown. Run them all at
+	// once; a second close would panic the test binary.
+	It("survives eviction, disconnects and shutdown racing each other", func() {
+		fd := newFakeDebugger()
+		h := hub.New(fd, nil)
+		h.Configure(hub.Options{KeepAliveWithoutClients: true})
+		cancel := runHub(h)
+
+		const clients = 32
+		conns := make([]*stallConn, clients)
+		for i := range conns {
+			conns[i] = newStallConn()
+		}
+
+		var wg sync.WaitGroup
+		for i, c := range conns {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				h.AddClient(c, nil)
+				if i%2 == 0 {
+					_ = c.Close()
+				}
+			}()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out := protocol.MustEvent(protocol.EventOutput, 0, protocol.OutputPayload{Stream: "stdout", Content: "x"})
+			for range 1000 {
+				select {
+				case fd.events <- out:
+				case <-h.Done():
+					return
+				}
+			}
+		}()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(5 * time.Millisecond)
+			cancel()
+		}()
+		wg.Wait()
+
+		Eventually(h.Done(), "2s").Should(BeClosed())
+		for _, c := range conns {
+			_ = c.Close()
+		}
+	})
+	It("keeps delivering to the other clients while evicting a stalled one", func() {
+		fd := newFakeDebugger()
+		h := hub.New(fd, nil)
+		h.Configure(hub.Options{KeepAliveWithoutClients: true})
+		cancel := runHub(h)
+		defer cancel()
+
+		stalled := newStallConn()
+		defer func() { _ = stalled.Close() }()
+		h.AddClient(stalled, nil)
+		healthy := newFakeWSConn()
+		h.AddClient(healthy, nil)
+
+		// More than a send buffer's worth, each read before the next is sent
+		// so the healthy client never falls behind: the stalled one
+		// overflows and is dropped partway through, and the healthy one must
+		// see every event in order regardless.
+		const events = 300
+		for i := range events {
+			fd.push(protocol.MustEvent(protocol.EventOutput, 0,
+				protocol.OutputPayload{Stream: "stdout", Content: strconv.Itoa(i)}))
+			var p protocol.OutputPayload
+			waitForEventKind(healthy, protocol.EventOutput, &p)
+			Expect(p.Content).To(Equal(strconv.Itoa(i)))
+		}
+		Eventually(h.ClientCount, "2s", "10ms").Should(Equal(1))
+	})
+})
+
+var _ = Describe("per-client event ordering", func() {
+	It("never shows a joining client an older state after a newer one", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		const joiners = 32
+		type seen struct {
+			seqs   []uint64
+			states []protocol.SessionState
+		}
+		views := make([]seen, joiners)
+		var wg sync.WaitGroup
+		for i := range joiners {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				time.Sleep(time.Duration(i) * time.Millisecond)
+				late := newFakeWSConn()
+				managed.AddClient(late, nil)
+				for {
+					e, ok := recvEvent(late)
+					if !ok {
+						return
+					}
+					views[i].seqs = append(views[i].seqs, e.Seq)
+					if e.Kind == protocol.EventSessionState {
+						var p protocol.SessionStatePayload
+						Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+						views[i].states = append(views[i].states, p.State)
+					}
+				}
+			}()
+		}
+
+		// Fast stop/continue cycles while the joiners arrive.
+		for range 20 {
+			fd.push(protocol.MustEvent(protocol.EventStepped, 1,
+				protocol.SteppedPayload{Location: protocol.Location{File: "main.go", Line: 11}}))
+			waitForEventKind(conn, protocol.EventStepped, nil)
+			conn.inject(mustCommand(protocol.CmdContinue, struct{}{}))
+			Eventually(managed.State, "500ms", "5ms").Should(Equal(protocol.StateRunning))
+		}
+		wg.Wait()
+
+		for i, v := range views {
+			Expect(v.seqs).NotTo(BeEmpty())
+			for j := 1; j < len(v.seqs); j++ {
+				Expect(v.seqs[j]).To(BeNumerically(">", v.seqs[j-1]), "client %d: seq went backwards", i)
+			}
+			Expect(v.states).NotTo(BeEmpty())
+			Expect(v.states[len(v.states)-1]).To(Equal(protocol.StateRunning), "client %d ended on a stale state", i)
+		}
+	})
+})
+
+var _ = Describe("welcome snapshot for joining clients", func() {
+	It("carries the suspended location and installed breakpoints", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		loc := protocol.Location{File: "main.go", Line: 10, Function: "main.main"}
+		fd.setBPResult = protocol.Breakpoint{ID: 1, Location: loc, Enabled: true}
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{File: "main.go", Line: 10}))
+		waitForEventKind(conn, protocol.EventBreakpointSet, nil)
+
+		fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+			protocol.BreakpointHitPayload{Breakpoint: fd.setBPResult}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateSuspended))
+
+		late := newFakeWSConn()
+		managed.AddClient(late, nil)
+		welcome, ok := recvEvent(late)
+		Expect(ok).To(BeTrue())
+		Expect(welcome.Kind).To(Equal(protocol.EventSessionState))
+
+		var p protocol.SessionStatePayload
+		Expect(protocol.DecodeEventPayload(welcome, &p)).To(Succeed())
+		Expect(p.State).To(Equal(protocol.StateSuspended))
+		Expect(p.Location).To(Equal(&loc))
+		Expect(p.Breakpoints).To(ConsistOf(fd.setBPResult))
+	})
+
+	It("drops breakpoints the engine reports lost", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		bp := protocol.Breakpoint{ID: 1, Location: protocol.Location{File: "main.go", Line: 10}, Enabled: true}
+		fd.setBPResult = bp
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{File: "main.go", Line: 10}))
+		waitForEventKind(conn, protocol.EventBreakpointSet, nil)
+
+		fd.push(protocol.MustEvent(protocol.EventBreakpointLost, 1,
+			protocol.BreakpointLostPayload{Breakpoint: bp, Message: "reinstall failed"}))
+		waitForEventKind(conn, protocol.EventBreakpointLost, nil)
+
+		late := newFakeWSConn()
+		managed.AddClient(late, nil)
+		welcome, _ := recvEvent(late)
+		var p protocol.SessionStatePayload
+		Expect(protocol.DecodeEventPayload(welcome, &p)).To(Succeed())
+		Expect(p.Breakpoints).To(BeEmpty())
+	})
+
+	It("tracks breakpoints disabled and re-enabled as a group", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		bp := protocol.Breakpoint{ID: 1, Location: protocol.Location{File: "main.go", Line: 10}, Enabled: true}
+		fd.setBPResult = bp
+		fd.toggleBPs = []protocol.Breakpoint{bp}
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{
+			File: "main.go", Line: 10, Actions: []string{"print x"},
+		}))
+		waitForEventKind(conn, protocol.EventBreakpointSet, nil)
+
+		conn.inject(mustCommand(protocol.CmdDisableAllBreakpoints, struct{}{}))
+		var toggled protocol.BreakpointsToggledPayload
+		waitForEventKind(conn, protocol.EventBreakpointsToggled, &toggled)
+		Expect(toggled.Enabled).To(BeFalse())
+		Expect(toggled.Breakpoints).To(HaveLen(1))
+		Expect(toggled.Breakpoints[0].Enabled).To(BeFalse())
+		Expect(toggled.Breakpoints[0].Actions).To(Equal([]string{"print x"}))
+
+		late := newFakeWSConn()
+		managed.AddClient(late, nil)
+		welcome, _ := recvEvent(late)
+		var p protocol.SessionStatePayload
+		Expect(protocol.DecodeEventPayload(welcome, &p)).To(Succeed())
+		Expect(p.Breakpoints).To(HaveLen(1))
+		Expect(p.Breakpoints[0].Enabled).To(BeFalse())
+
+		conn.inject(mustCommand(protocol.CmdEnableAllBreakpoints, struct{}{}))
+		waitForEventKind(conn, protocol.EventBreakpointsToggled, &toggled)
+		Expect(toggled.Enabled).To(BeTrue())
+		Expect(toggled.Breakpoints[0].Enabled).To(BeTrue())
+		Expect(fd.recordedCalls()).To(ContainElements("DisableAllBreakpoints", "EnableAllBreakpoints"))
+	})
+
+	It("sets a breakpoint's ignore count with it and on its own", func() {
+		fd := newFakeDebugger()
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		fd.setBPResult = protocol.Breakpoint{ID: 1, Location: protocol.Location{File: "main.go", Line: 10}, Enabled: true}
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{
+			File: "main.go", Line: 10, Actions: []string{"print x"}, IgnoreCount: 3,
+		}))
+		var set protocol.BreakpointSetPayload
+		waitForEventKind(conn, protocol.EventBreakpointSet, &set)
+		Expect(set.Breakpoint.IgnoreCount).To(Equal(3))
+		Expect(set.Breakpoint.Actions).To(Equal([]string{"print x"}))
+		Expect(fd.recordedCalls()).To(ContainElement("SetIgnoreCount(1, 3)"))
+
+		conn.inject(mustCommand(protocol.CmdSetIgnoreCount, protocol.SetIgnoreCountPayload{ID: 1, Count: 0}))
+		var p protocol.IgnoreCountSetPayload
+		waitForEventKind(conn, protocol.EventIgnoreCountSet, &p)
+		Expect(p.Breakpoint.ID).To(Equal(1))
+		Expect(p.Breakpoint.IgnoreCount).To(BeZero())
+		Expect(p.Breakpoint.Actions).To(Equal([]string{"print x"}), "the hub fills in the actions it tracks")
+	})
+
+	It("clears a breakpoint again when its ignore count cannot be set", func() {
+		fd := newFakeDebugger()
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		fd.setBPResult = protocol.Breakpoint{ID: 1, Location: protocol.Location{File: "main.go", Line: 10}, Enabled: true}
+		fd.ignoreErr = errors.New("breakpoint 1 not found")
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{
+			File: "main.go", Line: 10, IgnoreCount: 2,
+		}))
+		var p protocol.ErrorPayload
+		waitForEventKind(conn, protocol.EventError, &p)
+		Expect(p.Message).To(ContainSubstring("not found"))
+		Expect(fd.recordedCalls()).To(ContainElement("ClearBreakpoint"))
+	})
+
+	It("sets a goroutine count condition with a breakpoint and keeps it across a restart", func() {
+		fd := newFakeDebugger()
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		cond := &protocol.GoroutineCountCondition{Op: ">", Count: 1000}
+		fd.setBPResult = protocol.Breakpoint{ID: 1, Location: protocol.Location{File: "main.go", Line: 10}, Enabled: true}
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{
+			File: "main.go", Line: 10, GoroutineCount: cond,
+		}))
+		var set protocol.BreakpointSetPayload
+		waitForEventKind(conn, protocol.EventBreakpointSet, &set)
+		Expect(set.Breakpoint.GoroutineCount).To(Equal(cond))
+
+		conn.inject(mustCommand(protocol.CmdRestart, protocol.RestartPayload{}))
+		var restarted protocol.RestartedPayload
+		waitForEventKind(conn, protocol.EventRestarted, &restarted)
+		Expect(restarted.Breakpoints).To(HaveLen(1))
+		Expect(restarted.Breakpoints[0].GoroutineCount).To(Equal(cond))
+		Expect(countCalls(fd.recordedCalls(), "SetGoroutineCondition(1, goroutines > 1000)")).To(Equal(2))
+	})
+
+	It("refuses an invalid goroutine count condition before setting anything", func() {
+		fd := newFakeDebugger()
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{
+			File: "main.go", Line: 10, GoroutineCount: &protocol.GoroutineCountCondition{Op: "=>", Count: 5},
+		}))
+		var p protocol.ErrorPayload
+		waitForEventKind(conn, protocol.EventError, &p)
+		Expect(p.Message).To(ContainSubstring("goroutines => 5 is not a valid goroutine count condition"))
+		Expect(fd.recordedCalls()).NotTo(ContainElement("SetBreakpoint"))
+	})
+
+	It("forgets every breakpoint a clear-all removed", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		for id, line := range map[int]int{1: 10, 2: 20} {
+			fd.setBPResult = protocol.Breakpoint{ID: id, Location: protocol.Location{File: "main.go", Line: line}}
+			conn.inject(mustCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{File: "main.go", Line: line}))
+			waitForEventKind(conn, protocol.EventBreakpointSet, nil)
+		}
+
+		fd.clearAllIDs = []int{1, 2}
+		conn.inject(mustCommand(protocol.CmdClearAllBreakpoints, struct{}{}))
+		var cleared protocol.BreakpointsClearedPayload
+		waitForEventKind(conn, protocol.EventBreakpointsCleared, &cleared)
+		Expect(cleared).To(Equal(protocol.BreakpointsClearedPayload{IDs: []int{1, 2}, Count: 2}))
+
+		late := newFakeWSConn()
+		managed.AddClient(late, nil)
+		welcome, _ := recvEvent(late)
+		var p protocol.SessionStatePayload
+		Expect(protocol.DecodeEventPayload(welcome, &p)).To(Succeed())
+		Expect(p.Breakpoints).To(BeEmpty())
+	})
+
+	It("answers a clear-all with none set with a count of zero", func() {
+		fd := newFakeDebugger()
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		conn.inject(mustCommand(protocol.CmdClearAllBreakpoints, struct{}{}))
+		var cleared protocol.BreakpointsClearedPayload
+		waitForEventKind(conn, protocol.EventBreakpointsCleared, &cleared)
+		Expect(cleared.Count).To(BeZero())
+		Expect(cleared.IDs).To(BeEmpty())
+	})
+
+	It("omits the location once the process resumes", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		fd.push(protocol.MustEvent(protocol.EventStepped, 1,
+			protocol.SteppedPayload{Location: protocol.Location{File: "main.go", Line: 11}}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateSuspended))
+		conn.inject(mustCommand(protocol.CmdContinue, struct{}{}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateRunning))
+
+		late := newFakeWSConn()
+		managed.AddClient(late, nil)
+		welcome, _ := recvEvent(late)
+		var p protocol.SessionStatePayload
+		Expect(protocol.DecodeEventPayload(welcome, &p)).To(Succeed())
+		Expect(p.State).To(Equal(protocol.StateRunning))
+		Expect(p.Location).To(BeNil())
+	})
+})
+
+var _ = Describe("session logs", func() {
+	It("returns recent session log lines on CmdLogs", func() {
+		fd := newFakeDebugger()
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		conn.inject(mustCommand(protocol.CmdLogs, protocol.LogsPayloadCmd{}))
+		var p protocol.LogsPayload
+		waitForEventKind(conn, protocol.EventLogs, &p)
+
+		messages := make([]string, 0, len(p.Entries))
+		for _, e := range p.Entries {
+			messages = append(messages, e.Message)
+		}
+		Expect(messages).To(ContainElement("client connected"))
+		Expect(messages).To(ContainElement("command executed"))
+	})
+
+	It("honours Limit and the configured buffer size", func() {
+		fd := newFakeDebugger()
+		managed := hub.NewSession("session", func() debugger.Debugger { return fd }, nil)
+		managed.Configure(hub.Options{LogBufferSize: 3})
+		cancel := runHub(managed)
+		defer cancel()
+		conn := newFakeWSConn()
+		managed.AddClient(conn, nil)
+		_, _ = recvEvent(conn)
+		for i := 0; i < 5; i++ {
+			managed.Logger().Info("line", "i", i)
+		}
+
+		conn.inject(mustCommand(protocol.CmdLogs, protocol.LogsPayloadCmd{}))
+		var all protocol.LogsPayload
+		waitForEventKind(conn, protocol.EventLogs, &all)
+		Expect(all.Entries).To(HaveLen(3))
+		Expect(all.Entries[2].Attrs).To(HaveKeyWithValue("i", "4"))
+
+		conn.inject(mustCommand(protocol.CmdLogs, protocol.LogsPayloadCmd{Limit: 1}))
+		var one protocol.LogsPayload
+		waitForEventKind(conn, protocol.EventLogs, &one)
+		Expect(one.Entries).To(HaveLen(1))
+		Expect(one.Entries[0].Attrs).To(HaveKeyWithValue("i", "4"))
+	})
+})
+
+var _ = Describe("idle timeout", func() {
+	newIdleHub := func(timeout time.Duration) (*hub.Hub, *fakeDebugger, context.CancelFunc) {
+		fd := newFakeDebugger()
+		h := hub.New(fd, nil)
+		h.Configure(hub.Options{IdleTimeout: timeout})
+		return h, fd, runHub(h)
+	}
+
+	It("shuts the session down after IdleTimeout without client activity", func() {
+		h, fd, cancel := newIdleHub(50 * time.Millisecond)
+		defer cancel()
+		h.AddClient(newFakeWSConn(), nil)
+
+		Eventually(h.Done(), "1s", "10ms").Should(BeClosed())
+		Expect(fd.recordedCalls()).To(ContainElement("Kill"))
+	})
+
+	It("postpones idle shutdown while clients keep sending commands", func() {
+		h, _, cancel := newIdleHub(150 * time.Millisecond)
+		defer cancel()
+		conn := newFakeWSConn()
+		h.AddClient(conn, nil)
+
+		for i := 0; i < 6; i++ {
+			conn.inject(mustCommand(protocol.CmdLocals, protocol.LocalsPayloadCmd{}))
+			time.Sleep(50 * time.Millisecond)
+		}
+		Expect(h.Done()).NotTo(BeClosed())
+	})
+
+	It("never idle-shuts a zero-timeout session that still has clients", func() {
+		h, _, cancel := newIdleHub(0)
+		defer cancel()
+		h.AddClient(newFakeWSConn(), nil)
+
+		Consistently(h.Done(), "300ms", "20ms").ShouldNot(BeClosed())
+	})
+
+	It("still shuts a zero-timeout session down when the last client leaves", func() {
+		h, fd, cancel := newIdleHub(0)
+		defer cancel()
+		conn := newFakeWSConn()
+		h.AddClient(conn, nil)
+		closeFakeWS(conn)
+
+		Eventually(h.Done(), "1s", "10ms").Should(BeClosed())
+		Expect(fd.recordedCalls()).To(ContainElement("Kill"))
+	})
+})
+
+var _ = Describe("keep alive without clients", func() {
+	It("keeps the session and debuggee running after the last client leaves", func() {
+		fd := newFakeDebugger()
+		managed := hub.NewSession("session", func() debugger.Debugger { return fd }, nil)
+		managed.Configure(hub.Options{KeepAliveWithoutClients: true})
+		cancel := runHub(managed)
+		defer cancel()
+		conn := newFakeWSConn()
+		managed.AddClient(conn, nil)
+		_, _ = recvEvent(conn)
+		launchManaged(conn, fd, "myapp")
+
+		closeFakeWS(conn)
+		Eventually(managed.ClientCount, "500ms", "10ms").Should(BeZero())
+		Consistently(managed.Done(), "100ms", "10ms").ShouldNot(BeClosed())
+		Expect(fd.recordedCalls()).NotTo(ContainElement("Kill"))
+
+		// A reconnecting client is welcomed into the still-running session.
+		again := newFakeWSConn()
+		managed.AddClient(again, nil)
+		var p protocol.SessionStatePayload
+		waitForEventKind(again, protocol.EventSessionState, &p)
+		Expect(p.State).To(Equal(protocol.StateRunning))
+	})
+
+	It("still honours the idle timeout with no clients", func() {
+		fd := newFakeDebugger()
+		managed := hub.NewSession("session", func() debugger.Debugger { return fd }, nil)
+		managed.Configure(hub.Options{
+			KeepAliveWithoutClients: true,
+			IdleTimeout:             50 * time.Millisecond,
+		})
+		cancel := runHub(managed)
+		defer cancel()
+		conn := newFakeWSConn()
+		managed.AddClient(conn, nil)
+		closeFakeWS(conn)
+
+		Eventually(managed.Done(), "1s", "10ms").Should(BeClosed())
+	})
+})
+
+var _ = Describe("disconnect grace", func() {
+	var (
+		fd      *fakeDebugger
+		managed *hub.Hub
+		conn    *fakeWSConn
+		cancel  context.CancelFunc
+	)
+
+	// suspendAndLeave starts a session with opts, stops its process at a
+	// breakpoint and disconnects the only client.
+	suspendAndLeave := func(opts hub.Options) {
+		fd = newFakeDebugger()
+		managed = hub.NewSession("session", func() debugger.Debugger { return fd }, nil)
+		opts.KeepAliveWithoutClients = true
+		managed.Configure(opts)
+		cancel = runHub(managed)
+		conn = newFakeWSConn()
+		managed.AddClient(conn, nil)
+		_, _ = recvEvent(conn)
+		launchManaged(conn, fd, "myapp")
+		fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+			protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateSuspended))
+		closeFakeWS(conn)
+		Eventually(managed.ClientCount, "500ms", "10ms").Should(BeZero())
+	}
+
+	AfterEach(func() { cancel() })
+
+	It("leaves the process alone when a client reconnects in time", func() {
+		suspendAndLeave(hub.Options{DisconnectGrace: 200 * time.Millisecond})
+		managed.AddClient(newFakeWSConn(), nil)
+
+		Consistently(fd.recordedCalls, "400ms", "20ms").ShouldNot(ContainElement("Continue"))
+		Expect(managed.State()).To(Equal(protocol.StateSuspended))
+		Expect(managed.Done()).NotTo(BeClosed())
+	})
+
+	It("resumes the process once the grace runs out", func() {
+		suspendAndLeave(hub.Options{DisconnectGrace: 50 * time.Millisecond})
+
+		Eventually(fd.recordedCalls, "1s", "10ms").Should(ContainElement("Continue"))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateRunning))
+		Expect(managed.Done()).NotTo(BeClosed(), "the session stays for a later reconnect")
+	})
+
+	It("resumes a process that stops after the grace ran out", func() {
+		suspendAndLeave(hub.Options{DisconnectGrace: 50 * time.Millisecond})
+		Eventually(managed.State, "1s", "10ms").Should(Equal(protocol.StateRunning))
+
+		fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 2,
+			protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+		Eventually(func() int { return countCalls(fd.recordedCalls(), "Continue") }, "1s", "10ms").Should(Equal(2))
+	})
+
+	It("ends the session once the grace runs out with DisconnectEnd", func() {
+		suspendAndLeave(hub.Options{DisconnectGrace: 50 * time.Millisecond, DisconnectAction: hub.DisconnectEnd})
+
+		Eventually(managed.Done(), "1s", "10ms").Should(BeClosed())
+		Expect(fd.recordedCalls()).To(ContainElement("Kill"))
+		Expect(fd.recordedCalls()).NotTo(ContainElement("Continue"))
+	})
+})
+
+var _ = Describe("PID files", func() {
+	var (
+		dir    string
+		cancel context.CancelFunc
+	)
+
+	// start runs a session recording into dir whose process reports pid.
+	start := func(id string, pid int) (*hub.Hub, *fakeWSConn, *fakeDebugger) {
+		fd := newFakeDebugger()
+		fd.statusResult = protocol.StatusPayload{Process: protocol.StateRunning, PID: pid}
+		managed := hub.NewSession(id, func() debugger.Debugger { return fd }, nil)
+		managed.Configure(hub.Options{PIDDir: dir})
+		cancelRun := runHub(managed)
+		prev := cancel
+		cancel = func() { cancelRun(); prev() }
+		conn := newFakeWSConn()
+		managed.AddClient(conn, nil)
+		_, _ = recvEvent(conn)
+		return managed, conn, fd
+	}
+	records := func() []hub.TargetFile {
+		files, err := hub.ReadTargetFiles(dir)
+		Expect(err).NotTo(HaveOccurred())
+		return files
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		cancel = func() {}
+	})
+	AfterEach(func() { cancel() })
+
+	It("records a launched process until it exits", func() {
+		_, conn, fd := start("s1", 4242)
+		launchManaged(conn, fd, "myapp")
+
+		Eventually(records, "500ms", "10ms").Should(HaveLen(1))
+		program, err := filepath.Abs("myapp")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records()[0].TargetRecord).To(Equal(hub.TargetRecord{
+			PID: 4242, Session: "s1", ServerPID: os.Getpid(), Program: program,
+		}))
+		Expect(records()[0].Path).To(Equal(filepath.Join(dir, "s1.pid.json")))
+
+		fd.closeEvents()
+		Eventually(records, "500ms", "10ms").Should(BeEmpty())
+	})
+
+	It("records the new process on Restart", func() {
+		_, conn, fd := start("s1", 4242)
+		launchManaged(conn, fd, "myapp")
+		Eventually(records, "500ms", "10ms").Should(HaveLen(1))
+
+		fd.statusResult.PID = 4343
+		conn.inject(mustCommand(protocol.CmdRestart, protocol.RestartPayload{}))
+		waitForEventKind(conn, protocol.EventRestarted, nil)
+		Expect(records()).To(HaveLen(1))
+		Expect(records()[0].PID).To(Equal(4343))
+	})
+
+	It("removes the record when the session ends", func() {
+		managed, conn, fd := start("s1", 4242)
+		launchManaged(conn, fd, "myapp")
+		Eventually(records, "500ms", "10ms").Should(HaveLen(1))
+
+		cancel()
+		Eventually(managed.Done(), "1s", "10ms").Should(BeClosed())
+		Expect(fd.recordedCalls()).To(ContainElement("Kill"))
+		Expect(records()).To(BeEmpty())
+	})
+
+	It("keeps concurrent sessions' records apart", func() {
+		_, connA, fdA := start("a", 1001)
+		_, connB, fdB := start("b", 1002)
+		launchManaged(connA, fdA, "myapp")
+		launchManaged(connB, fdB, "myapp")
+
+		Eventually(records, "500ms", "10ms").Should(HaveLen(2))
+		Expect([]int{records()[0].PID, records()[1].PID}).To(ConsistOf(1001, 1002))
+
+		fdA.closeEvents()
+		Eventually(records, "500ms", "10ms").Should(HaveLen(1))
+		Expect(records()[0].Session).To(Equal("b"))
+	})
+
+	It("records nothing for an attached process", func() {
+		managed, conn, fd := start("s1", 4242)
+		conn.inject(mustCommand(protocol.CmdAttach, protocol.AttachPayload{PID: 4242}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateRunning))
+		Expect(fd.recordedCalls()).To(ContainElement("Attach"))
+		Expect(records()).To(BeEmpty())
+	})
+
+	It("refuses a directory other users could write to", func() {
+		Expect(os.Chmod(dir, 0o755)).To(Succeed())
+		_, conn, fd := start("s1", 4242)
+		launchManaged(conn, fd, "myapp")
+
+		Consistently(func() []os.DirEntry {
+			entries, _ := os.ReadDir(dir)
+			return entries
+		}, "100ms", "10ms").Should(BeEmpty())
+		_, err := hub.ReadTargetFiles(dir)
+		Expect(err).To(MatchError(ContainSubstring("want 0700")))
+	})
+
+	It("doesn't take a PID it can't tie to the program as running", func() {
+		if _, err := os.Stat("/proc/self/exe"); err != nil {
+			Skip("no /proc")
+		}
+		Expect(hub.TargetRecord{PID: os.Getpid(), Program: "/no/such/program"}.Running()).To(BeFalse())
+		self, err := os.Executable()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hub.TargetRecord{PID: os.Getpid(), Program: self}.Running()).To(BeTrue())
+	})
+
+	It("prunes a crashed server's records for processes that are gone", func() {
+		gone := exec.Command("true")
+		Expect(gone.Run()).To(Succeed())
+		write := func(r hub.TargetRecord) {
+			data, err := json.Marshal(r)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(dir, r.Session+".pid.json"), data, 0o600)).To(Succeed())
+		}
+		write(hub.TargetRecord{Session: "crashed", PID: gone.Process.Pid, ServerPID: gone.Process.Pid})
+		write(hub.TargetRecord{Session: "live", PID: gone.Process.Pid, ServerPID: os.Getpid()})
+
+		orphans, err := hub.PruneTargetFiles(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orphans).To(BeEmpty())
+		Expect(records()).To(HaveLen(1))
+		Expect(records()[0].Session).To(Equal("live"), "a live server's records are its own")
+	})
+})
+
+var _ = Describe("capabilities", func() {
+	capabilities := func(conn *fakeWSConn) protocol.CapabilitiesPayload {
+		conn.inject(mustCommand(protocol.CmdCapabilities, struct{}{}))
+		var p protocol.CapabilitiesPayload
+		waitForEventKind(conn, protocol.EventCapabilities, &p)
+		return p
+	}
+
+	It("offers only starting a process before one is launched", func() {
+		fd := newFakeDebugger()
+		_, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+
+		p := capabilities(conn)
+		Expect(p.State).To(Equal(protocol.StateIdle))
+		Expect(p.Supported).To(ContainElements(protocol.CmdLaunch, protocol.CmdContinue, protocol.CmdCapabilities))
+		Expect(p.Valid).To(ConsistOf(protocol.CmdLaunch, protocol.CmdAttach, protocol.CmdLogs, protocol.CmdCapabilities,
+			protocol.CmdStatus, protocol.CmdEcho, protocol.CmdSubscribe))
+		Expect(fd.recordedCalls()).To(BeEmpty(), "answered without the debugger")
+	})
+
+	It("follows the session from running to suspended", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+
+		p := capabilities(conn)
+		Expect(p.State).To(Equal(protocol.StateRunning))
+		Expect(p.Valid).To(ContainElements(protocol.CmdPause, protocol.CmdSetBreakpoint, protocol.CmdRestart))
+		Expect(p.Valid).NotTo(ContainElements(protocol.CmdContinue, protocol.CmdLocals, protocol.CmdLaunch))
+
+		fd.push(protocol.MustEvent(protocol.EventStepped, 1, protocol.SteppedPayload{}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateSuspended))
+		p = capabilities(conn)
+		Expect(p.State).To(Equal(protocol.StateSuspended))
+		Expect(p.Valid).To(ContainElements(protocol.CmdContinue, protocol.CmdStepInstruction, protocol.CmdLocals))
+		Expect(p.Valid).NotTo(ContainElement(protocol.CmdPause))
+	})
+})
+
+var _ = Describe("status", func() {
+	var (
+		fd      *fakeDebugger
+		managed *hub.Hub
+		conn    *fakeWSConn
+		cancel  context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		fd = newFakeDebugger()
+		managed, conn, cancel = newManagedRestartHub(fd)
+	})
+
+	AfterEach(func() { cancel() })
+
+	status := func() protocol.StatusPayload {
+		conn.inject(mustCommand(protocol.CmdStatus, struct{}{}))
+		var p protocol.StatusPayload
+		waitForEventKind(conn, protocol.EventStatus, &p)
+		return p
+	}
+
+	It("answers idle without a debugger", func() {
+		p := status()
+		p.CreatedAt, p.LastActivity = time.Time{}, time.Time{}
+		Expect(p).To(Equal(protocol.StatusPayload{Session: protocol.StateIdle, Process: protocol.StateIdle}))
+		Expect(fd.recordedCalls()).To(BeEmpty())
+	})
+
+	It("stamps when the session was created and last used", func() {
+		p := status()
+		Expect(p.CreatedAt).To(BeTemporally("==", managed.CreatedAt()))
+		Expect(p.LastActivity).NotTo(BeTemporally("<", p.CreatedAt))
+		Expect(p.LastActivity).To(BeTemporally("~", time.Now(), time.Second),
+			"the Status command itself is activity")
+
+		time.Sleep(20 * time.Millisecond)
+		again := status()
+		Expect(again.CreatedAt).To(BeTemporally("==", p.CreatedAt), "creation time does not move")
+		Expect(again.LastActivity).To(BeTemporally(">", p.LastActivity))
+	})
+
+	It("reports the engine's view next to the hub's", func() {
+		launchManaged(conn, fd, "myapp")
+		// The engine has stopped; the hub has not heard yet.
+		fd.statusResult = protocol.StatusPayload{Process: protocol.StateSuspended, PC: 0x401000, TID: 7}
+
+		p := status()
+		Expect(p.Session).To(Equal(protocol.StateRunning))
+		Expect(p.Process).To(Equal(protocol.StateSuspended))
+		Expect(p.PC).To(Equal(uint64(0x401000)))
+		Expect(p.TID).To(Equal(7))
+		Expect(managed.State()).To(Equal(protocol.StateRunning), "a query changes nothing")
+	})
+
+	It("answers exited once the engine's loop has ended", func() {
+		launchManaged(conn, fd, "myapp")
+		fd.statusErr = debugger.ErrProcessExited
+
+		Expect(status().Process).To(Equal(protocol.StateExited))
+	})
+})
+
+var _ = Describe("echo", func() {
+	It("answers the sender alone, with its payload and a send time", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+		other := newFakeWSConn()
+		managed.AddClient(other, nil)
+		_, _ = recvEvent(other) // welcome
+
+		before := time.Now()
+		conn.inject(mustCommand(protocol.CmdEcho, protocol.EchoPayload{Data: "hello"}))
+		var evt protocol.Event
+		Eventually(func() protocol.EventKind {
+			evt, _ = recvEvent(conn)
+			return evt.Kind
+		}, "500ms", "10ms").Should(Equal(protocol.EventEcho))
+
+		var p protocol.EchoPayload
+		Expect(protocol.DecodeEventPayload(evt, &p)).To(Succeed())
+		Expect(p.Data).To(Equal("hello"))
+		Expect(evt.Time).To(BeTemporally(">=", before))
+		Expect(managed.State()).To(Equal(protocol.StateRunning))
+		Expect(fd.recordedCalls()).To(Equal([]string{"Launch"}), "the process is not touched")
+
+		Consistently(func() protocol.EventKind {
+			e, _ := recvEvent(other)
+			return e.Kind
+		}, "100ms", "10ms").ShouldNot(Equal(protocol.EventEcho))
+	})
+})
+
+var _ = Describe("event subscriptions", func() {
+	It("sends a subscribed client only the kinds it asked for, and errors", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+		other := newFakeWSConn()
+		managed.AddClient(other, nil)
+
+		other.inject(mustCommand(protocol.CmdSubscribe, protocol.SubscribePayload{
+			Events: []protocol.EventKind{protocol.EventOutput},
+		}))
+		var sub protocol.SubscribePayload
+		waitForEventKind(other, protocol.EventSubscribed, &sub)
+		Expect(sub.Events).To(ConsistOf(protocol.EventOutput))
+
+		fd.push(protocol.MustEvent(protocol.EventThreadStarted, 0, protocol.ThreadPayload{TID: 7}))
+		fd.push(protocol.MustEvent(protocol.EventOutput, 0, protocol.OutputPayload{Content: "hi"}))
+		waitForEventKind(conn, protocol.EventThreadStarted, nil)
+		waitForEventKind(conn, protocol.EventOutput, nil)
+		e, ok := recvEvent(other)
+		Expect(ok).To(BeTrue())
+		Expect(e.Kind).To(Equal(protocol.EventOutput), "ThreadStarted is filtered out")
+
+		fd.clearBPErr = errors.New("no such breakpoint")
+		conn.inject(mustCommand(protocol.CmdClearBreakpoint, protocol.ClearBreakpointPayload{ID: 9}))
+		e, ok = recvEvent(other)
+		Expect(ok).To(BeTrue())
+		Expect(e.Kind).To(Equal(protocol.EventError), "errors get through any filter")
+
+		other.inject(mustCommand(protocol.CmdSubscribe, protocol.SubscribePayload{}))
+		waitForEventKind(other, protocol.EventSubscribed, nil)
+		fd.push(protocol.MustEvent(protocol.EventThreadStarted, 0, protocol.ThreadPayload{TID: 8}))
+		waitForEventKind(other, protocol.EventThreadStarted, nil)
+	})
+
+	It("answers the subscriber alone and leaves other clients unfiltered", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		other := newFakeWSConn()
+		managed.AddObserver(other, nil)
+		_, _ = recvEvent(other) // welcome
+
+		other.inject(mustCommand(protocol.CmdSubscribe, protocol.SubscribePayload{
+			Events: []protocol.EventKind{protocol.EventGoroutines},
+		}))
+		waitForEventKind(other, protocol.EventSubscribed, nil)
+		Consistently(func() protocol.EventKind {
+			e, _ := recvEvent(conn)
+			return e.Kind
+		}, "100ms", "10ms").ShouldNot(Equal(protocol.EventSubscribed))
+
+		launchManaged(conn, fd, "myapp")
+		Expect(managed.State()).To(Equal(protocol.StateRunning))
+		Consistently(func() protocol.EventKind {
+			e, _ := recvEvent(other)
+			return e.Kind
+		}, "100ms", "10ms").ShouldNot(Equal(protocol.EventSessionState))
+	})
+
+	It("sends a bad subscription's error to the sender alone", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		other := newFakeWSConn()
+		managed.AddClient(other, nil)
+		_, _ = recvEvent(other) // welcome
+
+		other.inject(mustCommand(protocol.CmdSubscribe, protocol.SubscribePayload{
+			Events: []protocol.EventKind{""},
+		}))
+		var p protocol.ErrorPayload
+		waitForEventKind(other, protocol.EventError, &p)
+		Expect(p.Command).To(Equal(protocol.CmdSubscribe))
+		Expect(p.Message).To(ContainSubstring("empty event kind"))
+
+		other.inject(protocol.Command{
+			Version: protocol.Version,
+			Kind:    protocol.CmdSubscribe,
+			Payload: json.RawMessage(`{"events":7}`),
+		})
+		var decodeErr protocol.ErrorPayload
+		waitForEventKind(other, protocol.EventError, &decodeErr)
+		Expect(decodeErr.Message).To(ContainSubstring("DecodeCommandPayload(Subscribe)"))
+
+		Consistently(func() protocol.EventKind {
+			e, _ := recvEvent(conn)
+			return e.Kind
+		}, "100ms", "10ms").ShouldNot(Equal(protocol.EventError))
+	})
+})
+
+var _ = Describe("read-only observers", func() {
+	It("refuses an observer's resume to it alone and lets reads through", func() {
+		fd := newFakeDebugger()
+		managed, conn, cancel := newManagedRestartHub(fd)
+		defer cancel()
+		launchManaged(conn, fd, "myapp")
+		observer := newFakeWSConn()
+		managed.AddObserver(observer, nil)
+		_, _ = recvEvent(observer) // welcome
+
+		observer.inject(mustCommand(protocol.CmdKill, nil))
+		var errEvt protocol.ErrorPayload
+		waitForEventKind(observer, protocol.EventError, &errEvt)
+		Expect(errEvt.Command).To(Equal(protocol.CmdKill))
+		Expect(errEvt.Message).To(ContainSubstring("read-only"))
+		Expect(fd.recordedCalls()).To(Equal([]string{"Launch"}), "the process is not touched")
+		Consistently(func() protocol.EventKind {
+			e, _ := recvEvent(conn)
+			return e.Kind
+		}, "100ms", "10ms").ShouldNot(Equal(protocol.EventError))
+
+		observer.inject(mustCommand(protocol.CmdEcho, protocol.EchoPayload{Data: "watching"}))
+		var echo protocol.EchoPayload
+		waitForEventKind(observer, protocol.EventEcho, &echo)
+		Expect(echo.Data).To(Equal("watching"))
+	})
+})
+
+var _ = Describe("command latency metrics", func() {
+	metricsText := func(m *hub.Metrics) string {
+		var b strings.Builder
+		Expect(m.WritePrometheus(&b)).To(Succeed())
+		return b.String()
+	}
+
+	It("times a step until the stop it ends at", func() {
+		fd := newFakeDebugger()
+		metrics := hub.NewMetrics()
+		managed := hub.NewSession("session", func() debugger.Debugger { return fd }, nil)
+		managed.Configure(hub.Options{Metrics: metrics})
+		cancel := runHub(managed)
+		defer cancel()
+		conn := newFakeWSConn()
+		managed.AddClient(conn, nil)
+		_, _ = recvEvent(conn)
+		launchManaged(conn, fd, "myapp")
+
+		Eventually(func() string { return metricsText(metrics) }, "500ms", "10ms").
+			Should(ContainSubstring(`bingo_command_duration_seconds_count{command="Launch"} 1`))
+
+		fd.push(protocol.MustEvent(protocol.EventStepped, 1, protocol.SteppedPayload{}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateSuspended))
+		conn.inject(mustCommand(protocol.CmdStepOver, struct{}{}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateRunning))
+		Expect(metricsText(metrics)).NotTo(ContainSubstring(`command="StepOver"`),
+			"a step is not done while the process runs")
+
+		fd.push(protocol.MustEvent(protocol.EventStepped, 2, protocol.SteppedPayload{}))
+		Eventually(func() string { return metricsText(metrics) }, "500ms", "10ms").
+			Should(ContainSubstring(`bingo_command_duration_seconds_count{command="StepOver"} 1`))
+		Expect(metricsText(metrics)).To(ContainSubstring(`bingo_command_duration_seconds_bucket{command="StepOver",le="+Inf"} 1`))
+	})
+})
+
+var _ = Describe("default breakpoints", func() {
+	var (
+		fd      *fakeDebugger
+		managed *hub.Hub
+		cancel  context.CancelFunc
+		conn    *fakeWSConn
+	)
+
+	BeforeEach(func() {
+		fd = newFakeDebugger()
+		managed = hub.NewSession("session", func() debugger.Debugger { return fd }, nil)
+		managed.Configure(hub.Options{DefaultBreakpoints: []protocol.Location{
+			{File: "main.go", Line: 10},
+			{File: "util.go", Line: 5},
+		}})
+		cancel = runHub(managed)
+		conn = newFakeWSConn()
+		managed.AddClient(conn, nil)
+		_, _ = recvEvent(conn)
+	})
+
+	AfterEach(func() { cancel() })
+
+	It("sets each one on a freshly launched process and announces it", func() {
+		fd.setBPResult = protocol.Breakpoint{ID: 1, Location: protocol.Location{File: "main.go", Line: 10}}
+		launchManaged(conn, fd, "myapp")
+
+		var p protocol.BreakpointSetPayload
+		waitForEventKind(conn, protocol.EventBreakpointSet, &p)
+		Expect(p.Breakpoint.ID).To(Equal(1))
+		Expect(countCalls(fd.recordedCalls(), "SetBreakpoint")).To(Equal(2))
+	})
+
+	It("hands them to the debugger before it launches", func() {
+		launchManaged(conn, fd, "myapp")
+
+		calls := fd.recordedCalls()
+		Expect(slices.Index(calls, "SetInitialBreakpoints(2)")).To(BeNumerically(">=", 0))
+		Expect(slices.Index(calls, "SetInitialBreakpoints(2)")).To(BeNumerically("<", slices.Index(calls, "Launch")))
+	})
+
+	It("reinstalls them on Restart as remembered breakpoints", func() {
+		fd.setBPResult = protocol.Breakpoint{ID: 1, Location: protocol.Location{File: "main.go", Line: 10}}
+		launchManaged(conn, fd, "myapp")
+		waitForEventKind(conn, protocol.EventBreakpointSet, nil)
+
+		conn.inject(mustCommand(protocol.CmdRestart, protocol.RestartPayload{}))
+		var p protocol.RestartedPayload
+		waitForEventKind(conn, protocol.EventRestarted, &p)
+		Expect(p.Breakpoints).To(ConsistOf(fd.setBPResult))
+	})
+
+	It("reports one the target can't resolve with a BreakpointError", func() {
+		fd.setBPErr = errors.New("no address for main.go:10")
+		launchManaged(conn, fd, "myapp")
+
+		var p protocol.BreakpointErrorPayload
+		waitForEventKind(conn, protocol.EventBreakpointError, &p)
+		Expect(p.Location).To(Equal(protocol.Location{File: "main.go", Line: 10}))
+		Expect(p.Message).To(ContainSubstring("no address"))
+		Expect(managed.State()).To(Equal(protocol.StateRunning))
+	})
+})
+
+var _ = Describe("breakpoint actions", func() {
+	var (
+		fd     *fakeDebugger
+		h      *hub.Hub
+		cancel context.CancelFunc
+		conn   *fakeWSConn
+	)
+
+	BeforeEach(func() {
+		fd = newFakeDebugger()
+		h = hub.New(fd, nil)
+		cancel = runHub(h)
+		conn = newFakeWSConn()
+		h.AddClient(conn, nil)
+		_, _ = recvEvent(conn)
+	})
+
+	AfterEach(func() { cancel() })
+
+	setWithActions := func(id int, actions ...string) {
+		fd.setBPResult = protocol.Breakpoint{ID: id, Location: protocol.Location{File: "main.go", Line: 10}}
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint,
+			protocol.SetBreakpointPayload{File: "main.go", Line: 10, Actions: actions}))
+		var set protocol.BreakpointSetPayload
+		waitForEventKind(conn, protocol.EventBreakpointSet, &set)
+		Expect(set.Breakpoint.Actions).To(Equal(actions))
+	}
+
+	hit := func(id int) {
+		fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+			protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: id}}))
+	}
+
+	It("rejects an unknown action without setting the breakpoint", func() {
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint,
+			protocol.SetBreakpointPayload{File: "main.go", Line: 10, Actions: []string{"frobnicate"}}))
+
+		var p protocol.ErrorPayload
+		waitForEventKind(conn, protocol.EventError, &p)
+		Expect(p.Message).To(ContainSubstring("frobnicate"))
+		Expect(fd.recordedCalls()).NotTo(ContainElement("SetBreakpoint"))
+	})
+
+	It("rejects continue anywhere but last", func() {
+		conn.inject(mustCommand(protocol.CmdSetBreakpoint,
+			protocol.SetBreakpointPayload{File: "main.go", Line: 10, Actions: []string{"continue", "print x"}}))
+
+		var p protocol.ErrorPayload
+		waitForEventKind(conn, protocol.EventError, &p)
+		Expect(p.Message).To(ContainSubstring("must be the last"))
+	})
+
+	It("prints and resumes without reporting a stop", func() {
+		fd.localsResult = []protocol.Variable{{Name: "x", Value: "42", Type: "int"}}
+		setWithActions(1, "print x", "print nope", "continue")
+
+		hit(1)
+
+		var p protocol.BreakpointActionsPayload
+		waitForEventKind(conn, protocol.EventBreakpointActions, &p)
+		Expect(p.Breakpoint.ID).To(Equal(1))
+		Expect(p.Output).To(Equal([]string{"x = 42", "print nope: no such local"}))
+		Expect(p.Resumed).To(BeTrue())
+		Expect(fd.recordedCalls()).To(ContainElement("Continue"))
+
+		Consistently(func() protocol.EventKind {
+			e, _ := recvEvent(conn)
+			return e.Kind
+		}, "400ms", "50ms").ShouldNot(Equal(protocol.EventBreakpointHit))
+		Expect(h.State()).NotTo(Equal(protocol.StateSuspended))
+	})
+
+	It("prints and then stops when the actions don't continue", func() {
+		fd.localsResult = []protocol.Variable{{Name: "x", Value: "42", Type: "int"}}
+		setWithActions(2, "print x")
+
+		hit(2)
+
+		var p protocol.BreakpointActionsPayload
+		waitForEventKind(conn, protocol.EventBreakpointActions, &p)
+		Expect(p.Resumed).To(BeFalse())
+		waitForEventKind(conn, protocol.EventBreakpointHit, nil)
+		Eventually(h.State, "500ms", "10ms").Should(Equal(protocol.StateSuspended))
+		Expect(fd.recordedCalls()).NotTo(ContainElement("Continue"))
+	})
+
+	It("leaves breakpoints without actions alone", func() {
+		setWithActions(3)
+
+		hit(3)
+
+		waitForEventKind(conn, protocol.EventBreakpointHit, nil)
+	})
+})
+
+var _ = Describe("max run time", func() {
+	var (
+		fd      *fakeDebugger
+		managed *hub.Hub
+		conn    *fakeWSConn
+		cancel  context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		fd = newFakeDebugger()
+		managed, conn, cancel = newManagedRestartHub(fd)
+	})
+
+	AfterEach(func() { cancel() })
+
+	launch := func(limit time.Duration) {
+		conn.inject(mustCommand(protocol.CmdLaunch, protocol.LaunchPayload{Program: "myapp", MaxRunTime: limit}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateRunning))
+	}
+
+	It("pauses a process that runs past its limit, once", func() {
+		launch(50 * time.Millisecond)
+
+		var p protocol.RunTimeoutPayload
+		waitForEventKind(conn, protocol.EventRunTimeout, &p)
+		Expect(p.MaxRunTime).To(Equal(50 * time.Millisecond))
+		Eventually(fd.recordedCalls, "500ms", "10ms").Should(ContainElement("Pause"))
+
+		fd.push(protocol.MustEvent(protocol.EventPaused, 1, protocol.PausedPayload{}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateSuspended))
+		conn.inject(mustCommand(protocol.CmdContinue, struct{}{}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateRunning))
+		Consistently(func() int { return countCalls(fd.recordedCalls(), "Pause") }, "200ms", "20ms").
+			Should(Equal(1), "a spent budget stays spent")
+	})
+
+	It("only counts time spent running", func() {
+		launch(150 * time.Millisecond)
+		fd.push(protocol.MustEvent(protocol.EventBreakpointHit, 1,
+			protocol.BreakpointHitPayload{Breakpoint: protocol.Breakpoint{ID: 1}}))
+		Eventually(managed.State, "500ms", "10ms").Should(Equal(protocol.StateSuspended))
+
+		Consistently(fd.recordedCalls, "300ms", "20ms").ShouldNot(ContainElement("Pause"))
+
+		conn.inject(mustCommand(protocol.CmdContinue, struct{}{}))
+		waitForEventKind(conn, protocol.EventRunTimeout, nil)
+		Eventually(fd.recordedCalls, "500ms", "10ms").Should(ContainElement("Pause"))
+	})
+
+	It("starts over on Restart", func() {
+		launch(300 * time.Millisecond)
+		time.Sleep(200 * time.Millisecond)
+		conn.inject(mustCommand(protocol.CmdRestart, protocol.RestartPayload{}))
+		waitForEventKind(conn, protocol.EventRestarted, nil)
+
+		// Past the first launch's limit, well inside the relaunch's.
+		Consistently(fd.recordedCalls, "150ms", "20ms").ShouldNot(ContainElement("Pause"))
+		waitForEventKind(conn, protocol.EventRunTimeout, nil)
+	})
+})
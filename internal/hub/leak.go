@@ -0,0 +1,65 @@
+package hub
+
+import (
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// defaultLeakWindow is how many snapshots Options.LeakThreshold looks back
+// over when Options.LeakWindow is zero.
+const defaultLeakWindow = 5
+
+// leakWatch keeps the goroutine counts of the most recent snapshots and flags
+// a window in which the count never fell and grew by at least threshold. A
+// single jump is normal (a worker pool starting); a count that only ever goes
+// up across several stops usually means goroutines that never exit. Touched
+// only on the Run goroutine.
+type leakWatch struct {
+	threshold int
+	window    int
+	counts    []int
+}
+
+// observe records count and returns the window's counts when they show a
+// leak. The history then restarts from count, so a steady leak is reported
+// once per window rather than on every stop.
+func (w *leakWatch) observe(count int) ([]int, bool) {
+	if w.threshold <= 0 {
+		return nil, false
+	}
+	w.counts = append(w.counts, count)
+	if len(w.counts) > w.window {
+		w.counts = w.counts[len(w.counts)-w.window:]
+	}
+	if len(w.counts) < w.window || count-w.counts[0] < w.threshold {
+		return nil, false
+	}
+	for i := 1; i < len(w.counts); i++ {
+		if w.counts[i] < w.counts[i-1] {
+			return nil, false
+		}
+	}
+	window := append([]int(nil), w.counts...)
+	w.counts = append(w.counts[:0], count)
+	return window, true
+}
+
+func (w *leakWatch) reset() { w.counts = nil }
+
+// checkGoroutineLeak feeds a snapshot's goroutine count to the leak watch and
+// broadcasts EventGoroutineLeak when the trend crosses the threshold.
+func (h *Hub) checkGoroutineLeak(goroutines []protocol.Goroutine) {
+	counts, leaking := h.leaks.observe(len(goroutines))
+	if !leaking {
+		return
+	}
+	h.log.Warn("goroutine count keeps growing", "counts", counts)
+	evt, err := protocol.NewEvent(protocol.EventGoroutineLeak, 0, protocol.GoroutineLeakPayload{
+		Counts: counts,
+		Growth: counts[len(counts)-1] - counts[0],
+	})
+	if err != nil {
+		h.log.Error("failed to create GoroutineLeak event", "err", err)
+		return
+	}
+	h.broadcast(evt)
+}
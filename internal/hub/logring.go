@@ -0,0 +1,121 @@
+package hub
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// defaultLogBufferSize is the per-session log capacity when Options leaves
+// LogBufferSize at zero.
+const defaultLogBufferSize = 256
+
+// logRing is a bounded, goroutine-safe buffer of the most recent log entries
+// for one session. Oldest entries are overwritten once full.
+type logRing struct {
+	mu      sync.Mutex
+	entries []protocol.LogEntry
+	next    int
+	full    bool
+}
+
+func newLogRing(size int) *logRing {
+	if size <= 0 {
+		size = defaultLogBufferSize
+	}
+	return &logRing{entries: make([]protocol.LogEntry, size)}
+}
+
+func (r *logRing) add(e protocol.LogEntry) {
+	r.mu.Lock()
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	r.mu.Unlock()
+}
+
+// resize changes the capacity, keeping the newest entries that still fit.
+func (r *logRing) resize(size int) {
+	if size <= 0 {
+		size = defaultLogBufferSize
+	}
+	kept := r.recent(size)
+	r.mu.Lock()
+	r.entries = make([]protocol.LogEntry, size)
+	copy(r.entries, kept)
+	r.next = len(kept) % size
+	r.full = len(kept) == size
+	r.mu.Unlock()
+}
+
+// recent returns up to limit entries, oldest first. limit <= 0 means all.
+func (r *logRing) recent(limit int) []protocol.LogEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ordered []protocol.LogEntry
+	if r.full {
+		ordered = append(ordered, r.entries[r.next:]...)
+	}
+	ordered = append(ordered, r.entries[:r.next]...)
+	if limit > 0 && len(ordered) > limit {
+		ordered = ordered[len(ordered)-limit:]
+	}
+	out := make([]protocol.LogEntry, len(ordered))
+	copy(out, ordered)
+	return out
+}
+
+// ringHandler tees every record the wrapped handler accepts into a logRing,
+// so the session's log stays retrievable by clients without changing where
+// the server's own logs go. Level filtering is inherited from next.
+type ringHandler struct {
+	next  slog.Handler
+	ring  *logRing
+	attrs []slog.Attr
+}
+
+func newRingHandler(next slog.Handler, ring *logRing) *ringHandler {
+	return &ringHandler{next: next, ring: ring}
+}
+
+func (h *ringHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *ringHandler) Handle(ctx context.Context, rec slog.Record) error {
+	entry := protocol.LogEntry{
+		Time:    rec.Time,
+		Level:   rec.Level.String(),
+		Message: rec.Message,
+	}
+	if n := len(h.attrs) + rec.NumAttrs(); n > 0 {
+		entry.Attrs = make(map[string]string, n)
+		for _, a := range h.attrs {
+			entry.Attrs[a.Key] = a.Value.String()
+		}
+		rec.Attrs(func(a slog.Attr) bool {
+			entry.Attrs[a.Key] = a.Value.String()
+			return true
+		})
+	}
+	h.ring.add(entry)
+	return h.next.Handle(ctx, rec)
+}
+
+func (h *ringHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	merged := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	merged = append(merged, h.attrs...)
+	merged = append(merged, attrs...)
+	return &ringHandler{next: h.next.WithAttrs(attrs), ring: h.ring, attrs: merged}
+}
+
+// WithGroup only affects the wrapped handler; ring entries stay flat, which
+// is all a client-facing session log needs.
+func (h *ringHandler) WithGroup(name string) slog.Handler {
+	return &ringHandler{next: h.next.WithGroup(name), ring: h.ring, attrs: h.attrs}
+}
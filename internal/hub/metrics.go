@@ -0,0 +1,89 @@
+package hub
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the command latency
+// histogram. They span a fast inspection (well under a millisecond) to a step
+// over a slow call, which is the range a slow session falls somewhere in.
+var latencyBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics collects command latency across every session that shares it and
+// renders it in the Prometheus text format. A nil *Metrics records nothing,
+// so hubs without Options.Metrics pay only a nil check.
+type Metrics struct {
+	mu       sync.Mutex
+	commands map[protocol.CommandKind]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative; the last one is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics returns an empty Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{commands: make(map[protocol.CommandKind]*histogram)}
+}
+
+// observeCommand records that a command of kind took d from the hub
+// receiving it to the event that answers it.
+func (m *Metrics) observeCommand(kind protocol.CommandKind, d time.Duration) {
+	if m == nil {
+		return
+	}
+	s := d.Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.commands[kind]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets)+1)}
+		m.commands[kind] = h
+	}
+	i := sort.SearchFloat64s(latencyBuckets, s)
+	h.counts[i]++
+	h.sum += s
+	h.count++
+}
+
+// WritePrometheus writes the histogram in the Prometheus text exposition
+// format (version 0.0.4), one series per command kind seen so far.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kinds := make([]string, 0, len(m.commands))
+	for k := range m.commands {
+		kinds = append(kinds, string(k))
+	}
+	sort.Strings(kinds)
+
+	const name = "bingo_command_duration_seconds"
+	if _, err := fmt.Fprintf(w, "# HELP %s Time from the hub receiving a command to the event that answers it.\n# TYPE %s histogram\n", name, name); err != nil {
+		return err
+	}
+	for _, k := range kinds {
+		h := m.commands[protocol.CommandKind(k)]
+		var cum uint64
+		for i, le := range latencyBuckets {
+			cum += h.counts[i]
+			if _, err := fmt.Fprintf(w, "%s_bucket{command=%q,le=%q} %d\n", name, k, strconv.FormatFloat(le, 'g', -1, 64), cum); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket{command=%q,le=\"+Inf\"} %d\n%s_sum{command=%q} %s\n%s_count{command=%q} %d\n",
+			name, k, h.count, name, k, strconv.FormatFloat(h.sum, 'g', -1, 64), name, k, h.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package hub
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// observerCommands are the commands a read-only client may send: each reads
+// the session, the process or its binary without changing any of them. A
+// resume, a breakpoint or a line of input is the session owner's call.
+var observerCommands = map[protocol.CommandKind]bool{
+	protocol.CmdLogs:         true,
+	protocol.CmdCapabilities: true,
+	protocol.CmdStatus:       true,
+	protocol.CmdEcho:         true,
+	protocol.CmdSubscribe:    true,
+	protocol.CmdLocals:       true,
+	protocol.CmdInspectArgs:  true,
+	protocol.CmdFrames:       true,
+	protocol.CmdGoroutines:   true,
+	protocol.CmdResolveLine:  true,
+	protocol.CmdAddrToLine:   true,
+	protocol.CmdReadString:   true,
+	protocol.CmdReadSlice:    true,
+	protocol.CmdGetFile:      true,
+}
+
+// AddObserver is AddClient for a read-only client: it sees every event, but
+// a command outside observerCommands is refused with an EventError sent to
+// it alone, before it can reach the Run loop. Observers count as clients
+// for the session's lifetime like any other.
+func (h *Hub) AddObserver(conn WSConn, log *slog.Logger) *Client {
+	return h.addClient(conn, log, true)
+}
+
+// refuseReadOnly tells read-only client c that it may not send kind. Only c
+// is told: the refusal changes nothing anyone else could see.
+func (h *Hub) refuseReadOnly(c *Client, kind protocol.CommandKind) {
+	h.sendError(c, kind, fmt.Errorf("%s: read-only client", kind))
+}
@@ -0,0 +1,42 @@
+package hub
+
+import "github.com/bingosuite/bingo/pkg/protocol"
+
+// Outcome is how the session's most recent run ended, for whoever inspects
+// a session after its clients are gone (server.Options.Retention). Both
+// fields are nil until there is something to report.
+type Outcome struct {
+	// Exit is the process's exit, nil while it has not exited.
+	Exit *protocol.ProcessExitedPayload
+	// LastStop is where the process was last suspended. Unlike the stop
+	// location replayed to joining clients, it survives the resume, so it
+	// still says where a process that crashed on its way out was last seen.
+	LastStop *protocol.Location
+}
+
+// Outcome returns how the latest run ended. Safe from any goroutine; a new
+// launch or attach starts it over.
+func (h *Hub) Outcome() Outcome {
+	h.stateMu.RLock()
+	defer h.stateMu.RUnlock()
+	return h.outcome
+}
+
+// Logs returns up to limit of the session's most recent log entries, oldest
+// first, as CmdLogs does; limit <= 0 returns everything buffered. Safe from
+// any goroutine, including after Run has returned.
+func (h *Hub) Logs(limit int) []protocol.LogEntry {
+	return h.logs.recent(limit)
+}
+
+// recordExit notes the process's exit in the outcome.
+func (h *Hub) recordExit(evt protocol.Event) {
+	var p protocol.ProcessExitedPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		h.log.Warn("failed to decode process exit", "err", err)
+		return
+	}
+	h.stateMu.Lock()
+	h.outcome.Exit = &p
+	h.stateMu.Unlock()
+}
@@ -0,0 +1,228 @@
+package hub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// TargetRecord is what a PID file says about a process a session launched.
+// A traced process outlives a server that crashes, left stopped if it was
+// suspended, and nothing else remembers it was bingo's; the record is how
+// bingo cleanup finds it again. ServerPID tells a crashed server's targets
+// from those of one still running.
+type TargetRecord struct {
+	PID       int    `json:"pid"`
+	Session   string `json:"session"`
+	ServerPID int    `json:"serverPid"`
+	Program   string `json:"program"`
+}
+
+// Orphaned reports whether the server that wrote r is gone, leaving its
+// process to whoever finds the record.
+func (r TargetRecord) Orphaned() bool { return !processExists(r.ServerPID) }
+
+// Running reports whether r's process is still there. A PID the system has
+// since given to some other program does not count, and neither does one
+// whose executable can't be read back (no /proc, or not ours to read):
+// bingo cleanup signals what this reports, so a PID it can't tie to the
+// record's program is left alone.
+func (r TargetRecord) Running() bool {
+	if !processExists(r.PID) {
+		return false
+	}
+	exe, err := os.Readlink("/proc/" + strconv.Itoa(r.PID) + "/exe")
+	if err != nil {
+		return false
+	}
+	program := r.Program
+	if resolved, err := filepath.EvalSymlinks(program); err == nil {
+		program = resolved
+	}
+	return exe == program
+}
+
+// processExists reports whether a process with this PID exists. EPERM means
+// it does, just not as ours to signal.
+func processExists(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// TargetFile is a TargetRecord and the file it was read from.
+type TargetFile struct {
+	Path string
+	TargetRecord
+}
+
+const pidFileExt = ".pid.json"
+
+// DefaultPIDDir is where a server records its targets unless told
+// otherwise: the user's runtime directory, which the system clears at
+// logout or reboot along with the processes, or a per-user directory under
+// the temporary directory where there is none.
+func DefaultPIDDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "bingo")
+	}
+	return filepath.Join(os.TempDir(), "bingo-"+strconv.Itoa(os.Getuid()))
+}
+
+// checkPIDDir refuses a PID directory anyone but this user could have put
+// records in. The default one under the temporary directory has a name any
+// local user can create first, and cleanup kills what its records name.
+func checkPIDDir(dir string) error {
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is owned by uid %d, not %d", dir, st.Uid, os.Getuid())
+	}
+	if perm := fi.Mode().Perm(); perm != 0o700 {
+		return fmt.Errorf("%s has mode %#o, want 0700", dir, perm)
+	}
+	return nil
+}
+
+// writeTargetRecord records r in dir, in a file named after its session, and
+// returns the file's path. Session IDs are unique, so concurrent sessions,
+// even of different servers sharing dir, never write the same file. The file
+// is written under a temporary name and renamed, so a reader never sees half
+// a record.
+func writeTargetRecord(dir string, r TargetRecord) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("pid file: %w", err)
+	}
+	if err := checkPIDDir(dir); err != nil {
+		return "", fmt.Errorf("pid file: %w", err)
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("pid file: %w", err)
+	}
+	path := filepath.Join(dir, r.Session+pidFileExt)
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("pid file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("pid file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("pid file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("pid file: %w", err)
+	}
+	return path, nil
+}
+
+// ReadTargetFiles returns every record in dir. A missing directory holds
+// none, and one checkPIDDir refuses is an error rather than trusted; a file
+// that can't be parsed is skipped, since it can't name a process either way.
+func ReadTargetFiles(dir string) ([]TargetFile, error) {
+	if err := checkPIDDir(dir); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read pid files: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read pid files: %w", err)
+	}
+	var files []TargetFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), pidFileExt) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var r TargetRecord
+		if err := json.Unmarshal(data, &r); err != nil || r.PID <= 0 {
+			continue
+		}
+		files = append(files, TargetFile{Path: path, TargetRecord: r})
+	}
+	return files, nil
+}
+
+// PruneTargetFiles removes the records in dir that a crashed server left for
+// processes that are gone too, and returns the orphaned ones still running:
+// those only a signal will end. Records of live servers are left alone.
+func PruneTargetFiles(dir string) ([]TargetFile, error) {
+	files, err := ReadTargetFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var orphans []TargetFile
+	for _, f := range files {
+		switch {
+		case !f.Orphaned():
+		case f.Running():
+			orphans = append(orphans, f)
+		default:
+			if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return orphans, fmt.Errorf("prune pid files: %w", err)
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// recordTarget writes the PID file for the process the session just
+// launched, replacing any previous one. A failure is logged, not reported:
+// the record only matters if the server later crashes, and the launch it
+// describes has already succeeded. Run goroutine only.
+func (h *Hub) recordTarget() {
+	h.forgetTarget()
+	if h.opts.PIDDir == "" || h.sessionID == "" || h.dbg == nil || h.lastLaunch == nil {
+		return
+	}
+	st, err := h.dbg.Status()
+	if err != nil || st.PID == 0 {
+		return
+	}
+	program, err := filepath.Abs(h.lastLaunch.Program)
+	if err != nil {
+		program = h.lastLaunch.Program
+	}
+	path, err := writeTargetRecord(h.opts.PIDDir, TargetRecord{
+		PID:       st.PID,
+		Session:   h.sessionID,
+		ServerPID: os.Getpid(),
+		Program:   program,
+	})
+	if err != nil {
+		h.log.Warn("failed to record target pid", "pid", st.PID, "err", err)
+		return
+	}
+	h.pidFile = path
+}
+
+// forgetTarget removes the PID file of the session's process, once that
+// process is gone. Run goroutine only.
+func (h *Hub) forgetTarget() {
+	if h.pidFile == "" {
+		return
+	}
+	if err := os.Remove(h.pidFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		h.log.Warn("failed to remove pid file", "path", h.pidFile, "err", err)
+	}
+	h.pidFile = ""
+}
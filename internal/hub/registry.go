@@ -1,48 +1,64 @@
 package hub
 
-import "sync"
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
 
 // registry is a thread-safe set of clients. broadcast is called from the
-// hub's event-loop goroutine; add/remove may be called from any goroutine.
+// hub's event-loop goroutine for every event; add/remove may be called from
+// any goroutine, but only when a client comes or goes. So the set is
+// copy-on-write: writers build a new slice under mu and publish it, and
+// snapshot is a single atomic load, with no lock or copy per event.
 type registry struct {
-	mu      sync.RWMutex
-	clients map[*Client]struct{}
+	mu      sync.Mutex // serialises writers
+	clients atomic.Pointer[[]*Client]
 }
 
 func newRegistry() *registry {
-	return &registry{clients: make(map[*Client]struct{})}
+	r := &registry{}
+	r.clients.Store(&[]*Client{})
+	return r
 }
 
 func (r *registry) add(c *Client) {
 	r.mu.Lock()
-	r.clients[c] = struct{}{}
-	r.mu.Unlock()
+	defer r.mu.Unlock()
+	old := *r.clients.Load()
+	if slices.Contains(old, c) {
+		return
+	}
+	next := make([]*Client, len(old), len(old)+1)
+	copy(next, old)
+	next = append(next, c)
+	r.clients.Store(&next)
 }
 
 func (r *registry) remove(c *Client) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, ok := r.clients[c]; !ok {
+	old := *r.clients.Load()
+	i := slices.Index(old, c)
+	if i < 0 {
 		return false
 	}
-	delete(r.clients, c)
+	next := make([]*Client, 0, len(old)-1)
+	next = append(next, old[:i]...)
+	next = append(next, old[i+1:]...)
+	r.clients.Store(&next)
 	return true
 }
 
 func (r *registry) count() int {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	return len(r.clients)
+	return len(*r.clients.Load())
 }
 
+// snapshot returns the clients registered at the call. The slice is shared
+// with every other caller and must not be modified; a later add or remove
+// publishes a new one instead of touching it.
 func (r *registry) snapshot() []*Client {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	clients := make([]*Client, 0, len(r.clients))
-	for c := range r.clients {
-		clients = append(clients, c)
-	}
-	return clients
+	return *r.clients.Load()
 }
 
 // closeAll closes every client's send channel and empties the registry.
@@ -51,8 +67,7 @@ func (r *registry) snapshot() []*Client {
 func (r *registry) closeAll() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	for c := range r.clients {
+	for _, c := range *r.clients.Swap(&[]*Client{}) {
 		c.closeSend()
-		delete(r.clients, c)
 	}
 }
@@ -0,0 +1,86 @@
+package hub
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// runBudget is how much longer a launched process may run before the hub
+// pauses it (LaunchPayload.MaxRunTime). Only time spent in StateRunning is
+// charged: a user parked at a breakpoint is not what a CI run limit guards
+// against. The zero value has no limit. Touched only on the Run goroutine,
+// which owns every state transition.
+type runBudget struct {
+	limit   time.Duration
+	left    time.Duration
+	started time.Time
+	timer   *time.Timer
+}
+
+// reset replaces the budget with a fresh one of limit; zero disables it.
+func (b *runBudget) reset(limit time.Duration) {
+	b.stop()
+	*b = runBudget{limit: limit, left: limit}
+}
+
+// start arms the timer for whatever budget is left. Spent budgets stay
+// disarmed so a timed-out process the user resumes is not paused again.
+func (b *runBudget) start() {
+	if b.left <= 0 || b.timer != nil {
+		return
+	}
+	b.started = time.Now()
+	b.timer = time.NewTimer(b.left)
+}
+
+// stop disarms the timer and charges the time run since start.
+func (b *runBudget) stop() {
+	if b.timer == nil {
+		return
+	}
+	b.timer.Stop()
+	b.timer = nil
+	b.left -= time.Since(b.started)
+	if b.left <= 0 {
+		// Charging can round the remainder down to nothing before the
+		// timer fired; keep a sliver so the next start still trips it.
+		b.left = time.Nanosecond
+	}
+}
+
+// C is the channel the budget fires on, or nil (blocks forever in select)
+// while no timer is armed.
+func (b *runBudget) C() <-chan time.Time {
+	if b.timer == nil {
+		return nil
+	}
+	return b.timer.C
+}
+
+// handleRunTimeout spends the budget, tells clients why, and pauses the
+// process through the same path as a client Pause.
+func (h *Hub) handleRunTimeout() {
+	h.budget.timer = nil
+	h.budget.left = 0
+	if h.dbg == nil {
+		return
+	}
+
+	h.log.Warn("max run time exceeded, pausing", "maxRunTime", h.budget.limit)
+	evt, err := protocol.NewEvent(protocol.EventRunTimeout, 0, protocol.RunTimeoutPayload{
+		MaxRunTime: h.budget.limit,
+	})
+	if err != nil {
+		h.log.Error("failed to marshal run timeout event", "err", err)
+	} else {
+		h.broadcast(evt)
+	}
+
+	// ErrNotRunning: a stop raced the timer and is already on its way.
+	if err := h.dbg.Pause(); err != nil && !errors.Is(err, debugger.ErrNotRunning) {
+		h.broadcastError(protocol.CmdPause, err)
+	}
+}
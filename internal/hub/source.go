@@ -0,0 +1,71 @@
+package hub
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+const (
+	// maxSourceFileSize is the largest source file CmdGetFile serves. Go
+	// source past this is generated data, not something to read in a UI.
+	maxSourceFileSize = 8 << 20
+
+	// sourceChunkSize is the most CmdGetFile returns at once, so one large
+	// file can't hold up every other event queued for the client.
+	sourceChunkSize = 64 << 10
+)
+
+// readSourceChunk reads up to limit bytes of the source file at path from
+// offset on. A chunk that stops short of the end of the file is cut back to
+// its last full line, or failing that its last full rune, so every chunk is
+// valid text and lines are never split across two of them.
+func readSourceChunk(path string, offset int64, limit int) (protocol.FileContentsPayload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return protocol.FileContentsPayload{}, fmt.Errorf("get file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return protocol.FileContentsPayload{}, fmt.Errorf("get file: %w", err)
+	}
+	size := info.Size()
+	if size > maxSourceFileSize {
+		return protocol.FileContentsPayload{}, fmt.Errorf("get file: %s is %d bytes, over the %d byte limit", path, size, maxSourceFileSize)
+	}
+	if offset < 0 || offset > size {
+		return protocol.FileContentsPayload{}, fmt.Errorf("get file: offset %d outside %s (%d bytes)", offset, path, size)
+	}
+	if limit <= 0 || limit > sourceChunkSize {
+		limit = sourceChunkSize
+	}
+
+	buf := make([]byte, min(int64(limit), size-offset))
+	n, err := f.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return protocol.FileContentsPayload{}, fmt.Errorf("get file: %w", err)
+	}
+	buf = buf[:n]
+	if offset+int64(n) < size {
+		if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+			buf = buf[:i+1]
+		} else {
+			for len(buf) > 0 && !utf8.Valid(buf) {
+				buf = buf[:len(buf)-1]
+			}
+		}
+	}
+	return protocol.FileContentsPayload{
+		Path:   path,
+		Offset: offset,
+		Data:   string(buf),
+		Size:   size,
+		More:   offset+int64(len(buf)) < size,
+	}, nil
+}
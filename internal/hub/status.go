@@ -0,0 +1,35 @@
+package hub
+
+import (
+	"errors"
+
+	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// handleStatus answers CmdStatus with the engine's view of the process next
+// to the hub's own state and timestamps. With no debugger, or one whose loop has ended,
+// the process part needs no engine to answer.
+func (h *Hub) handleStatus(cmd protocol.Command) {
+	st := protocol.StatusPayload{Process: protocol.StateIdle}
+	if h.dbg != nil {
+		var err error
+		st, err = h.dbg.Status()
+		if errors.Is(err, debugger.ErrProcessExited) {
+			st = protocol.StatusPayload{Process: protocol.StateExited}
+		} else if err != nil {
+			h.broadcastError(cmd.Kind, err)
+			return
+		}
+	}
+	st.Session = h.State()
+	st.CreatedAt = h.CreatedAt()
+	st.LastActivity = h.LastActivity()
+
+	evt, err := protocol.NewEvent(protocol.EventStatus, 0, st)
+	if err != nil {
+		h.broadcastError(cmd.Kind, err)
+		return
+	}
+	h.broadcast(evt)
+}
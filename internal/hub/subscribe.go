@@ -0,0 +1,45 @@
+package hub
+
+import (
+	"fmt"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// handleSubscribe sets the event kinds broadcasts to the sending client are
+// filtered to, for a UI that only renders some of them; an empty list lifts
+// the filter. broadcastLocked applies it, so every broadcast is covered
+// whatever it answers. The reply goes to the sender alone, like Echo's, and
+// so does an error: another client's filter is no concern of anyone else's.
+func (h *Hub) handleSubscribe(cc clientCommand) {
+	if cc.from == nil {
+		h.broadcastError(cc.cmd.Kind, fmt.Errorf("subscribe: no client to subscribe"))
+		return
+	}
+	var p protocol.SubscribePayload
+	if err := protocol.DecodeCommandPayload(cc.cmd, &p); err != nil {
+		h.sendError(cc.from, cc.cmd.Kind, err)
+		return
+	}
+	var events map[protocol.EventKind]bool
+	if len(p.Events) > 0 {
+		events = make(map[protocol.EventKind]bool, len(p.Events))
+		for _, k := range p.Events {
+			if k == "" {
+				h.sendError(cc.from, cc.cmd.Kind, fmt.Errorf("subscribe: empty event kind"))
+				return
+			}
+			events[k] = true
+		}
+	}
+	evt, err := protocol.NewEvent(protocol.EventSubscribed, 0, p)
+	if err != nil {
+		h.sendError(cc.from, cc.cmd.Kind, err)
+		return
+	}
+	h.emitMu.Lock()
+	defer h.emitMu.Unlock()
+	cc.from.events = events
+	h.sendLocked(cc.from, evt)
+	h.log.Info("client subscribed", "events", len(events))
+}
@@ -2,18 +2,33 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/bingosuite/bingo/pkg/protocol"
 )
 
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  4096,
 	WriteBufferSize: 4096,
 	CheckOrigin:     sameHostOrigin,
+	Subprotocols:    []string{protocol.Subprotocol},
+}
+
+// acceptsSubprotocol reports whether r can be served: it offers no
+// subprotocol (every client before Subprotocol existed) or offers ours. The
+// upgrader would otherwise complete the handshake without one and leave the
+// client to discover the mismatch from the messages.
+func acceptsSubprotocol(r *http.Request) bool {
+	offered := websocket.Subprotocols(r)
+	return len(offered) == 0 || slices.Contains(offered, protocol.Subprotocol)
 }
 
 func sameHostOrigin(r *http.Request) bool {
@@ -41,10 +56,53 @@ func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleSessionLogs: GET /api/sessions/{id}/logs[?limit=n], the session's
+// buffered log entries as CmdLogs would return them. Works on a retained
+// session too, which is the point: its clients, and CmdLogs, are gone.
+func (s *Server) handleSessionLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	sess := s.sessions.get(r.PathValue("id"))
+	if sess == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(protocol.LogsPayload{Entries: sess.hub.Logs(limit)}); err != nil {
+		s.log.Error("failed to encode session logs", "err", err)
+	}
+}
+
+// handleMetrics: GET /metrics, in the Prometheus text format.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := s.metrics.WritePrometheus(w); err != nil {
+		s.log.Error("failed to write metrics", "err", err)
+	}
+}
+
 // handleWS upgrades to WebSocket and either creates or joins a session.
 //
 //	GET /ws?create        — create + join
 //	GET /ws?session={id}  — join existing
+//
+// Either may add v={protocol.Version}; an incompatible one is refused before
+// the upgrade.
 func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
@@ -56,6 +114,17 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if v := query.Get("v"); !protocol.CompatibleVersion(v) {
+		w.Header().Set(protocol.VersionHeader, protocol.Version)
+		http.Error(w, fmt.Sprintf("protocol version %s not supported, server speaks %s", v, protocol.Version),
+			http.StatusBadRequest)
+		return
+	}
+	if !acceptsSubprotocol(r) {
+		http.Error(w, "unsupported WebSocket subprotocol, want "+protocol.Subprotocol, http.StatusBadRequest)
+		return
+	}
+
 	// Upgrade before session logic so we can send descriptive close frames on error.
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -82,7 +151,7 @@ func (s *Server) wsCreate(conn *websocket.Conn, log *slog.Logger) {
 func (s *Server) wsJoin(conn *websocket.Conn, sessionID string, log *slog.Logger) {
 	log = log.With("session", sessionID, "action", "join")
 
-	sess := s.sessions.get(sessionID)
+	sess := s.sessions.getLive(sessionID)
 	if sess == nil {
 		log.Warn("session not found")
 		msg := websocket.FormatCloseMessage(
@@ -4,21 +4,104 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/bingosuite/bingo/internal/dap"
+	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/internal/hub"
+	"github.com/bingosuite/bingo/pkg/protocol"
 )
 
+// Options configures optional server behaviour. The zero value matches New.
+type Options struct {
+	// Session is applied to the hub of every session the server creates.
+	Session hub.Options
+	// Debugger is applied to every debugger a session launches or attaches.
+	Debugger debugger.Options
+
+	// Retention keeps a session that has ended listed in /api/sessions, with
+	// its exit code and last location, and its logs at
+	// /api/sessions/{id}/logs, for this long before it is removed. It can't
+	// be joined meanwhile. Zero removes sessions as soon as they end.
+	Retention time.Duration
+
+	// TLSCertFile and TLSKeyFile, when both set, make Start serve HTTPS (and
+	// so wss:// for /ws). The DAP listener is unaffected.
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// Validate checks opts before anything is started, so a misconfigured server
+// fails at startup with a message naming the bad setting.
+func (o Options) Validate() error {
+	if err := o.Session.Validate(); err != nil {
+		return fmt.Errorf("server options: %w", err)
+	}
+	if o.Debugger.MaxSliceElements < 0 {
+		return fmt.Errorf("server options: max slice elements must not be negative, got %d", o.Debugger.MaxSliceElements)
+	}
+	if m := o.Debugger.InitialStop; m != "" && !m.Valid() {
+		return fmt.Errorf("server options: initial stop must be %s, %s or %s, got %q",
+			protocol.InitialStopEntry, protocol.InitialStopMain, protocol.InitialStopRun, m)
+	}
+	if o.Retention < 0 {
+		return fmt.Errorf("server options: retention must not be negative, got %s", o.Retention)
+	}
+	if (o.TLSCertFile == "") != (o.TLSKeyFile == "") {
+		return errors.New("server options: TLS needs both a certificate and a key file")
+	}
+	if o.TLSCertFile == "" {
+		return nil
+	}
+	if err := checkReadableFile("TLS certificate", o.TLSCertFile); err != nil {
+		return fmt.Errorf("server options: %w", err)
+	}
+	if err := checkReadableFile("TLS key", o.TLSKeyFile); err != nil {
+		return fmt.Errorf("server options: %w", err)
+	}
+	// Readable is not enough: a swapped pair or a truncated PEM would
+	// otherwise surface only as the first client's failed handshake.
+	if _, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile); err != nil {
+		return fmt.Errorf("server options: TLS certificate and key: %w", err)
+	}
+	return nil
+}
+
+// checkReadableFile reports why path can't serve as a file the server reads
+// at startup: missing, unreadable, or a directory (which os.Stat accepts and
+// only fails much later, on first read).
+func checkReadableFile(what, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", what, err)
+	}
+	defer func() { _ = f.Close() }()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("%s: %w", what, err)
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("%s %s: is a directory", what, path)
+	}
+	return nil
+}
+
 // Server owns the HTTP listener, the session store, and the lifecycle of all
 // debug sessions.
 type Server struct {
 	httpServer *http.Server
+	tlsCert    string
+	tlsKey     string
 	dapServer  *dap.Server
 	sessions   *sessionStore
+	metrics    *hub.Metrics
 	log        *slog.Logger
 	ctx        context.Context
 	cancel     context.CancelFunc
@@ -26,14 +109,28 @@ type Server struct {
 
 // New creates a Server that will listen on addr (e.g. ":6060").
 func New(addr string, log *slog.Logger) *Server {
+	return NewWithOptions(addr, Options{}, log)
+}
+
+// NewWithOptions is New with explicit Options.
+func NewWithOptions(addr string, opts Options, log *slog.Logger) *Server {
 	if log == nil {
 		log = slog.Default()
 	}
 
+	// Every session records into one Metrics, so /metrics covers the whole
+	// server rather than whichever session happens to be asked.
+	if opts.Session.Metrics == nil {
+		opts.Session.Metrics = hub.NewMetrics()
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	s := &Server{
-		sessions: newSessionStore(log.With("component", "sessions")),
+		sessions: newSessionStore(opts, log.With("component", "sessions")),
+		metrics:  opts.Session.Metrics,
+		tlsCert:  opts.TLSCertFile,
+		tlsKey:   opts.TLSKeyFile,
 		log:      log,
 		ctx:      ctx,
 		cancel:   cancel,
@@ -41,7 +138,9 @@ func New(addr string, log *slog.Logger) *Server {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/sessions", s.handleListSessions)
+	mux.HandleFunc("/api/sessions/{id}/logs", s.handleSessionLogs)
 	mux.HandleFunc("/ws", s.handleWS)
+	mux.HandleFunc("/metrics", s.handleMetrics)
 
 	s.httpServer = &http.Server{
 		Addr:              addr,
@@ -54,21 +153,30 @@ func New(addr string, log *slog.Logger) *Server {
 
 // Start blocks until shutdown or a fatal listener error.
 func (s *Server) Start() error {
+	if (s.tlsCert == "") != (s.tlsKey == "") {
+		return errors.New("server: TLS needs both a certificate and a key file")
+	}
 	ln, err := net.Listen("tcp4", s.httpServer.Addr)
 	if err != nil {
 		return err
 	}
-	s.log.Info("bingo server listening", "addr", ln.Addr().String())
-
-	err = s.httpServer.Serve(ln)
+	if s.tlsCert != "" && s.tlsKey != "" {
+		s.log.Info("bingo server listening", "addr", ln.Addr().String(), "tls", true)
+		err = s.httpServer.ServeTLS(ln, s.tlsCert, s.tlsKey)
+	} else {
+		s.log.Info("bingo server listening", "addr", ln.Addr().String())
+		err = s.httpServer.Serve(ln)
+	}
 	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
 	return err
 }
 
-// Shutdown closes the HTTP listener, drains in-flight requests, and cancels
-// all session contexts.
+// Shutdown closes the HTTP listener, drains in-flight requests, cancels all
+// session contexts, and waits for the sessions to stop. A session stops by
+// killing its debuggee, so once Shutdown returns no traced process is left
+// behind — unless timeout ran out first, which is logged.
 func (s *Server) Shutdown(timeout time.Duration) {
 	s.log.Info("shutting down server")
 	s.cancel()
@@ -84,4 +192,7 @@ func (s *Server) Shutdown(timeout time.Duration) {
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		s.log.Error("http shutdown error", "err", err)
 	}
+	if err := s.sessions.wait(ctx); err != nil {
+		s.log.Warn("sessions still stopping at shutdown timeout", "remaining", s.sessions.count())
+	}
 }
@@ -1,10 +1,23 @@
 package server
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +26,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/internal/hub"
 	"github.com/bingosuite/bingo/pkg/protocol"
 )
 
@@ -104,6 +119,33 @@ var _ = Describe("Server", func() {
 			Expect(sessions).To(HaveLen(1))
 			Expect(sessions[0].State).To(Equal(protocol.StateIdle))
 			Expect(sessions[0].Clients).To(Equal(1))
+			Expect(sessions[0].CreatedAt).To(BeTemporally("~", time.Now(), 5*time.Second))
+			Expect(sessions[0].LastActivity).NotTo(BeTemporally("<", sessions[0].CreatedAt),
+				"connecting is activity")
+		})
+	})
+
+	Describe("GET /metrics", func() {
+		It("serves command latency once a session has run a command", func() {
+			conn, _, err := websocket.DefaultDialer.Dial(toWS(ts, "/ws?create"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			defer closeWS(conn)
+			_, _ = recvState(conn)
+
+			Expect(conn.WriteJSON(protocol.Command{
+				Version: protocol.Version, Kind: protocol.CmdLogs, Payload: json.RawMessage(`{}`),
+			})).To(Succeed())
+
+			Eventually(func() string {
+				resp, err := http.Get(ts.URL + "/metrics")
+				if err != nil {
+					return ""
+				}
+				defer resp.Body.Close() //nolint:errcheck
+				Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/plain"))
+				body, _ := io.ReadAll(resp.Body)
+				return string(body)
+			}, "2s", "20ms").Should(ContainSubstring(`bingo_command_duration_seconds_count{command="Logs"} 1`))
 		})
 	})
 
@@ -138,6 +180,46 @@ var _ = Describe("Server", func() {
 				Expect(resp.StatusCode).To(Equal(http.StatusSwitchingProtocols))
 			})
 
+			It("accepts a client on a newer minor protocol version", func() {
+				conn, resp, err := websocket.DefaultDialer.Dial(toWS(ts, "/ws?create&v=1.9"), nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer closeWS(conn)
+				Expect(resp.StatusCode).To(Equal(http.StatusSwitchingProtocols))
+			})
+
+			It("refuses a client on another major protocol version", func() {
+				conn, resp, err := websocket.DefaultDialer.Dial(toWS(ts, "/ws?create&v=2.0"), nil)
+				if conn != nil {
+					closeWS(conn)
+				}
+				Expect(err).To(HaveOccurred())
+				Expect(resp).NotTo(BeNil())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(resp.Header.Get(protocol.VersionHeader)).To(Equal(protocol.Version))
+				Expect(srv.sessions.list()).To(BeEmpty())
+			})
+
+			It("negotiates the bingo subprotocol when the client offers it", func() {
+				d := websocket.Dialer{Subprotocols: []string{"other.v9", protocol.Subprotocol}}
+				conn, resp, err := d.Dial(toWS(ts, "/ws?create"), nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer closeWS(conn)
+				Expect(resp.StatusCode).To(Equal(http.StatusSwitchingProtocols))
+				Expect(conn.Subprotocol()).To(Equal(protocol.Subprotocol))
+			})
+
+			It("refuses a client that offers only unknown subprotocols", func() {
+				d := websocket.Dialer{Subprotocols: []string{"bingo.v0"}}
+				conn, resp, err := d.Dial(toWS(ts, "/ws?create"), nil)
+				if conn != nil {
+					closeWS(conn)
+				}
+				Expect(err).To(HaveOccurred())
+				Expect(resp).NotTo(BeNil())
+				Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(srv.sessions.list()).To(BeEmpty())
+			})
+
 			It("rejects cross-host browser origins", func() {
 				header := http.Header{"Origin": []string{"http://evil.example"}}
 				conn, resp, err := websocket.DefaultDialer.Dial(toWS(ts, "/ws?create"), header)
@@ -194,6 +276,40 @@ var _ = Describe("Server", func() {
 				Expect(p2.Clients).To(Equal(2))
 			})
 
+			It("keeps each client's seq stream gap-free while others join", func() {
+				conn1, _, err := websocket.DefaultDialer.Dial(toWS(ts, "/ws?create"), nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer closeWS(conn1)
+				p1, _ := recvState(conn1)
+
+				logs, err := json.Marshal(protocol.Command{
+					Version: protocol.Version, Kind: protocol.CmdLogs, Payload: json.RawMessage(`{}`),
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				// Each join sends the newcomer a welcome of its own; conn1
+				// must not see that as a hole in its stream.
+				var seqs []uint64
+				for range 3 {
+					conn2, _, err := websocket.DefaultDialer.Dial(
+						toWS(ts, "/ws?session="+p1.SessionID), nil)
+					Expect(err).NotTo(HaveOccurred())
+					_, _ = recvState(conn2)
+					Expect(conn1.WriteMessage(websocket.TextMessage, logs)).To(Succeed())
+
+					_ = conn1.SetReadDeadline(time.Now().Add(time.Second))
+					_, msg, err := conn1.ReadMessage()
+					Expect(err).NotTo(HaveOccurred())
+					var evt protocol.Event
+					Expect(json.Unmarshal(msg, &evt)).To(Succeed())
+					Expect(evt.Kind).To(Equal(protocol.EventLogs))
+					seqs = append(seqs, evt.Seq)
+					closeWS(conn2)
+				}
+				Expect(seqs[1]).To(Equal(seqs[0] + 1))
+				Expect(seqs[2]).To(Equal(seqs[1] + 1))
+			})
+
 			It("closes the connection when the session does not exist", func() {
 				conn, _, err := websocket.DefaultDialer.Dial(
 					toWS(ts, "/ws?session=does-not-exist"), nil)
@@ -250,6 +366,102 @@ var _ = Describe("Server", func() {
 		})
 	})
 
+	Describe("retention of finished sessions", func() {
+		var (
+			rsrv *Server
+			rts  *httptest.Server
+		)
+
+		BeforeEach(func() {
+			rsrv = NewWithOptions(":0", Options{Retention: 300 * time.Millisecond}, nil)
+			rts = httptest.NewServer(rsrv.httpServer.Handler)
+		})
+
+		AfterEach(func() {
+			rsrv.cancel()
+			rts.Close()
+		})
+
+		listRetained := func() []SessionInfo {
+			resp, err := http.Get(rts.URL + "/api/sessions")
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			defer resp.Body.Close() //nolint:errcheck
+			var sessions []SessionInfo
+			ExpectWithOffset(1, json.NewDecoder(resp.Body).Decode(&sessions)).To(Succeed())
+			return sessions
+		}
+
+		It("keeps an ended session listed and its logs readable, then reaps it", func() {
+			conn, _, err := websocket.DefaultDialer.Dial(toWS(rts, "/ws?create"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			p, err := recvState(conn)
+			Expect(err).NotTo(HaveOccurred())
+			closeWS(conn)
+
+			Eventually(func() time.Time {
+				sessions := listRetained()
+				if len(sessions) != 1 {
+					return time.Time{}
+				}
+				return sessions[0].FinishedAt
+			}, "2s", "20ms").ShouldNot(BeZero())
+
+			resp, err := http.Get(rts.URL + "/api/sessions/" + p.SessionID + "/logs")
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close() //nolint:errcheck
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			var logs protocol.LogsPayload
+			Expect(json.NewDecoder(resp.Body).Decode(&logs)).To(Succeed())
+			Expect(logs.Entries).NotTo(BeEmpty())
+
+			Eventually(rsrv.sessions.count, "2s", "20ms").Should(Equal(0))
+		})
+
+		It("refuses to let a client join a retained session", func() {
+			conn, _, err := websocket.DefaultDialer.Dial(toWS(rts, "/ws?create"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			p, err := recvState(conn)
+			Expect(err).NotTo(HaveOccurred())
+			closeWS(conn)
+			Eventually(func() bool {
+				s := rsrv.sessions.get(p.SessionID)
+				return s != nil && rsrv.sessions.getLive(p.SessionID) == nil
+			}, "2s", "20ms").Should(BeTrue())
+
+			joined, _, err := websocket.DefaultDialer.Dial(toWS(rts, "/ws?session="+p.SessionID), nil)
+			Expect(err).NotTo(HaveOccurred())
+			defer closeWS(joined)
+			_ = joined.SetReadDeadline(time.Now().Add(time.Second))
+			_, _, err = joined.ReadMessage()
+			Expect(websocket.IsCloseError(err, websocket.CloseNormalClosure)).To(BeTrue())
+		})
+
+		It("reaps retained sessions at once on shutdown", func() {
+			rsrv.cancel()
+			rsrv = NewWithOptions(":0", Options{Retention: time.Hour}, nil)
+			rts.Close()
+			rts = httptest.NewServer(rsrv.httpServer.Handler)
+
+			conn, _, err := websocket.DefaultDialer.Dial(toWS(rts, "/ws?create"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, _ = recvState(conn)
+			closeWS(conn)
+			Eventually(func() []SessionInfo { return listRetained() }, "2s", "20ms").Should(
+				ContainElement(HaveField("FinishedAt", Not(BeZero()))))
+
+			rsrv.Shutdown(2 * time.Second)
+
+			Expect(rsrv.sessions.count()).To(Equal(0))
+		})
+
+		It("answers 404 for the logs of an unknown session", func() {
+			resp, err := http.Get(rts.URL + "/api/sessions/does-not-exist/logs")
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close() //nolint:errcheck
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
 	Describe("Start and Shutdown", func() {
 		It("starts on a random port and shuts down cleanly", func() {
 			s := New("127.0.0.1:0", nil)
@@ -263,5 +475,149 @@ var _ = Describe("Server", func() {
 
 			Eventually(errCh, "2s").Should(Receive(BeNil()))
 		})
+
+		It("returns only after every session has stopped", func() {
+			conn, _, err := websocket.DefaultDialer.Dial(toWS(ts, "/ws?create"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			defer closeWS(conn)
+
+			Eventually(srv.sessions.count, "2s", "50ms").Should(Equal(1))
+
+			srv.Shutdown(2 * time.Second)
+
+			Expect(srv.sessions.count()).To(Equal(0))
+		})
+
+		It("refuses to start with a TLS certificate but no key", func() {
+			s := NewWithOptions("127.0.0.1:0", Options{TLSCertFile: "cert.pem"}, nil)
+			Expect(s.Start()).To(MatchError(ContainSubstring("both a certificate and a key")))
+		})
+
+		It("serves HTTPS when given a certificate and key", func() {
+			certFile, keyFile := writeSelfSignedCert(GinkgoT().TempDir())
+			addr := freeAddr()
+			s := NewWithOptions(addr, Options{TLSCertFile: certFile, TLSKeyFile: keyFile}, nil)
+
+			errCh := make(chan error, 1)
+			go func() { errCh <- s.Start() }()
+			defer func() {
+				s.Shutdown(time.Second)
+				Eventually(errCh, "2s").Should(Receive(BeNil()))
+			}()
+
+			httpClient := &http.Client{Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // self-signed test cert
+			}}
+			Eventually(func() error {
+				resp, err := httpClient.Get("https://" + addr + "/api/sessions")
+				if err != nil {
+					return err
+				}
+				return resp.Body.Close()
+			}, "2s", "20ms").Should(Succeed())
+		})
+	})
+
+	Describe("Options.Validate", func() {
+		It("accepts the zero value", func() {
+			Expect(Options{}.Validate()).To(Succeed())
+		})
+
+		It("accepts a readable certificate and key pair", func() {
+			certFile, keyFile := writeSelfSignedCert(GinkgoT().TempDir())
+			Expect(Options{TLSCertFile: certFile, TLSKeyFile: keyFile}.Validate()).To(Succeed())
+		})
+
+		It("rejects a certificate path that is a directory", func() {
+			dir := GinkgoT().TempDir()
+			_, keyFile := writeSelfSignedCert(dir)
+			err := Options{TLSCertFile: dir, TLSKeyFile: keyFile}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("TLS certificate " + dir + ": is a directory")))
+		})
+
+		It("rejects a key file it cannot read", func() {
+			if os.Geteuid() == 0 {
+				Skip("root reads files regardless of their mode")
+			}
+			certFile, keyFile := writeSelfSignedCert(GinkgoT().TempDir())
+			Expect(os.Chmod(keyFile, 0)).To(Succeed())
+			err := Options{TLSCertFile: certFile, TLSKeyFile: keyFile}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("TLS key")))
+			Expect(errors.Is(err, os.ErrPermission)).To(BeTrue())
+		})
+
+		It("rejects a key that does not match the certificate", func() {
+			certFile, _ := writeSelfSignedCert(GinkgoT().TempDir())
+			_, otherKey := writeSelfSignedCert(GinkgoT().TempDir())
+			err := Options{TLSCertFile: certFile, TLSKeyFile: otherKey}.Validate()
+			Expect(err).To(MatchError(ContainSubstring("TLS certificate and key")))
+		})
+
+		DescribeTable("rejects",
+			func(opts Options, want string) {
+				Expect(opts.Validate()).To(MatchError(ContainSubstring(want)))
+			},
+			Entry("a negative log buffer",
+				Options{Session: hub.Options{LogBufferSize: -1}}, "log buffer size"),
+			Entry("a negative idle timeout",
+				Options{Session: hub.Options{IdleTimeout: -time.Second}}, "idle timeout"),
+			Entry("a negative disconnect grace",
+				Options{Session: hub.Options{DisconnectGrace: -time.Second}}, "disconnect grace"),
+			Entry("a disconnect grace without keep-alive",
+				Options{Session: hub.Options{DisconnectGrace: time.Minute}}, "needs keep-alive"),
+			Entry("an unknown disconnect action",
+				Options{Session: hub.Options{DisconnectAction: "detach"}}, "disconnect action"),
+			Entry("a default breakpoint without a line",
+				Options{Session: hub.Options{DefaultBreakpoints: []protocol.Location{{File: "main.go"}}}}, "default breakpoint"),
+			Entry("leak detection without goroutine snapshots",
+				Options{Session: hub.Options{LeakThreshold: 10}}, "needs goroutine snapshots"),
+			Entry("a one-snapshot leak window",
+				Options{Session: hub.Options{GoroutineSnapshots: true, LeakThreshold: 10, LeakWindow: 1}}, "leak window"),
+			Entry("a negative slice element cap",
+				Options{Debugger: debugger.Options{MaxSliceElements: -1}}, "max slice elements"),
+			Entry("an unknown initial stop",
+				Options{Debugger: debugger.Options{InitialStop: "later"}}, `initial stop must be stop, continue-to-main or run, got "later"`),
+			Entry("a negative retention",
+				Options{Retention: -time.Second}, "retention"),
+			Entry("a key without a certificate",
+				Options{TLSKeyFile: "key.pem"}, "both a certificate and a key"),
+			Entry("a certificate file that does not exist",
+				Options{TLSCertFile: "/nonexistent/cert.pem", TLSKeyFile: "/nonexistent/key.pem"}, "cert.pem"),
+		)
 	})
 })
+
+// freeAddr returns a loopback address with a port that was free a moment ago.
+func freeAddr() string {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	addr := ln.Addr().String()
+	ExpectWithOffset(1, ln.Close()).To(Succeed())
+	return addr
+}
+
+// writeSelfSignedCert writes a throwaway localhost certificate and key into
+// dir and returns their paths.
+func writeSelfSignedCert(dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	ExpectWithOffset(1, os.WriteFile(certFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)).To(Succeed())
+	ExpectWithOffset(1, os.WriteFile(keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)).To(Succeed())
+	return certFile, keyFile
+}
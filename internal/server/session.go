@@ -14,38 +14,66 @@ import (
 )
 
 // SessionInfo is the public view of a session, returned by the listing API.
+// FinishedAt is set once the session has ended and is only being retained
+// for inspection (Options.Retention); ExitCode and LastLocation then say how
+// its last run ended, where known. LastActivity is when a client last
+// connected or sent a command, which the idle timeout counts from.
 type SessionInfo struct {
-	ID        string                `json:"id"`
-	State     protocol.SessionState `json:"state"`
-	Clients   int                   `json:"clients"`
-	CreatedAt time.Time             `json:"createdAt"`
+	ID           string                `json:"id"`
+	State        protocol.SessionState `json:"state"`
+	Clients      int                   `json:"clients"`
+	CreatedAt    time.Time             `json:"createdAt"`
+	LastActivity time.Time             `json:"lastActivity"`
+	FinishedAt   time.Time             `json:"finishedAt,omitzero"`
+	ExitCode     *int                  `json:"exitCode,omitempty"`
+	LastLocation *protocol.Location    `json:"lastLocation,omitempty"`
 }
 
 type session struct {
-	id        string
-	hub       *hub.Hub
-	createdAt time.Time
+	id  string
+	hub *hub.Hub
+	// finishedAt is when the hub stopped, zero while it runs. Guarded by
+	// the store's mu.
+	finishedAt time.Time
+	// removed is closed once the hub has stopped (killing its debuggee) and
+	// the session is out of the store.
+	removed chan struct{}
 }
 
+// info describes s. Callers hold the store's mu, for finishedAt.
 func (s *session) info() SessionInfo {
-	return SessionInfo{
-		ID:        s.id,
-		State:     s.hub.State(),
-		Clients:   s.hub.ClientCount(),
-		CreatedAt: s.createdAt,
+	info := SessionInfo{
+		ID:           s.id,
+		State:        s.hub.State(),
+		Clients:      s.hub.ClientCount(),
+		CreatedAt:    s.hub.CreatedAt(),
+		LastActivity: s.hub.LastActivity(),
+		FinishedAt:   s.finishedAt,
 	}
+	if s.finishedAt.IsZero() {
+		return info
+	}
+	out := s.hub.Outcome()
+	if out.Exit != nil {
+		code := out.Exit.ExitCode
+		info.ExitCode = &code
+	}
+	info.LastLocation = out.LastStop
+	return info
 }
 
 // sessionStore is the goroutine-safe set of active sessions.
 type sessionStore struct {
 	mu       sync.RWMutex
 	sessions map[string]*session
+	opts     Options
 	log      *slog.Logger
 }
 
-func newSessionStore(log *slog.Logger) *sessionStore {
+func newSessionStore(opts Options, log *slog.Logger) *sessionStore {
 	return &sessionStore{
 		sessions: make(map[string]*session),
+		opts:     opts,
 		log:      log,
 	}
 }
@@ -57,19 +85,22 @@ func (ss *sessionStore) create(ctx context.Context) *session {
 
 	log := ss.log.With("session", id)
 
-	// Each launch/re-launch gets a fresh debugger, sharing the session's
-	// scoped logger so debugger logs are correlated with the rest of the
-	// session's log lines instead of going to the package-level default.
+	// Each launch/re-launch gets a fresh debugger, sharing the hub's session
+	// logger so debugger logs are correlated with the rest of the session's
+	// log lines and captured in its CmdLogs buffer. The factory only runs
+	// once Run is processing commands, by which time h is assigned.
+	var h *hub.Hub
 	factory := func() debugger.Debugger {
-		return debugger.New(log)
+		return debugger.NewWithOptions(ss.opts.Debugger, h.Logger())
 	}
 
-	h := hub.NewSession(id, factory, log)
+	h = hub.NewSession(id, factory, log)
+	h.Configure(ss.opts.Session)
 
 	s := &session{
-		id:        id,
-		hub:       h,
-		createdAt: time.Now(),
+		id:      id,
+		hub:     h,
+		removed: make(chan struct{}),
 	}
 
 	ss.mu.Lock()
@@ -78,26 +109,81 @@ func (ss *sessionStore) create(ctx context.Context) *session {
 
 	go func() {
 		h.Run(ctx)
+		ss.retain(ctx, s)
 		ss.remove(id)
 		log.Info("session removed")
+		close(s.removed)
 	}()
 
 	ss.log.Info("session created", "id", id)
 	return s
 }
 
+// retain keeps the finished session s listed for Options.Retention, so its
+// outcome and logs can still be fetched once the last client has gone.
+// Server shutdown (ctx) ends the wait at once: there is no one left to ask.
+func (ss *sessionStore) retain(ctx context.Context, s *session) {
+	if ss.opts.Retention <= 0 || ctx.Err() != nil {
+		return
+	}
+	ss.mu.Lock()
+	s.finishedAt = time.Now()
+	ss.mu.Unlock()
+	ss.log.Info("session finished; retaining", "id", s.id, "retention", ss.opts.Retention)
+
+	t := time.NewTimer(ss.opts.Retention)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
 func (ss *sessionStore) get(id string) *session {
 	ss.mu.RLock()
 	defer ss.mu.RUnlock()
 	return ss.sessions[id]
 }
 
+// getLive is get for sessions still running: a retained one can be
+// inspected but not joined.
+func (ss *sessionStore) getLive(id string) *session {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	s := ss.sessions[id]
+	if s == nil || !s.finishedAt.IsZero() {
+		return nil
+	}
+	return s
+}
+
 func (ss *sessionStore) remove(id string) {
 	ss.mu.Lock()
 	delete(ss.sessions, id)
 	ss.mu.Unlock()
 }
 
+// wait blocks until every session present at the call has been removed, or
+// ctx is done. It doesn't stop anything itself; cancel the sessions' context
+// first.
+func (ss *sessionStore) wait(ctx context.Context) error {
+	ss.mu.RLock()
+	pending := make([]*session, 0, len(ss.sessions))
+	for _, s := range ss.sessions {
+		pending = append(pending, s)
+	}
+	ss.mu.RUnlock()
+
+	for _, s := range pending {
+		select {
+		case <-s.removed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 func (ss *sessionStore) list() []SessionInfo {
 	ss.mu.RLock()
 	defer ss.mu.RUnlock()
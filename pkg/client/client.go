@@ -4,26 +4,80 @@
 package client
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/bingosuite/bingo/pkg/protocol"
+
+	"github.com/gorilla/websocket"
 )
 
-const listSessionsTimeout = 5 * time.Second
+const restTimeout = 5 * time.Second
+
+// ErrNotRunning is returned by Interrupt when the session isn't running.
+var ErrNotRunning = errors.New("client: session is not running")
+
+// ErrBreakpointExists is returned by SetBreakpoint, together with the
+// breakpoint already there, when the line already has one: the call changed
+// nothing.
+var ErrBreakpointExists = errors.New("client: breakpoint already set")
+
+// ErrSessionEnded is returned by WaitForState when the process exits before
+// the session reaches the state waited for.
+var ErrSessionEnded = errors.New("client: session ended")
+
+// ErrIncompatibleVersion is returned by Create and Join when the server
+// speaks a different major protocol version than this client.
+var ErrIncompatibleVersion = errors.New("client: incompatible protocol version")
 
 // Client interacts with a bingo debug server. All methods are goroutine-safe.
 type Client interface {
 	SessionID() string
 	State() protocol.SessionState
 
+	// WaitForState blocks until the session is in state, returning at once if
+	// it already is. Waiting for running or suspended fails with
+	// ErrSessionEnded once the process exits; any wait fails when ctx is done
+	// or the connection drops. Pair it with the fire-and-forget commands:
+	// Continue, then wait for StateSuspended. Every state reported after the
+	// call counts, however briefly it held.
+	WaitForState(ctx context.Context, state protocol.SessionState) error
+
+	// MissedEvents counts events the server sent that never arrived: the
+	// hub numbers its broadcasts consecutively, so a jump in Event.Seq is a
+	// loss. Each jump is also logged as a warning.
+	MissedEvents() uint64
+
 	// Events delivers async server events. Closed when the connection drops or
 	// Close is called. Callers must drain continuously to avoid backpressure.
 	Events() <-chan protocol.Event
 
+	// Breakpoints delivers every breakpoint hit, in addition to its
+	// EventBreakpointHit on Events(), for `for hit := range c.Breakpoints()`
+	// scripting. Closed together with Events(). When nobody keeps up, hits
+	// are dropped unless Options.BlockOnBreakpoints is set.
+	Breakpoints() <-chan protocol.BreakpointHitPayload
+
 	Launch(program string, args, env []string) error
+
+	// LaunchWith is Launch with every LaunchPayload option, such as
+	// MaxRunTime.
+	LaunchWith(p protocol.LaunchPayload) error
+
+	// LaunchContext is LaunchWith that blocks until the server confirms the
+	// process is running via EventDebuggerReady, whose payload (with the
+	// PID) it returns, or rejects the launch. ctx bounds the wait; a
+	// cancelled wait does not cancel the launch. The DebuggerReady event is
+	// not delivered on Events().
+	LaunchContext(ctx context.Context, p protocol.LaunchPayload) (protocol.DebuggerReadyPayload, error)
+
 	Attach(pid int, binaryPath string) error
 	Kill() error
 
@@ -39,58 +93,305 @@ type Client interface {
 	StepInto() error
 	StepOut() error
 
+	// ContinueThread resumes only the thread the session is stopped on; the
+	// threads listed in the hit's OtherStops stay stopped until a Continue.
+	ContinueThread() error
+
+	// StepInstruction executes exactly one machine instruction, unlike
+	// StepOver, which runs to the next source line.
+	StepInstruction() error
+
+	// StepOverN and StepIntoN run n steps server-side and report one
+	// EventStepped (Steps = n) at the end, or stop early at a breakpoint,
+	// pause or exit with an EventNotice saying how many completed.
+	StepOverN(n int) error
+	StepIntoN(n int) error
+
 	// Pause asynchronously interrupts a running process, forcing it to
 	// suspend. Fire-and-forget like Continue: it returns as soon as the
 	// command is sent; the halt is reported later via EventPaused on Events().
 	Pause() error
 
+	// Input types data at the terminal of a process launched on a PTY
+	// (bingo -pty). Fire-and-forget: the process's output arrives as
+	// EventOutput, and a process without a terminal answers EventError.
+	Input(data string) error
+
+	// Interrupt is Pause for a process the caller believes is running, e.g.
+	// a user cancelling a long Continue. Unlike Pause it checks State()
+	// first and returns ErrNotRunning without sending anything when there is
+	// nothing to interrupt, so an interactive client can fall back to its
+	// usual Ctrl-C meaning.
+	Interrupt() error
+
 	// SetBreakpoint blocks until the server confirms the resolved Breakpoint.
-	SetBreakpoint(file string, line int) (protocol.Breakpoint, error)
+	// actions, if given, run on every hit ("print <local>", and a final
+	// "continue" to log without stopping); their output arrives as
+	// EventBreakpointActions. On a line that already has a breakpoint it
+	// returns that one, unchanged, with ErrBreakpointExists.
+	SetBreakpoint(file string, line int, actions ...string) (protocol.Breakpoint, error)
+
+	// SetBreakpoints sets a whole batch in one round trip and returns one
+	// result per entry, in order. An entry the server could not set carries
+	// its Error; the error return is only for the batch as a whole.
+	SetBreakpoints(bps []protocol.SetBreakpointPayload) ([]protocol.BreakpointResult, error)
 	ClearBreakpoint(id int) error
 
+	// SetIgnoreCount makes breakpoint id let its next count hits go by
+	// without stopping (gdb's ignore); 0 stops on the next hit again. It
+	// returns the breakpoint with its new count.
+	SetIgnoreCount(id, count int) (protocol.Breakpoint, error)
+
+	// ClearAllBreakpoints clears every breakpoint and returns how many there
+	// were; none is not an error.
+	ClearAllBreakpoints() (int, error)
+
+	// DisableAllBreakpoints lets the process run free while keeping every
+	// breakpoint's ID and actions; EnableAllBreakpoints re-arms them. Both
+	// block until the server confirms and return the updated breakpoints.
+	DisableAllBreakpoints() ([]protocol.Breakpoint, error)
+	EnableAllBreakpoints() ([]protocol.Breakpoint, error)
+
 	Locals(frameIndex int) ([]protocol.Variable, error)
+
+	// InspectArgs returns the function the process is stopped in and its
+	// arguments. An argument the server could not read has the reason as
+	// its Value.
+	InspectArgs() (protocol.ArgsPayload, error)
+
 	StackFrames() ([]protocol.Frame, error)
 	Goroutines() ([]protocol.Goroutine, error)
 
+	// ResolveLine reports the PC file:line maps to without setting a
+	// breakpoint. The result's Location.Line is the line actually used,
+	// which is the next executable one when the requested line has no code.
+	ResolveLine(file string, line int) (protocol.LineResolvedPayload, error)
+
+	// ResolveFunction is ResolveLine for a line named relative to function:
+	// offset lines past where a breakpoint on it stops, or with atReturn its
+	// last line. The server refuses a line outside the function.
+	ResolveFunction(function string, offset int, atReturn bool) (protocol.LineResolvedPayload, error)
+
+	// GetFile returns the chunk of the binary's source file named file that
+	// starts at offset, at most limit bytes (0 for the server's chunk size).
+	// Request the next chunk at offset+len(Data) while More is set.
+	GetFile(file string, offset int64, limit int) (protocol.FileContentsPayload, error)
+
+	// AddrToLine maps a runtime address to its source location. Function is
+	// "unknown" when pc lies outside every function.
+	AddrToLine(pc uint64) (protocol.Location, error)
+
+	// ReadString decodes the Go string header at addr. The server reads a
+	// bounded prefix; the result's Truncated says whether Value is partial.
+	ReadString(addr uint64) (protocol.StringValuePayload, error)
+
+	// ReadSlice decodes the Go slice header at addr and returns its leading
+	// elements as elemSize-byte hex strings. The server caps the element
+	// count; Truncated says whether there are more.
+	ReadSlice(addr uint64, elemSize int) (protocol.SliceValuePayload, error)
+
+	// Logs returns up to limit of the session's most recent log entries,
+	// oldest first. limit <= 0 returns everything the server still buffers.
+	Logs(limit int) ([]protocol.LogEntry, error)
+
+	// Capabilities returns the commands the server supports and those the
+	// session's current state allows.
+	Capabilities() (protocol.CapabilitiesPayload, error)
+
+	// Status returns the server's ground-truth view of the process: the
+	// engine's own state, where it is stopped and whether a Pause or step is
+	// still under way. Use it when State() and the events seem to disagree.
+	Status() (protocol.StatusPayload, error)
+
+	// Ping sends CmdEcho and returns how long the answer took. A reply means
+	// the session itself is answering, not just the connection.
+	Ping() (time.Duration, error)
+
+	// Subscribe limits the events the server sends this connection to
+	// kinds, plus EventError; none restores all of them. The answers the
+	// other methods wait for are events too, so a filtered client has to
+	// subscribe to those it calls, and to EventSessionState for State and
+	// WaitForState. MissedEvents stops counting while a filter is on: the
+	// gaps it leaves in Event.Seq are not losses.
+	Subscribe(kinds ...protocol.EventKind) error
+
 	Close() error
 }
 
-// SessionInfo describes an active debug session, returned by ListSessions.
+// SessionInfo describes a debug session, returned by ListSessions.
+// FinishedAt is non-zero for a session that has ended and that the server
+// only keeps for inspection (bingo -retention); ExitCode and LastLocation
+// then report how its last run ended, when known. Such a session can't be
+// joined, but SessionLogs still reads its logs. LastActivity is when a
+// client last connected or sent a command, which the idle timeout counts
+// from.
 type SessionInfo struct {
-	ID        string                `json:"id"`
-	State     protocol.SessionState `json:"state"`
-	Clients   int                   `json:"clients"`
-	CreatedAt time.Time             `json:"createdAt"`
+	ID           string                `json:"id"`
+	State        protocol.SessionState `json:"state"`
+	Clients      int                   `json:"clients"`
+	CreatedAt    time.Time             `json:"createdAt"`
+	LastActivity time.Time             `json:"lastActivity"`
+	FinishedAt   time.Time             `json:"finishedAt,omitzero"`
+	ExitCode     *int                  `json:"exitCode,omitempty"`
+	LastLocation *protocol.Location    `json:"lastLocation,omitempty"`
+}
+
+// Options configures how the client reaches the server. The zero value is
+// plain ws:// and http://, which is what Create, Join and ListSessions use.
+type Options struct {
+	// TLS switches to wss:// and https:// using this config. nil with
+	// InsecureSkipVerify unset means no TLS.
+	TLS *tls.Config
+
+	// InsecureSkipVerify enables TLS and accepts any server certificate, for
+	// self-signed certs in development. Never use it across an untrusted
+	// network: it defeats the point of TLS.
+	InsecureSkipVerify bool
+
+	// Dialer replaces the WebSocket dialer (proxy, net dialer, buffer sizes).
+	// A copy is used; TLS above still applies on top of it.
+	Dialer *websocket.Dialer
+
+	// HandshakeTimeout bounds the whole connect: TCP dial, TLS and the
+	// WebSocket upgrade. Zero means defaultHandshakeTimeout, or the Dialer's
+	// own timeout when one is supplied with a non-zero value.
+	HandshakeTimeout time.Duration
+
+	// BlockOnBreakpoints makes a full Breakpoints() channel stall the read
+	// loop (and with it Events()) instead of dropping the hit. Only set it
+	// when something is guaranteed to drain Breakpoints().
+	BlockOnBreakpoints bool
+}
+
+// dialer returns the WebSocket dialer to connect with.
+func (o Options) dialer() *websocket.Dialer {
+	d := *websocket.DefaultDialer
+	if o.Dialer != nil {
+		d = *o.Dialer
+	}
+	if cfg := o.tlsConfig(); cfg != nil {
+		d.TLSClientConfig = cfg
+	}
+	if len(d.Subprotocols) == 0 {
+		d.Subprotocols = []string{protocol.Subprotocol}
+	}
+	switch {
+	case o.HandshakeTimeout > 0:
+		d.HandshakeTimeout = o.HandshakeTimeout
+	case o.Dialer == nil || o.Dialer.HandshakeTimeout == 0:
+		d.HandshakeTimeout = defaultHandshakeTimeout
+	}
+	return &d
+}
+
+// tlsConfig returns the effective TLS config, or nil for plaintext.
+func (o Options) tlsConfig() *tls.Config {
+	if o.TLS == nil && !o.InsecureSkipVerify {
+		return nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.TLS != nil {
+		cfg = o.TLS.Clone()
+	}
+	if o.InsecureSkipVerify {
+		cfg.InsecureSkipVerify = true //nolint:gosec // explicit dev-only opt-in
+	}
+	return cfg
+}
+
+// url builds a server URL, choosing the TLS scheme when TLS is configured.
+func (o Options) url(plain, secure, addr, path string) string {
+	scheme := plain
+	if o.tlsConfig() != nil {
+		scheme = secure
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, addr, path)
 }
 
 // ListSessions queries the server's REST API for all active sessions.
 func ListSessions(addr string) ([]SessionInfo, error) {
-	url := fmt.Sprintf("http://%s/api/sessions", addr)
+	return ListSessionsWithOptions(addr, Options{})
+}
 
-	httpClient := http.Client{Timeout: listSessionsTimeout}
-	resp, err := httpClient.Get(url) //nolint:gosec // no auth by design
-	if err != nil {
+// ListSessionsWithOptions is ListSessions with explicit Options.
+func ListSessionsWithOptions(addr string, opts Options) ([]SessionInfo, error) {
+	var sessions []SessionInfo
+	if err := opts.getJSON(addr, "/api/sessions", &sessions); err != nil {
 		return nil, fmt.Errorf("list sessions: %w", err)
 	}
+	return sessions, nil
+}
+
+// SessionLogs fetches up to limit of a session's most recent log entries,
+// oldest first, over the REST API; limit <= 0 fetches all the server kept.
+// Unlike Client.Logs it needs no connection, so it also reads the trace of
+// a session that has finished but is still retained (SessionInfo.FinishedAt).
+func SessionLogs(addr, sessionID string, limit int) ([]protocol.LogEntry, error) {
+	return SessionLogsWithOptions(addr, sessionID, limit, Options{})
+}
+
+// SessionLogsWithOptions is SessionLogs with explicit Options.
+func SessionLogsWithOptions(addr, sessionID string, limit int, opts Options) ([]protocol.LogEntry, error) {
+	path := "/api/sessions/" + url.PathEscape(sessionID) + "/logs"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var p protocol.LogsPayload
+	if err := opts.getJSON(addr, path, &p); err != nil {
+		return nil, fmt.Errorf("session logs: %w", err)
+	}
+	return p.Entries, nil
+}
+
+// getJSON GETs path from the server's REST API and decodes the JSON reply
+// into out.
+func (o Options) getJSON(addr, path string, out any) error {
+	httpClient := http.Client{Timeout: restTimeout}
+	if cfg := o.tlsConfig(); cfg != nil {
+		httpClient.Transport = &http.Transport{TLSClientConfig: cfg}
+	}
+	resp, err := httpClient.Get(o.url("http", "https", addr, path)) //nolint:gosec // no auth by design
+	if err != nil {
+		return err
+	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("list sessions: HTTP %d", resp.StatusCode)
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
-
-	var sessions []SessionInfo
-	if err := json.NewDecoder(resp.Body).Decode(&sessions); err != nil {
-		return nil, fmt.Errorf("list sessions: decode: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode: %w", err)
 	}
-	return sessions, nil
+	return nil
 }
 
 // Create connects to the server and creates a new debug session.
 func Create(addr string) (Client, error) {
-	return dial(addr, "create=1")
+	return CreateWithOptions(addr, Options{})
+}
+
+// CreateWithOptions is Create with explicit Options.
+func CreateWithOptions(addr string, opts Options) (Client, error) {
+	return CreateContext(context.Background(), addr, opts)
+}
+
+// CreateContext is CreateWithOptions bounded by ctx: cancelling it aborts the
+// connect, including the wait for the server's welcome.
+func CreateContext(ctx context.Context, addr string, opts Options) (Client, error) {
+	return dial(ctx, addr, "create=1", opts)
 }
 
 // Join connects to the server and joins an existing session by UUID.
 func Join(addr, sessionID string) (Client, error) {
-	return dial(addr, fmt.Sprintf("session=%s", sessionID))
+	return JoinWithOptions(addr, sessionID, Options{})
+}
+
+// JoinWithOptions is Join with explicit Options.
+func JoinWithOptions(addr, sessionID string, opts Options) (Client, error) {
+	return JoinContext(context.Background(), addr, sessionID, opts)
+}
+
+// JoinContext is JoinWithOptions bounded by ctx.
+func JoinContext(ctx context.Context, addr, sessionID string, opts Options) (Client, error) {
+	return dial(ctx, addr, fmt.Sprintf("session=%s", sessionID), opts)
 }
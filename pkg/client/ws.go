@@ -1,10 +1,12 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bingosuite/bingo/pkg/protocol"
@@ -18,8 +20,26 @@ const (
 	syncTimeout     = 10 * time.Second
 	dialTimeout     = 5 * time.Second
 	eventBufferSize = 64
+
+	// defaultHandshakeTimeout replaces gorilla's 45s default so an unreachable
+	// server fails fast enough for scripts and retry loops.
+	defaultHandshakeTimeout = 10 * time.Second
+
+	// breakpointBufferSize is small on purpose: a script ranging over
+	// Breakpoints() reacts to each hit, it doesn't batch them.
+	breakpointBufferSize = 8
 )
 
+// stateChange is one entry in the session's state history. The read pump
+// appends a change by setting next and closing changed, so a WaitForState
+// woken late still walks every state in between instead of only seeing the
+// latest one.
+type stateChange struct {
+	state   protocol.SessionState
+	next    *stateChange
+	changed chan struct{}
+}
+
 // pendingReq is a synchronous method blocked on its confirmation event (or an
 // EventError for the same command kind).
 type pendingReq struct {
@@ -34,10 +54,24 @@ type wsClient struct {
 
 	metaMu    sync.RWMutex
 	sessionID string
-	state     protocol.SessionState
+	state     *stateChange
 
 	events chan protocol.Event
 
+	// lastSeq and seenSeq are only touched by readPump; missed is read by
+	// MissedEvents.
+	lastSeq uint64
+	seenSeq bool
+	missed  atomic.Uint64
+
+	// filtered is set while a Subscribe filter leaves deliberate seq gaps.
+	filtered atomic.Bool
+
+	// bpHits mirrors breakpoint hits for Breakpoints(); blockOnBP selects
+	// backpressure over dropping when it is full.
+	bpHits    chan protocol.BreakpointHitPayload
+	blockOnBP bool
+
 	// syncMu serialises sendAndWait so one in-flight pending request at a time.
 	syncMu sync.Mutex
 
@@ -54,19 +88,28 @@ type wsClient struct {
 }
 
 // dial opens the WebSocket and waits for the server's welcome SessionState.
-func dial(addr, query string) (Client, error) {
-	url := fmt.Sprintf("ws://%s/ws?%s", addr, query)
+func dial(ctx context.Context, addr, query string, opts Options) (Client, error) {
+	url := opts.url("ws", "wss", addr, "/ws?"+query+"&v="+protocol.Version)
 
-	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	conn, resp, err := opts.dialer().DialContext(ctx, url, nil)
 	if err != nil {
+		if resp != nil {
+			if v := resp.Header.Get(protocol.VersionHeader); v != "" && !protocol.CompatibleVersion(v) {
+				return nil, fmt.Errorf("dial %s: %w: client speaks %s, server %s",
+					url, ErrIncompatibleVersion, protocol.Version, v)
+			}
+		}
 		return nil, fmt.Errorf("dial %s: %w", url, err)
 	}
 
 	c := &wsClient{
-		conn:   conn,
-		log:    slog.Default(),
-		events: make(chan protocol.Event, eventBufferSize),
-		done:   make(chan struct{}),
+		conn:      conn,
+		log:       slog.Default(),
+		events:    make(chan protocol.Event, eventBufferSize),
+		bpHits:    make(chan protocol.BreakpointHitPayload, breakpointBufferSize),
+		blockOnBP: opts.BlockOnBreakpoints,
+		state:     &stateChange{changed: make(chan struct{})},
+		done:      make(chan struct{}),
 	}
 	cleanup := true
 	defer func() {
@@ -85,8 +128,16 @@ func dial(addr, query string) (Client, error) {
 		if evt.Kind != protocol.EventSessionState {
 			return nil, fmt.Errorf("expected SessionState event, got %s", evt.Kind)
 		}
+		// A server that predates the v check accepts any client; its
+		// welcome still says what it speaks.
+		if !protocol.CompatibleVersion(evt.Version) {
+			return nil, fmt.Errorf("%w: client speaks %s, server %s",
+				ErrIncompatibleVersion, protocol.Version, evt.Version)
+		}
 	case <-time.After(dialTimeout):
 		return nil, fmt.Errorf("timeout waiting for session state from server")
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for session state: %w", ctx.Err())
 	}
 
 	cleanup = false
@@ -97,6 +148,7 @@ func (c *wsClient) readPump() {
 	defer func() {
 		c.signalDone()
 		close(c.events)
+		close(c.bpHits)
 	}()
 
 	for {
@@ -110,17 +162,25 @@ func (c *wsClient) readPump() {
 			c.log.Warn("invalid event from server", "err", err)
 			continue
 		}
+		c.checkSeq(evt.Seq)
 
 		if evt.Kind == protocol.EventSessionState {
 			var p protocol.SessionStatePayload
 			if protocol.DecodeEventPayload(evt, &p) == nil {
 				c.metaMu.Lock()
 				c.sessionID = p.SessionID
-				c.state = p.State
+				next := &stateChange{state: p.State, changed: make(chan struct{})}
+				c.state.next = next
+				close(c.state.changed)
+				c.state = next
 				c.metaMu.Unlock()
 			}
 		}
 
+		if evt.Kind == protocol.EventBreakpointHit {
+			c.forwardBreakpointHit(evt)
+		}
+
 		if c.routeToPending(evt) {
 			continue
 		}
@@ -133,6 +193,44 @@ func (c *wsClient) readPump() {
 	}
 }
 
+// checkSeq counts the events skipped between the previous seq and this one.
+// Equal seqs are fine: per-client replays (welcome, snapshot) reuse the last
+// broadcast's seq.
+func (c *wsClient) checkSeq(seq uint64) {
+	last, seen := c.lastSeq, c.seenSeq
+	if seq > last || !seen {
+		c.lastSeq, c.seenSeq = seq, true
+	}
+	if !seen || seq <= last+1 || c.filtered.Load() {
+		return
+	}
+	n := seq - last - 1
+	c.missed.Add(n)
+	c.log.Warn("events missed", "count", n, "after_seq", last)
+}
+
+func (c *wsClient) forwardBreakpointHit(evt protocol.Event) {
+	var p protocol.BreakpointHitPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		c.log.Warn("invalid BreakpointHit payload", "err", err)
+		return
+	}
+	if c.blockOnBP {
+		select {
+		case c.bpHits <- p:
+		case <-c.done:
+		}
+		return
+	}
+	select {
+	case c.bpHits <- p:
+	default:
+		// Most clients never read Breakpoints(); dropping here is the norm,
+		// so don't log it as a warning.
+		c.log.Debug("breakpoints buffer full — dropping", "id", p.Breakpoint.ID)
+	}
+}
+
 func (c *wsClient) routeToPending(evt protocol.Event) bool {
 	c.pendingMu.Lock()
 	p := c.pending
@@ -169,8 +267,18 @@ func (c *wsClient) send(cmd protocol.Command) error {
 }
 
 // sendAndWait sends cmd and blocks for the matching confirmation event or an
-// EventError for the same command kind.
+// EventError for the same command kind, for at most syncTimeout.
 func (c *wsClient) sendAndWait(cmd protocol.Command, wantKind protocol.EventKind) (protocol.Event, error) {
+	ctx, cancel := context.WithTimeoutCause(context.Background(), syncTimeout,
+		fmt.Errorf("timeout waiting for %s response", wantKind))
+	defer cancel()
+	return c.sendAndWaitContext(ctx, cmd, wantKind)
+}
+
+// sendAndWaitContext is sendAndWait bounded by ctx instead of syncTimeout,
+// for commands such as a launch whose confirmation can take arbitrarily
+// long.
+func (c *wsClient) sendAndWaitContext(ctx context.Context, cmd protocol.Command, wantKind protocol.EventKind) (protocol.Event, error) {
 	c.syncMu.Lock()
 	defer c.syncMu.Unlock()
 
@@ -199,8 +307,8 @@ func (c *wsClient) sendAndWait(cmd protocol.Command, wantKind protocol.EventKind
 			return protocol.Event{}, fmt.Errorf("server: %s", ep.Message)
 		}
 		return evt, nil
-	case <-time.After(syncTimeout):
-		return protocol.Event{}, fmt.Errorf("timeout waiting for %s response", wantKind)
+	case <-ctx.Done():
+		return protocol.Event{}, context.Cause(ctx)
 	case <-c.done:
 		return protocol.Event{}, fmt.Errorf("client closed")
 	}
@@ -223,21 +331,72 @@ func (c *wsClient) SessionID() string {
 func (c *wsClient) State() protocol.SessionState {
 	c.metaMu.RLock()
 	defer c.metaMu.RUnlock()
-	return c.state
+	return c.state.state
+}
+
+func (c *wsClient) WaitForState(ctx context.Context, state protocol.SessionState) error {
+	c.metaMu.RLock()
+	cur := c.state
+	c.metaMu.RUnlock()
+
+	// Idle and exited are what a process leaves behind, so only a wait for a
+	// live state can be cut short by the exit.
+	live := state == protocol.StateRunning || state == protocol.StateSuspended
+	for cur.state != state {
+		if live && cur.state == protocol.StateExited {
+			return fmt.Errorf("wait for %s: %w", state, ErrSessionEnded)
+		}
+		select {
+		case <-cur.changed:
+			// next is set before changed is closed, so reading it needs no
+			// lock.
+			cur = cur.next
+		case <-c.done:
+			if c.State() == state {
+				return nil
+			}
+			return fmt.Errorf("wait for %s: client closed", state)
+		case <-ctx.Done():
+			return fmt.Errorf("wait for %s: %w", state, ctx.Err())
+		}
+	}
+	return nil
 }
 
+func (c *wsClient) MissedEvents() uint64 { return c.missed.Load() }
+
 func (c *wsClient) Events() <-chan protocol.Event { return c.events }
 
+func (c *wsClient) Breakpoints() <-chan protocol.BreakpointHitPayload { return c.bpHits }
+
 func (c *wsClient) Launch(program string, args, env []string) error {
-	cmd, err := newCommand(protocol.CmdLaunch, protocol.LaunchPayload{
-		Program: program, Args: args, Env: env,
-	})
+	return c.LaunchWith(protocol.LaunchPayload{Program: program, Args: args, Env: env})
+}
+
+func (c *wsClient) LaunchWith(p protocol.LaunchPayload) error {
+	cmd, err := newCommand(protocol.CmdLaunch, p)
 	if err != nil {
 		return err
 	}
 	return c.send(cmd)
 }
 
+func (c *wsClient) LaunchContext(ctx context.Context, p protocol.LaunchPayload) (protocol.DebuggerReadyPayload, error) {
+	cmd, err := newCommand(protocol.CmdLaunch, p)
+	if err != nil {
+		return protocol.DebuggerReadyPayload{}, err
+	}
+	evt, err := c.sendAndWaitContext(ctx, cmd, protocol.EventDebuggerReady)
+	if err != nil {
+		return protocol.DebuggerReadyPayload{}, fmt.Errorf("launch %s: %w", p.Program, err)
+	}
+	var ready protocol.DebuggerReadyPayload
+	if err := protocol.DecodeEventPayload(evt, &ready); err != nil {
+		return protocol.DebuggerReadyPayload{}, fmt.Errorf("decode DebuggerReady: %w", err)
+	}
+	return ready, nil
+}
+
 func (c *wsClient) Attach(pid int, binaryPath string) error {
 	cmd, err := newCommand(protocol.CmdAttach, protocol.AttachPayload{
 		PID: pid, BinaryPath: binaryPath,
@@ -280,6 +439,14 @@ func (c *wsClient) Continue() error {
 	return c.send(cmd)
 }
 
+func (c *wsClient) ContinueThread() error {
+	cmd, err := newCommand(protocol.CmdContinue, protocol.ContinuePayload{Scope: protocol.ScopeThread})
+	if err != nil {
+		return err
+	}
+	return c.send(cmd)
+}
+
 func (c *wsClient) StepOver() error {
 	cmd, err := newCommand(protocol.CmdStepOver, struct{}{})
 	if err != nil {
@@ -296,6 +463,22 @@ func (c *wsClient) StepInto() error {
 	return c.send(cmd)
 }
 
+func (c *wsClient) StepOverN(n int) error {
+	cmd, err := newCommand(protocol.CmdStepOver, protocol.StepPayload{Count: n})
+	if err != nil {
+		return err
+	}
+	return c.send(cmd)
+}
+
+func (c *wsClient) StepIntoN(n int) error {
+	cmd, err := newCommand(protocol.CmdStepInto, protocol.StepPayload{Count: n})
+	if err != nil {
+		return err
+	}
+	return c.send(cmd)
+}
+
 func (c *wsClient) StepOut() error {
 	cmd, err := newCommand(protocol.CmdStepOut, struct{}{})
 	if err != nil {
@@ -304,6 +487,14 @@ func (c *wsClient) StepOut() error {
 	return c.send(cmd)
 }
 
+func (c *wsClient) StepInstruction() error {
+	cmd, err := newCommand(protocol.CmdStepInstruction, struct{}{})
+	if err != nil {
+		return err
+	}
+	return c.send(cmd)
+}
+
 // Pause is fire-and-forget like Continue: it sends CmdPause and returns. The
 // resulting halt arrives asynchronously as EventPaused on Events().
 func (c *wsClient) Pause() error {
@@ -314,9 +505,24 @@ func (c *wsClient) Pause() error {
 	return c.send(cmd)
 }
 
-func (c *wsClient) SetBreakpoint(file string, line int) (protocol.Breakpoint, error) {
+func (c *wsClient) Input(data string) error {
+	cmd, err := newCommand(protocol.CmdInput, protocol.InputPayload{Data: data})
+	if err != nil {
+		return err
+	}
+	return c.send(cmd)
+}
+
+func (c *wsClient) Interrupt() error {
+	if c.State() != protocol.StateRunning {
+		return ErrNotRunning
+	}
+	return c.Pause()
+}
+
+func (c *wsClient) SetBreakpoint(file string, line int, actions ...string) (protocol.Breakpoint, error) {
 	cmd, err := newCommand(protocol.CmdSetBreakpoint, protocol.SetBreakpointPayload{
-		File: file, Line: line,
+		File: file, Line: line, Actions: actions,
 	})
 	if err != nil {
 		return protocol.Breakpoint{}, err
@@ -329,9 +535,29 @@ func (c *wsClient) SetBreakpoint(file string, line int) (protocol.Breakpoint, er
 	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
 		return protocol.Breakpoint{}, fmt.Errorf("decode BreakpointSet: %w", err)
 	}
+	if p.AlreadySet {
+		loc := p.Breakpoint.Location
+		return p.Breakpoint, fmt.Errorf("%w at %s:%d (breakpoint %d)", ErrBreakpointExists, loc.File, loc.Line, p.Breakpoint.ID)
+	}
 	return p.Breakpoint, nil
 }
 
+func (c *wsClient) SetBreakpoints(bps []protocol.SetBreakpointPayload) ([]protocol.BreakpointResult, error) {
+	cmd, err := newCommand(protocol.CmdSetBreakpoints, protocol.SetBreakpointsPayload{Breakpoints: bps})
+	if err != nil {
+		return nil, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventBreakpointsSet)
+	if err != nil {
+		return nil, err
+	}
+	var p protocol.BreakpointsSetPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return nil, fmt.Errorf("decode BreakpointsSet: %w", err)
+	}
+	return p.Results, nil
+}
+
 func (c *wsClient) ClearBreakpoint(id int) error {
 	cmd, err := newCommand(protocol.CmdClearBreakpoint, protocol.ClearBreakpointPayload{ID: id})
 	if err != nil {
@@ -341,6 +567,62 @@ func (c *wsClient) ClearBreakpoint(id int) error {
 	return err
 }
 
+func (c *wsClient) SetIgnoreCount(id, count int) (protocol.Breakpoint, error) {
+	cmd, err := newCommand(protocol.CmdSetIgnoreCount, protocol.SetIgnoreCountPayload{ID: id, Count: count})
+	if err != nil {
+		return protocol.Breakpoint{}, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventIgnoreCountSet)
+	if err != nil {
+		return protocol.Breakpoint{}, err
+	}
+	var p protocol.IgnoreCountSetPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return protocol.Breakpoint{}, fmt.Errorf("decode IgnoreCountSet: %w", err)
+	}
+	return p.Breakpoint, nil
+}
+
+func (c *wsClient) ClearAllBreakpoints() (int, error) {
+	cmd, err := newCommand(protocol.CmdClearAllBreakpoints, struct{}{})
+	if err != nil {
+		return 0, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventBreakpointsCleared)
+	if err != nil {
+		return 0, err
+	}
+	var p protocol.BreakpointsClearedPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return 0, fmt.Errorf("decode BreakpointsCleared: %w", err)
+	}
+	return p.Count, nil
+}
+
+func (c *wsClient) DisableAllBreakpoints() ([]protocol.Breakpoint, error) {
+	return c.toggleBreakpoints(protocol.CmdDisableAllBreakpoints)
+}
+
+func (c *wsClient) EnableAllBreakpoints() ([]protocol.Breakpoint, error) {
+	return c.toggleBreakpoints(protocol.CmdEnableAllBreakpoints)
+}
+
+func (c *wsClient) toggleBreakpoints(kind protocol.CommandKind) ([]protocol.Breakpoint, error) {
+	cmd, err := newCommand(kind, struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventBreakpointsToggled)
+	if err != nil {
+		return nil, err
+	}
+	var p protocol.BreakpointsToggledPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return nil, fmt.Errorf("decode BreakpointsToggled: %w", err)
+	}
+	return p.Breakpoints, nil
+}
+
 func (c *wsClient) Locals(frameIndex int) ([]protocol.Variable, error) {
 	cmd, err := newCommand(protocol.CmdLocals, protocol.LocalsPayloadCmd{FrameIndex: frameIndex})
 	if err != nil {
@@ -357,6 +639,22 @@ func (c *wsClient) Locals(frameIndex int) ([]protocol.Variable, error) {
 	return p.Variables, nil
 }
 
+func (c *wsClient) InspectArgs() (protocol.ArgsPayload, error) {
+	cmd, err := newCommand(protocol.CmdInspectArgs, struct{}{})
+	if err != nil {
+		return protocol.ArgsPayload{}, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventArgs)
+	if err != nil {
+		return protocol.ArgsPayload{}, err
+	}
+	var p protocol.ArgsPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return protocol.ArgsPayload{}, fmt.Errorf("decode Args: %w", err)
+	}
+	return p, nil
+}
+
 func (c *wsClient) StackFrames() ([]protocol.Frame, error) {
 	cmd, err := newCommand(protocol.CmdFrames, struct{}{})
 	if err != nil {
@@ -389,9 +687,182 @@ func (c *wsClient) Goroutines() ([]protocol.Goroutine, error) {
 	return p.Goroutines, nil
 }
 
-// Close disconnects from the server. Safe to call multiple times.
+func (c *wsClient) ResolveLine(file string, line int) (protocol.LineResolvedPayload, error) {
+	return c.resolveLine(protocol.ResolveLinePayload{File: file, Line: line})
+}
+
+func (c *wsClient) ResolveFunction(function string, offset int, atReturn bool) (protocol.LineResolvedPayload, error) {
+	return c.resolveLine(protocol.ResolveLinePayload{Function: function, Offset: offset, Return: atReturn})
+}
+
+func (c *wsClient) resolveLine(req protocol.ResolveLinePayload) (protocol.LineResolvedPayload, error) {
+	cmd, err := newCommand(protocol.CmdResolveLine, req)
+	if err != nil {
+		return protocol.LineResolvedPayload{}, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventLineResolved)
+	if err != nil {
+		return protocol.LineResolvedPayload{}, err
+	}
+	var p protocol.LineResolvedPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return protocol.LineResolvedPayload{}, fmt.Errorf("decode LineResolved: %w", err)
+	}
+	return p, nil
+}
+
+func (c *wsClient) GetFile(file string, offset int64, limit int) (protocol.FileContentsPayload, error) {
+	cmd, err := newCommand(protocol.CmdGetFile, protocol.GetFilePayload{File: file, Offset: offset, Limit: limit})
+	if err != nil {
+		return protocol.FileContentsPayload{}, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventFileContents)
+	if err != nil {
+		return protocol.FileContentsPayload{}, err
+	}
+	var p protocol.FileContentsPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return protocol.FileContentsPayload{}, fmt.Errorf("decode FileContents: %w", err)
+	}
+	return p, nil
+}
+
+func (c *wsClient) AddrToLine(pc uint64) (protocol.Location, error) {
+	cmd, err := newCommand(protocol.CmdAddrToLine, protocol.AddrToLinePayload{PC: pc})
+	if err != nil {
+		return protocol.Location{}, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventAddrResolved)
+	if err != nil {
+		return protocol.Location{}, err
+	}
+	var p protocol.AddrResolvedPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return protocol.Location{}, fmt.Errorf("decode AddrResolved: %w", err)
+	}
+	return p.Location, nil
+}
+
+func (c *wsClient) ReadString(addr uint64) (protocol.StringValuePayload, error) {
+	cmd, err := newCommand(protocol.CmdReadString, protocol.ReadStringPayload{Addr: addr})
+	if err != nil {
+		return protocol.StringValuePayload{}, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventStringValue)
+	if err != nil {
+		return protocol.StringValuePayload{}, err
+	}
+	var p protocol.StringValuePayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return protocol.StringValuePayload{}, fmt.Errorf("decode StringValue: %w", err)
+	}
+	return p, nil
+}
+
+func (c *wsClient) ReadSlice(addr uint64, elemSize int) (protocol.SliceValuePayload, error) {
+	cmd, err := newCommand(protocol.CmdReadSlice, protocol.ReadSlicePayload{Addr: addr, ElemSize: elemSize})
+	if err != nil {
+		return protocol.SliceValuePayload{}, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventSliceValue)
+	if err != nil {
+		return protocol.SliceValuePayload{}, err
+	}
+	var p protocol.SliceValuePayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return protocol.SliceValuePayload{}, fmt.Errorf("decode SliceValue: %w", err)
+	}
+	return p, nil
+}
+
+func (c *wsClient) Logs(limit int) ([]protocol.LogEntry, error) {
+	cmd, err := newCommand(protocol.CmdLogs, protocol.LogsPayloadCmd{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventLogs)
+	if err != nil {
+		return nil, err
+	}
+	var p protocol.LogsPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return nil, fmt.Errorf("decode Logs: %w", err)
+	}
+	return p.Entries, nil
+}
+
+func (c *wsClient) Capabilities() (protocol.CapabilitiesPayload, error) {
+	cmd, err := newCommand(protocol.CmdCapabilities, struct{}{})
+	if err != nil {
+		return protocol.CapabilitiesPayload{}, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventCapabilities)
+	if err != nil {
+		return protocol.CapabilitiesPayload{}, err
+	}
+	var p protocol.CapabilitiesPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return protocol.CapabilitiesPayload{}, fmt.Errorf("decode Capabilities: %w", err)
+	}
+	return p, nil
+}
+
+func (c *wsClient) Ping() (time.Duration, error) {
+	cmd, err := newCommand(protocol.CmdEcho, protocol.EchoPayload{})
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	if _, err := c.sendAndWait(cmd, protocol.EventEcho); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+func (c *wsClient) Subscribe(kinds ...protocol.EventKind) error {
+	cmd, err := newCommand(protocol.CmdSubscribe, protocol.SubscribePayload{Events: kinds})
+	if err != nil {
+		return err
+	}
+	// Stop counting gaps before the filter starts making them.
+	was := c.filtered.Load()
+	if len(kinds) > 0 {
+		c.filtered.Store(true)
+	}
+	if _, err := c.sendAndWait(cmd, protocol.EventSubscribed); err != nil {
+		c.filtered.Store(was)
+		return err
+	}
+	c.filtered.Store(len(kinds) > 0)
+	return nil
+}
+
+func (c *wsClient) Status() (protocol.StatusPayload, error) {
+	cmd, err := newCommand(protocol.CmdStatus, struct{}{})
+	if err != nil {
+		return protocol.StatusPayload{}, err
+	}
+	evt, err := c.sendAndWait(cmd, protocol.EventStatus)
+	if err != nil {
+		return protocol.StatusPayload{}, err
+	}
+	var p protocol.StatusPayload
+	if err := protocol.DecodeEventPayload(evt, &p); err != nil {
+		return protocol.StatusPayload{}, fmt.Errorf("decode Status: %w", err)
+	}
+	return p, nil
+}
+
+// Close disconnects from the server. Safe to call multiple times. It sends a
+// close frame first so the server drops this client straight away instead of
+// noticing the dead connection on its next write.
 func (c *wsClient) Close() error {
 	c.signalDone()
+	c.writeMu.Lock()
+	_ = c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	c.writeMu.Unlock()
 	return c.conn.Close()
 }
 
@@ -1,11 +1,18 @@
 package client_test
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/bingosuite/bingo/pkg/client"
 	"github.com/bingosuite/bingo/pkg/protocol"
@@ -22,13 +29,30 @@ type fakeServer struct {
 
 	mu       sync.Mutex
 	commands []protocol.Command
+	readErr  error    // why the connection's read loop ended
+	offered  []string // subprotocols the client's handshake offered
 }
 
 func newFakeServer(reply func(protocol.Command) (protocol.Event, bool)) *fakeServer {
+	return startFakeServer(reply, false)
+}
+
+// newFakeTLSServer is newFakeServer over wss, with httptest's self-signed cert.
+func newFakeTLSServer(reply func(protocol.Command) (protocol.Event, bool)) *fakeServer {
+	return startFakeServer(reply, true)
+}
+
+func startFakeServer(reply func(protocol.Command) (protocol.Event, bool), secure bool) *fakeServer {
 	fs := &fakeServer{reply: reply}
-	up := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	up := websocket.Upgrader{
+		CheckOrigin:  func(*http.Request) bool { return true },
+		Subprotocols: []string{protocol.Subprotocol},
+	}
 
-	fs.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	fs.ts = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fs.mu.Lock()
+		fs.offered = websocket.Subprotocols(r)
+		fs.mu.Unlock()
 		conn, err := up.Upgrade(w, r, nil)
 		if err != nil {
 			return
@@ -48,6 +72,9 @@ func newFakeServer(reply func(protocol.Command) (protocol.Event, bool)) *fakeSer
 		for {
 			_, data, err := conn.ReadMessage()
 			if err != nil {
+				fs.mu.Lock()
+				fs.readErr = err
+				fs.mu.Unlock()
 				return
 			}
 			cmd, err := protocol.UnmarshalCommand(data)
@@ -67,11 +94,18 @@ func newFakeServer(reply func(protocol.Command) (protocol.Event, bool)) *fakeSer
 			}
 		}
 	}))
+	if secure {
+		fs.ts.StartTLS()
+	} else {
+		fs.ts.Start()
+	}
 	return fs
 }
 
-func (fs *fakeServer) addr() string { return strings.TrimPrefix(fs.ts.URL, "http://") }
-func (fs *fakeServer) close()       { fs.ts.Close() }
+func (fs *fakeServer) addr() string {
+	return strings.TrimPrefix(strings.TrimPrefix(fs.ts.URL, "http://"), "https://")
+}
+func (fs *fakeServer) close() { fs.ts.Close() }
 
 func (fs *fakeServer) lastCommand() (protocol.Command, bool) {
 	fs.mu.Lock()
@@ -95,6 +129,23 @@ func dialTestClient(t *testing.T, fs *fakeServer) client.Client {
 	return c
 }
 
+// TestDialOffersSubprotocol checks the handshake names the message format, so
+// a server that doesn't speak it can refuse the connection outright.
+func TestDialOffersSubprotocol(t *testing.T) {
+	fs := newFakeServer(nil)
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	fs.mu.Lock()
+	offered := fs.offered
+	fs.mu.Unlock()
+	if len(offered) != 1 || offered[0] != protocol.Subprotocol {
+		t.Errorf("offered subprotocols = %q, want [%q]", offered, protocol.Subprotocol)
+	}
+}
+
 // TestRestartEmptyArgsOverrideReachesWire is the client-side regression for
 // issue #102: an explicit empty-slice override must serialise as [] so the hub
 // can distinguish "clear the args" from "reuse the original Launch args".
@@ -183,6 +234,193 @@ func TestSetBreakpointReturnsConfirmation(t *testing.T) {
 	}
 }
 
+func TestClearAllBreakpointsReturnsTheCount(t *testing.T) {
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind == protocol.CmdClearAllBreakpoints {
+			return replyEvent(protocol.EventBreakpointsCleared, protocol.BreakpointsClearedPayload{
+				IDs: []int{3, 5}, Count: 2,
+			}), true
+		}
+		return protocol.Event{}, false
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	n, err := c.ClearAllBreakpoints()
+	if err != nil {
+		t.Fatalf("ClearAllBreakpoints: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("ClearAllBreakpoints = %d, want 2", n)
+	}
+}
+
+func TestSetIgnoreCountReturnsTheBreakpoint(t *testing.T) {
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind != protocol.CmdSetIgnoreCount {
+			return protocol.Event{}, false
+		}
+		var p protocol.SetIgnoreCountPayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return protocol.Event{}, false
+		}
+		return replyEvent(protocol.EventIgnoreCountSet, protocol.IgnoreCountSetPayload{
+			Breakpoint: protocol.Breakpoint{ID: p.ID, IgnoreCount: p.Count, Enabled: true},
+		}), true
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	bp, err := c.SetIgnoreCount(4, 10)
+	if err != nil {
+		t.Fatalf("SetIgnoreCount: %v", err)
+	}
+	if bp.ID != 4 || bp.IgnoreCount != 10 {
+		t.Errorf("SetIgnoreCount = %+v, want breakpoint 4 ignoring 10", bp)
+	}
+}
+
+func TestResolveFunctionSendsTheFunctionForm(t *testing.T) {
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind != protocol.CmdResolveLine {
+			return protocol.Event{}, false
+		}
+		var p protocol.ResolveLinePayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil || p.Function != "main.worker" || !p.Return {
+			return protocol.Event{}, false
+		}
+		return replyEvent(protocol.EventLineResolved, protocol.LineResolvedPayload{
+			PC:       0x401020,
+			Location: protocol.Location{File: "main.go", Line: 23, Function: p.Function},
+		}), true
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	r, err := c.ResolveFunction("main.worker", 0, true)
+	if err != nil {
+		t.Fatalf("ResolveFunction: %v", err)
+	}
+	if r.PC != 0x401020 || r.Location.Line != 23 {
+		t.Errorf("ResolveFunction = %+v, want main.go:23 at 0x401020", r)
+	}
+}
+
+// TestSetBreakpointReportsAlreadySet: a second set on the same line hands
+// back the existing breakpoint with a distinct error, so a caller can tell
+// the no-op from a failure.
+func TestSetBreakpointReportsAlreadySet(t *testing.T) {
+	existing := protocol.Breakpoint{ID: 4, Enabled: true, Location: protocol.Location{File: "main.go", Line: 42}}
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind == protocol.CmdSetBreakpoint {
+			return replyEvent(protocol.EventBreakpointSet, protocol.BreakpointSetPayload{
+				Breakpoint: existing, AlreadySet: true,
+			}), true
+		}
+		return protocol.Event{}, false
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	bp, err := c.SetBreakpoint("main.go", 42)
+	if !errors.Is(err, client.ErrBreakpointExists) {
+		t.Fatalf("got %v, want ErrBreakpointExists", err)
+	}
+	if !strings.Contains(err.Error(), "already set at main.go:42") {
+		t.Errorf("error %q does not name the line", err)
+	}
+	if bp.ID != existing.ID {
+		t.Errorf("got breakpoint %+v, want the existing %+v", bp, existing)
+	}
+}
+
+// TestSetBreakpointsReturnsPerEntryResults: a batch with a failing entry is
+// still one successful call, with the failure reported against its entry.
+func TestSetBreakpointsReturnsPerEntryResults(t *testing.T) {
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind != protocol.CmdSetBreakpoints {
+			return protocol.Event{}, false
+		}
+		var p protocol.SetBreakpointsPayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return protocol.Event{}, false
+		}
+		results := make([]protocol.BreakpointResult, len(p.Breakpoints))
+		for i, req := range p.Breakpoints {
+			results[i] = protocol.BreakpointResult{File: req.File, Line: req.Line}
+			if req.File == "ghost.go" {
+				results[i].Error = "no code at ghost.go"
+				continue
+			}
+			results[i].Breakpoint = &protocol.Breakpoint{
+				ID: i + 1, Location: protocol.Location{File: req.File, Line: req.Line}, Actions: req.Actions,
+			}
+		}
+		return replyEvent(protocol.EventBreakpointsSet, protocol.BreakpointsSetPayload{Results: results}), true
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	results, err := c.SetBreakpoints([]protocol.SetBreakpointPayload{
+		{File: "main.go", Line: 10, Actions: []string{"continue"}},
+		{File: "ghost.go", Line: 3},
+	})
+	if err != nil {
+		t.Fatalf("SetBreakpoints: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if bp := results[0].Breakpoint; bp == nil || bp.Location.Line != 10 || len(bp.Actions) != 1 {
+		t.Errorf("first result: %+v", results[0])
+	}
+	if results[1].Breakpoint != nil || results[1].Error == "" {
+		t.Errorf("second result should have failed: %+v", results[1])
+	}
+}
+
+// TestPingTimesTheEchoRoundTrip: Ping returns once the echo is back, and
+// the time it reports covers the server's answer.
+func TestPingTimesTheEchoRoundTrip(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind != protocol.CmdEcho {
+			return protocol.Event{}, false
+		}
+		var p protocol.EchoPayload
+		if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+			return protocol.Event{}, false
+		}
+		time.Sleep(delay)
+		return replyEvent(protocol.EventEcho, p), true
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	rtt, err := c.Ping()
+	if err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if rtt < delay {
+		t.Errorf("Ping reported %s, less than the server's %s delay", rtt, delay)
+	}
+	if cmd, ok := fs.lastCommand(); !ok || cmd.Kind != protocol.CmdEcho {
+		t.Errorf("server got %+v, want an Echo", cmd)
+	}
+}
+
 // TestSyncCommandRoutesServerError verifies an EventError for the same command
 // kind satisfies (and fails) the pending synchronous request.
 func TestSyncCommandRoutesServerError(t *testing.T) {
@@ -231,3 +469,723 @@ func TestCloseUnblocksPendingSyncCall(t *testing.T) {
 		t.Fatal("expected an error after Close, got nil")
 	}
 }
+
+// TestCloseSendsCloseFrame checks that Close says goodbye with a normal
+// close frame, so the server drops the client at once rather than on its
+// next failed write.
+func TestCloseSendsCloseFrame(t *testing.T) {
+	fs := newFakeServer(nil)
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	_ = c.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		fs.mu.Lock()
+		err := fs.readErr
+		fs.mu.Unlock()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				t.Fatalf("server read ended with %v, want a normal close frame", err)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server never saw the connection close")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestTLSDialUsesWSS checks that TLS options switch the client to wss and
+// that certificate verification is on unless explicitly skipped.
+func TestTLSDialUsesWSS(t *testing.T) {
+	fs := newFakeTLSServer(nil)
+	defer fs.close()
+
+	if c, err := client.CreateWithOptions(fs.addr(), client.Options{TLS: &tls.Config{MinVersion: tls.VersionTLS12}}); err == nil {
+		_ = c.Close()
+		t.Fatal("dial to a self-signed server succeeded with verification on")
+	}
+
+	c, err := client.CreateWithOptions(fs.addr(), client.Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("CreateWithOptions(InsecureSkipVerify): %v", err)
+	}
+	defer func() { _ = c.Close() }()
+	if c.SessionID() != "test-session" {
+		t.Errorf("SessionID = %q, want test-session", c.SessionID())
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(fs.ts.Certificate())
+	trusted, err := client.CreateWithOptions(fs.addr(), client.Options{TLS: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}})
+	if err != nil {
+		t.Fatalf("CreateWithOptions(RootCAs): %v", err)
+	}
+	_ = trusted.Close()
+}
+
+// TestPlainDialToTLSServerFails guards the default: no Options means ws://,
+// which a TLS-only server refuses.
+func TestPlainDialToTLSServerFails(t *testing.T) {
+	fs := newFakeTLSServer(nil)
+	defer fs.close()
+
+	if c, err := client.Create(fs.addr()); err == nil {
+		_ = c.Close()
+		t.Fatal("plain ws dial to a TLS server unexpectedly succeeded")
+	}
+}
+
+// TestDialNonListeningAddressFailsFast: nothing listens on a just-closed port,
+// so Create must fail promptly instead of hanging.
+func TestDialNonListeningAddressFailsFast(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	start := time.Now()
+	if c, err := client.CreateWithOptions(addr, client.Options{HandshakeTimeout: time.Second}); err == nil {
+		_ = c.Close()
+		t.Fatal("dial to a closed port unexpectedly succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("dial took %v, want well under the handshake timeout", elapsed)
+	}
+}
+
+// TestHandshakeTimeoutBoundsSilentServer: a server that accepts TCP but never
+// answers the upgrade must be abandoned after HandshakeTimeout.
+func TestHandshakeTimeoutBoundsSilentServer(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// Hold the connection open without ever answering the upgrade.
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		<-done
+		_ = conn.Close()
+	}()
+
+	start := time.Now()
+	if c, err := client.CreateWithOptions(ln.Addr().String(), client.Options{HandshakeTimeout: 100 * time.Millisecond}); err == nil {
+		_ = c.Close()
+		t.Fatal("dial to a silent server unexpectedly succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("dial took %v, want about the 100ms handshake timeout", elapsed)
+	}
+}
+
+// TestDialRefusedForIncompatibleVersion: a server that refuses the handshake
+// over the protocol version surfaces as ErrIncompatibleVersion, not as a bare
+// "bad handshake".
+func TestDialRefusedForIncompatibleVersion(t *testing.T) {
+	var gotV string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotV = r.URL.Query().Get("v")
+		w.Header().Set(protocol.VersionHeader, "2.0")
+		http.Error(w, "protocol version not supported", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	c, err := client.Create(strings.TrimPrefix(ts.URL, "http://"))
+	if err == nil {
+		_ = c.Close()
+		t.Fatal("Create unexpectedly succeeded")
+	}
+	if !errors.Is(err, client.ErrIncompatibleVersion) {
+		t.Errorf("err = %v, want ErrIncompatibleVersion", err)
+	}
+	if gotV != protocol.Version {
+		t.Errorf("client sent v=%q, want %q", gotV, protocol.Version)
+	}
+}
+
+// TestDialRejectsIncompatibleWelcome covers a server that doesn't check the
+// client's version: the welcome's envelope version still gives it away.
+func TestDialRejectsIncompatibleWelcome(t *testing.T) {
+	up := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := up.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		welcome := protocol.MustEvent(protocol.EventSessionState, 1, protocol.SessionStatePayload{State: protocol.StateIdle})
+		welcome.Version = "2.0"
+		b, _ := protocol.MarshalEvent(welcome)
+		_ = conn.WriteMessage(websocket.TextMessage, b)
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer ts.Close()
+
+	c, err := client.Create(strings.TrimPrefix(ts.URL, "http://"))
+	if err == nil {
+		_ = c.Close()
+		t.Fatal("Create unexpectedly succeeded")
+	}
+	if !errors.Is(err, client.ErrIncompatibleVersion) {
+		t.Errorf("err = %v, want ErrIncompatibleVersion", err)
+	}
+}
+
+// TestCreateContextHonoursCancellation: the caller's deadline wins even when
+// it is shorter than the handshake timeout.
+func TestCreateContextHonoursCancellation(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.CreateContext(ctx, ln.Addr().String(), client.Options{})
+	if err == nil {
+		t.Fatal("CreateContext succeeded against a server that never accepts")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("CreateContext took %v after a 100ms deadline", elapsed)
+	}
+}
+
+// TestBreakpointsChannelDeliversHitsAndCloses covers the scripting iterator:
+// hits arrive on Breakpoints() and the channel closes with the connection.
+func TestBreakpointsChannelDeliversHitsAndCloses(t *testing.T) {
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind == protocol.CmdContinue {
+			return replyEvent(protocol.EventBreakpointHit, protocol.BreakpointHitPayload{
+				Breakpoint: protocol.Breakpoint{ID: 3, Location: protocol.Location{File: "main.go", Line: 12}},
+			}), true
+		}
+		return protocol.Event{}, false
+	})
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	if err := c.Continue(); err != nil {
+		t.Fatalf("Continue: %v", err)
+	}
+	select {
+	case hit := <-c.Breakpoints():
+		if hit.Breakpoint.ID != 3 || hit.Breakpoint.Location.Line != 12 {
+			t.Errorf("unexpected hit: %+v", hit)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no breakpoint hit delivered")
+	}
+
+	_ = c.Close()
+	fs.close()
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-c.Breakpoints():
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Breakpoints() not closed after the connection ended")
+		}
+	}
+}
+
+// TestBreakpointsChannelDropsWhenUnread: by default an unread Breakpoints()
+// must never hold up Events().
+func TestBreakpointsChannelDropsWhenUnread(t *testing.T) {
+	const hits = 20
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind == protocol.CmdContinue {
+			return replyEvent(protocol.EventBreakpointHit, protocol.BreakpointHitPayload{}), true
+		}
+		return protocol.Event{}, false
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	for i := 0; i < hits; i++ {
+		if err := c.Continue(); err != nil {
+			t.Fatalf("Continue: %v", err)
+		}
+	}
+	for i := 0; i < hits; i++ {
+		select {
+		case <-c.Events():
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Events() stalled after %d of %d hits", i, hits)
+		}
+	}
+}
+
+// TestInterruptWhileRunningSendsPause checks that Interrupt goes out as a
+// Pause once the session has reported it is running.
+func TestInterruptWhileRunningSendsPause(t *testing.T) {
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind == protocol.CmdContinue {
+			return replyEvent(protocol.EventSessionState, protocol.SessionStatePayload{
+				SessionID: "test-session", State: protocol.StateRunning, Clients: 1,
+			}), true
+		}
+		return protocol.Event{}, false
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	if err := c.Continue(); err != nil {
+		t.Fatalf("Continue: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for c.State() != protocol.StateRunning {
+		if time.Now().After(deadline) {
+			t.Fatalf("state = %s, want running", c.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := c.Interrupt(); err != nil {
+		t.Fatalf("Interrupt: %v", err)
+	}
+	for {
+		if cmd, ok := fs.lastCommand(); ok && cmd.Kind == protocol.CmdPause {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server never received CmdPause")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestInputSendsTextVerbatim checks that Input puts the text on the wire
+// untouched, trailing newline included.
+func TestInputSendsTextVerbatim(t *testing.T) {
+	fs := newFakeServer(func(protocol.Command) (protocol.Event, bool) { return protocol.Event{}, false })
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	if err := c.Input("  two  spaces\n"); err != nil {
+		t.Fatalf("Input: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if cmd, ok := fs.lastCommand(); ok && cmd.Kind == protocol.CmdInput {
+			var p protocol.InputPayload
+			if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if p.Data != "  two  spaces\n" {
+				t.Errorf("Data = %q, want %q", p.Data, "  two  spaces\n")
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server never received CmdInput")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestContinueThreadSendsThreadScope(t *testing.T) {
+	fs := newFakeServer(func(protocol.Command) (protocol.Event, bool) { return protocol.Event{}, false })
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	if err := c.ContinueThread(); err != nil {
+		t.Fatalf("ContinueThread: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if cmd, ok := fs.lastCommand(); ok && cmd.Kind == protocol.CmdContinue {
+			var p protocol.ContinuePayload
+			if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if p.Scope != protocol.ScopeThread {
+				t.Errorf("Scope = %q, want %q", p.Scope, protocol.ScopeThread)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server never received CmdContinue")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLaunchWithSendsMaxRunTime(t *testing.T) {
+	fs := newFakeServer(func(protocol.Command) (protocol.Event, bool) { return protocol.Event{}, false })
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	if err := c.LaunchWith(protocol.LaunchPayload{Program: "/tmp/app", MaxRunTime: 30 * time.Second}); err != nil {
+		t.Fatalf("LaunchWith: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if cmd, ok := fs.lastCommand(); ok && cmd.Kind == protocol.CmdLaunch {
+			var p protocol.LaunchPayload
+			if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if p.MaxRunTime != 30*time.Second {
+				t.Errorf("MaxRunTime = %v, want 30s", p.MaxRunTime)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server never received CmdLaunch")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLaunchContextReturnsTheReadyProcess(t *testing.T) {
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind != protocol.CmdLaunch {
+			return protocol.Event{}, false
+		}
+		return replyEvent(protocol.EventDebuggerReady, protocol.DebuggerReadyPayload{
+			PID: 4242, PGID: 4242, Program: "/tmp/app",
+		}), true
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	ready, err := c.LaunchContext(context.Background(), protocol.LaunchPayload{Program: "/tmp/app"})
+	if err != nil {
+		t.Fatalf("LaunchContext: %v", err)
+	}
+	if ready.PID != 4242 || ready.Program != "/tmp/app" {
+		t.Errorf("LaunchContext = %+v, want PID 4242 for /tmp/app", ready)
+	}
+}
+
+func TestLaunchContextRoutesServerError(t *testing.T) {
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind != protocol.CmdLaunch {
+			return protocol.Event{}, false
+		}
+		return replyEvent(protocol.EventError, protocol.ErrorPayload{
+			Command: protocol.CmdLaunch,
+			Message: "fork/exec /tmp/app: no such file or directory",
+		}), true
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	_, err := c.LaunchContext(context.Background(), protocol.LaunchPayload{Program: "/tmp/app"})
+	if err == nil || !strings.Contains(err.Error(), "no such file or directory") {
+		t.Fatalf("LaunchContext error = %v, want the server's error", err)
+	}
+}
+
+func TestLaunchContextHonoursCancellation(t *testing.T) {
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind != protocol.CmdEcho {
+			return protocol.Event{}, false // never ready
+		}
+		var p protocol.EchoPayload
+		_ = protocol.DecodeCommandPayload(cmd, &p)
+		return replyEvent(protocol.EventEcho, p), true
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.LaunchContext(ctx, protocol.LaunchPayload{Program: "/tmp/app"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("LaunchContext error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// The abandoned wait must not swallow the next synchronous reply.
+	if _, err := c.Ping(); err != nil {
+		t.Fatalf("Ping after a cancelled LaunchContext: %v", err)
+	}
+}
+
+// TestInterruptWhenNotRunning checks that Interrupt refuses without sending
+// anything when the session isn't running.
+func TestInterruptWhenNotRunning(t *testing.T) {
+	fs := newFakeServer(nil)
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	if err := c.Interrupt(); !errors.Is(err, client.ErrNotRunning) {
+		t.Fatalf("Interrupt on an idle session = %v, want ErrNotRunning", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if cmd, ok := fs.lastCommand(); ok {
+		t.Fatalf("server received %s, want nothing", cmd.Kind)
+	}
+}
+
+// TestMissedEventsCountsSeqGaps: a jump in seq is counted as lost events; a
+// repeated seq (a per-client replay) is not.
+func TestMissedEventsCountsSeqGaps(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seqs = []uint64{2, 2, 6, 7}
+	)
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		if cmd.Kind != protocol.CmdContinue {
+			return protocol.Event{}, false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		seq := seqs[0]
+		seqs = seqs[1:]
+		return protocol.MustEvent(protocol.EventContinued, seq, struct{}{}), true
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	for i := 0; i < 4; i++ {
+		if err := c.Continue(); err != nil {
+			t.Fatalf("Continue: %v", err)
+		}
+		select {
+		case <-c.Events():
+		case <-time.After(2 * time.Second):
+			t.Fatalf("event %d not delivered", i)
+		}
+	}
+	// Welcome is seq 1; 2, 2 are in order; 2 -> 6 skips three.
+	if got := c.MissedEvents(); got != 3 {
+		t.Fatalf("MissedEvents = %d, want 3", got)
+	}
+}
+
+// TestSubscribeStopsCountingFilteredGaps: the gaps a Subscribe filter leaves
+// in Seq are not losses, until the filter is lifted.
+func TestSubscribeStopsCountingFilteredGaps(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		last     = uint64(1)
+		seqs     = []uint64{9, 12}
+		requests []protocol.SubscribePayload
+	)
+	fs := newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch cmd.Kind {
+		case protocol.CmdSubscribe:
+			var p protocol.SubscribePayload
+			if err := protocol.DecodeCommandPayload(cmd, &p); err != nil {
+				return protocol.Event{}, false
+			}
+			requests = append(requests, p)
+			return protocol.MustEvent(protocol.EventSubscribed, last, p), true
+		case protocol.CmdContinue:
+			last, seqs = seqs[0], seqs[1:]
+			return protocol.MustEvent(protocol.EventContinued, last, struct{}{}), true
+		}
+		return protocol.Event{}, false
+	})
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	continued := func() {
+		t.Helper()
+		if err := c.Continue(); err != nil {
+			t.Fatalf("Continue: %v", err)
+		}
+		select {
+		case <-c.Events():
+		case <-time.After(2 * time.Second):
+			t.Fatal("Continued not delivered")
+		}
+	}
+
+	if err := c.Subscribe(protocol.EventContinued); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	continued() // 1 -> 9 while filtered
+	if got := c.MissedEvents(); got != 0 {
+		t.Fatalf("MissedEvents = %d while filtered, want 0", got)
+	}
+
+	if err := c.Subscribe(); err != nil {
+		t.Fatalf("Subscribe(): %v", err)
+	}
+	continued() // 9 -> 12 with every event expected
+	if got := c.MissedEvents(); got != 2 {
+		t.Fatalf("MissedEvents = %d after lifting the filter, want 2", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 || !slices.Equal(requests[0].Events, []protocol.EventKind{protocol.EventContinued}) || requests[1].Events != nil {
+		t.Errorf("Subscribe sent %+v, want [Continued] then no filter", requests)
+	}
+}
+
+// stateServer answers Continue with running, Pause with suspended and Kill
+// with exited, the way the hub reports those transitions.
+func stateServer() *fakeServer {
+	return newFakeServer(func(cmd protocol.Command) (protocol.Event, bool) {
+		var state protocol.SessionState
+		switch cmd.Kind {
+		case protocol.CmdContinue:
+			state = protocol.StateRunning
+		case protocol.CmdPause:
+			state = protocol.StateSuspended
+		case protocol.CmdKill:
+			state = protocol.StateExited
+		default:
+			return protocol.Event{}, false
+		}
+		return replyEvent(protocol.EventSessionState, protocol.SessionStatePayload{
+			SessionID: "test-session", State: state, Clients: 1,
+		}), true
+	})
+}
+
+// TestWaitForStateSeesEveryTransition: a waiter that only wakes once the
+// session has moved on must still count the state it was waiting for.
+func TestWaitForStateSeesEveryTransition(t *testing.T) {
+	fs := stateServer()
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := c.WaitForState(ctx, protocol.StateIdle); err != nil {
+		t.Fatalf("WaitForState(idle) in idle session: %v", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.WaitForState(ctx, protocol.StateRunning) }()
+	if err := c.Continue(); err != nil {
+		t.Fatalf("Continue: %v", err)
+	}
+	if err := c.Pause(); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if err := c.WaitForState(ctx, protocol.StateSuspended); err != nil {
+		t.Fatalf("WaitForState(suspended): %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("WaitForState(running): %v", err)
+	}
+}
+
+// TestWaitForStateFailsWhenSessionEnds: a process that exits will never stop
+// at a breakpoint, so the wait must not run on until the context gives up.
+func TestWaitForStateFailsWhenSessionEnds(t *testing.T) {
+	fs := stateServer()
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := c.Continue(); err != nil {
+		t.Fatalf("Continue: %v", err)
+	}
+	if err := c.WaitForState(ctx, protocol.StateRunning); err != nil {
+		t.Fatalf("WaitForState(running): %v", err)
+	}
+	if err := c.Kill(); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	if err := c.WaitForState(ctx, protocol.StateSuspended); !errors.Is(err, client.ErrSessionEnded) {
+		t.Fatalf("WaitForState(suspended) after exit: got %v, want ErrSessionEnded", err)
+	}
+}
+
+func TestWaitForStateHonoursContext(t *testing.T) {
+	fs := stateServer()
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+	defer func() { _ = c.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.WaitForState(ctx, protocol.StateSuspended); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWaitForStateUnblocksOnClose(t *testing.T) {
+	fs := stateServer()
+	defer fs.close()
+
+	c := dialTestClient(t, fs)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.WaitForState(context.Background(), protocol.StateSuspended) }()
+	_ = c.Close()
+
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatal("expected an error after Close, got nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("WaitForState still blocked after Close")
+	}
+}
+
+// TestSessionLogsReadsTheRESTEndpoint: SessionLogs needs no WebSocket, so it
+// can read a retained session's trace after every client has left.
+func TestSessionLogsReadsTheRESTEndpoint(t *testing.T) {
+	var gotPath, gotLimit string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotLimit = r.URL.Path, r.URL.Query().Get("limit")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"entries":[{"level":"INFO","message":"process exited"}]}`))
+	}))
+	defer ts.Close()
+
+	entries, err := client.SessionLogs(strings.TrimPrefix(ts.URL, "http://"), "s1", 10)
+	if err != nil {
+		t.Fatalf("SessionLogs: %v", err)
+	}
+	if gotPath != "/api/sessions/s1/logs" || gotLimit != "10" {
+		t.Errorf("request = %s?limit=%s, want /api/sessions/s1/logs?limit=10", gotPath, gotLimit)
+	}
+	if len(entries) != 1 || entries[0].Message != "process exited" {
+		t.Errorf("entries = %+v, want the one served", entries)
+	}
+}
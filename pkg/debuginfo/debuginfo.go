@@ -0,0 +1,72 @@
+// Package debuginfo answers source-level questions about a Go binary — which
+// address a line compiles to, which line an address came from, where a
+// function starts — from its DWARF, without launching or attaching to it.
+// It is meant for tooling and tests that only need the static picture.
+// Info.AttachPID relocates the answers to a running PIE process.
+package debuginfo
+
+import (
+	"github.com/bingosuite/bingo/internal/debugger"
+	"github.com/bingosuite/bingo/pkg/protocol"
+)
+
+// Info is the debug information of one binary.
+type Info struct {
+	d *debugger.DebugInfo
+}
+
+// Open loads the debug information of the binary at path. It fails when the
+// file is not a binary for the host's object format or carries no DWARF
+// (built with -ldflags=-w).
+func Open(path string) (*Info, error) {
+	d, err := debugger.NewDebugInfoFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Info{d: d}, nil
+}
+
+// AttachPID makes every address i reports match process pid, a running copy
+// of the binary, instead of the addresses it was linked at. That only
+// differs for a PIE binary. Linux only.
+func (i *Info) AttachPID(pid int) error {
+	return i.d.AttachPID(pid)
+}
+
+// LineToPC returns the address of the first statement on file:line. file may
+// be a path suffix ("main.go", "cmd/app/main.go").
+func (i *Info) LineToPC(file string, line int) (uint64, error) {
+	return i.d.LineToPC(file, line)
+}
+
+// PCToLine returns the source location pc belongs to.
+func (i *Info) PCToLine(pc uint64) (protocol.Location, error) {
+	return i.d.PCToLine(pc)
+}
+
+// LookupFunc returns the address just past the prologue of the function
+// with the fully qualified name ("main.main", "net/http.(*Server).Serve"),
+// where a breakpoint on it stops.
+func (i *Info) LookupFunc(name string) (uint64, error) {
+	return i.d.LookupFunc(name)
+}
+
+// FuncLine returns the source line offset lines into the function named
+// name, counting from the line it is declared on, and that line's
+// address. A line with no code moves forward to the next one that has
+// some. It errors when the line would be past the function's end.
+func (i *Info) FuncLine(name string, offset int) (uint64, protocol.Location, error) {
+	return i.d.FuncLine(name, offset, false)
+}
+
+// FuncReturn returns the last source line with code in the function named
+// name, its final return, and that line's address.
+func (i *Info) FuncReturn(name string) (uint64, protocol.Location, error) {
+	return i.d.FuncLine(name, 0, true)
+}
+
+// Files returns every source file the binary has line information for,
+// sorted. These are the files breakpoints can be set in.
+func (i *Info) Files() []string {
+	return i.d.Files()
+}
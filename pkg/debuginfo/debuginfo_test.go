@@ -0,0 +1,198 @@
+package debuginfo_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bingosuite/bingo/pkg/debuginfo"
+)
+
+const fixtureSrc = `package main
+
+import "time"
+
+func double(x int) int {
+	return x * 2 // double-marker
+}
+
+func main() {
+	println(double(21))
+	time.Sleep(time.Minute) // long enough to be attached to
+}
+`
+
+// buildFixture compiles fixtureSrc, with any extra go build flags, and
+// returns the binary's path.
+func buildFixture(t *testing.T, flags ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "fix.go")
+	if err := os.WriteFile(src, []byte(fixtureSrc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bin := filepath.Join(dir, "fix")
+	args := append([]string{"build", "-gcflags=all=-N -l", "-o", bin}, flags...)
+	cmd := exec.Command("go", append(args, src)...)
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("build fixture: %v\n%s", err, out)
+	}
+	return bin
+}
+
+func markerLine(t *testing.T, marker string) int {
+	t.Helper()
+	for i, line := range strings.Split(fixtureSrc, "\n") {
+		if strings.Contains(line, marker) {
+			return i + 1
+		}
+	}
+	t.Fatalf("marker %q not in fixture", marker)
+	return 0
+}
+
+func TestQueriesWithoutAProcess(t *testing.T) {
+	info, err := debuginfo.Open(buildFixture(t))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	line := markerLine(t, "double-marker")
+	pc, err := info.LineToPC("fix.go", line)
+	if err != nil {
+		t.Fatalf("LineToPC: %v", err)
+	}
+	loc, err := info.PCToLine(pc)
+	if err != nil {
+		t.Fatalf("PCToLine(%#x): %v", pc, err)
+	}
+	if !strings.HasSuffix(loc.File, "fix.go") || loc.Line != line || loc.Function != "main.double" {
+		t.Errorf("PCToLine(%#x) = %+v, want fix.go:%d in main.double", pc, loc, line)
+	}
+
+	entry, err := info.LookupFunc("main.double")
+	if err != nil {
+		t.Fatalf("LookupFunc: %v", err)
+	}
+	if loc, _ := info.PCToLine(entry); loc.Function != "main.double" {
+		t.Errorf("LookupFunc entry %#x is in %q", entry, loc.Function)
+	}
+	if _, err := info.LookupFunc("main.nope"); err == nil {
+		t.Error("LookupFunc of a missing function succeeded")
+	}
+
+	files := info.Files()
+	if !slices.ContainsFunc(files, func(f string) bool { return strings.HasSuffix(f, "fix.go") }) {
+		t.Errorf("Files() has no fix.go among %d files", len(files))
+	}
+}
+
+func TestFuncRelativeLines(t *testing.T) {
+	info, err := debuginfo.Open(buildFixture(t))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	line := markerLine(t, "double-marker")
+
+	if _, loc, err := info.FuncLine("main.double", 1); err != nil || loc.Line != line {
+		t.Errorf("FuncLine(main.double, 1) = %+v, %v; want line %d", loc, err, line)
+	}
+	if _, loc, err := info.FuncReturn("main.double"); err != nil || loc.Line != line {
+		t.Errorf("FuncReturn(main.double) = %+v, %v; want line %d", loc, err, line)
+	}
+	if _, _, err := info.FuncLine("main.double", 5); err == nil {
+		t.Error("FuncLine past the end of main.double succeeded")
+	}
+	if _, _, err := info.FuncLine("main.nope", 0); err == nil {
+		t.Error("FuncLine of a missing function succeeded")
+	}
+}
+
+func TestOpenRejectsNonBinary(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(f, []byte("not a binary"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := debuginfo.Open(f); err == nil {
+		t.Error("Open of a text file succeeded")
+	}
+}
+
+func TestAttachPIDRelocatesPIE(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("AttachPID reads /proc")
+	}
+	bin := buildFixture(t, "-buildmode=pie")
+	info, err := debuginfo.Open(bin)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	linked, err := info.LookupFunc("main.double")
+	if err != nil {
+		t.Fatalf("LookupFunc: %v", err)
+	}
+
+	cmd := exec.Command(bin)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cmd.Process.Kill(); _ = cmd.Wait() }()
+
+	// The kernel maps the binary before the first instruction runs, but the
+	// process may still be the forked test binary for a moment.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err = info.AttachPID(cmd.Process.Pid)
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("AttachPID: %v", err)
+	}
+
+	loaded, err := info.LookupFunc("main.double")
+	if err != nil {
+		t.Fatalf("LookupFunc after AttachPID: %v", err)
+	}
+	if loaded == linked {
+		t.Fatalf("main.double still at link-time %#x", linked)
+	}
+	if !inExecutableMapping(t, cmd.Process.Pid, loaded) {
+		t.Errorf("relocated main.double %#x is not in an executable mapping", loaded)
+	}
+	if loc, err := info.PCToLine(loaded); err != nil || loc.Function != "main.double" {
+		t.Errorf("PCToLine(%#x) = %+v, %v; want main.double", loaded, loc, err)
+	}
+}
+
+// inExecutableMapping reports whether addr lies in an r-x mapping of pid.
+func inExecutableMapping(t *testing.T, pid int, addr uint64) bool {
+	t.Helper()
+	maps, err := os.ReadFile(fmt.Sprintf("/proc/%d/maps", pid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(maps), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.Contains(fields[1], "x") {
+			continue
+		}
+		lo, hi, _ := strings.Cut(fields[0], "-")
+		start, err1 := strconv.ParseUint(lo, 16, 64)
+		end, err2 := strconv.ParseUint(hi, 16, 64)
+		if err1 == nil && err2 == nil && addr >= start && addr < end {
+			return true
+		}
+	}
+	return false
+}
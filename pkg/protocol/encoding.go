@@ -3,6 +3,7 @@ package protocol
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // NewEvent constructs a versioned Event with payload marshalled into the
@@ -33,21 +34,84 @@ func DecodeEventPayload(e Event, dst any) error {
 	return nil
 }
 
-// DecodeCommandPayload unmarshals cmd.Payload into dst.
+// optionalPayload are the commands whose payload may be left out: those
+// that take none, and those whose every field defaults sensibly to zero.
+var optionalPayload = map[CommandKind]bool{
+	CmdKill:                  true,
+	CmdDisableAllBreakpoints: true,
+	CmdEnableAllBreakpoints:  true,
+	CmdClearAllBreakpoints:   true,
+	CmdContinue:              true,
+	CmdStepOver:              true,
+	CmdStepInto:              true,
+	CmdStepOut:               true,
+	CmdStepInstruction:       true,
+	CmdPause:                 true,
+	CmdLocals:                true,
+	CmdFrames:                true,
+	CmdGoroutines:            true,
+	CmdInspectArgs:           true,
+	CmdRestart:               true,
+	CmdLogs:                  true,
+	CmdCapabilities:          true,
+	CmdStatus:                true,
+	CmdEcho:                  true,
+	CmdSubscribe:             true,
+}
+
+// DecodeCommandPayload unmarshals cmd.Payload into dst. A payload that is
+// absent or null leaves dst at its zero value for the commands in
+// optionalPayload, so a bare {"kind":"Continue"} works; for any other
+// command it is an error, rather than a Launch of "" or a breakpoint at
+// line 0.
 func DecodeCommandPayload(cmd Command, dst any) error {
+	if len(cmd.Payload) == 0 || string(cmd.Payload) == "null" {
+		if optionalPayload[cmd.Kind] {
+			return nil
+		}
+		return fmt.Errorf("protocol.DecodeCommandPayload(%s): missing payload", cmd.Kind)
+	}
 	if err := json.Unmarshal(cmd.Payload, dst); err != nil {
 		return fmt.Errorf("protocol.DecodeCommandPayload(%s): %w", cmd.Kind, err)
 	}
 	return nil
 }
 
-// MarshalEvent serialises an Event to JSON for a WebSocket text frame.
+// eventHead is Event without its payload, which MarshalEvent appends as is.
+type eventHead struct {
+	Version string        `json:"v"`
+	Kind    EventKind     `json:"kind"`
+	Seq     uint64        `json:"seq"`
+	Time    time.Time     `json:"time,omitzero"`
+	Mono    time.Duration `json:"mono,omitempty"`
+}
+
+// MarshalEvent serialises an Event to JSON for a WebSocket text frame. The
+// payload is copied in verbatim, not re-validated: it is JSON already, from
+// NewEvent, and json.Marshal would scan and re-compact it on every send,
+// which for a large goroutine snapshot is nearly all of a broadcast's cost.
+// An Event built by hand must therefore carry a valid JSON payload (or
+// none, sent as null).
 func MarshalEvent(e Event) ([]byte, error) {
-	b, err := json.Marshal(e)
+	head, err := json.Marshal(eventHead{
+		Version: e.Version,
+		Kind:    e.Kind,
+		Seq:     e.Seq,
+		Time:    e.Time,
+		Mono:    e.Mono,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("protocol.MarshalEvent: %w", err)
 	}
-	return b, nil
+	payload := []byte(e.Payload)
+	if len(payload) == 0 {
+		payload = []byte("null")
+	}
+	b := make([]byte, 0, len(head)+len(`,"payload":`)+len(payload))
+	b = append(b, head[:len(head)-1]...) // drop the closing brace
+	b = append(b, `,"payload":`...)
+	b = append(b, payload...)
+	return append(b, '}'), nil
 }
 
 // UnmarshalCommand parses raw WebSocket bytes into a Command envelope.
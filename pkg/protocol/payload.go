@@ -1,5 +1,10 @@
 package protocol
 
+import (
+	"fmt"
+	"time"
+)
+
 // Location is a source position.
 type Location struct {
 	File     string `json:"file"`
@@ -12,6 +17,55 @@ type Breakpoint struct {
 	ID       int      `json:"id"`
 	Location Location `json:"location"`
 	Enabled  bool     `json:"enabled"`
+	// Actions run each time the breakpoint fires; see SetBreakpointPayload.
+	Actions []string `json:"actions,omitempty"`
+	// IgnoreCount is how many more hits the breakpoint lets go by without
+	// stopping, as of the event that carries it.
+	IgnoreCount int `json:"ignoreCount,omitempty"`
+	// GoroutineCount, when set, is the condition a hit must meet to stop.
+	GoroutineCount *GoroutineCountCondition `json:"goroutineCount,omitempty"`
+}
+
+// GoroutineCountCondition makes a breakpoint stop only when the number of
+// live goroutines compares to Count by Op: ">", ">=", "<", "<=", "==" or
+// "!=". {">", 1000} catches a goroutine leak at the hit where it spikes.
+// Hits where it does not hold resume at once, without an event.
+type GoroutineCountCondition struct {
+	Op    string `json:"op"`
+	Count int    `json:"count"`
+}
+
+// Valid reports whether c's Op is one of the comparisons and its Count is
+// not negative.
+func (c GoroutineCountCondition) Valid() bool {
+	switch c.Op {
+	case ">", ">=", "<", "<=", "==", "!=":
+		return c.Count >= 0
+	}
+	return false
+}
+
+// Holds reports whether n live goroutines meet c. An invalid c never holds.
+func (c GoroutineCountCondition) Holds(n int) bool {
+	switch c.Op {
+	case ">":
+		return n > c.Count
+	case ">=":
+		return n >= c.Count
+	case "<":
+		return n < c.Count
+	case "<=":
+		return n <= c.Count
+	case "==":
+		return n == c.Count
+	case "!=":
+		return n != c.Count
+	}
+	return false
+}
+
+func (c GoroutineCountCondition) String() string {
+	return fmt.Sprintf("goroutines %s %d", c.Op, c.Count)
 }
 
 // Variable is a local variable or function argument.
@@ -32,7 +86,7 @@ type Frame struct {
 // Goroutine is a snapshot of a running goroutine.
 type Goroutine struct {
 	ID         int      `json:"id"`
-	Status     string   `json:"status"` // "running" | "waiting" | "syscall" | "dead"
+	Status     string   `json:"status"` // "running" | "runnable" | "waiting" | "syscall" | "unknown"
 	CurrentLoc Location `json:"currentLoc"`
 	GoLoc      Location `json:"goLoc"` // where the goroutine was spawned
 	WaitReason string   `json:"waitReason,omitempty"`
@@ -53,6 +107,37 @@ type BreakpointHitPayload struct {
 	Breakpoint Breakpoint `json:"breakpoint"`
 	Goroutine  Goroutine  `json:"goroutine"`
 	Frames     []Frame    `json:"frames"`
+	// Registers is only set when the server runs with hit context enabled
+	// (bingo -hit-context).
+	Registers *Registers `json:"registers,omitempty"`
+	// OtherStops are the threads that stopped at the same time as this one.
+	// They stay stopped until the next Continue, which resumes them too.
+	OtherStops []ThreadStop `json:"otherStops,omitempty"`
+	// StoppedThreads is every thread stopped at the hit, this one first,
+	// including ones stopped for reasons of their own. Only set when the
+	// server runs with bingo -stopped-threads.
+	StoppedThreads []ThreadStop `json:"stoppedThreads,omitempty"`
+	// Reason is always StopReasonBreakpoint: the engine reports its own
+	// temporary breakpoints as steps, never as hits.
+	Reason StopReason `json:"reason,omitempty"`
+}
+
+// ThreadStop is a stopped thread and where it is. Breakpoint is the ID of the
+// breakpoint it stopped at and Signal the signal it stopped with; at most one
+// is set.
+type ThreadStop struct {
+	TID        int      `json:"tid"`
+	Location   Location `json:"location"`
+	Breakpoint int      `json:"breakpoint,omitempty"`
+	Signal     int      `json:"signal,omitempty"`
+}
+
+// Registers is the stopping thread's core registers: RIP/RSP/RBP on amd64,
+// PC/SP/X29 on arm64.
+type Registers struct {
+	PC uint64 `json:"pc"`
+	SP uint64 `json:"sp"`
+	BP uint64 `json:"bp"`
 }
 
 type PanicPayload struct {
@@ -68,39 +153,142 @@ type OutputPayload struct {
 
 type ProcessExitedPayload struct {
 	ExitCode int    `json:"exitCode"`
-	Reason   string `json:"reason,omitempty"` // "killed" | "exited"
+	Reason   string `json:"reason,omitempty"` // "killed" | "exited" | "gone" (tracee vanished, code unknown)
 }
 
 type BreakpointSetPayload struct {
 	Breakpoint Breakpoint `json:"breakpoint"`
+	// AlreadySet means the line already had Breakpoint, which is reported
+	// as it was: the set was a no-op, and any actions it carried were not
+	// applied.
+	AlreadySet bool `json:"alreadySet,omitempty"`
+}
+
+// BreakpointsSetPayload reports how each entry of a CmdSetBreakpoints went,
+// in request order.
+type BreakpointsSetPayload struct {
+	Results []BreakpointResult `json:"results"`
+}
+
+// BreakpointResult is the outcome of one CmdSetBreakpoints entry: the
+// breakpoint that was set, or the error that kept File:Line from getting one.
+// AlreadySet is as in BreakpointSetPayload.
+type BreakpointResult struct {
+	File       string      `json:"file"`
+	Line       int         `json:"line"`
+	Breakpoint *Breakpoint `json:"breakpoint,omitempty"`
+	AlreadySet bool        `json:"alreadySet,omitempty"`
+	Error      string      `json:"error,omitempty"`
 }
 
 type BreakpointClearedPayload struct {
 	ID int `json:"id"`
 }
 
+// IgnoreCountSetPayload is the breakpoint a CmdSetIgnoreCount changed.
+type IgnoreCountSetPayload struct {
+	Breakpoint Breakpoint `json:"breakpoint"`
+}
+
+// BreakpointsToggledPayload lists every breakpoint after a disable-all
+// (Enabled false) or enable-all (Enabled true).
+type BreakpointsToggledPayload struct {
+	Enabled     bool         `json:"enabled"`
+	Breakpoints []Breakpoint `json:"breakpoints"`
+}
+
+// BreakpointsClearedPayload lists the IDs a clear-all removed, in ascending
+// order; Count is len(IDs), for clients that only report it.
+type BreakpointsClearedPayload struct {
+	IDs   []int `json:"ids,omitempty"`
+	Count int   `json:"count"`
+}
+
+// BreakpointLostPayload names the breakpoint that is gone and why.
+type BreakpointLostPayload struct {
+	Breakpoint Breakpoint `json:"breakpoint"`
+	Message    string     `json:"message"`
+}
+
+// BreakpointWarningPayload names the breakpoint that was set, the runtime
+// function it landed in, and why that is risky.
+type BreakpointWarningPayload struct {
+	Breakpoint Breakpoint `json:"breakpoint"`
+	Function   string     `json:"function"`
+	Message    string     `json:"message"`
+}
+
+// BreakpointErrorPayload names the location that could not be set and why.
+type BreakpointErrorPayload struct {
+	Location Location `json:"location"`
+	Message  string   `json:"message"`
+}
+
 type SteppedPayload struct {
 	Goroutine Goroutine `json:"goroutine"`
 	Location  Location  `json:"location"`
 	Frames    []Frame   `json:"frames"`
+	// Steps is how many steps a counted StepOver/StepInto ran before this
+	// stop; zero for a plain step.
+	Steps int `json:"steps,omitempty"`
+	// From is where the step started and PCDelta how far the program counter
+	// moved, in bytes (negative after a return or a backward jump). Both are
+	// omitted for stops no step produced, like the initial stop after Launch.
+	From    *Location `json:"from,omitempty"`
+	PCDelta int64     `json:"pcDelta,omitempty"`
+	// Instructions is how many machine instructions the step executed. It is
+	// only known when every one was single-stepped, so it is zero when part
+	// of the step ran freely to a temporary breakpoint — a source-line
+	// StepOver or a StepOut.
+	Instructions int `json:"instructions,omitempty"`
+	// Reason tells a completed step from the other stops reported this way:
+	// StopReasonTemp for the temporary breakpoint of continue-to-main and
+	// StopReasonEntry for the initial stop after Launch or Attach.
+	Reason StopReason `json:"reason,omitempty"`
 }
 
 // PausedPayload reports where the tracee was halted by a Pause request. It
 // mirrors SteppedPayload: the location is wherever execution happened to be
 // interrupted (an async stop), not a source-line boundary.
 type PausedPayload struct {
-	Goroutine Goroutine `json:"goroutine"`
-	Location  Location  `json:"location"`
-	Frames    []Frame   `json:"frames"`
+	Goroutine Goroutine  `json:"goroutine"`
+	Location  Location   `json:"location"`
+	Frames    []Frame    `json:"frames"`
+	Reason    StopReason `json:"reason,omitempty"`
 }
 
 type ContinuedPayload struct{}
 
+// ConditionErrorPayload reports that Breakpoint's condition could not be
+// evaluated at a hit, and why. The hit stops as if it held; see
+// EventConditionError.
+type ConditionErrorPayload struct {
+	Breakpoint Breakpoint `json:"breakpoint"`
+	Message    string     `json:"message"`
+}
+
+// ResumedPayload says which command resumed a suspended process and where
+// from. PC is zero when the debugger could not report it; Location is nil
+// when the stop that suspended the process carried none.
+type ResumedPayload struct {
+	Command  CommandKind `json:"command"`
+	PC       uint64      `json:"pc,omitempty"`
+	Location *Location   `json:"location,omitempty"`
+}
+
 type LocalsPayload struct {
 	FrameIndex int        `json:"frameIndex"`
 	Variables  []Variable `json:"variables"`
 }
 
+// ArgsPayload is the stopped function and its arguments, in declaration
+// order. An argument that could not be read has the reason as its Value,
+// like "<optimized out>"; results are not included.
+type ArgsPayload struct {
+	Function string     `json:"function"`
+	Args     []Variable `json:"args"`
+}
+
 type FramesPayload struct {
 	Frames []Frame `json:"frames"`
 }
@@ -109,10 +297,104 @@ type GoroutinesPayload struct {
 	Goroutines []Goroutine `json:"goroutines"`
 }
 
+// LineResolvedPayload echoes the requested File and Line next to the result.
+// Location.Line differs from Line when the requested line has no code
+// (blank, comment, declaration) and the next executable line was used.
+type LineResolvedPayload struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	PC       uint64   `json:"pc"`
+	Location Location `json:"location"`
+}
+
+// AddrResolvedPayload is the source location of PC. An address outside any
+// function comes back with Function "unknown" and no file or line.
+type AddrResolvedPayload struct {
+	PC       uint64   `json:"pc"`
+	Location Location `json:"location"`
+}
+
+// LogEntry is one captured session log line.
+type LogEntry struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+// CapabilitiesPayload lists every command the server supports and the subset
+// the session's State currently allows. Valid is a snapshot: the state can
+// change as soon as it is sent.
+type CapabilitiesPayload struct {
+	State     SessionState  `json:"state"`
+	Supported []CommandKind `json:"supported"`
+	Valid     []CommandKind `json:"valid"`
+}
+
+// StatusPayload is the ground truth CmdStatus reads. Session is the hub's
+// state, which follows the events it has broadcast; Process is the engine's,
+// which is ahead of it while a stop or resume is still on its way. A client
+// whose own picture disagrees with both has missed an event.
+type StatusPayload struct {
+	Session SessionState `json:"session"`
+	Process SessionState `json:"process"`
+	// PID is the operating system's ID for the process, while there is one.
+	PID int `json:"pid,omitempty"`
+	// PIE says whether the binary is position-independent. LoadBase is where
+	// its first segment is mapped: the link-time address, moved by however
+	// far the process loaded a PIE from it. Addresses bingo reports already
+	// include that move; LoadBase reconciles them with link-time ones from
+	// objdump or nm. Both are zero while there is no process.
+	PIE      bool   `json:"pie,omitempty"`
+	LoadBase uint64 `json:"loadBase,omitempty"`
+	// PC and TID are where the process is stopped; zero unless Process is
+	// suspended.
+	PC  uint64 `json:"pc,omitempty"`
+	TID int    `json:"tid,omitempty"`
+	// Pending is set while a Pause or a step is under way: it was accepted,
+	// and the stop that answers it has not been reported yet.
+	Pending bool `json:"pending,omitempty"`
+	// Reason is why the process is suspended, as reported by the event that
+	// suspended it; empty unless Process is suspended, and after a stop no
+	// event gave a reason for, like an error.
+	Reason StopReason `json:"reason,omitempty"`
+	// CreatedAt is when the session was created. LastActivity is when a
+	// client last connected or sent a command (this CmdStatus included),
+	// the clock the server's idle timeout runs on.
+	CreatedAt    time.Time `json:"createdAt,omitzero"`
+	LastActivity time.Time `json:"lastActivity,omitzero"`
+}
+
+// LogsPayload carries session log entries, oldest first.
+type LogsPayload struct {
+	Entries []LogEntry `json:"entries"`
+}
+
+// GoroutineSnapshotPayload is the goroutine picture captured at a stop.
+// Trigger is the suspending event that caused the snapshot.
+type GoroutineSnapshotPayload struct {
+	Trigger    EventKind   `json:"trigger"`
+	Goroutines []Goroutine `json:"goroutines"`
+}
+
+// GoroutineLeakPayload is the goroutine count at each snapshot in the window
+// that tripped the leak warning, oldest first, and how much it grew.
+type GoroutineLeakPayload struct {
+	Counts []int `json:"counts"`
+	Growth int   `json:"growth"`
+}
+
+// SessionStatePayload reports the session's lifecycle phase. The welcome
+// copy sent to a newly-joined client additionally carries the last-known
+// view — where the process is suspended and which breakpoints are installed —
+// so a reconnecting UI doesn't start out blank; state-transition broadcasts
+// leave those fields empty.
 type SessionStatePayload struct {
-	SessionID string       `json:"sessionID"`
-	State     SessionState `json:"state"`
-	Clients   int          `json:"clients"`
+	SessionID   string       `json:"sessionID"`
+	State       SessionState `json:"state"`
+	Clients     int          `json:"clients"`
+	Location    *Location    `json:"location,omitempty"`
+	Breakpoints []Breakpoint `json:"breakpoints,omitempty"`
 }
 
 // ErrorPayload reports a failed command. Command uses omitempty so CmdNone
@@ -122,10 +404,79 @@ type ErrorPayload struct {
 	Message string      `json:"message"`
 }
 
+// NoticePayload explains why a command was a no-op. Shaped like ErrorPayload.
+type NoticePayload struct {
+	Command CommandKind `json:"command,omitempty"`
+	Message string      `json:"message"`
+}
+
 type LaunchPayload struct {
 	Program string   `json:"program"`
 	Args    []string `json:"args,omitempty"`
 	Env     []string `json:"env,omitempty"` // additional KEY=VALUE entries
+
+	// MaxRunTime, when set, pauses the process once it has spent this long
+	// running, so an automated session whose target never reaches a
+	// breakpoint does not hang. Time suspended does not count. Restart keeps
+	// it and starts the count over.
+	MaxRunTime time.Duration `json:"maxRunTime,omitempty"`
+
+	// InitialStop chooses what happens at the stop the process makes at its
+	// first instruction. Empty leaves it to the server (bingo -initial-stop).
+	// Restart keeps it.
+	InitialStop InitialStop `json:"initialStop,omitempty"`
+}
+
+// InitialStop is what Launch does with the stop every launched process makes
+// at its first instruction, deep in runtime startup code.
+type InitialStop string
+
+const (
+	// InitialStopEntry reports that stop and leaves the process there, so
+	// breakpoints can be set before any of its code runs, init included.
+	InitialStopEntry InitialStop = "stop"
+	// InitialStopMain runs to the entry of main.main and reports that stop
+	// instead, as an ordinary Stepped.
+	InitialStopMain InitialStop = "continue-to-main"
+	// InitialStopRun reports no stop at all: the process runs until a
+	// breakpoint, a pause or its exit stops it.
+	InitialStopRun InitialStop = "run"
+)
+
+// Valid reports whether m is one of the InitialStop modes. The empty string
+// is not one.
+func (m InitialStop) Valid() bool {
+	switch m {
+	case InitialStopEntry, InitialStopMain, InitialStopRun:
+		return true
+	}
+	return false
+}
+
+// StopReason is why the process stopped, carried by the events that report a
+// stop: a client highlights a user breakpoint differently from where a step
+// or a pause left it, and need not guess from the event kind alone.
+type StopReason string
+
+const (
+	// StopReasonBreakpoint is a hit on a breakpoint the user set.
+	StopReasonBreakpoint StopReason = "breakpoint"
+	// StopReasonStep is a completed step, including a step-over or
+	// step-out that ran to a breakpoint of the engine's own.
+	StopReasonStep StopReason = "step"
+	// StopReasonPause is a halt requested by Pause.
+	StopReasonPause StopReason = "pause"
+	// StopReasonTemp is a temporary breakpoint the engine set for the user,
+	// not one in the breakpoint list: continue-to-main's breakpoint on
+	// main.main.
+	StopReasonTemp StopReason = "temp"
+	// StopReasonEntry is the initial stop after Launch or Attach.
+	StopReasonEntry StopReason = "entry"
+)
+
+// RunTimeoutPayload is the MaxRunTime the process exhausted.
+type RunTimeoutPayload struct {
+	MaxRunTime time.Duration `json:"maxRunTime"`
 }
 
 // AttachPayload asks the debugger to attach to PID. BinaryPath is optional but
@@ -138,12 +489,180 @@ type AttachPayload struct {
 type SetBreakpointPayload struct {
 	File string `json:"file"`
 	Line int    `json:"line"`
+	// Actions run in order each time the breakpoint fires (gdb's breakpoint
+	// commands): "print <local>" reports a variable of the innermost frame,
+	// and a final "continue" resumes without stopping, turning the
+	// breakpoint into a logpoint.
+	Actions []string `json:"actions,omitempty"`
+	// IgnoreCount skips the breakpoint's first IgnoreCount hits, resuming
+	// as if they never happened (gdb's ignore); see CmdSetIgnoreCount.
+	IgnoreCount int `json:"ignoreCount,omitempty"`
+	// GoroutineCount makes the breakpoint stop only on hits where the live
+	// goroutine count meets it. Hits it lets go by don't use up IgnoreCount;
+	// the condition is only checked once that has run out.
+	GoroutineCount *GoroutineCountCondition `json:"goroutineCount,omitempty"`
+}
+
+// SetBreakpointsPayload lists the breakpoints CmdSetBreakpoints sets.
+type SetBreakpointsPayload struct {
+	Breakpoints []SetBreakpointPayload `json:"breakpoints"`
 }
 
 type ClearBreakpointPayload struct {
 	ID int `json:"id"`
 }
 
+// SetIgnoreCountPayload sets breakpoint ID's ignore count to Count.
+type SetIgnoreCountPayload struct {
+	ID    int `json:"id"`
+	Count int `json:"count"`
+}
+
+// LogsPayloadCmd asks for at most Limit of the most recent log entries.
+// Zero returns everything still buffered.
+type LogsPayloadCmd struct {
+	Limit int `json:"limit,omitempty"`
+}
+
+// ResolveLinePayload names the source line to look up: File and Line, or,
+// when Function is set, Offset lines past Function's declaration (Return:
+// its last line with code) and File and Line are ignored.
+type ResolveLinePayload struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function,omitempty"`
+	Offset   int    `json:"offset,omitempty"`
+	Return   bool   `json:"return,omitempty"`
+}
+
+// GetFilePayload asks for the source file File from byte Offset on. Limit
+// caps the bytes returned; 0, or more than the server allows, means the
+// server's chunk size.
+type GetFilePayload struct {
+	File   string `json:"file"`
+	Offset int64  `json:"offset,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// FileContentsPayload is one chunk of a source file. Path is where the
+// server read File from. Data starts at Offset and ends on a line boundary
+// unless the file does; More says whether bytes remain after it, from
+// Offset+len(Data). Size is the whole file's.
+type FileContentsPayload struct {
+	File   string `json:"file"`
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+	Data   string `json:"data"`
+	Size   int64  `json:"size"`
+	More   bool   `json:"more,omitempty"`
+}
+
+// EchoPayload is the payload of both CmdEcho and the EventEcho answering it,
+// which carries Data back unchanged.
+type EchoPayload struct {
+	Data string `json:"data,omitempty"`
+}
+
+// SubscribePayload is the payload of both CmdSubscribe and the
+// EventSubscribed answering it: the event kinds the connection is sent,
+// none meaning all of them.
+type SubscribePayload struct {
+	Events []EventKind `json:"events,omitempty"`
+}
+
+// BreakpointActionsPayload carries one firing's action output, one line per
+// print action.
+type BreakpointActionsPayload struct {
+	Breakpoint Breakpoint `json:"breakpoint"`
+	Output     []string   `json:"output"`
+	Resumed    bool       `json:"resumed"`
+}
+
+// ContinuePayload is the optional payload of Continue. ScopeThread resumes
+// only the thread the session is stopped on and leaves the threads that
+// stopped with it (BreakpointHitPayload.OtherStops) stopped for inspection;
+// omitted or ScopeAll resumes them all.
+type ContinuePayload struct {
+	Scope ContinueScope `json:"scope,omitempty"`
+}
+
+type ContinueScope string
+
+const (
+	ScopeAll    ContinueScope = "all"
+	ScopeThread ContinueScope = "thread"
+)
+
+// StepPayload is the optional payload of StepOver and StepInto. Count > 1
+// runs that many steps and reports only the last stop; omitted or 1 is a
+// single step.
+type StepPayload struct {
+	Count int `json:"count,omitempty"`
+}
+
+// AddrToLinePayload names the runtime address to look up.
+type AddrToLinePayload struct {
+	PC uint64 `json:"pc"`
+}
+
+// ReadStringPayload is the address of the string header to read.
+type ReadStringPayload struct {
+	Addr uint64 `json:"addr"`
+}
+
+// StringValuePayload is the string whose header is at Addr. Len is its full
+// length; the server reads a bounded prefix, so Truncated reports that Value
+// holds fewer than Len bytes. Bytes that are not valid UTF-8 arrive as
+// U+FFFD, as JSON requires.
+type StringValuePayload struct {
+	Addr      uint64 `json:"addr"`
+	Len       uint64 `json:"len"`
+	Value     string `json:"value"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// InputPayload is text for the process's terminal, sent as-is: include the
+// newline to submit a line.
+type InputPayload struct {
+	Data string `json:"data"`
+}
+
+// ReadSlicePayload is the address of the slice header to read and the size
+// in bytes of one element (8 for []int on 64-bit, 16 for []string, ...).
+type ReadSlicePayload struct {
+	Addr     uint64 `json:"addr"`
+	ElemSize int    `json:"elemSize"`
+}
+
+// SliceValuePayload is the slice whose header is at Addr. Elements holds the
+// first min(Len, server cap) elements, each ElemSize bytes hex-encoded in
+// memory order (little-endian for integers); Truncated reports that there
+// are more. A nil slice has Data 0 and no elements.
+type SliceValuePayload struct {
+	Addr      uint64   `json:"addr"`
+	Data      uint64   `json:"data"`
+	Len       uint64   `json:"len"`
+	Cap       uint64   `json:"cap"`
+	ElemSize  int      `json:"elemSize"`
+	Elements  []string `json:"elements"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// ThreadPayload carries the OS thread ID of an EventThreadStarted or
+// EventThreadExited.
+type ThreadPayload struct {
+	TID int `json:"tid"`
+}
+
+// DebuggerReadyPayload identifies the process an EventDebuggerReady is about.
+// PGID is 0 when it couldn't be read; Program is empty for an Attach given no
+// binary.
+type DebuggerReadyPayload struct {
+	PID     int    `json:"pid"`
+	PGID    int    `json:"pgid,omitempty"`
+	Program string `json:"program,omitempty"`
+}
+
 // LocalsPayloadCmd asks for locals in a stack frame. FrameIndex 0 is innermost.
 type LocalsPayloadCmd struct {
 	FrameIndex int `json:"frameIndex"`
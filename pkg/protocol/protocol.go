@@ -2,18 +2,66 @@
 // between the bingo server and its clients over WebSocket.
 package protocol
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
+// Version is the message format version, "major.minor". Minor bumps only add
+// fields and kinds, which both sides ignore when unknown; a major bump breaks
+// the format. Clients send it as the v query parameter of /ws, and every
+// envelope carries it.
 const Version = "1.0"
 
+// VersionHeader carries the server's Version on a refused handshake, so a
+// client can tell a version mismatch from any other 400.
+const VersionHeader = "Bingo-Protocol-Version"
+
+// CompatibleVersion reports whether a peer speaking version v can talk to
+// this one: same major version. An empty v is a peer from before versions
+// were checked and is accepted.
+func CompatibleVersion(v string) bool {
+	if v == "" {
+		return true
+	}
+	major, _, _ := strings.Cut(v, ".")
+	ours, _, _ := strings.Cut(Version, ".")
+	return major == ours
+}
+
+// Subprotocol is the WebSocket subprotocol (Sec-WebSocket-Protocol) naming
+// this message format. A client that offers subprotocols must include it or
+// the server refuses the handshake, so an incompatible browser client fails
+// up front instead of mid-session. Clients that offer none are still served.
+const Subprotocol = "bingo.v1"
+
 // Event is the envelope for all server-to-client messages.
+//
+// Seq numbers the hub's broadcasts consecutively per session, so a jump means
+// events were lost; an event sent to one client only (the welcome) repeats
+// the previous seq.
+//
+// Time and Mono are stamped by the hub as the event leaves the server. Time is
+// wall clock, for display and trace export; Mono is the server's monotonic
+// clock (elapsed since server start), which never jumps and so orders events
+// precisely even across a wall-clock adjustment.
 type Event struct {
 	Version string          `json:"v"`
 	Kind    EventKind       `json:"kind"`
 	Seq     uint64          `json:"seq"`
+	Time    time.Time       `json:"time,omitzero"`
+	Mono    time.Duration   `json:"mono,omitempty"`
 	Payload json.RawMessage `json:"payload"`
 }
 
+// Stamp records now as e's send time, with Mono measured from epoch. Both
+// must carry a monotonic reading (i.e. come from time.Now).
+func (e *Event) Stamp(now, epoch time.Time) {
+	e.Time = now
+	e.Mono = now.Sub(epoch)
+}
+
 // Command is the envelope for all client-to-server messages.
 type Command struct {
 	Version string          `json:"v"`
@@ -45,20 +93,142 @@ const (
 	EventBreakpointCleared EventKind = "BreakpointCleared"
 	EventContinued         EventKind = "Continued"
 
+	// EventResumed announces a client's Continue or step once the debugger
+	// has taken it, saying where the suspended process resumed from and
+	// which command resumed it. A command the debugger rejects gets an
+	// EventError instead, with no EventResumed. It precedes every event the
+	// resume causes.
+	EventResumed EventKind = "Resumed"
+
+	// EventBreakpointsSet answers CmdSetBreakpoints with one result per
+	// requested breakpoint, in request order.
+	EventBreakpointsSet EventKind = "BreakpointsSet"
+
+	// EventBreakpointLost reports a breakpoint the engine could not re-arm
+	// after stepping the process off it, so it is no longer installed and
+	// will not fire again. Without it the breakpoint would vanish silently
+	// and execution would run past it.
+	EventBreakpointLost EventKind = "BreakpointLost"
+
+	// EventBreakpointError reports a breakpoint the server tried to set on
+	// its own at the initial stop (a -break default) and could not. It is
+	// distinct from EventError so it can't be taken for the reply to some
+	// client's in-flight CmdSetBreakpoint.
+	EventBreakpointError EventKind = "BreakpointError"
+
+	// EventConditionError reports that a breakpoint's condition could not
+	// be evaluated at a hit. The hit then stops, with the usual
+	// EventBreakpointHit after this event, rather than going by unnoticed
+	// every time; it is sent once per breakpoint, on its first failure.
+	EventConditionError EventKind = "ConditionError"
+
+	// EventBreakpointWarning accompanies a successful CmdSetBreakpoint whose
+	// line is inside a runtime function (scheduler, GC, allocator, runtime
+	// locks) where stopping a thread can deadlock the whole program. The
+	// breakpoint is set; the warning only says it is risky.
+	EventBreakpointWarning EventKind = "BreakpointWarning"
+
 	EventLocals     EventKind = "Locals"
 	EventFrames     EventKind = "Frames"
 	EventGoroutines EventKind = "Goroutines"
 
+	// EventArgs answers CmdInspectArgs.
+	EventArgs EventKind = "Args"
+
 	EventSessionState EventKind = "SessionState"
 
 	EventError EventKind = "Error"
 
+	// EventNotice tells clients a command was accepted but had nothing to do
+	// (e.g. Pause while already suspended). Informational, unlike EventError.
+	EventNotice EventKind = "Notice"
+
 	// EventRestarted confirms a completed Restart: the process was
 	// relaunched and previously-set breakpoints were reinstalled where
 	// possible. It is a confirmation, not a suspending event — the process's
 	// own suspend state is reported separately via the Stepped event emitted
 	// at the new process's entry point (same as after Launch).
 	EventRestarted EventKind = "Restarted"
+
+	// EventGoroutineSnapshot carries the state of every goroutine at the
+	// moment the target suspended. The hub emits it right after a suspending
+	// event when goroutine snapshots are enabled (they cost an extra
+	// goroutine enumeration per stop), so a UI can render the concurrency
+	// picture at each stop without issuing CmdGoroutines itself.
+	EventGoroutineSnapshot EventKind = "GoroutineSnapshot"
+
+	// EventGoroutineLeak warns that the goroutine count has only grown across
+	// the last several snapshots, by at least the configured threshold
+	// (bingo -leak-threshold). Informational; it never suspends anything.
+	EventGoroutineLeak EventKind = "GoroutineLeak"
+
+	// EventLogs answers CmdLogs with the session's recent log entries.
+	EventLogs EventKind = "Logs"
+
+	// EventLineResolved answers CmdResolveLine with the address a file:line
+	// maps to, and the line actually used when the requested one has no code.
+	EventLineResolved EventKind = "LineResolved"
+
+	// EventAddrResolved answers CmdAddrToLine with the source location of
+	// an address.
+	EventAddrResolved EventKind = "AddrResolved"
+
+	// EventBreakpointActions reports the output of a breakpoint's actions
+	// when it fires. Resumed says the actions ended in continue, in which
+	// case no EventBreakpointHit follows and the process keeps running.
+	EventBreakpointActions EventKind = "BreakpointActions"
+
+	// EventBreakpointsToggled answers CmdDisableAllBreakpoints and
+	// CmdEnableAllBreakpoints with every breakpoint's new Enabled flag.
+	EventBreakpointsToggled EventKind = "BreakpointsToggled"
+
+	// EventBreakpointsCleared answers CmdClearAllBreakpoints with the IDs
+	// it cleared.
+	EventBreakpointsCleared EventKind = "BreakpointsCleared"
+
+	// EventIgnoreCountSet answers CmdSetIgnoreCount with the breakpoint and
+	// its new ignore count.
+	EventIgnoreCountSet EventKind = "IgnoreCountSet"
+
+	// EventStringValue answers CmdReadString with the decoded string.
+	EventStringValue EventKind = "StringValue"
+
+	// EventSliceValue answers CmdReadSlice with the slice header and its
+	// leading elements.
+	EventSliceValue EventKind = "SliceValue"
+
+	// EventThreadStarted and EventThreadExited report tracee OS threads
+	// appearing and disappearing while the process runs. The engine only
+	// emits them with thread events enabled (bingo -thread-events); they
+	// never suspend the process.
+	EventThreadStarted EventKind = "ThreadStarted"
+	EventThreadExited  EventKind = "ThreadExited"
+
+	// EventCapabilities answers CmdCapabilities.
+	EventCapabilities EventKind = "Capabilities"
+
+	// EventRunTimeout reports that a launched process ran for its whole
+	// MaxRunTime. The hub pauses it straight after, so the suspending event
+	// that follows is the Pause's.
+	EventRunTimeout EventKind = "RunTimeout"
+
+	// EventStatus answers CmdStatus.
+	EventStatus EventKind = "Status"
+
+	// EventFileContents answers CmdGetFile with one chunk of a source file.
+	EventFileContents EventKind = "FileContents"
+
+	// EventEcho answers CmdEcho, to the client that sent it only.
+	EventEcho EventKind = "Echo"
+
+	// EventSubscribed answers CmdSubscribe, to the client that sent it only,
+	// with the event kinds it will be sent from now on.
+	EventSubscribed EventKind = "Subscribed"
+
+	// EventDebuggerReady reports that Launch or Attach has the process under
+	// control, with its debug info loaded, ahead of the first stop. Under
+	// InitialStopRun that stop may be a long way off, or never come.
+	EventDebuggerReady EventKind = "DebuggerReady"
 )
 
 type CommandKind string
@@ -74,11 +244,37 @@ const (
 	CmdSetBreakpoint   CommandKind = "SetBreakpoint"
 	CmdClearBreakpoint CommandKind = "ClearBreakpoint"
 
+	// CmdSetBreakpoints sets a batch of breakpoints in one round trip, for
+	// clients restoring a saved set over a slow link. One failing entry
+	// does not stop the rest.
+	CmdSetBreakpoints CommandKind = "SetBreakpoints"
+
+	// CmdDisableAllBreakpoints lets the process run free without losing
+	// breakpoint definitions: every trap is lifted but IDs, locations and
+	// actions are kept, and CmdEnableAllBreakpoints re-arms them all.
+	CmdDisableAllBreakpoints CommandKind = "DisableAllBreakpoints"
+	CmdEnableAllBreakpoints  CommandKind = "EnableAllBreakpoints"
+
+	// CmdClearAllBreakpoints clears every breakpoint at once, for a client
+	// starting over without tracking the IDs it set. With none set it
+	// succeeds, clearing nothing.
+	CmdClearAllBreakpoints CommandKind = "ClearAllBreakpoints"
+
+	// CmdSetIgnoreCount changes how many more hits an existing breakpoint
+	// lets go by before it stops, for a loop whose interesting iteration is
+	// the Nth. Each skipped hit resumes with no event; 0 stops on the next.
+	CmdSetIgnoreCount CommandKind = "SetIgnoreCount"
+
 	CmdContinue CommandKind = "Continue"
 	CmdStepOver CommandKind = "StepOver"
 	CmdStepInto CommandKind = "StepInto"
 	CmdStepOut  CommandKind = "StepOut"
 
+	// CmdStepInstruction executes exactly one machine instruction on the
+	// current thread, whatever line it belongs to. StepOver stays
+	// line-level; this is for stepping through assembly.
+	CmdStepInstruction CommandKind = "StepInstruction"
+
 	// CmdPause asynchronously interrupts a running tracee, forcing it to
 	// suspend (reported via EventPaused). Unlike the resuming commands it is
 	// issued while the process is RUNNING, so it is not a member of the hub's
@@ -89,9 +285,79 @@ const (
 	CmdFrames     CommandKind = "Frames"
 	CmdGoroutines CommandKind = "Goroutines"
 
+	// CmdInspectArgs asks for the arguments of the function the process is
+	// stopped in: a narrower Locals, for function-entry breakpoints.
+	CmdInspectArgs CommandKind = "InspectArgs"
+
 	// CmdRestart kills the current process (if any) and relaunches the last
 	// Launch'd binary, reinstalling previously-set breakpoints. Only
 	// supported for managed sessions started via Launch — see AGENTS.md →
 	// Restart.
 	CmdRestart CommandKind = "Restart"
+
+	// CmdLogs fetches the session's recent log entries (breakpoint history,
+	// commands, errors) from the hub's bounded per-session buffer. Answered
+	// by the hub itself, so it works with no process launched.
+	CmdLogs CommandKind = "Logs"
+
+	// CmdResolveLine looks up the PC a file:line maps to without setting a
+	// breakpoint, for showing exact addresses and for diagnosing why a
+	// breakpoint landed on a different line than asked. Answered by
+	// EventLineResolved.
+	CmdResolveLine CommandKind = "ResolveLine"
+
+	// CmdAddrToLine is the reverse of CmdResolveLine: it maps an address
+	// (from a stack trace, crash report or register) back to file, line and
+	// function. Answered by EventAddrResolved.
+	CmdAddrToLine CommandKind = "AddrToLine"
+
+	// CmdReadString reads the Go string whose header (data pointer, length)
+	// is at an address, e.g. one taken from Locals. Answered by
+	// EventStringValue.
+	CmdReadString CommandKind = "ReadString"
+
+	// CmdReadSlice reads the Go slice whose header (data pointer, len, cap)
+	// is at an address, returning elements as raw bytes of a caller-given
+	// size. Answered by EventSliceValue.
+	CmdReadSlice CommandKind = "ReadSlice"
+
+	// CmdInput types text at the terminal of a process launched on a PTY
+	// (bingo -pty). Fire-and-forget: what the process prints back arrives as
+	// EventOutput, and a process without a terminal answers EventError.
+	CmdInput CommandKind = "Input"
+
+	// CmdCapabilities asks which commands the server supports and which of
+	// them the session's current state allows, so a UI can enable controls
+	// without hard-coding the state machine. Answered by the hub itself with
+	// EventCapabilities.
+	CmdCapabilities CommandKind = "Capabilities"
+
+	// CmdStatus asks for the debugger's own view of the process, read from
+	// the engine at that moment rather than inferred from past events.
+	// Answered with EventStatus in any state.
+	CmdStatus CommandKind = "Status"
+
+	// CmdGetFile fetches a source file of the debugged binary, named as for
+	// CmdSetBreakpoint, so a UI can show it whole. Large files come in
+	// chunks: each EventFileContents says whether there is More, and the
+	// next request starts at the Offset after it.
+	CmdGetFile CommandKind = "GetFile"
+
+	// CmdEcho asks the hub to send its payload straight back as EventEcho,
+	// whose envelope Time is when the server answered. It touches neither
+	// the process nor the session, so it can measure round-trip latency and
+	// check that the session still answers. It is a test of the session
+	// loop, unlike a WebSocket ping, which only tests the connection. Valid
+	// in any state.
+	CmdEcho CommandKind = "Echo"
+
+	// CmdSubscribe limits the events the hub broadcasts to the sending
+	// connection to the kinds listed; an empty list restores all of them.
+	// EventError always gets through, and so does what the hub sends one
+	// client alone (the welcome state, EventEcho, EventSubscribed). The
+	// replies to other commands are broadcasts, so a filtered client only
+	// sees those it subscribed to. Event.Seq still counts every broadcast,
+	// so a filtered client sees gaps that are not losses. Valid in any
+	// state.
+	CmdSubscribe CommandKind = "Subscribe"
 )
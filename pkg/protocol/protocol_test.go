@@ -2,7 +2,9 @@ package protocol_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -136,6 +138,40 @@ var _ = Describe("Event", func() {
 					Expect(p.Breakpoint.Location.File).To(Equal("main.go"))
 					Expect(p.Goroutine.Status).To(Equal("waiting"))
 					Expect(p.Frames).To(HaveLen(2))
+					Expect(p.Registers).To(BeNil())
+				},
+			),
+
+			Entry("BreakpointHit with registers",
+				protocol.EventBreakpointHit,
+				protocol.BreakpointHitPayload{
+					Breakpoint: sampleBreakpoint,
+					Registers:  &protocol.Registers{PC: 0x4a1f20, SP: 0xc000050f00, BP: 0xc000050f40},
+				},
+				func(e protocol.Event) {
+					var p protocol.BreakpointHitPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Registers).To(Equal(&protocol.Registers{PC: 0x4a1f20, SP: 0xc000050f00, BP: 0xc000050f40}))
+				},
+			),
+
+			Entry("BreakpointHit with other stopped threads",
+				protocol.EventBreakpointHit,
+				protocol.BreakpointHitPayload{
+					Breakpoint: sampleBreakpoint,
+					OtherStops: []protocol.ThreadStop{
+						{TID: 1002, Location: protocol.Location{File: "worker.go", Line: 7}, Breakpoint: 1},
+						{TID: 1003, Signal: 11},
+					},
+					StoppedThreads: []protocol.ThreadStop{{TID: 1001, Breakpoint: 1}, {TID: 1002, Breakpoint: 1}},
+				},
+				func(e protocol.Event) {
+					var p protocol.BreakpointHitPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.OtherStops).To(HaveLen(2))
+					Expect(p.OtherStops[0].Location.File).To(Equal("worker.go"))
+					Expect(p.OtherStops[1].Signal).To(Equal(11))
+					Expect(p.StoppedThreads).To(HaveLen(2))
 				},
 			),
 
@@ -197,6 +233,34 @@ var _ = Describe("Event", func() {
 				},
 			),
 
+			Entry("BreakpointSet already set",
+				protocol.EventBreakpointSet,
+				protocol.BreakpointSetPayload{Breakpoint: sampleBreakpoint, AlreadySet: true},
+				func(e protocol.Event) {
+					var p protocol.BreakpointSetPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.AlreadySet).To(BeTrue())
+					Expect(p.Breakpoint.ID).To(Equal(1))
+				},
+			),
+
+			Entry("BreakpointsSet",
+				protocol.EventBreakpointsSet,
+				protocol.BreakpointsSetPayload{Results: []protocol.BreakpointResult{
+					{File: "main.go", Line: 42, Breakpoint: &sampleBreakpoint},
+					{File: "ghost.go", Line: 1, Error: "no code at ghost.go:1"},
+				}},
+				func(e protocol.Event) {
+					var p protocol.BreakpointsSetPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Results).To(HaveLen(2))
+					Expect(p.Results[0].Breakpoint).NotTo(BeNil())
+					Expect(p.Results[0].Breakpoint.ID).To(Equal(1))
+					Expect(p.Results[1].Breakpoint).To(BeNil())
+					Expect(p.Results[1].Error).To(Equal("no code at ghost.go:1"))
+				},
+			),
+
 			Entry("BreakpointCleared",
 				protocol.EventBreakpointCleared,
 				protocol.BreakpointClearedPayload{ID: 3},
@@ -207,18 +271,35 @@ var _ = Describe("Event", func() {
 				},
 			),
 
+			Entry("BreakpointLost",
+				protocol.EventBreakpointLost,
+				protocol.BreakpointLostPayload{
+					Breakpoint: protocol.Breakpoint{ID: 4, Location: protocol.Location{File: "main.go", Line: 9}},
+					Message:    "reinstall breakpoint 0x1000: EFAULT",
+				},
+				func(e protocol.Event) {
+					var p protocol.BreakpointLostPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Breakpoint.ID).To(Equal(4))
+					Expect(p.Breakpoint.Location.Line).To(Equal(9))
+					Expect(p.Message).To(ContainSubstring("EFAULT"))
+				},
+			),
+
 			Entry("Stepped",
 				protocol.EventStepped,
 				protocol.SteppedPayload{
 					Goroutine: sampleGoroutine,
 					Location:  sampleLocation,
 					Frames:    sampleFrames,
+					Reason:    protocol.StopReasonTemp,
 				},
 				func(e protocol.Event) {
 					var p protocol.SteppedPayload
 					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
 					Expect(p.Location.Line).To(Equal(42))
 					Expect(p.Frames).To(HaveLen(2))
+					Expect(p.Reason).To(Equal(protocol.StopReasonTemp))
 				},
 			),
 
@@ -259,6 +340,17 @@ var _ = Describe("Event", func() {
 				},
 			),
 
+			Entry("Args",
+				protocol.EventArgs,
+				protocol.ArgsPayload{Function: "main.gamma", Args: sampleVariables},
+				func(e protocol.Event) {
+					var p protocol.ArgsPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Function).To(Equal("main.gamma"))
+					Expect(p.Args).To(HaveLen(2))
+				},
+			),
+
 			Entry("Frames",
 				protocol.EventFrames,
 				protocol.FramesPayload{Frames: sampleFrames},
@@ -292,6 +384,17 @@ var _ = Describe("Event", func() {
 				},
 			),
 
+			Entry("Notice",
+				protocol.EventNotice,
+				protocol.NoticePayload{Command: protocol.CmdPause, Message: "process is already suspended"},
+				func(e protocol.Event) {
+					var p protocol.NoticePayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Command).To(Equal(protocol.CmdPause))
+					Expect(p.Message).To(Equal("process is already suspended"))
+				},
+			),
+
 			Entry("Error with CmdNone omits command field on wire",
 				protocol.EventError,
 				protocol.ErrorPayload{Command: protocol.CmdNone, Message: "backend failure"},
@@ -325,6 +428,323 @@ var _ = Describe("Event", func() {
 					Expect(p.Discarded[0].Reason).To(Equal("no such file"))
 				},
 			),
+
+			Entry("GoroutineSnapshot",
+				protocol.EventGoroutineSnapshot,
+				protocol.GoroutineSnapshotPayload{
+					Trigger:    protocol.EventBreakpointHit,
+					Goroutines: []protocol.Goroutine{sampleGoroutine},
+				},
+				func(e protocol.Event) {
+					var p protocol.GoroutineSnapshotPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Trigger).To(Equal(protocol.EventBreakpointHit))
+					Expect(p.Goroutines).To(ConsistOf(sampleGoroutine))
+				},
+			),
+
+			Entry("Logs",
+				protocol.EventLogs,
+				protocol.LogsPayload{Entries: []protocol.LogEntry{
+					{Level: "INFO", Message: "client connected", Attrs: map[string]string{"total": "1"}},
+				}},
+				func(e protocol.Event) {
+					var p protocol.LogsPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Entries).To(HaveLen(1))
+					Expect(p.Entries[0].Attrs).To(HaveKeyWithValue("total", "1"))
+				},
+			),
+
+			Entry("LineResolved",
+				protocol.EventLineResolved,
+				protocol.LineResolvedPayload{
+					File: "main.go", Line: 10, PC: 0x401000,
+					Location: protocol.Location{File: "main.go", Line: 12, Function: "main.main"},
+				},
+				func(e protocol.Event) {
+					var p protocol.LineResolvedPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.PC).To(Equal(uint64(0x401000)))
+					Expect(p.Line).To(Equal(10))
+					Expect(p.Location.Line).To(Equal(12))
+				},
+			),
+
+			Entry("AddrResolved",
+				protocol.EventAddrResolved,
+				protocol.AddrResolvedPayload{
+					PC:       0x401000,
+					Location: protocol.Location{File: "main.go", Line: 12, Function: "main.main"},
+				},
+				func(e protocol.Event) {
+					var p protocol.AddrResolvedPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.PC).To(Equal(uint64(0x401000)))
+					Expect(p.Location.Function).To(Equal("main.main"))
+				},
+			),
+
+			Entry("BreakpointActions",
+				protocol.EventBreakpointActions,
+				protocol.BreakpointActionsPayload{
+					Breakpoint: protocol.Breakpoint{ID: 3, Actions: []string{"print x", "continue"}},
+					Output:     []string{"x = 42"},
+					Resumed:    true,
+				},
+				func(e protocol.Event) {
+					var p protocol.BreakpointActionsPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Breakpoint.Actions).To(Equal([]string{"print x", "continue"}))
+					Expect(p.Output).To(Equal([]string{"x = 42"}))
+					Expect(p.Resumed).To(BeTrue())
+				},
+			),
+
+			Entry("BreakpointsToggled",
+				protocol.EventBreakpointsToggled,
+				protocol.BreakpointsToggledPayload{
+					Enabled:     false,
+					Breakpoints: []protocol.Breakpoint{{ID: 1}, {ID: 2}},
+				},
+				func(e protocol.Event) {
+					var p protocol.BreakpointsToggledPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Enabled).To(BeFalse())
+					Expect(p.Breakpoints).To(HaveLen(2))
+					Expect(p.Breakpoints[1].Enabled).To(BeFalse())
+				},
+			),
+
+			Entry("StringValue",
+				protocol.EventStringValue,
+				protocol.StringValuePayload{Addr: 0xc000010000, Len: 5, Value: "hello"},
+				func(e protocol.Event) {
+					var p protocol.StringValuePayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Addr).To(Equal(uint64(0xc000010000)))
+					Expect(p.Len).To(Equal(uint64(5)))
+					Expect(p.Value).To(Equal("hello"))
+					Expect(p.Truncated).To(BeFalse())
+				},
+			),
+
+			Entry("SliceValue",
+				protocol.EventSliceValue,
+				protocol.SliceValuePayload{
+					Addr: 0xc000010000, Data: 0xc000020000, Len: 3, Cap: 8, ElemSize: 8,
+					Elements: []string{"0100000000000000"}, Truncated: true,
+				},
+				func(e protocol.Event) {
+					var p protocol.SliceValuePayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Cap).To(Equal(uint64(8)))
+					Expect(p.Elements).To(Equal([]string{"0100000000000000"}))
+					Expect(p.Truncated).To(BeTrue())
+				},
+			),
+
+			Entry("GoroutineLeak",
+				protocol.EventGoroutineLeak,
+				protocol.GoroutineLeakPayload{Counts: []int{4, 9, 15}, Growth: 11},
+				func(e protocol.Event) {
+					var p protocol.GoroutineLeakPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Counts).To(Equal([]int{4, 9, 15}))
+					Expect(p.Growth).To(Equal(11))
+				},
+			),
+
+			Entry("BreakpointsCleared",
+				protocol.EventBreakpointsCleared,
+				protocol.BreakpointsClearedPayload{IDs: []int{1, 4}, Count: 2},
+				func(e protocol.Event) {
+					var p protocol.BreakpointsClearedPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.IDs).To(Equal([]int{1, 4}))
+					Expect(p.Count).To(Equal(2))
+				},
+			),
+
+			Entry("IgnoreCountSet",
+				protocol.EventIgnoreCountSet,
+				protocol.IgnoreCountSetPayload{Breakpoint: protocol.Breakpoint{
+					ID: 3, Location: protocol.Location{File: "loop.go", Line: 12}, Enabled: true, IgnoreCount: 9,
+				}},
+				func(e protocol.Event) {
+					var p protocol.IgnoreCountSetPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Breakpoint.ID).To(Equal(3))
+					Expect(p.Breakpoint.IgnoreCount).To(Equal(9))
+				},
+			),
+
+			Entry("ConditionError",
+				protocol.EventConditionError,
+				protocol.ConditionErrorPayload{
+					Breakpoint: protocol.Breakpoint{ID: 2, GoroutineCount: &protocol.GoroutineCountCondition{Op: ">", Count: 1000}},
+					Message:    "goroutines > 1000: count goroutines: no DWARF info",
+				},
+				func(e protocol.Event) {
+					var p protocol.ConditionErrorPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Breakpoint.ID).To(Equal(2))
+					Expect(p.Breakpoint.GoroutineCount.Count).To(Equal(1000))
+					Expect(p.Message).To(ContainSubstring("no DWARF info"))
+				},
+			),
+
+			Entry("Resumed",
+				protocol.EventResumed,
+				protocol.ResumedPayload{
+					Command:  protocol.CmdStepOver,
+					PC:       0x401000,
+					Location: &protocol.Location{File: "main.go", Line: 12, Function: "main.main"},
+				},
+				func(e protocol.Event) {
+					var p protocol.ResumedPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Command).To(Equal(protocol.CmdStepOver))
+					Expect(p.PC).To(Equal(uint64(0x401000)))
+					Expect(p.Location).To(Equal(&protocol.Location{File: "main.go", Line: 12, Function: "main.main"}))
+				},
+			),
+
+			Entry("DebuggerReady",
+				protocol.EventDebuggerReady,
+				protocol.DebuggerReadyPayload{PID: 4242, PGID: 4242, Program: "/tmp/myapp"},
+				func(e protocol.Event) {
+					var p protocol.DebuggerReadyPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p).To(Equal(protocol.DebuggerReadyPayload{PID: 4242, PGID: 4242, Program: "/tmp/myapp"}))
+				},
+			),
+
+			Entry("ThreadStarted",
+				protocol.EventThreadStarted,
+				protocol.ThreadPayload{TID: 4242},
+				func(e protocol.Event) {
+					var p protocol.ThreadPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.TID).To(Equal(4242))
+				},
+			),
+
+			Entry("BreakpointError",
+				protocol.EventBreakpointError,
+				protocol.BreakpointErrorPayload{
+					Location: protocol.Location{File: "main.go", Line: 99},
+					Message:  "no code at main.go:99",
+				},
+				func(e protocol.Event) {
+					var p protocol.BreakpointErrorPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Location.Line).To(Equal(99))
+					Expect(p.Message).To(ContainSubstring("no code"))
+				},
+			),
+
+			Entry("BreakpointWarning",
+				protocol.EventBreakpointWarning,
+				protocol.BreakpointWarningPayload{
+					Breakpoint: protocol.Breakpoint{ID: 4, Location: protocol.Location{File: "malloc.go", Line: 1010}, Enabled: true},
+					Function:   "runtime.mallocgc",
+					Message:    "runtime.mallocgc is part of the runtime memory allocator",
+				},
+				func(e protocol.Event) {
+					var p protocol.BreakpointWarningPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Breakpoint.ID).To(Equal(4))
+					Expect(p.Function).To(Equal("runtime.mallocgc"))
+					Expect(p.Message).To(ContainSubstring("allocator"))
+				},
+			),
+
+			Entry("Capabilities",
+				protocol.EventCapabilities,
+				protocol.CapabilitiesPayload{
+					State:     protocol.StateSuspended,
+					Supported: []protocol.CommandKind{protocol.CmdLaunch, protocol.CmdContinue},
+					Valid:     []protocol.CommandKind{protocol.CmdContinue},
+				},
+				func(e protocol.Event) {
+					var p protocol.CapabilitiesPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.State).To(Equal(protocol.StateSuspended))
+					Expect(p.Valid).To(Equal([]protocol.CommandKind{protocol.CmdContinue}))
+				},
+			),
+
+			Entry("Status",
+				protocol.EventStatus,
+				protocol.StatusPayload{
+					Session:      protocol.StateRunning,
+					Process:      protocol.StateSuspended,
+					PC:           0x401000,
+					TID:          7,
+					CreatedAt:    time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+					LastActivity: time.Date(2026, 3, 1, 9, 45, 30, 0, time.UTC),
+				},
+				func(e protocol.Event) {
+					var p protocol.StatusPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Process).To(Equal(protocol.StateSuspended))
+					Expect(p.PC).To(Equal(uint64(0x401000)))
+					Expect(p.Pending).To(BeFalse())
+					Expect(p.CreatedAt).To(Equal(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)))
+					Expect(p.LastActivity.Sub(p.CreatedAt)).To(Equal(45*time.Minute + 30*time.Second))
+				},
+			),
+
+			Entry("Echo",
+				protocol.EventEcho,
+				protocol.EchoPayload{Data: "probe-1"},
+				func(e protocol.Event) {
+					var p protocol.EchoPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Data).To(Equal("probe-1"))
+				},
+			),
+
+			Entry("Subscribed",
+				protocol.EventSubscribed,
+				protocol.SubscribePayload{Events: []protocol.EventKind{protocol.EventGoroutines}},
+				func(e protocol.Event) {
+					var p protocol.SubscribePayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Events).To(Equal([]protocol.EventKind{protocol.EventGoroutines}))
+				},
+			),
+
+			Entry("FileContents",
+				protocol.EventFileContents,
+				protocol.FileContentsPayload{
+					File:   "main.go",
+					Path:   "/src/app/main.go",
+					Offset: 4096,
+					Data:   "func main() {}\n",
+					Size:   8192,
+					More:   true,
+				},
+				func(e protocol.Event) {
+					var p protocol.FileContentsPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.Path).To(Equal("/src/app/main.go"))
+					Expect(p.Offset).To(Equal(int64(4096)))
+					Expect(p.Data).To(Equal("func main() {}\n"))
+					Expect(p.More).To(BeTrue())
+				},
+			),
+
+			Entry("RunTimeout",
+				protocol.EventRunTimeout,
+				protocol.RunTimeoutPayload{MaxRunTime: 90 * time.Second},
+				func(e protocol.Event) {
+					var p protocol.RunTimeoutPayload
+					Expect(protocol.DecodeEventPayload(e, &p)).To(Succeed())
+					Expect(p.MaxRunTime).To(Equal(90 * time.Second))
+				},
+			),
 		)
 	})
 
@@ -387,12 +807,17 @@ var _ = Describe("Command", func() {
 
 			Entry("Launch",
 				protocol.CmdLaunch,
-				protocol.LaunchPayload{Program: "/tmp/myapp", Args: []string{"--verbose"}},
+				protocol.LaunchPayload{
+					Program: "/tmp/myapp", Args: []string{"--verbose"}, MaxRunTime: time.Minute,
+					InitialStop: protocol.InitialStopMain,
+				},
 				func(c protocol.Command) {
 					var p protocol.LaunchPayload
 					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
 					Expect(p.Program).To(Equal("/tmp/myapp"))
 					Expect(p.Args).To(ConsistOf("--verbose"))
+					Expect(p.MaxRunTime).To(Equal(time.Minute))
+					Expect(p.InitialStop).To(Equal(protocol.InitialStopMain))
 				},
 			),
 
@@ -425,6 +850,53 @@ var _ = Describe("Command", func() {
 				},
 			),
 
+			Entry("SetBreakpoint with actions",
+				protocol.CmdSetBreakpoint,
+				protocol.SetBreakpointPayload{File: "server.go", Line: 100, Actions: []string{"print req", "continue"}},
+				func(c protocol.Command) {
+					var p protocol.SetBreakpointPayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.Actions).To(Equal([]string{"print req", "continue"}))
+				},
+			),
+
+			Entry("SetBreakpoint with a goroutine count condition",
+				protocol.CmdSetBreakpoint,
+				protocol.SetBreakpointPayload{File: "server.go", Line: 100,
+					GoroutineCount: &protocol.GoroutineCountCondition{Op: ">", Count: 1000}},
+				func(c protocol.Command) {
+					var p protocol.SetBreakpointPayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.GoroutineCount).To(Equal(&protocol.GoroutineCountCondition{Op: ">", Count: 1000}))
+				},
+			),
+
+			Entry("SetBreakpoints",
+				protocol.CmdSetBreakpoints,
+				protocol.SetBreakpointsPayload{Breakpoints: []protocol.SetBreakpointPayload{
+					{File: "server.go", Line: 100},
+					{File: "handler.go", Line: 7, Actions: []string{"continue"}},
+				}},
+				func(c protocol.Command) {
+					var p protocol.SetBreakpointsPayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.Breakpoints).To(HaveLen(2))
+					Expect(p.Breakpoints[1].File).To(Equal("handler.go"))
+					Expect(p.Breakpoints[1].Actions).To(Equal([]string{"continue"}))
+				},
+			),
+
+			Entry("SetIgnoreCount",
+				protocol.CmdSetIgnoreCount,
+				protocol.SetIgnoreCountPayload{ID: 3, Count: 9},
+				func(c protocol.Command) {
+					var p protocol.SetIgnoreCountPayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.ID).To(Equal(3))
+					Expect(p.Count).To(Equal(9))
+				},
+			),
+
 			Entry("ClearBreakpoint",
 				protocol.CmdClearBreakpoint,
 				protocol.ClearBreakpointPayload{ID: 7},
@@ -451,6 +923,16 @@ var _ = Describe("Command", func() {
 				},
 			),
 
+			Entry("StepOver with a count",
+				protocol.CmdStepOver,
+				protocol.StepPayload{Count: 5},
+				func(c protocol.Command) {
+					var p protocol.StepPayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.Count).To(Equal(5))
+				},
+			),
+
 			Entry("StepInto",
 				protocol.CmdStepInto,
 				json.RawMessage(`{}`),
@@ -501,6 +983,16 @@ var _ = Describe("Command", func() {
 				},
 			),
 
+			Entry("Logs",
+				protocol.CmdLogs,
+				protocol.LogsPayloadCmd{Limit: 20},
+				func(c protocol.Command) {
+					var p protocol.LogsPayloadCmd
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.Limit).To(Equal(20))
+				},
+			),
+
 			Entry("Restart",
 				protocol.CmdRestart,
 				protocol.RestartPayload{Args: []string{"--verbose"}},
@@ -510,6 +1002,115 @@ var _ = Describe("Command", func() {
 					Expect(p.Args).To(ConsistOf("--verbose"))
 				},
 			),
+
+			Entry("ResolveLine",
+				protocol.CmdResolveLine,
+				protocol.ResolveLinePayload{File: "main.go", Line: 10},
+				func(c protocol.Command) {
+					var p protocol.ResolveLinePayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.File).To(Equal("main.go"))
+					Expect(p.Line).To(Equal(10))
+				},
+			),
+
+			Entry("ResolveLine relative to a function",
+				protocol.CmdResolveLine,
+				protocol.ResolveLinePayload{Function: "main.worker", Offset: 3, Return: true},
+				func(c protocol.Command) {
+					var p protocol.ResolveLinePayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.Function).To(Equal("main.worker"))
+					Expect(p.Offset).To(Equal(3))
+					Expect(p.Return).To(BeTrue())
+				},
+			),
+
+			Entry("GetFile",
+				protocol.CmdGetFile,
+				protocol.GetFilePayload{File: "main.go", Offset: 4096, Limit: 1024},
+				func(c protocol.Command) {
+					var p protocol.GetFilePayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.File).To(Equal("main.go"))
+					Expect(p.Offset).To(Equal(int64(4096)))
+					Expect(p.Limit).To(Equal(1024))
+				},
+			),
+
+			Entry("Echo",
+				protocol.CmdEcho,
+				protocol.EchoPayload{Data: "probe-1"},
+				func(c protocol.Command) {
+					var p protocol.EchoPayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.Data).To(Equal("probe-1"))
+				},
+			),
+
+			Entry("Subscribe",
+				protocol.CmdSubscribe,
+				protocol.SubscribePayload{Events: []protocol.EventKind{protocol.EventOutput, protocol.EventGoroutineSnapshot}},
+				func(c protocol.Command) {
+					var p protocol.SubscribePayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.Events).To(Equal([]protocol.EventKind{protocol.EventOutput, protocol.EventGoroutineSnapshot}))
+				},
+			),
+
+			Entry("AddrToLine",
+				protocol.CmdAddrToLine,
+				protocol.AddrToLinePayload{PC: 0x401000},
+				func(c protocol.Command) {
+					var p protocol.AddrToLinePayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.PC).To(Equal(uint64(0x401000)))
+				},
+			),
+
+			Entry("ClearAllBreakpoints",
+				protocol.CmdClearAllBreakpoints,
+				json.RawMessage(`{}`),
+				func(c protocol.Command) {
+					Expect(c.Kind).To(Equal(protocol.CmdClearAllBreakpoints))
+				},
+			),
+
+			Entry("DisableAllBreakpoints",
+				protocol.CmdDisableAllBreakpoints,
+				json.RawMessage(`{}`),
+				func(c protocol.Command) {
+					Expect(c.Kind).To(Equal(protocol.CmdDisableAllBreakpoints))
+				},
+			),
+
+			Entry("EnableAllBreakpoints",
+				protocol.CmdEnableAllBreakpoints,
+				json.RawMessage(`{}`),
+				func(c protocol.Command) {
+					Expect(c.Kind).To(Equal(protocol.CmdEnableAllBreakpoints))
+				},
+			),
+
+			Entry("ReadString",
+				protocol.CmdReadString,
+				protocol.ReadStringPayload{Addr: 0xc000010000},
+				func(c protocol.Command) {
+					var p protocol.ReadStringPayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.Addr).To(Equal(uint64(0xc000010000)))
+				},
+			),
+
+			Entry("ReadSlice",
+				protocol.CmdReadSlice,
+				protocol.ReadSlicePayload{Addr: 0xc000010000, ElemSize: 16},
+				func(c protocol.Command) {
+					var p protocol.ReadSlicePayload
+					Expect(protocol.DecodeCommandPayload(c, &p)).To(Succeed())
+					Expect(p.ElemSize).To(Equal(16))
+				},
+			),
 		)
 	})
 
@@ -523,6 +1124,43 @@ var _ = Describe("Command", func() {
 			var p protocol.SetBreakpointPayload
 			Expect(protocol.DecodeCommandPayload(cmd, &p)).To(HaveOccurred())
 		})
+
+		It("leaves the zero value for a command sent without a payload", func() {
+			cmd, err := protocol.UnmarshalCommand([]byte(`{"v":"` + protocol.Version + `","kind":"Continue"}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmd.Payload).To(BeEmpty())
+
+			p := protocol.ContinuePayload{Scope: protocol.ScopeThread}
+			Expect(protocol.DecodeCommandPayload(cmd, &p)).To(Succeed())
+			Expect(p.Scope).To(Equal(protocol.ScopeThread), "an absent payload must not overwrite dst")
+
+			var step protocol.StepPayload
+			Expect(protocol.DecodeCommandPayload(protocol.Command{Kind: protocol.CmdStepOver}, &step)).To(Succeed())
+			Expect(step).To(BeZero())
+		})
+
+		It("treats a null payload like an absent one", func() {
+			cmd := protocol.Command{Kind: protocol.CmdLocals, Payload: json.RawMessage(`null`)}
+			var p protocol.LocalsPayloadCmd
+			Expect(protocol.DecodeCommandPayload(cmd, &p)).To(Succeed())
+			Expect(p).To(BeZero())
+
+			var launch protocol.LaunchPayload
+			cmd = protocol.Command{Kind: protocol.CmdLaunch, Payload: json.RawMessage(`null`)}
+			Expect(protocol.DecodeCommandPayload(cmd, &launch)).To(MatchError(ContainSubstring("missing payload")))
+		})
+
+		DescribeTable("still requires the payload of a command that needs one",
+			func(kind protocol.CommandKind, dst any) {
+				err := protocol.DecodeCommandPayload(protocol.Command{Kind: kind}, dst)
+				Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("DecodeCommandPayload(%s): missing payload", kind))))
+			},
+			Entry("Launch", protocol.CmdLaunch, &protocol.LaunchPayload{}),
+			Entry("Attach", protocol.CmdAttach, &protocol.AttachPayload{}),
+			Entry("SetBreakpoint", protocol.CmdSetBreakpoint, &protocol.SetBreakpointPayload{}),
+			Entry("ReadString", protocol.CmdReadString, &protocol.ReadStringPayload{}),
+			Entry("ReadSlice", protocol.CmdReadSlice, &protocol.ReadSlicePayload{}),
+		)
 	})
 
 	// Regression for issue #102. The hub's handleRestart distinguishes a nil
@@ -600,14 +1238,42 @@ var _ = Describe("Kind constants", func() {
 			protocol.EventProcessExited,
 			protocol.EventBreakpointSet,
 			protocol.EventBreakpointCleared,
+			protocol.EventBreakpointLost,
 			protocol.EventStepped,
 			protocol.EventContinued,
+			protocol.EventResumed,
 			protocol.EventLocals,
+			protocol.EventArgs,
 			protocol.EventFrames,
 			protocol.EventGoroutines,
 			protocol.EventError,
+			protocol.EventNotice,
 			protocol.EventRestarted,
 			protocol.EventPaused,
+			protocol.EventGoroutineSnapshot,
+			protocol.EventGoroutineLeak,
+			protocol.EventLogs,
+			protocol.EventLineResolved,
+			protocol.EventAddrResolved,
+			protocol.EventBreakpointActions,
+			protocol.EventBreakpointsToggled,
+			protocol.EventStringValue,
+			protocol.EventSliceValue,
+			protocol.EventThreadStarted,
+			protocol.EventThreadExited,
+			protocol.EventBreakpointError,
+			protocol.EventCapabilities,
+			protocol.EventRunTimeout,
+			protocol.EventStatus,
+			protocol.EventFileContents,
+			protocol.EventBreakpointsSet,
+			protocol.EventEcho,
+			protocol.EventSubscribed,
+			protocol.EventDebuggerReady,
+			protocol.EventBreakpointsCleared,
+			protocol.EventBreakpointWarning,
+			protocol.EventConditionError,
+			protocol.EventIgnoreCountSet,
 		}
 		for _, k := range kinds {
 			Expect(string(k)).NotTo(BeEmpty(), "EventKind %q should not be empty", k)
@@ -621,15 +1287,33 @@ var _ = Describe("Kind constants", func() {
 			protocol.CmdKill,
 			protocol.CmdSetBreakpoint,
 			protocol.CmdClearBreakpoint,
+			protocol.CmdClearAllBreakpoints,
+			protocol.CmdDisableAllBreakpoints,
+			protocol.CmdEnableAllBreakpoints,
 			protocol.CmdContinue,
 			protocol.CmdStepOver,
 			protocol.CmdStepInto,
 			protocol.CmdStepOut,
 			protocol.CmdLocals,
+			protocol.CmdInspectArgs,
 			protocol.CmdFrames,
 			protocol.CmdGoroutines,
 			protocol.CmdRestart,
 			protocol.CmdPause,
+			protocol.CmdLogs,
+			protocol.CmdResolveLine,
+			protocol.CmdAddrToLine,
+			protocol.CmdReadString,
+			protocol.CmdReadSlice,
+			protocol.CmdInput,
+			protocol.CmdStepInstruction,
+			protocol.CmdCapabilities,
+			protocol.CmdStatus,
+			protocol.CmdGetFile,
+			protocol.CmdSetBreakpoints,
+			protocol.CmdEcho,
+			protocol.CmdSubscribe,
+			protocol.CmdSetIgnoreCount,
 		}
 		for _, k := range kinds {
 			Expect(string(k)).NotTo(BeEmpty(), "CommandKind %q should not be empty", k)
@@ -656,6 +1340,54 @@ var _ = Describe("Sequence numbers", func() {
 	})
 })
 
+var _ = Describe("MarshalEvent", func() {
+	It("writes the same bytes json.Marshal would", func() {
+		epoch := time.Now()
+		stamped := protocol.MustEvent(protocol.EventGoroutineSnapshot, 7, protocol.GoroutineSnapshotPayload{
+			Trigger:    protocol.EventPaused,
+			Goroutines: []protocol.Goroutine{{ID: 1, Status: "waiting", WaitReason: "chan <- send & receive"}},
+		})
+		stamped.Stamp(epoch.Add(time.Second), epoch)
+		for _, e := range []protocol.Event{
+			stamped,
+			protocol.MustEvent(protocol.EventOutput, 1, protocol.OutputPayload{Content: "x"}),
+			{Version: protocol.Version, Kind: protocol.EventPaused, Seq: 2},
+		} {
+			want, err := json.Marshal(e)
+			Expect(err).NotTo(HaveOccurred())
+			got, err := protocol.MarshalEvent(e)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(got)).To(Equal(string(want)))
+		}
+	})
+})
+
+var _ = Describe("Timestamps", func() {
+	It("survive marshal/unmarshal once stamped", func() {
+		epoch := time.Now()
+		now := epoch.Add(1500 * time.Millisecond)
+		e := protocol.MustEvent(protocol.EventOutput, 1, protocol.OutputPayload{Content: "x"})
+		e.Stamp(now, epoch)
+
+		wire, err := protocol.MarshalEvent(e)
+		Expect(err).NotTo(HaveOccurred())
+		decoded, err := protocol.UnmarshalEvent(wire)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decoded.Time.Equal(now)).To(BeTrue())
+		Expect(decoded.Mono).To(Equal(1500 * time.Millisecond))
+	})
+
+	It("are omitted from the wire until stamped", func() {
+		e := protocol.MustEvent(protocol.EventOutput, 1, protocol.OutputPayload{Content: "x"})
+		wire, err := protocol.MarshalEvent(e)
+		Expect(err).NotTo(HaveOccurred())
+		var raw map[string]any
+		Expect(json.Unmarshal(wire, &raw)).To(Succeed())
+		Expect(raw).NotTo(HaveKey("time"))
+		Expect(raw).NotTo(HaveKey("mono"))
+	})
+})
+
 var _ = Describe("Version", func() {
 	It("is non-empty", func() {
 		Expect(protocol.Version).NotTo(BeEmpty())
@@ -667,4 +1399,38 @@ var _ = Describe("Version", func() {
 		decoded, _ := protocol.UnmarshalEvent(wire)
 		Expect(decoded.Version).To(Equal(protocol.Version))
 	})
+
+	DescribeTable("CompatibleVersion",
+		func(v string, want bool) {
+			Expect(protocol.CompatibleVersion(v)).To(Equal(want))
+		},
+		Entry("itself", protocol.Version, true),
+		Entry("a newer minor version", "1.9", true),
+		Entry("a bare major version", "1", true),
+		Entry("a peer that sent none", "", true),
+		Entry("another major version", "2.0", false),
+		Entry("a major that only shares a prefix", "10.0", false),
+	)
+})
+
+var _ = Describe("GoroutineCountCondition", func() {
+	DescribeTable("Holds",
+		func(op string, n int, want bool) {
+			Expect(protocol.GoroutineCountCondition{Op: op, Count: 10}.Holds(n)).To(Equal(want))
+		},
+		Entry("> above", ">", 11, true),
+		Entry("> at", ">", 10, false),
+		Entry(">= at", ">=", 10, true),
+		Entry("< below", "<", 9, true),
+		Entry("<= above", "<=", 11, false),
+		Entry("== at", "==", 10, true),
+		Entry("!= at", "!=", 10, false),
+		Entry("an unknown op", "=>", 11, false),
+	)
+
+	It("is valid only with a known op and a count of at least 0", func() {
+		Expect(protocol.GoroutineCountCondition{Op: ">=", Count: 0}.Valid()).To(BeTrue())
+		Expect(protocol.GoroutineCountCondition{Op: "=>", Count: 1}.Valid()).To(BeFalse())
+		Expect(protocol.GoroutineCountCondition{Op: ">", Count: -1}.Valid()).To(BeFalse())
+	})
 })
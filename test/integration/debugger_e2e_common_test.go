@@ -26,6 +26,7 @@ package integration
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -215,6 +216,85 @@ func main() {
 }
 `
 
+// spinTargetSrc never stops on its own: it spins without a breakpoint, a
+// sleep, or an exit, the hung CI target MaxRunTime is there to catch.
+const spinTargetSrc = `package main
+
+import (
+	"os"
+	"time"
+)
+
+var n int
+
+func main() {
+	// Safety net: self-exit if the debugger abandons us while running.
+	go func() { time.Sleep(180 * time.Second); os.Exit(0) }()
+	for {
+		n++
+	}
+}
+`
+
+// lateThreadsTargetSrc starts its workers only once main runs, long after
+// the launch stop where the spec sets its breakpoint. Each worker locks its
+// own OS thread and they all wait on one another before calling work, so the
+// breakpoint is hit on lateThreadsWorkers threads cloned after it was set.
+const lateThreadsWorkers = 8
+
+const lateThreadsTargetSrc = `package main
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var sink [8]int
+
+func work(i int) {
+	sink[i]++ // BP
+}
+
+func main() {
+	go func() { time.Sleep(180 * time.Second); os.Exit(0) }()
+	var ready, done sync.WaitGroup
+	release := make(chan struct{})
+	for i := 0; i < len(sink); i++ {
+		ready.Add(1)
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			runtime.LockOSThread()
+			ready.Done()
+			<-release
+			work(i)
+		}(i)
+	}
+	ready.Wait()
+	close(release)
+	done.Wait()
+}
+`
+
+// ptyTargetSrc reads one line from its terminal and answers it in upper case,
+// so the PTY spec can drive a full input → output round trip.
+const ptyTargetSrc = `package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Printf("GOT %s\n", strings.ToUpper(strings.TrimSpace(line)))
+}
+`
+
 // attachTargetSrc is the target for the Attach spec: a process the debugger did
 // NOT launch. It pins the main goroutine to the main OS thread. Attach on linux
 // only traces the single thread we PTRACE_ATTACH to (the main thread; the
@@ -255,6 +335,75 @@ func main() {
 }
 `
 
+// argsTargetSrc calls a function taking an int, a string, a bool and a
+// float64, which the Go ABI passes in RAX, RBX+RCX, RDI and X0. ARGS_ENTRY is
+// the function's first line, where they are still in those registers;
+// ARGS_BODY is past the prologue's spill, where DWARF has them on the stack.
+const argsTargetSrc = `package main
+
+import (
+	"os"
+	"time"
+)
+
+func gamma(n int, s string, ok bool, f float64) int { // ARGS_ENTRY
+	m := n + len(s) // ARGS_BODY
+	if ok && f > 0 {
+		m++
+	}
+	return m
+}
+
+func main() {
+	go func() { time.Sleep(180 * time.Second); os.Exit(0) }()
+	x := 0
+	for i := 0; i < 1000000; i++ {
+		x += gamma(40+i%5, "hello", true, 2.5)
+		time.Sleep(time.Millisecond)
+		_ = x
+	}
+}
+`
+
+// blockedTargetSrc parks goroutines on a channel receive, a select and a
+// held mutex before main reaches BLOCKED_BP, so a goroutine listing there
+// has one of each to name.
+const blockedTargetSrc = `package main
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+func checkpoint(i int) int {
+	return i * 2 // BLOCKED_BP
+}
+
+func main() {
+	go func() { time.Sleep(180 * time.Second); os.Exit(0) }()
+	ch := make(chan int)
+	other := make(chan int)
+	var mu sync.Mutex
+	mu.Lock()
+	go func() { <-ch }()
+	go func() {
+		select {
+		case <-ch:
+		case <-other:
+		}
+	}()
+	go func() { mu.Lock() }()
+	time.Sleep(200 * time.Millisecond)
+	x := 0
+	for i := 0; i < 1000000; i++ {
+		x += checkpoint(i)
+		time.Sleep(time.Millisecond)
+	}
+	_ = x
+}
+`
+
 // declareBasicStepOverSpec adds the continue+step-over acceptance spec to the
 // enclosing Ginkgo container. It is the correctness gate: set a breakpoint on a
 // line that calls a function, repeatedly Continue to it and StepOver the call,
@@ -500,6 +649,95 @@ func declareInspectSpec() {
 	})
 }
 
+// declareInspectArgsSpec asserts InspectArgs reads a function's arguments
+// both where the ABI passed them and where the function spilled them: it
+// stops on gamma's first line, then on its first body line, and checks the
+// values each time. The float arrives in X0, which the backend does not read,
+// so at the first stop it is only required to be present.
+func declareInspectArgsSpec() {
+	It("reads register- and stack-passed function arguments", Label("inspect"), func() {
+		bin := buildTarget("args_target", argsTargetSrc)
+		h := newE2EHarness(bin)
+		h.waitFor(15*time.Second, protocol.EventStepped) // initial launch stop
+
+		valueOf := func(p protocol.ArgsPayload, name string) string {
+			GinkgoHelper()
+			for _, v := range p.Args {
+				if v.Name == name {
+					return v.Value
+				}
+			}
+			Fail(fmt.Sprintf("no argument %q in %+v", name, p.Args))
+			return ""
+		}
+		stopAt := func(marker string) protocol.ArgsPayload {
+			GinkgoHelper()
+			bp, err := h.d.SetBreakpoint("args_target.go", markerLine(argsTargetSrc, marker))
+			Expect(err).NotTo(HaveOccurred(), "SetBreakpoint at %s", marker)
+			Expect(h.d.Continue()).To(Succeed())
+			evt := h.waitFor(15*time.Second,
+				protocol.EventBreakpointHit, protocol.EventProcessExited, protocol.EventError)
+			Expect(evt.Kind).To(Equal(protocol.EventBreakpointHit), "stopped at %s", marker)
+			Expect(h.d.ClearBreakpoint(bp.ID)).To(Succeed())
+			p, err := h.d.InspectArgs()
+			Expect(err).NotTo(HaveOccurred(), "InspectArgs at %s", marker)
+			Expect(p.Function).To(Equal("main.gamma"))
+			names := make([]string, 0, len(p.Args))
+			for _, v := range p.Args {
+				names = append(names, v.Name)
+			}
+			Expect(names).To(Equal([]string{"n", "s", "ok", "f"}), "arguments, results excluded")
+			return p
+		}
+
+		p := stopAt("// ARGS_ENTRY")
+		Expect(valueOf(p, "n")).To(BeElementOf("40", "41", "42", "43", "44"))
+		Expect(valueOf(p, "s")).To(Equal(`"hello"`))
+		Expect(valueOf(p, "ok")).To(Equal("true"))
+
+		p = stopAt("// ARGS_BODY")
+		Expect(valueOf(p, "n")).To(BeElementOf("40", "41", "42", "43", "44"))
+		Expect(valueOf(p, "s")).To(Equal(`"hello"`))
+		Expect(valueOf(p, "ok")).To(Equal("true"))
+		Expect(valueOf(p, "f")).To(Equal("2.5"))
+	})
+}
+
+// declareWaitReasonSpec asserts the goroutine listing reads what the
+// runtime recorded about each blocked goroutine: the names are the target
+// runtime's own, so they are compared as the runtime spells them.
+func declareWaitReasonSpec() {
+	It("names what each blocked goroutine waits on", Label("inspect"), func() {
+		bin := buildTarget("blocked_target", blockedTargetSrc)
+		h := newE2EHarness(bin)
+		h.waitFor(15*time.Second, protocol.EventStepped) // initial launch stop
+
+		_, err := h.d.SetBreakpoint("blocked_target.go", markerLine(blockedTargetSrc, "// BLOCKED_BP"))
+		Expect(err).NotTo(HaveOccurred(), "SetBreakpoint")
+		Expect(h.d.Continue()).To(Succeed())
+		evt := h.waitFor(15*time.Second,
+			protocol.EventBreakpointHit, protocol.EventProcessExited, protocol.EventError)
+		Expect(evt.Kind).To(Equal(protocol.EventBreakpointHit))
+		var hit protocol.BreakpointHitPayload
+		Expect(protocol.DecodeEventPayload(evt, &hit)).To(Succeed())
+		Expect(hit.Goroutine.ID).To(Equal(1), "main's goroutine hit the breakpoint")
+
+		grs, err := h.d.Goroutines()
+		Expect(err).NotTo(HaveOccurred(), "Goroutines")
+		Expect(grs).NotTo(BeEmpty())
+		Expect(grs[0].ID).To(Equal(1), "the stopped goroutine comes first")
+		Expect(grs[0].Status).To(Equal("running"))
+		Expect(grs[0].CurrentLoc.Function).To(Equal("main.checkpoint"))
+		reasons := make([]string, 0, len(grs))
+		for _, g := range grs {
+			if g.Status == "waiting" {
+				reasons = append(reasons, g.WaitReason)
+			}
+		}
+		Expect(reasons).To(ContainElements("chan receive", "select", "sync.Mutex.Lock"))
+	})
+}
+
 // declareClearBreakpointSpec asserts a cleared breakpoint stops firing. It sets
 // two breakpoints (A before B in the loop body), advances until it is stopped at
 // B, clears A (the non-current one — clearing the breakpoint the process is
@@ -520,6 +758,11 @@ func declareClearBreakpointSpec() {
 
 		bpA, err := h.d.SetBreakpoint("clearbp_target.go", lineA)
 		Expect(err).NotTo(HaveOccurred(), "SetBreakpoint A")
+		// A second set on A must not save A's trap as the original bytes:
+		// clearing A below would then leave the trap behind.
+		again, err := h.d.SetBreakpoint("clearbp_target.go", lineA)
+		Expect(errors.Is(err, debugger.ErrBreakpointExists)).To(BeTrue(), "second SetBreakpoint A: %v", err)
+		Expect(again.ID).To(Equal(bpA.ID), "second SetBreakpoint A reports the first")
 		_, err = h.d.SetBreakpoint("clearbp_target.go", lineB)
 		Expect(err).NotTo(HaveOccurred(), "SetBreakpoint B")
 
@@ -552,6 +795,82 @@ func declareClearBreakpointSpec() {
 	})
 }
 
+// declareIgnoreCountSpec asserts a breakpoint's ignore count lets exactly
+// that many hits go by. A (ignored) and B (not) alternate in the loop, so B's
+// stops count the iterations: A is due again only after three Bs.
+func declareIgnoreCountSpec() {
+	It("stops only after the ignored hits", Label("breakpoints"), func() {
+		lineA := markerLine(twoBPTargetSrc, "// BP_A")
+		lineB := markerLine(twoBPTargetSrc, "// BP_B")
+		bin := buildTarget("ignore_target", twoBPTargetSrc)
+
+		h := newE2EHarness(bin)
+		h.waitFor(15*time.Second, protocol.EventStepped) // initial launch stop
+
+		bpA, err := h.d.SetBreakpoint("ignore_target.go", lineA)
+		Expect(err).NotTo(HaveOccurred(), "SetBreakpoint A")
+		_, err = h.d.SetBreakpoint("ignore_target.go", lineB)
+		Expect(err).NotTo(HaveOccurred(), "SetBreakpoint B")
+		_, err = h.d.SetIgnoreCount(bpA.ID, 3)
+		Expect(err).NotTo(HaveOccurred(), "SetIgnoreCount")
+
+		stops := func(n int) []int {
+			GinkgoHelper()
+			lines := make([]int, 0, n)
+			for i := 0; i < n; i++ {
+				Expect(h.d.Continue()).To(Succeed(), "Continue #%d", i)
+				evt := h.waitFor(15*time.Second,
+					protocol.EventBreakpointHit, protocol.EventProcessExited, protocol.EventError)
+				Expect(evt.Kind).To(Equal(protocol.EventBreakpointHit), "stop #%d", i)
+				lines = append(lines, bpLine(evt))
+			}
+			return lines
+		}
+
+		Expect(stops(6)).To(Equal([]int{lineB, lineB, lineB, lineA, lineB, lineA}),
+			"A's first three hits go by; once the count is used up it stops every time")
+
+		// Set while parked on A: the step off A is not a hit, so the next
+		// one is the one skipped.
+		_, err = h.d.SetIgnoreCount(bpA.ID, 1)
+		Expect(err).NotTo(HaveOccurred(), "SetIgnoreCount while parked on A")
+		Expect(stops(4)).To(Equal([]int{lineB, lineB, lineA, lineB}))
+	})
+}
+
+// declareLateThreadsSpec adds the clone-inheritance spec. The breakpoint is
+// set at the launch stop, while the tracee is still a single thread; every
+// thread that later hits it was cloned afterwards and is only traced because
+// PTRACE_O_TRACECLONE carried over to it. A thread the tracer missed would
+// take the trap itself and die with "trace trap" instead of stopping. Linux
+// only: darwin catches the trap on any thread through its task exception port.
+func declareLateThreadsSpec() {
+	It("stops threads cloned after the breakpoint was set", Label("clone"), func() {
+		line := markerLine(lateThreadsTargetSrc, "// BP")
+		bin := buildTarget("late_threads_target", lateThreadsTargetSrc)
+
+		h := newE2EHarness(bin)
+		h.waitFor(15*time.Second, protocol.EventStepped) // initial launch stop
+
+		_, err := h.d.SetBreakpoint("late_threads_target.go", line)
+		Expect(err).NotTo(HaveOccurred(), "SetBreakpoint")
+
+		// Each worker calls work once, so exactly one hit per worker and
+		// then a clean exit means none of them got past the trap untraced.
+		for i := 0; i < lateThreadsWorkers; i++ {
+			Expect(h.d.Continue()).To(Succeed(), "Continue #%d", i)
+			evt := h.waitFor(20*time.Second,
+				protocol.EventBreakpointHit, protocol.EventProcessExited, protocol.EventError)
+			Expect(evt.Kind).To(Equal(protocol.EventBreakpointHit),
+				"Continue #%d expected BreakpointHit, got %s: %s", i, evt.Kind, evt.Payload)
+		}
+
+		Expect(h.d.Continue()).To(Succeed(), "final Continue")
+		evt := h.waitFor(20*time.Second, protocol.EventProcessExited, protocol.EventError)
+		Expect(evt.Kind).To(Equal(protocol.EventProcessExited), "got %s: %s", evt.Kind, evt.Payload)
+	})
+}
+
 // declareKillRunningSpec asserts Kill terminates a RUNNING tracee, not just a
 // suspended one. It Continues the process (so it is genuinely running, past the
 // launch stop), then Kills and asserts the engine tears down — proving Kill
@@ -651,6 +970,41 @@ func declareExitCodeSpec() {
 	})
 }
 
+// declarePTYSpec adds the PTY spec: a target launched with Options.PTY reads
+// its input from WriteInput and its output comes back as EventOutput on the
+// "tty" stream instead of going to the test's own stdout.
+func declarePTYSpec() {
+	It("runs the tracee on a pseudo-terminal", Label("pty"), func() {
+		bin := buildTarget("pty_target", ptyTargetSrc)
+
+		d := debugger.NewWithOptions(debugger.Options{PTY: true}, nil)
+		Expect(d.Launch(bin, nil, nil)).To(Succeed(), "Launch target on a PTY")
+		DeferCleanup(func() { _ = d.Kill() })
+		awaitEvent(d.Events(), 15*time.Second, protocol.EventStepped)
+
+		Expect(d.Continue()).To(Succeed())
+		Expect(d.WriteInput("hello\n")).To(Succeed())
+
+		var out strings.Builder
+		deadline := time.After(15 * time.Second)
+		for !strings.Contains(out.String(), "GOT HELLO") {
+			select {
+			case evt, ok := <-d.Events():
+				Expect(ok).To(BeTrue(), "events closed before the reply; got %q", out.String())
+				if evt.Kind != protocol.EventOutput {
+					continue
+				}
+				var p protocol.OutputPayload
+				Expect(json.Unmarshal(evt.Payload, &p)).To(Succeed())
+				Expect(p.Stream).To(Equal("tty"))
+				out.WriteString(p.Content)
+			case <-deadline:
+				Fail(fmt.Sprintf("TIMEOUT waiting for the tracee's reply; got %q", out.String()))
+			}
+		}
+	})
+}
+
 // declareAttachSpec adds the Attach acceptance spec — the only e2e that drives
 // Debugger.Attach against a process the debugger did NOT launch. It starts the
 // target independently, attaches by PID (linux PTRACE_ATTACH; darwin
@@ -51,12 +51,15 @@ var _ = Describe("Darwin arm64 debugger backend (Mach exceptions) E2E", Label("d
 	declareStepIntoSpec()
 	declareStepOutSpec()
 	declareInspectSpec()
+	declareWaitReasonSpec()
 	declareClearBreakpointSpec()
+	declareIgnoreCountSpec()
 	declareKillRunningSpec()
 	declareExitCodeSpec()
 	declareAttachSpec()
 	declareFullStackSpec()
 	declareRestartSpec()
+	declareRunTimeoutSpec()
 	declareDAPSpec()
 	declareDAPExitSpec()
 	declareDAPMultiClientSpec()
@@ -161,6 +161,29 @@ func declareRestartSpec() {
 	})
 }
 
+// declareRunTimeoutSpec adds the MaxRunTime acceptance spec: a target that
+// spins forever is paused by the hub once it has run for its limit, with the
+// RunTimeout event ahead of the Paused one.
+func declareRunTimeoutSpec() {
+	It("pauses a spinning target once it exceeds MaxRunTime", Label("runtimeout"), func() {
+		bin := buildTarget("spin_target", spinTargetSrc)
+		const limit = 500 * time.Millisecond
+
+		h := newFullStackHarnessWith(protocol.LaunchPayload{Program: bin, MaxRunTime: limit})
+
+		start := time.Now()
+		Expect(h.c.Continue()).To(Succeed(), "Continue into the spin")
+		evt := awaitEvent(h.c.Events(), 20*time.Second,
+			protocol.EventRunTimeout, protocol.EventPaused, protocol.EventProcessExited, protocol.EventError)
+		Expect(evt.Kind).To(Equal(protocol.EventRunTimeout), "got %s: %s", evt.Kind, evt.Payload)
+		Expect(time.Since(start)).To(BeNumerically(">=", limit), "timed out early")
+
+		evt = awaitEvent(h.c.Events(), 20*time.Second,
+			protocol.EventPaused, protocol.EventProcessExited, protocol.EventError)
+		Expect(evt.Kind).To(Equal(protocol.EventPaused), "got %s: %s", evt.Kind, evt.Payload)
+	})
+}
+
 // awaitEventFunc is awaitEvent with a per-event observer, so callers can assert
 // invariants (e.g. seq monotonicity) over every event drained, not just the one
 // that matches. onEach runs for each event before the kind match test.
@@ -203,6 +226,13 @@ type fullStackHarness struct {
 // context, which unblocks a suspended hub.
 func newFullStackHarness(bin string) *fullStackHarness {
 	GinkgoHelper()
+	return newFullStackHarnessWith(protocol.LaunchPayload{Program: bin})
+}
+
+// newFullStackHarnessWith is newFullStackHarness launching with launch's
+// options.
+func newFullStackHarnessWith(launch protocol.LaunchPayload) *fullStackHarness {
+	GinkgoHelper()
 
 	srv, addr := startTestServer()
 
@@ -228,7 +258,7 @@ func newFullStackHarness(bin string) *fullStackHarness {
 	// A launch-time failure (e.g. task_for_pid denied on a locked-down host)
 	// comes back asynchronously as an EventError, so surface it clearly instead
 	// of masquerading as a Stepped timeout.
-	Expect(c.Launch(bin, nil, nil)).To(Succeed(), "client.Launch")
+	Expect(c.LaunchWith(launch)).To(Succeed(), "client.LaunchWith")
 	evt := awaitEvent(c.Events(), 20*time.Second, protocol.EventStepped, protocol.EventError)
 	if evt.Kind == protocol.EventError {
 		var ep protocol.ErrorPayload
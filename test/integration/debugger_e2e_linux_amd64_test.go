@@ -14,12 +14,18 @@ var _ = Describe("Linux amd64 debugger backend (ptrace) E2E", Label("linux"), fu
 	declareStepIntoSpec()
 	declareStepOutSpec()
 	declareInspectSpec()
+	declareInspectArgsSpec()
+	declareWaitReasonSpec()
 	declareClearBreakpointSpec()
+	declareIgnoreCountSpec()
 	declareKillRunningSpec()
+	declareLateThreadsSpec()
 	declareExitCodeSpec()
 	declareAttachSpec()
+	declarePTYSpec()
 	declareFullStackSpec()
 	declareRestartSpec()
+	declareRunTimeoutSpec()
 	declareDAPSpec()
 	declareDAPExitSpec()
 	declareDAPMultiClientSpec()